@@ -0,0 +1,117 @@
+package advisory
+
+import (
+	"archive/zip"
+	"github.com/onsi/gomega"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(Compare("1.0", "1.0.0")).To(gomega.Equal(0))
+	g.Expect(Compare("v1.2.3", "1.2.3")).To(gomega.Equal(0))
+	g.Expect(Compare("1.2.10", "1.2.9")).To(gomega.Equal(1))
+	g.Expect(Compare("1.0.0-rc1", "1.0.0")).To(gomega.Equal(-1))
+	g.Expect(Compare("1.0.0", "1.0.0.Final")).To(gomega.Equal(0))
+	g.Expect(Compare("2.0.0-beta", "2.0.0-alpha")).To(gomega.Equal(1))
+	g.Expect(Compare("5.3.18", "5.3.20")).To(gomega.Equal(-1))
+	g.Expect(Compare("0", "0.0.1")).To(gomega.Equal(-1))
+}
+
+func TestMatch(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	affected := Affected{
+		Ranges: []Range{
+			{
+				Type: "ECOSYSTEM",
+				Events: []Event{
+					{Fixed: "5.2.20"},
+					{Introduced: "0"},
+					{Introduced: "5.3.0"},
+					{Fixed: "5.3.18"},
+				},
+			},
+			{
+				Type: "GIT",
+				Events: []Event{
+					{Introduced: "0"},
+				},
+			},
+		},
+		Versions: []string{"6.0.0-M1"},
+	}
+	g.Expect(affected.Match("5.2.19")).To(gomega.BeTrue())
+	g.Expect(affected.Match("5.2.20")).To(gomega.BeFalse())
+	g.Expect(affected.Match("5.3.17")).To(gomega.BeTrue())
+	g.Expect(affected.Match("5.3.18")).To(gomega.BeFalse())
+	g.Expect(affected.Match("6.0.0-M1")).To(gomega.BeTrue())
+	g.Expect(affected.Match("")).To(gomega.BeFalse())
+	lastAffected := Range{
+		Type: "SEMVER",
+		Events: []Event{
+			{Introduced: "1.0.0"},
+			{LastAffected: "1.4.2"},
+		},
+	}
+	g.Expect(lastAffected.Match("1.4.2")).To(gomega.BeTrue())
+	g.Expect(lastAffected.Match("1.4.3")).To(gomega.BeFalse())
+	g.Expect(lastAffected.Match("0.9")).To(gomega.BeFalse())
+}
+
+func TestKey(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(DepKey("java", "org.springframework.spring-core")).To(
+		gomega.Equal(Key("Maven", "org.springframework:spring-core")))
+	g.Expect(DepKey("go", "github.com/x/y")).To(
+		gomega.Equal(Key("Go", "github.com/x/y")))
+	g.Expect(DepKey("unknown", "x")).To(gomega.Equal(""))
+}
+
+func TestFeed(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	tmpDir, err := os.MkdirTemp("", "advisory-*")
+	g.Expect(err).To(gomega.BeNil())
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+	feed := Feed{Path: path.Join(tmpDir, "feed")}
+	digest, err := feed.Digest()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(digest).To(gomega.Equal(""))
+	err = os.Mkdir(feed.Path, 0755)
+	g.Expect(err).To(gomega.BeNil())
+	// json
+	err = os.WriteFile(
+		path.Join(feed.Path, "GHSA-1.json"),
+		[]byte(`{
+		  "id": "GHSA-1",
+		  "aliases": ["CVE-2022-22965"],
+		  "affected": [{"package": {"ecosystem": "Maven", "name": "a:b"}}],
+		  "database_specific": {"severity": "critical"}
+		}`),
+		0644)
+	g.Expect(err).To(gomega.BeNil())
+	// zip
+	f, err := os.Create(path.Join(feed.Path, "all.zip"))
+	g.Expect(err).To(gomega.BeNil())
+	writer := zip.NewWriter(f)
+	entry, _ := writer.Create("GHSA-2.json")
+	_, _ = entry.Write([]byte(`{"id": "GHSA-2", "severity": [{"type": "CVSS_V3", "score": "7.5"}]}`))
+	entry, _ = writer.Create("GHSA-3.json")
+	_, _ = entry.Write([]byte(`{"id": "GHSA-3", "withdrawn": "2023-01-01T00:00:00Z"}`))
+	_ = writer.Close()
+	_ = f.Close()
+	digest, err = feed.Digest()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(digest).ToNot(gomega.Equal(""))
+	list, err := feed.Load()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(list)).To(gomega.Equal(2))
+	g.Expect(list[0].ID).To(gomega.Equal("GHSA-1"))
+	g.Expect(list[0].Level()).To(gomega.Equal("CRITICAL"))
+	g.Expect(list[0].Affected[0].Key()).To(gomega.Equal(DepKey("java", "a.b")))
+	g.Expect(list[1].ID).To(gomega.Equal("GHSA-2"))
+	g.Expect(list[1].Level()).To(gomega.Equal("7.5"))
+}
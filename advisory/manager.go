@@ -0,0 +1,273 @@
+package advisory
+
+import (
+	"context"
+	"encoding/json"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/jortel/go-utils/logr"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/settings"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+const (
+	Unit = time.Minute
+)
+
+var (
+	Settings = &settings.Settings
+	Log      = logr.WithName("advisory")
+)
+
+//
+// refresh requested.
+var refresh = make(chan struct{}, 1)
+
+//
+// Refresh requests the feed be reloaded and all
+// dependencies re-matched.
+func Refresh() {
+	select {
+	case refresh <- struct{}{}:
+	default:
+	}
+}
+
+//
+// entry index entry.
+type entry struct {
+	id       uint
+	affected Affected
+}
+
+//
+// Manager provides advisory (vulnerability) management.
+// The offline feed is (re)loaded when changed and the tech
+// dependencies are matched against the advisories.
+type Manager struct {
+	// DB
+	DB *gorm.DB
+	// feed digest.
+	digest string
+	// index of affected packages.
+	index map[string][]entry
+	// highest dependency ID matched.
+	matched uint
+}
+
+//
+// Run the manager.
+func (m *Manager) Run(ctx context.Context) {
+	go func() {
+		Log.Info("Started.")
+		defer Log.Info("Died.")
+		forced := true
+		for {
+			err := m.update(forced)
+			if err != nil {
+				Log.Error(err, "")
+			}
+			d := Unit * time.Duration(Settings.Frequency.Advisory)
+			select {
+			case <-ctx.Done():
+				return
+			case <-refresh:
+				forced = true
+			case <-time.After(d):
+				forced = false
+			}
+		}
+	}()
+}
+
+//
+// update the advisories and matches.
+// When the feed has changed (or forced), the feed is loaded
+// and all dependencies are re-matched. Else, only dependencies
+// created since the last update are matched.
+func (m *Manager) update(forced bool) (err error) {
+	feed := Feed{Path: Settings.Advisory.Path}
+	digest, err := feed.Digest()
+	if err != nil {
+		return
+	}
+	if forced || digest != m.digest {
+		if digest != "" {
+			err = m.load(&feed)
+			if err != nil {
+				return
+			}
+		}
+		m.digest = digest
+		err = m.buildIndex()
+		if err != nil {
+			return
+		}
+		err = m.matchAll()
+		return
+	}
+	err = m.matchNew()
+	return
+}
+
+//
+// load the feed and update the advisories.
+// Advisories no longer in the feed are deleted.
+func (m *Manager) load(feed *Feed) (err error) {
+	list, err := feed.Load()
+	if err != nil {
+		return
+	}
+	err = m.DB.Transaction(func(tx *gorm.DB) (err error) {
+		var existing []model.Advisory
+		err = tx.Select("ID", "Key").Find(&existing).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		ids := make(map[string]uint)
+		for _, a := range existing {
+			ids[a.Key] = a.ID
+		}
+		seen := make(map[string]bool)
+		for i := range list {
+			osv := &list[i]
+			if seen[osv.ID] {
+				continue
+			}
+			seen[osv.ID] = true
+			a := &model.Advisory{}
+			a.ID = ids[osv.ID]
+			a.Key = osv.ID
+			a.Summary = osv.Summary
+			a.Severity = osv.Level()
+			a.Published = osv.Published
+			a.Modified = osv.Modified
+			a.Aliases, _ = json.Marshal(osv.Aliases)
+			a.Affected, _ = json.Marshal(osv.Affected)
+			a.References, _ = json.Marshal(osv.References)
+			err = tx.Save(a).Error
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+		}
+		for key, id := range ids {
+			if seen[key] {
+				continue
+			}
+			err = tx.Delete(&model.Advisory{}, id).Error
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+		}
+		return
+	})
+	if err != nil {
+		return
+	}
+	Log.Info("Feed loaded.", "path", feed.Path, "advisories", len(list))
+	return
+}
+
+//
+// buildIndex builds the index of affected packages.
+func (m *Manager) buildIndex() (err error) {
+	m.index = make(map[string][]entry)
+	var list []model.Advisory
+	err = m.DB.Select("ID", "Affected").Find(&list).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for i := range list {
+		a := &list[i]
+		var affected []Affected
+		_ = json.Unmarshal(a.Affected, &affected)
+		for _, af := range affected {
+			k := af.Key()
+			m.index[k] = append(
+				m.index[k],
+				entry{
+					id:       a.ID,
+					affected: af,
+				})
+		}
+	}
+	return
+}
+
+//
+// matchAll deletes all matches and matches all dependencies.
+func (m *Manager) matchAll() (err error) {
+	db := m.DB.Session(&gorm.Session{AllowGlobalUpdate: true})
+	err = db.Delete(&model.DependencyAdvisory{}).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	m.matched = 0
+	err = m.matchNew()
+	return
+}
+
+//
+// matchNew matches dependencies created since the last match.
+func (m *Manager) matchNew() (err error) {
+	var batch []model.TechDependency
+	db := m.DB.Select("ID", "Provider", "Name", "Version")
+	db = db.Where("ID > ?", m.matched)
+	db = db.Order("ID")
+	result := db.FindInBatches(
+		&batch,
+		1000,
+		func(tx *gorm.DB, n int) (err error) {
+			err = m.match(batch)
+			return
+		})
+	err = result.Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// match dependencies.
+func (m *Manager) match(list []model.TechDependency) (err error) {
+	matches := []model.DependencyAdvisory{}
+	for i := range list {
+		dep := &list[i]
+		if dep.ID > m.matched {
+			m.matched = dep.ID
+		}
+		k := DepKey(dep.Provider, dep.Name)
+		if k == "" {
+			continue
+		}
+		for _, e := range m.index[k] {
+			if e.affected.Match(dep.Version) {
+				matches = append(
+					matches,
+					model.DependencyAdvisory{
+						TechDependencyID: dep.ID,
+						AdvisoryID:       e.id,
+					})
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return
+	}
+	db := m.DB.Clauses(clause.OnConflict{DoNothing: true})
+	err = db.Create(&matches).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
@@ -0,0 +1,307 @@
+package advisory
+
+import (
+	"archive/zip"
+	"encoding/json"
+	liberr "github.com/jortel/go-utils/error"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//
+// OSV advisory.
+// See: https://ossf.github.io/osv-schema
+type OSV struct {
+	ID        string     `json:"id"`
+	Modified  time.Time  `json:"modified"`
+	Published time.Time  `json:"published"`
+	Withdrawn *time.Time `json:"withdrawn,omitempty"`
+	Aliases   []string   `json:"aliases,omitempty"`
+	Summary   string     `json:"summary,omitempty"`
+	Details   string     `json:"details,omitempty"`
+	Severity  []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity,omitempty"`
+	Affected   []Affected  `json:"affected,omitempty"`
+	References []Reference `json:"references,omitempty"`
+	Database   struct {
+		Severity string `json:"severity,omitempty"`
+	} `json:"database_specific,omitempty"`
+}
+
+//
+// Level returns the severity level.
+// The database (GHSA) severity is preferred over the score.
+func (r *OSV) Level() (level string) {
+	level = strings.ToUpper(r.Database.Severity)
+	if level != "" {
+		return
+	}
+	for _, s := range r.Severity {
+		level = s.Score
+		break
+	}
+	return
+}
+
+//
+// Affected package.
+type Affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges   []Range  `json:"ranges,omitempty"`
+	Versions []string `json:"versions,omitempty"`
+}
+
+//
+// Key returns the index key.
+func (r *Affected) Key() (k string) {
+	k = Key(r.Package.Ecosystem, r.Package.Name)
+	return
+}
+
+//
+// Match returns true when the version is affected.
+func (r *Affected) Match(version string) (matched bool) {
+	if version == "" {
+		return
+	}
+	for _, v := range r.Versions {
+		if Compare(version, v) == 0 {
+			matched = true
+			return
+		}
+	}
+	for i := range r.Ranges {
+		if r.Ranges[i].Match(version) {
+			matched = true
+			return
+		}
+	}
+	return
+}
+
+//
+// Range affected range.
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+//
+// Match returns true when the version is within the range.
+// Events are evaluated in version order: introduced (re)enters,
+// and fixed|last_affected leaves the range.
+// GIT (commit) ranges are not supported.
+func (r *Range) Match(version string) (matched bool) {
+	switch strings.ToUpper(r.Type) {
+	case "SEMVER", "ECOSYSTEM":
+	default:
+		return
+	}
+	events := make([]Event, len(r.Events))
+	copy(events, r.Events)
+	sort.SliceStable(
+		events,
+		func(i, j int) bool {
+			return Compare(events[i].version(), events[j].version()) < 0
+		})
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" || Compare(version, e.Introduced) >= 0 {
+				matched = true
+			}
+		case e.Fixed != "":
+			if Compare(version, e.Fixed) >= 0 {
+				matched = false
+			}
+		case e.LastAffected != "":
+			if Compare(version, e.LastAffected) > 0 {
+				matched = false
+			}
+		}
+	}
+	return
+}
+
+//
+// Event range event.
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+//
+// version returns the event version.
+func (r *Event) version() (v string) {
+	switch {
+	case r.Introduced != "":
+		v = r.Introduced
+	case r.Fixed != "":
+		v = r.Fixed
+	default:
+		v = r.LastAffected
+	}
+	return
+}
+
+//
+// Reference advisory reference.
+type Reference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+//
+// Feed offline OSV feed.
+// The path may be a directory, a JSON file or a zip (OSV export).
+// Directories are walked for JSON and zip files.
+type Feed struct {
+	Path string
+}
+
+//
+// Digest returns a fingerprint of the feed.
+// Based on file names, sizes and modification times.
+// An empty digest is returned when the feed does not exist.
+func (r *Feed) Digest() (digest string, err error) {
+	_, err = os.Stat(r.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	parts := []string{}
+	err = r.walk(func(path string, info fs.FileInfo) (err error) {
+		parts = append(
+			parts,
+			path,
+			info.ModTime().UTC().String(),
+			strconv.FormatInt(info.Size(), 10))
+		return
+	})
+	if err != nil {
+		return
+	}
+	digest = strings.Join(parts, "|")
+	return
+}
+
+//
+// Load the feed.
+// Withdrawn advisories are skipped.
+func (r *Feed) Load() (list []OSV, err error) {
+	err = r.walk(func(path string, info fs.FileInfo) (err error) {
+		var loaded []OSV
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			loaded, err = r.loadFile(path)
+		case ".zip":
+			loaded, err = r.loadZip(path)
+		}
+		list = append(list, loaded...)
+		return
+	})
+	return
+}
+
+//
+// loadFile loads a JSON file.
+func (r *Feed) loadFile(path string) (list []OSV, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	list, err = r.decode(f)
+	if err != nil {
+		err = liberr.Wrap(err, "path", path)
+		return
+	}
+	return
+}
+
+//
+// loadZip loads the JSON entries in a zip file.
+func (r *Feed) loadZip(path string) (list []OSV, err error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		_ = archive.Close()
+	}()
+	for _, entry := range archive.File {
+		if strings.ToLower(filepath.Ext(entry.Name)) != ".json" {
+			continue
+		}
+		var reader io.ReadCloser
+		reader, err = entry.Open()
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		var decoded []OSV
+		decoded, err = r.decode(reader)
+		_ = reader.Close()
+		if err != nil {
+			err = liberr.Wrap(err, "path", path, "entry", entry.Name)
+			return
+		}
+		list = append(list, decoded...)
+	}
+	return
+}
+
+//
+// decode an advisory.
+func (r *Feed) decode(reader io.Reader) (list []OSV, err error) {
+	osv := OSV{}
+	err = json.NewDecoder(reader).Decode(&osv)
+	if err != nil {
+		return
+	}
+	if osv.ID == "" || osv.Withdrawn != nil {
+		return
+	}
+	list = append(list, osv)
+	return
+}
+
+//
+// walk the feed files.
+func (r *Feed) walk(fn func(path string, info fs.FileInfo) error) (err error) {
+	err = filepath.Walk(
+		r.Path,
+		func(path string, info fs.FileInfo, wErr error) (err error) {
+			if wErr != nil {
+				err = liberr.Wrap(wErr)
+				return
+			}
+			if info.IsDir() {
+				return
+			}
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".json", ".zip":
+				err = fn(path, info)
+			}
+			return
+		})
+	return
+}
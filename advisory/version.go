@@ -0,0 +1,164 @@
+package advisory
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//
+// Ecosystems (OSV) by provider.
+var Ecosystems = map[string]string{
+	"java":       "Maven",
+	"go":         "Go",
+	"python":     "PyPI",
+	"nodejs":     "npm",
+	"javascript": "npm",
+	"dotnet":     "NuGet",
+	"csharp":     "NuGet",
+}
+
+//
+// Key returns the index key for an ecosystem and package name.
+// Maven names are normalized from: group:artifact to the
+// form reported by analysis: group.artifact.
+func Key(ecosystem, name string) (k string) {
+	if strings.EqualFold(ecosystem, "Maven") {
+		name = strings.ReplaceAll(name, ":", ".")
+	}
+	k = strings.ToLower(ecosystem + "|" + name)
+	return
+}
+
+//
+// DepKey returns the index key for a dependency.
+// Empty when the provider has no ecosystem.
+func DepKey(provider, name string) (k string) {
+	ecosystem, found := Ecosystems[strings.ToLower(provider)]
+	if !found {
+		return
+	}
+	k = Key(ecosystem, name)
+	return
+}
+
+//
+// Qualifiers equal to a release.
+var released = map[string]bool{
+	"final":   true,
+	"ga":      true,
+	"release": true,
+}
+
+//
+// Compare versions.
+// Returns: -1 (a < b), 0 (a == b), 1 (a > b).
+// Versions are split into numeric and alpha tokens which are
+// compared numerically and lexically. A trailing qualifier
+// (rc, beta, SNAPSHOT) sorts before the release.
+func Compare(a, b string) (n int) {
+	ta := tokens(a)
+	tb := tokens(b)
+	for i := 0; i < len(ta) || i < len(tb); i++ {
+		switch {
+		case i >= len(ta):
+			n = -trailing(tb[i])
+		case i >= len(tb):
+			n = trailing(ta[i])
+		default:
+			n = compareToken(ta[i], tb[i])
+		}
+		if n != 0 {
+			return
+		}
+	}
+	return
+}
+
+//
+// trailing returns the order of a version with the
+// additional (trailing) token relative to one without.
+func trailing(t string) (n int) {
+	if isNumeric(t) {
+		if t == "0" || strings.Trim(t, "0") == "" {
+			return
+		}
+		n = 1
+		return
+	}
+	if released[t] {
+		return
+	}
+	n = -1
+	return
+}
+
+//
+// compareToken compares tokens.
+// Numeric tokens are greater than alpha tokens.
+func compareToken(a, b string) (n int) {
+	na, aErr := strconv.ParseUint(a, 10, 64)
+	nb, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		switch {
+		case na < nb:
+			n = -1
+		case na > nb:
+			n = 1
+		}
+	case aErr == nil:
+		n = 1
+	case bErr == nil:
+		n = -1
+	default:
+		n = strings.Compare(a, b)
+	}
+	return
+}
+
+//
+// tokens splits the version.
+// Leading "v" and build metadata (+) are ignored.
+func tokens(version string) (list []string) {
+	version = strings.ToLower(strings.TrimSpace(version))
+	version = strings.TrimPrefix(version, "v")
+	version = strings.Split(version, "+")[0]
+	token := []rune{}
+	digit := false
+	flush := func() {
+		if len(token) > 0 {
+			list = append(list, string(token))
+			token = []rune{}
+		}
+	}
+	for _, r := range version {
+		switch {
+		case unicode.IsDigit(r):
+			if !digit {
+				flush()
+			}
+			digit = true
+			token = append(token, r)
+		case unicode.IsLetter(r):
+			if digit {
+				flush()
+			}
+			digit = false
+			token = append(token, r)
+		default:
+			flush()
+			digit = false
+		}
+	}
+	flush()
+	return
+}
+
+//
+// isNumeric returns true when the token is numeric.
+func isNumeric(t string) (b bool) {
+	_, err := strconv.ParseUint(t, 10, 64)
+	b = err == nil
+	return
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/advisory"
+	qf "github.com/konveyor/tackle2-hub/api/filter"
+	"github.com/konveyor/tackle2-hub/model"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//
+// Routes
+const (
+	AdvisoriesRoot        = "/advisories"
+	AdvisoryRoot          = AdvisoriesRoot + "/:" + ID
+	AdvisoriesRefreshRoot = AdvisoriesRoot + "/refresh"
+)
+
+//
+// AdvisoryHandler handles advisory routes.
+type AdvisoryHandler struct {
+	BaseHandler
+}
+
+//
+// AddRoutes adds routes.
+func (h AdvisoryHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("advisories"))
+	routeGroup.GET(AdvisoriesRoot, h.List)
+	routeGroup.GET(AdvisoriesRoot+"/", h.List)
+	routeGroup.GET(AdvisoryRoot, h.Get)
+	routeGroup.POST(AdvisoriesRefreshRoot, h.Refresh)
+}
+
+// Get godoc
+// @summary Get an advisory by ID.
+// @description Get an advisory by ID.
+// @tags advisories
+// @produce json
+// @success 200 {object} api.Advisory
+// @router /advisories/{id} [get]
+// @param id path int true "Advisory ID"
+func (h AdvisoryHandler) Get(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.Advisory{}
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r := Advisory{}
+	r.With(m)
+
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// List godoc
+// @summary List advisories.
+// @description List advisories.
+// @description filters:
+// @description - key
+// @description - severity
+// @tags advisories
+// @produce json
+// @success 200 {object} []api.Advisory
+// @router /advisories [get]
+func (h AdvisoryHandler) List(ctx *gin.Context) {
+	resources := []Advisory{}
+	// Filter
+	filter, err := qf.New(ctx,
+		[]qf.Assert{
+			{Field: "key", Kind: qf.STRING},
+			{Field: "severity", Kind: qf.STRING},
+		})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	// Sort
+	sort := Sort{}
+	err = sort.With(ctx, &model.Advisory{})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	// Find
+	db := h.DB(ctx)
+	db = db.Model(&model.Advisory{})
+	db = filter.Where(db)
+	db = sort.Sorted(db)
+	var list []model.Advisory
+	var m model.Advisory
+	page := Page{}
+	page.With(ctx)
+	cursor := Cursor{}
+	cursor.With(db, page)
+	defer func() {
+		cursor.Close()
+	}()
+	for cursor.Next(&m) {
+		if cursor.Error != nil {
+			_ = ctx.Error(cursor.Error)
+			return
+		}
+		list = append(list, m)
+	}
+	err = h.WithCount(ctx, cursor.Count())
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	// Render
+	for i := range list {
+		r := Advisory{}
+		r.With(&list[i])
+		resources = append(resources, r)
+	}
+
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+// Refresh godoc
+// @summary Refresh advisories.
+// @description Request the advisory feed be reloaded and all
+// @description dependencies re-matched. Performed asynchronously.
+// @tags advisories
+// @success 202
+// @router /advisories/refresh [post]
+func (h AdvisoryHandler) Refresh(ctx *gin.Context) {
+	advisory.Refresh()
+	h.Status(ctx, http.StatusAccepted)
+}
+
+//
+// Advisory REST resource.
+type Advisory struct {
+	Resource   `yaml:",inline"`
+	Key        string            `json:"key"`
+	Summary    string            `json:"summary,omitempty" yaml:",omitempty"`
+	Severity   string            `json:"severity,omitempty" yaml:",omitempty"`
+	Aliases    []string          `json:"aliases,omitempty" yaml:",omitempty"`
+	Affected   []AffectedPackage `json:"affected,omitempty" yaml:",omitempty"`
+	References []string          `json:"references,omitempty" yaml:",omitempty"`
+	Published  time.Time         `json:"published"`
+	Modified   time.Time         `json:"modified"`
+}
+
+//
+// With updates the resource with the model.
+func (r *Advisory) With(m *model.Advisory) {
+	r.Resource.With(&m.Model)
+	r.Key = m.Key
+	r.Summary = m.Summary
+	r.Severity = m.Severity
+	r.Published = m.Published
+	r.Modified = m.Modified
+	_ = json.Unmarshal(m.Aliases, &r.Aliases)
+	var affected []advisory.Affected
+	_ = json.Unmarshal(m.Affected, &affected)
+	for i := range affected {
+		p := AffectedPackage{}
+		p.With(&affected[i])
+		r.Affected = append(r.Affected, p)
+	}
+	var refs []advisory.Reference
+	_ = json.Unmarshal(m.References, &refs)
+	for _, ref := range refs {
+		r.References = append(r.References, ref.URL)
+	}
+}
+
+//
+// AffectedPackage REST resource.
+// Ranges are expressed as: >=introduced,<fixed|<=last_affected.
+type AffectedPackage struct {
+	Ecosystem string   `json:"ecosystem"`
+	Name      string   `json:"name"`
+	Ranges    []string `json:"ranges,omitempty" yaml:",omitempty"`
+	Versions  []string `json:"versions,omitempty" yaml:",omitempty"`
+}
+
+//
+// With updates the resource with the affected package.
+func (r *AffectedPackage) With(m *advisory.Affected) {
+	r.Ecosystem = m.Package.Ecosystem
+	r.Name = m.Package.Name
+	r.Versions = m.Versions
+	for _, rg := range m.Ranges {
+		var parts []string
+		for _, e := range rg.Events {
+			switch {
+			case e.Introduced != "":
+				if len(parts) > 0 {
+					r.Ranges = append(r.Ranges, strings.Join(parts, ","))
+				}
+				parts = []string{">=" + e.Introduced}
+			case e.Fixed != "":
+				parts = append(parts, "<"+e.Fixed)
+			case e.LastAffected != "":
+				parts = append(parts, "<="+e.LastAffected)
+			}
+		}
+		if len(parts) > 0 {
+			r.Ranges = append(r.Ranges, strings.Join(parts, ","))
+		}
+	}
+}
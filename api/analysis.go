@@ -8,6 +8,8 @@ import (
 	"github.com/gin-gonic/gin/binding"
 	qf "github.com/konveyor/tackle2-hub/api/filter"
 	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/quota"
+	"github.com/konveyor/tackle2-hub/sbom"
 	"github.com/konveyor/tackle2-hub/tar"
 	"gopkg.in/yaml.v2"
 	"gorm.io/gorm"
@@ -41,12 +43,15 @@ const (
 	AnalysisReportDepsAppsRoot   = AnalysisReportDepsRoot + "/applications"
 	AnalysisReportAppsIssuesRoot = AnalysisReportAppsRoot + "/:" + ID + "/issues"
 	AnalysisReportFileRoot       = AnalysisReportIssueRoot + "/files"
+	AnalysisReportAdvisoriesRoot = AnalysesReportRoot + "/advisories"
+	AnalysisReportPortfolioRoot  = AnalysesReportRoot + "/portfolio"
 	//
-	AppAnalysesRoot       = ApplicationRoot + "/analyses"
-	AppAnalysisRoot       = ApplicationRoot + "/analysis"
-	AppAnalysisReportRoot = AppAnalysisRoot + "/report"
-	AppAnalysisDepsRoot   = AppAnalysisRoot + "/dependencies"
-	AppAnalysisIssuesRoot = AppAnalysisRoot + "/issues"
+	AppAnalysesRoot           = ApplicationRoot + "/analyses"
+	AppAnalysisRoot           = ApplicationRoot + "/analysis"
+	AppAnalysisReportRoot     = AppAnalysisRoot + "/report"
+	AppAnalysisDepsRoot       = AppAnalysisRoot + "/dependencies"
+	AppAnalysisIssuesRoot     = AppAnalysisRoot + "/issues"
+	AppAnalysisAdvisoriesRoot = AppAnalysisRoot + "/advisories"
 )
 
 const (
@@ -66,27 +71,31 @@ func (h AnalysisHandler) AddRoutes(e *gin.Engine) {
 	// Primary
 	routeGroup := e.Group("/")
 	routeGroup.Use(Required("analyses"))
-	routeGroup.GET(AnalysisRoot, h.Get)
-	routeGroup.DELETE(AnalysisRoot, h.Delete)
+	routeGroup.GET(AnalysisRoot, OwnedAnalysis, h.Get)
+	routeGroup.DELETE(AnalysisRoot, OwnedAnalysis, h.Delete)
 	routeGroup.GET(AnalysesDepsRoot, h.Deps)
 	routeGroup.GET(AnalysesIssuesRoot, h.Issues)
-	routeGroup.GET(AnalysesIssueRoot, h.Issue)
-	routeGroup.GET(AnalysisIncidentsRoot, h.Incidents)
+	routeGroup.GET(AnalysesIssueRoot, OwnedIssue, h.Issue)
+	routeGroup.GET(AnalysisIncidentsRoot, OwnedIssue, h.Incidents)
 	routeGroup.GET(AnalysisReportRuleRoot, h.RuleReports)
-	routeGroup.GET(AnalysisReportAppsIssuesRoot, h.AppIssueReports)
+	routeGroup.GET(AnalysisReportAppsIssuesRoot, OwnedApplication, h.AppIssueReports)
 	routeGroup.GET(AnalysisReportIssuesAppsRoot, h.IssueAppReports)
-	routeGroup.GET(AnalysisReportFileRoot, h.FileReports)
+	routeGroup.GET(AnalysisReportFileRoot, OwnedIssue, h.FileReports)
 	routeGroup.GET(AnalysisReportDepsRoot, h.DepReports)
 	routeGroup.GET(AnalysisReportDepsAppsRoot, h.DepAppReports)
+	routeGroup.GET(AnalysisReportAdvisoriesRoot, h.AdvisoryReports)
+	routeGroup.GET(AnalysisReportPortfolioRoot, h.PortfolioReport)
 	// Application
 	routeGroup = e.Group("/")
-	routeGroup.Use(Required("applications.analyses"))
+	routeGroup.Use(Required("applications.analyses"), OwnedApplication)
 	routeGroup.POST(AppAnalysesRoot, h.AppCreate)
 	routeGroup.GET(AppAnalysesRoot, h.AppList)
 	routeGroup.GET(AppAnalysisRoot, h.AppLatest)
 	routeGroup.GET(AppAnalysisReportRoot, h.AppLatestReport)
 	routeGroup.GET(AppAnalysisDepsRoot, h.AppDeps)
+	routeGroup.POST(AppAnalysisDepsRoot, Transaction, h.AppImportDeps)
 	routeGroup.GET(AppAnalysisIssuesRoot, h.AppIssues)
+	routeGroup.GET(AppAnalysisAdvisoriesRoot, h.AppAdvisories)
 }
 
 // Get godoc
@@ -165,6 +174,48 @@ func (h AnalysisHandler) AppLatestReport(ctx *gin.Context) {
 	reportWriter.Write(m.ID)
 }
 
+// PortfolioReport godoc
+// @summary Get the portfolio (static) report.
+// @description Get the (static) report containing the latest analysis
+// @description of each of the selected applications.
+// @description filters:
+// @description - application.id
+// @description - application.name
+// @description - businessService.id
+// @description - businessService.name
+// @description - tag.id
+// @tags analyses
+// @produce octet-stream
+// @success 200
+// @router /analyses/report/portfolio [get]
+func (h AnalysisHandler) PortfolioReport(ctx *gin.Context) {
+	filter, err := qf.New(ctx,
+		[]qf.Assert{
+			{Field: "application.id", Kind: qf.LITERAL},
+			{Field: "application.name", Kind: qf.STRING},
+			{Field: "businessService.id", Kind: qf.LITERAL},
+			{Field: "businessService.name", Kind: qf.STRING},
+			{Field: "tag.id", Kind: qf.LITERAL, And: true},
+		})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	var ids []uint
+	db := h.DB(ctx)
+	db = db.Model(&model.Analysis{})
+	db = db.Where("ID IN (?)", h.analysisIDs(ctx, filter))
+	db = db.Order("ApplicationID")
+	err = db.Pluck("ID", &ids).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	h.Attachment(ctx, "portfolio.tar.gz")
+	reportWriter := ReportWriter{ctx: ctx}
+	reportWriter.Write(ids...)
+}
+
 // AppList godoc
 // @summary List analyses.
 // @description List analyses for an application.
@@ -226,6 +277,11 @@ func (h AnalysisHandler) AppList(ctx *gin.Context) {
 // @description   - file: file that contains the api.Analysis resource.
 // @description   - issues: file that multiple api.Issue resources.
 // @description   - dependencies: file that multiple api.TechDependency resources.
+// @description     May be an SBOM when the encoding is CycloneDX or SPDX (JSON).
+// @description The analysis references the bucket snapshot it ran against. When not
+// @description specified, the application bucket is snapshot when created by a task and
+// @description automatic snapshots (BUCKET_SNAPSHOT) are enabled.
+// @description When created by a task, the ruleSet revisions pinned by the task are recorded.
 // @tags analyses
 // @produce json
 // @success 201 {object} api.Analysis
@@ -233,7 +289,8 @@ func (h AnalysisHandler) AppList(ctx *gin.Context) {
 // @param id path int true "Application ID"
 func (h AnalysisHandler) AppCreate(ctx *gin.Context) {
 	id := h.pk(ctx)
-	result := h.DB(ctx).First(&model.Application{}, id)
+	application := &model.Application{}
+	result := h.DB(ctx).First(application, id)
 	if result.Error != nil {
 		_ = ctx.Error(result.Error)
 		return
@@ -284,6 +341,19 @@ func (h AnalysisHandler) AppCreate(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	r.RuleSets, err = h.ruleSets(ctx, r.RuleSets)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	if r.RuleSets != nil {
+		analysis.RuleSets, _ = json.Marshal(r.RuleSets)
+	}
+	analysis.SnapshotID, err = h.snapshot(ctx, application, r.Snapshot)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	//
 	// Issues
 	input, err = ctx.FormFile(IssueField)
@@ -347,31 +417,39 @@ func (h AnalysisHandler) AppCreate(ctx *gin.Context) {
 		_ = reader.Close()
 	}()
 	encoding = input.Header.Get(ContentType)
-	d, err = h.Decoder(ctx, encoding, reader)
-	if err != nil {
-		err = &BadRequestError{err.Error()}
-		_ = ctx.Error(err)
-		return
-	}
-	for {
-		r := &TechDependency{}
-		err = d.Decode(r)
+	if sbom.Supported(encoding) {
+		err = h.importSBOM(db, analysis.ID, encoding, reader)
 		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			} else {
-				err = &BadRequestError{err.Error()}
-				_ = ctx.Error(err)
-				return
-			}
+			_ = ctx.Error(err)
+			return
 		}
-		m := r.Model()
-		m.AnalysisID = analysis.ID
-		err = db.Create(m).Error
+	} else {
+		d, err = h.Decoder(ctx, encoding, reader)
 		if err != nil {
+			err = &BadRequestError{err.Error()}
 			_ = ctx.Error(err)
 			return
 		}
+		for {
+			r := &TechDependency{}
+			err = d.Decode(r)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				} else {
+					err = &BadRequestError{err.Error()}
+					_ = ctx.Error(err)
+					return
+				}
+			}
+			m := r.Model()
+			m.AnalysisID = analysis.ID
+			err = db.Create(m).Error
+			if err != nil {
+				_ = ctx.Error(err)
+				return
+			}
+		}
 	}
 	//
 	// Update effort.
@@ -427,8 +505,11 @@ func (h AnalysisHandler) Delete(ctx *gin.Context) {
 // @description - sha
 // @description - indirect
 // @description - labels
+// @description An SBOM is returned when CycloneDX or SPDX (JSON) is accepted.
 // @tags dependencies
 // @produce json
+// @produce application/vnd.cyclonedx+json
+// @produce application/spdx+json
 // @success 200 {object} []api.TechDependency
 // @router /application/{id}/analysis/dependencies [get]
 // @param id path int true "Application ID"
@@ -468,6 +549,10 @@ func (h AnalysisHandler) AppDeps(ctx *gin.Context) {
 	db = db.Where("AnalysisID = ?", analysis.ID)
 	db = db.Where("ID IN (?)", h.depIDs(ctx, filter))
 	db = sort.Sorted(db)
+	if h.Accepted(ctx, sbom.MIMEs...) {
+		h.exportSBOM(ctx, id, db)
+		return
+	}
 	var list []model.TechDependency
 	var m model.TechDependency
 	page := Page{}
@@ -495,10 +580,89 @@ func (h AnalysisHandler) AppDeps(ctx *gin.Context) {
 		r.With(&list[i])
 		resources = append(resources, r)
 	}
+	err = h.depAdvisories(ctx, resources)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 
 	h.Respond(ctx, http.StatusOK, resources)
 }
 
+// AppImportDeps godoc
+// @summary Import application dependencies.
+// @description Create an analysis with dependencies imported from an SBOM.
+// @description Intended for applications analyzed elsewhere. Prior analyses are archived.
+// @description Form fields:
+// @description   - file: CycloneDX or SPDX (JSON) document. The encoding is
+// @description     determined by the part Content-Type.
+// @tags dependencies
+// @accept multipart/form-data
+// @produce json
+// @success 201 {object} api.Analysis
+// @router /application/{id}/analysis/dependencies [post]
+// @param id path int true "Application ID"
+func (h AnalysisHandler) AppImportDeps(ctx *gin.Context) {
+	id := h.pk(ctx)
+	result := h.DB(ctx).First(&model.Application{}, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	input, err := ctx.FormFile(FileField)
+	if err != nil {
+		err = &BadRequestError{err.Error()}
+		_ = ctx.Error(err)
+		return
+	}
+	reader, err := input.Open()
+	if err != nil {
+		err = &BadRequestError{err.Error()}
+		_ = ctx.Error(err)
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	encoding := input.Header.Get(ContentType)
+	if !sbom.Supported(encoding) {
+		err = &BadRequestError{"SBOM: MIME not supported."}
+		_ = ctx.Error(err)
+		return
+	}
+	err = h.archive(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	analysis := &model.Analysis{}
+	analysis.ApplicationID = id
+	analysis.CreateUser = h.BaseHandler.CurrentUser(ctx)
+	db := h.DB(ctx)
+	db.Logger = db.Logger.LogMode(logger.Error)
+	err = db.Create(analysis).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	err = h.importSBOM(db, analysis.ID, encoding, reader)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	db = h.DB(ctx)
+	db = db.Preload(clause.Associations)
+	err = db.First(analysis).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := Analysis{}
+	r.With(analysis)
+
+	h.Respond(ctx, http.StatusCreated, r)
+}
+
 // AppIssues godoc
 // @summary List application issues.
 // @description List application issues.
@@ -1343,6 +1507,11 @@ func (h AnalysisHandler) Deps(ctx *gin.Context) {
 		r.With(&list[i])
 		resources = append(resources, r)
 	}
+	err = h.depAdvisories(ctx, resources)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 
 	h.Respond(ctx, http.StatusOK, resources)
 }
@@ -1366,6 +1535,7 @@ func (h AnalysisHandler) Deps(ctx *gin.Context) {
 // @description - provider
 // @description - name
 // @description - labels
+// @description - advisories
 // @tags dependencies
 // @produce json
 // @success 200 {object} []api.TechDependency
@@ -1375,6 +1545,7 @@ func (h AnalysisHandler) DepReports(ctx *gin.Context) {
 	type M struct {
 		model.TechDependency
 		Applications int
+		Advisories   int
 	}
 	// Filter
 	filter, err := qf.New(ctx,
@@ -1409,9 +1580,11 @@ func (h AnalysisHandler) DepReports(ctx *gin.Context) {
 		"d.Provider",
 		"d.Name",
 		"json_group_array(distinct j.value) Labels",
-		"COUNT(distinct d.AnalysisID) Applications")
+		"COUNT(distinct d.AnalysisID) Applications",
+		"COUNT(distinct da.AdvisoryID) Advisories")
 	q = q.Table("TechDependency d")
 	q = q.Joins(",json_each(Labels) j")
+	q = q.Joins("LEFT JOIN DependencyAdvisory da ON da.TechDependencyID = d.ID")
 	q = q.Where("d.AnalysisID IN (?)", h.analysisIDs(ctx, filter))
 	q = q.Where("d.ID IN (?)", h.depIDs(ctx, filter))
 	q = q.Group("d.Provider, d.Name")
@@ -1448,6 +1621,7 @@ func (h AnalysisHandler) DepReports(ctx *gin.Context) {
 			Provider:     m.Provider,
 			Name:         m.Name,
 			Applications: m.Applications,
+			Advisories:   m.Advisories,
 		}
 		if m.Labels != nil {
 			var aggregated []string
@@ -1496,6 +1670,7 @@ func (h AnalysisHandler) DepReports(ctx *gin.Context) {
 // @description - version
 // @description - sha
 // @description - indirect
+// @description - advisories
 // @tags depappreports
 // @produce json
 // @success 200 {object} []api.DepAppReport
@@ -1514,6 +1689,7 @@ func (h AnalysisHandler) DepAppReports(ctx *gin.Context) {
 		SHA             string
 		Indirect        bool
 		Labels          model.JSON
+		Advisories      int
 	}
 	// Filter
 	filter, err := qf.New(ctx,
@@ -1563,7 +1739,8 @@ func (h AnalysisHandler) DepAppReports(ctx *gin.Context) {
 		"d.Version",
 		"d.SHA",
 		"d.Indirect",
-		"d.Labels")
+		"d.Labels",
+		"(SELECT COUNT(*) FROM DependencyAdvisory da WHERE da.TechDependencyID = d.ID) Advisories")
 	q = q.Table("TechDependency d")
 	q = q.Joins("LEFT JOIN Analysis a ON a.ID = d.AnalysisID")
 	q = q.Joins("LEFT JOIN Application app ON app.ID = a.ApplicationID")
@@ -1611,6 +1788,7 @@ func (h AnalysisHandler) DepAppReports(ctx *gin.Context) {
 		r.Dependency.Version = m.Version
 		r.Dependency.SHA = m.SHA
 		r.Dependency.Indirect = m.Indirect
+		r.Dependency.Advisories = m.Advisories
 		if m.Labels != nil {
 			_ = json.Unmarshal(m.Labels, &r.Dependency.Labels)
 		}
@@ -1620,6 +1798,288 @@ func (h AnalysisHandler) DepAppReports(ctx *gin.Context) {
 	h.Respond(ctx, http.StatusOK, resources)
 }
 
+// AppAdvisories godoc
+// @summary List application advisories.
+// @description List advisories matched to the dependencies of
+// @description the latest analysis. One report per advisory and dependency.
+// @description filters:
+// @description - key
+// @description - severity
+// @description - dep.provider
+// @description - dep.name
+// @description - dep.version
+// @description - dep.indirect
+// @tags advisories
+// @produce json
+// @success 200 {object} []api.AppAdvisoryReport
+// @router /application/{id}/analysis/advisories [get]
+// @param id path int true "Application ID"
+func (h AnalysisHandler) AppAdvisories(ctx *gin.Context) {
+	resources := []AppAdvisoryReport{}
+	type M struct {
+		ID       uint
+		Key      string
+		Summary  string
+		Severity string
+		Aliases  model.JSON
+		DepID    uint
+		Provider string
+		DepName  string
+		Version  string
+		Indirect bool
+	}
+	// Latest
+	id := h.pk(ctx)
+	analysis := &model.Analysis{}
+	db := h.DB(ctx)
+	db = db.Where("ApplicationID = ?", id)
+	result := db.Last(analysis)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	// Filter
+	filter, err := qf.New(ctx,
+		[]qf.Assert{
+			{Field: "key", Kind: qf.STRING},
+			{Field: "severity", Kind: qf.STRING},
+			{Field: "dep.provider", Kind: qf.STRING},
+			{Field: "dep.name", Kind: qf.STRING},
+			{Field: "dep.version", Kind: qf.STRING},
+			{Field: "dep.indirect", Kind: qf.STRING},
+		})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	// Sort
+	sort := Sort{}
+	err = sort.With(ctx, &M{})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	// Inner Query
+	q := h.DB(ctx)
+	q = q.Select(
+		"a.ID",
+		"a.Key",
+		"a.Summary",
+		"a.Severity",
+		"a.Aliases",
+		"d.ID DepID",
+		"d.Provider",
+		"d.Name DepName",
+		"d.Version",
+		"d.Indirect")
+	q = q.Table("DependencyAdvisory da")
+	q = q.Joins("JOIN Advisory a ON a.ID = da.AdvisoryID")
+	q = q.Joins("JOIN TechDependency d ON d.ID = da.TechDependencyID")
+	q = q.Where("d.AnalysisID = ?", analysis.ID)
+	q = q.Where("d.ID IN (?)", h.depIDs(ctx, filter.Resource("dep")))
+	// Find
+	db = h.DB(ctx)
+	db = db.Select("*")
+	db = db.Table("(?)", q)
+	db = filter.Where(db)
+	db = sort.Sorted(db)
+	var list []M
+	var m M
+	page := Page{}
+	page.With(ctx)
+	cursor := Cursor{}
+	cursor.With(db, page)
+	defer func() {
+		cursor.Close()
+	}()
+	for cursor.Next(&m) {
+		if cursor.Error != nil {
+			_ = ctx.Error(cursor.Error)
+			return
+		}
+		list = append(list, m)
+	}
+	err = h.WithCount(ctx, cursor.Count())
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	// Render
+	for i := range list {
+		m := &list[i]
+		r := AppAdvisoryReport{}
+		r.ID = m.ID
+		r.Key = m.Key
+		r.Summary = m.Summary
+		r.Severity = m.Severity
+		_ = json.Unmarshal(m.Aliases, &r.Aliases)
+		r.Dependency.ID = m.DepID
+		r.Dependency.Provider = m.Provider
+		r.Dependency.Name = m.DepName
+		r.Dependency.Version = m.Version
+		r.Dependency.Indirect = m.Indirect
+		resources = append(resources, r)
+	}
+
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+// AdvisoryReports godoc
+// @summary List advisory reports.
+// @description Each report collates the applications and dependencies
+// @description (of the latest analyses) matched to an advisory.
+// @description filters:
+// @description - key
+// @description - severity
+// @description - applications
+// @description - dependencies
+// @description - application.id
+// @description - application.name
+// @description - businessService.id
+// @description - businessService.name
+// @description - tag.id
+// @description sort:
+// @description - key
+// @description - severity
+// @description - applications
+// @description - dependencies
+// @tags advisories
+// @produce json
+// @success 200 {object} []api.AdvisoryReport
+// @router /analyses/report/advisories [get]
+func (h AnalysisHandler) AdvisoryReports(ctx *gin.Context) {
+	resources := []AdvisoryReport{}
+	type M struct {
+		ID           uint
+		Key          string
+		Summary      string
+		Severity     string
+		Aliases      model.JSON
+		Applications int
+		Dependencies int
+	}
+	// Filter
+	filter, err := qf.New(ctx,
+		[]qf.Assert{
+			{Field: "key", Kind: qf.STRING},
+			{Field: "severity", Kind: qf.STRING},
+			{Field: "applications", Kind: qf.LITERAL},
+			{Field: "dependencies", Kind: qf.LITERAL},
+			{Field: "application.id", Kind: qf.LITERAL},
+			{Field: "application.name", Kind: qf.STRING},
+			{Field: "businessService.id", Kind: qf.LITERAL},
+			{Field: "businessService.name", Kind: qf.STRING},
+			{Field: "tag.id", Kind: qf.LITERAL, And: true},
+		})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	// Sort
+	sort := Sort{}
+	err = sort.With(ctx, &M{})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	// Inner Query
+	q := h.DB(ctx)
+	q = q.Select(
+		"a.ID",
+		"a.Key",
+		"a.Summary",
+		"a.Severity",
+		"a.Aliases",
+		"COUNT(distinct d.AnalysisID) Applications",
+		"COUNT(distinct d.ID) Dependencies")
+	q = q.Table("DependencyAdvisory da")
+	q = q.Joins("JOIN Advisory a ON a.ID = da.AdvisoryID")
+	q = q.Joins("JOIN TechDependency d ON d.ID = da.TechDependencyID")
+	q = q.Where("d.AnalysisID IN (?)", h.analysisIDs(ctx, filter))
+	q = q.Group("a.ID")
+	// Find
+	db := h.DB(ctx)
+	db = db.Select("*")
+	db = db.Table("(?)", q)
+	db = filter.Where(db)
+	db = sort.Sorted(db)
+	var list []M
+	var m M
+	page := Page{}
+	page.With(ctx)
+	cursor := Cursor{}
+	cursor.With(db, page)
+	defer func() {
+		cursor.Close()
+	}()
+	for cursor.Next(&m) {
+		if cursor.Error != nil {
+			_ = ctx.Error(cursor.Error)
+			return
+		}
+		list = append(list, m)
+	}
+	err = h.WithCount(ctx, cursor.Count())
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	// Render
+	for i := range list {
+		m := &list[i]
+		r := AdvisoryReport{}
+		r.ID = m.ID
+		r.Key = m.Key
+		r.Summary = m.Summary
+		r.Severity = m.Severity
+		r.Applications = m.Applications
+		r.Dependencies = m.Dependencies
+		_ = json.Unmarshal(m.Aliases, &r.Aliases)
+		resources = append(resources, r)
+	}
+
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+//
+// depAdvisories populates the advisories matched to the dependencies.
+func (h *AnalysisHandler) depAdvisories(ctx *gin.Context, resources []TechDependency) (err error) {
+	type M struct {
+		TechDependencyID uint
+		ID               uint
+		Key              string
+	}
+	ids := []uint{}
+	for i := range resources {
+		ids = append(ids, resources[i].ID)
+	}
+	if len(ids) == 0 {
+		return
+	}
+	var list []M
+	db := h.DB(ctx)
+	db = db.Select("da.TechDependencyID", "a.ID", "a.Key")
+	db = db.Table("DependencyAdvisory da")
+	db = db.Joins("JOIN Advisory a ON a.ID = da.AdvisoryID")
+	db = db.Where("da.TechDependencyID IN ?", ids)
+	db = db.Order("a.Key")
+	err = db.Find(&list).Error
+	if err != nil {
+		return
+	}
+	matched := make(map[uint][]Ref)
+	for _, m := range list {
+		matched[m.TechDependencyID] = append(
+			matched[m.TechDependencyID],
+			Ref{ID: m.ID, Name: m.Key})
+	}
+	for i := range resources {
+		r := &resources[i]
+		r.Advisories = matched[r.ID]
+	}
+	return
+}
+
 //
 // appIDs provides application IDs.
 // filter:
@@ -1629,6 +2089,8 @@ func (h *AnalysisHandler) appIDs(ctx *gin.Context, f qf.Filter) (q *gorm.DB) {
 	q = h.DB(ctx)
 	q = q.Model(&model.Application{})
 	q = q.Select("ID")
+	ownership := Ownership{ctx: ctx}
+	q = ownership.Where(q, "ID")
 	appFilter := f.Resource("application")
 	q = appFilter.Where(q)
 	tagFilter := f.Resource("tag")
@@ -1748,6 +2210,99 @@ func (h *AnalysisHandler) depIDs(ctx *gin.Context, f qf.Filter) (q *gorm.DB) {
 	return
 }
 
+//
+// ruleSets returns the (pinned) ruleSet revisions the analysis ran with.
+// When created by a task, the revisions pinned by the task are recorded
+// and the revisions reported by the addon are added for other ruleSets.
+func (h *AnalysisHandler) ruleSets(ctx *gin.Context, reported []RevisionRef) (refs []RevisionRef, err error) {
+	db := h.DB(ctx)
+	err = pin(db, reported)
+	if err != nil {
+		return
+	}
+	id := h.CurrentTask(ctx)
+	if id == 0 {
+		refs = reported
+		return
+	}
+	task := &model.Task{}
+	err = db.First(task, id).Error
+	if err != nil {
+		return
+	}
+	if task.RuleSets != nil {
+		err = json.Unmarshal(task.RuleSets, &refs)
+		if err != nil {
+			return
+		}
+	}
+	pinned := make(map[uint]bool)
+	for _, ref := range refs {
+		pinned[ref.ID] = true
+	}
+	for _, ref := range reported {
+		if !pinned[ref.ID] {
+			refs = append(refs, ref)
+		}
+	}
+	return
+}
+
+//
+// snapshot returns the bucket snapshot the analysis ran against.
+// When not referenced, the application bucket is snapshot when
+// the analysis is created by a task and automatic snapshots
+// are enabled.
+func (h *AnalysisHandler) snapshot(ctx *gin.Context, application *model.Application, ref *Ref) (id *uint, err error) {
+	if !application.HasBucket() {
+		return
+	}
+	if ref != nil {
+		m := &model.BucketSnapshot{}
+		err = h.DB(ctx).First(m, ref.ID).Error
+		if err != nil {
+			return
+		}
+		if m.BucketID != *application.BucketID {
+			err = &BadRequestError{Reason: "snapshot not of the application bucket."}
+			return
+		}
+		id = &m.ID
+		return
+	}
+	if !Settings.Hub.Bucket.Snapshot {
+		return
+	}
+	if h.CurrentTask(ctx) == 0 {
+		return
+	}
+	bucket := &model.Bucket{}
+	err = h.DB(ctx).First(bucket, *application.BucketID).Error
+	if err != nil {
+		return
+	}
+	size, err := quota.Size(bucket.Path)
+	if err != nil || size == 0 {
+		return
+	}
+	owner := BucketOwner{BaseHandler: h.BaseHandler}
+	m, err := owner.snapshot(ctx, bucket, SnapshotAnalysis)
+	if err != nil {
+		if errors.Is(err, &quota.Exceeded{}) {
+			Log.Info(
+				"Snapshot skipped.",
+				"bucket",
+				bucket.ID,
+				"reason",
+				err.Error())
+			err = nil
+		}
+		return
+	}
+	id = &m.ID
+	return
+}
+
 //
 // archive
 // - Set the 'archived' flag.
@@ -1810,6 +2365,74 @@ func (h *AnalysisHandler) archive(ctx *gin.Context) (err error) {
 	return
 }
 
+//
+// importSBOM creates the dependencies described by the SBOM.
+func (h *AnalysisHandler) importSBOM(db *gorm.DB, id uint, encoding string, reader io.Reader) (err error) {
+	codec, err := sbom.New(encoding)
+	if err != nil {
+		err = &BadRequestError{err.Error()}
+		return
+	}
+	deps, err := codec.Decode(reader)
+	if err != nil {
+		err = &BadRequestError{err.Error()}
+		return
+	}
+	for i := range deps {
+		r := TechDependency{}
+		r.WithSBOM(&deps[i])
+		m := r.Model()
+		m.AnalysisID = id
+		err = db.Create(m).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+//
+// exportSBOM renders the selected dependencies as an SBOM
+// in the accepted format.
+func (h *AnalysisHandler) exportSBOM(ctx *gin.Context, id uint, db *gorm.DB) {
+	application := &model.Application{}
+	err := h.DB(ctx).First(application, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	var list []model.TechDependency
+	err = db.Find(&list).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	deps := []sbom.Dependency{}
+	for i := range list {
+		r := TechDependency{}
+		r.With(&list[i])
+		deps = append(deps, r.SBOM())
+	}
+	var mime string
+	for _, mime = range sbom.MIMEs {
+		if h.Accepted(ctx, mime) {
+			break
+		}
+	}
+	codec, err := sbom.New(mime)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	b := bytes.Buffer{}
+	err = codec.Encode(&b, application.Name, deps)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.Data(http.StatusOK, mime, b.Bytes())
+}
+
 //
 // Analysis REST resource.
 type Analysis struct {
@@ -1819,6 +2442,8 @@ type Analysis struct {
 	Issues       []Issue          `json:"issues,omitempty" yaml:",omitempty"`
 	Dependencies []TechDependency `json:"dependencies,omitempty" yaml:",omitempty"`
 	Summary      []ArchivedIssue  `json:"summary,omitempty" yaml:",omitempty" swaggertype:"object"`
+	RuleSets     []RevisionRef    `json:"ruleSets,omitempty" yaml:"ruleSets,omitempty"`
+	Snapshot     *Ref             `json:"snapshot,omitempty" yaml:",omitempty"`
 }
 
 //
@@ -1846,6 +2471,10 @@ func (r *Analysis) With(m *model.Analysis) {
 	if m.Summary != nil {
 		_ = json.Unmarshal(m.Summary, &r.Summary)
 	}
+	if m.RuleSets != nil {
+		_ = json.Unmarshal(m.RuleSets, &r.RuleSets)
+	}
+	r.Snapshot = r.refPtr(m.SnapshotID, m.Snapshot)
 }
 
 //
@@ -1941,13 +2570,14 @@ func (r *Issue) Model() (m *model.Issue) {
 //
 // TechDependency REST resource.
 type TechDependency struct {
-	Resource `yaml:",inline"`
-	Provider string   `json:"provider" yaml:",omitempty"`
-	Name     string   `json:"name" binding:"required"`
-	Version  string   `json:"version,omitempty" yaml:",omitempty"`
-	Indirect bool     `json:"indirect,omitempty" yaml:",omitempty"`
-	Labels   []string `json:"labels,omitempty" yaml:",omitempty"`
-	SHA      string   `json:"sha,omitempty" yaml:",omitempty"`
+	Resource   `yaml:",inline"`
+	Provider   string   `json:"provider" yaml:",omitempty"`
+	Name       string   `json:"name" binding:"required"`
+	Version    string   `json:"version,omitempty" yaml:",omitempty"`
+	Indirect   bool     `json:"indirect,omitempty" yaml:",omitempty"`
+	Labels     []string `json:"labels,omitempty" yaml:",omitempty"`
+	SHA        string   `json:"sha,omitempty" yaml:",omitempty"`
+	Advisories []Ref    `json:"advisories,omitempty" yaml:",omitempty"`
 }
 
 //
@@ -1978,6 +2608,31 @@ func (r *TechDependency) Model() (m *model.TechDependency) {
 	return
 }
 
+//
+// WithSBOM updates the resource with the SBOM dependency.
+func (r *TechDependency) WithSBOM(d *sbom.Dependency) {
+	r.Provider = d.Provider
+	r.Name = d.Name
+	r.Version = d.Version
+	r.Indirect = d.Indirect
+	r.Labels = d.Labels
+	r.SHA = d.SHA
+}
+
+//
+// SBOM builds an SBOM dependency.
+func (r *TechDependency) SBOM() (d sbom.Dependency) {
+	d = sbom.Dependency{
+		Provider: r.Provider,
+		Name:     r.Name,
+		Version:  r.Version,
+		Indirect: r.Indirect,
+		Labels:   r.Labels,
+		SHA:      r.SHA,
+	}
+	return
+}
+
 //
 // Incident REST resource.
 type Incident struct {
@@ -2089,6 +2744,7 @@ type DepReport struct {
 	Name         string   `json:"name"`
 	Labels       []string `json:"labels"`
 	Applications int      `json:"applications"`
+	Advisories   int      `json:"advisories"`
 }
 
 //
@@ -2099,13 +2755,43 @@ type DepAppReport struct {
 	Description     string `json:"description"`
 	BusinessService string `json:"businessService"`
 	Dependency      struct {
-		ID       uint     `json:"id"`
-		Provider string   `json:"provider"`
-		Name     string   `json:"name"`
-		Version  string   `json:"version"`
-		SHA      string   `json:"sha"`
-		Indirect bool     `json:"indirect"`
-		Labels   []string `json:"labels"`
+		ID         uint     `json:"id"`
+		Provider   string   `json:"provider"`
+		Name       string   `json:"name"`
+		Version    string   `json:"version"`
+		SHA        string   `json:"sha"`
+		Indirect   bool     `json:"indirect"`
+		Labels     []string `json:"labels"`
+		Advisories int      `json:"advisories"`
+	} `json:"dependency"`
+}
+
+//
+// AdvisoryReport REST resource.
+type AdvisoryReport struct {
+	ID           uint     `json:"id"`
+	Key          string   `json:"key"`
+	Summary      string   `json:"summary"`
+	Severity     string   `json:"severity"`
+	Aliases      []string `json:"aliases,omitempty"`
+	Applications int      `json:"applications"`
+	Dependencies int      `json:"dependencies"`
+}
+
+//
+// AppAdvisoryReport REST resource.
+type AppAdvisoryReport struct {
+	ID         uint     `json:"id"`
+	Key        string   `json:"key"`
+	Summary    string   `json:"summary"`
+	Severity   string   `json:"severity"`
+	Aliases    []string `json:"aliases,omitempty"`
+	Dependency struct {
+		ID       uint   `json:"id"`
+		Provider string `json:"provider"`
+		Name     string `json:"name"`
+		Version  string `json:"version"`
+		Indirect bool   `json:"indirect"`
 	} `json:"dependency"`
 }
 
@@ -2275,9 +2961,12 @@ func (r *ReportWriter) db() (db *gorm.DB) {
 
 //
 // Write builds and streams the analysis report.
-func (r *ReportWriter) Write(id uint) {
+// The report includes each of the analyses (applications).
+// The output.js is rendered (once) to a temporary file and
+// then streamed into the tarball.
+func (r *ReportWriter) Write(ids ...uint) {
 	reportDir := Settings.Analysis.ReportPath
-	path, err := r.buildOutput(id)
+	path, err := r.buildOutput(ids)
 	if err != nil {
 		_ = r.ctx.Error(err)
 		return
@@ -2310,45 +2999,53 @@ func (r *ReportWriter) Write(id uint) {
 
 //
 // buildOutput creates the report output.js file.
-func (r *ReportWriter) buildOutput(id uint) (path string, err error) {
-	m := &model.Analysis{}
-	db := r.db()
-	db = db.Preload("Application")
-	db = db.Preload("Application.Tags")
-	db = db.Preload("Application.Tags.Category")
-	err = db.First(m, id).Error
-	if err != nil {
-		return
-	}
+func (r *ReportWriter) buildOutput(ids []uint) (path string, err error) {
 	file, err := os.CreateTemp("", "output-*.js")
 	if err != nil {
 		return
 	}
 	defer func() {
 		_ = file.Close()
+		if err != nil {
+			_ = os.Remove(path)
+		}
 	}()
 	path = file.Name()
 	r.encoder = &jsonEncoder{output: file}
 	r.write("window[\"apps\"]=[")
-	r.begin()
-	r.field("id").writeStr(strconv.Itoa(int(m.Application.ID)))
-	r.field("name").writeStr(m.Application.Name)
-	r.field("analysis").writeStr(strconv.Itoa(int(m.ID)))
-	aWriter := AnalysisWriter{ctx: r.ctx}
-	aWriter.encoder = r.encoder
-	err = aWriter.addIssues(m)
-	if err != nil {
-		return
-	}
-	err = aWriter.addDeps(m)
-	if err != nil {
-		return
-	}
-	err = r.addTags(m)
-	if err != nil {
-		return
+	for i, id := range ids {
+		m := &model.Analysis{}
+		db := r.db()
+		db = db.Preload("Application")
+		db = db.Preload("Application.Tags")
+		db = db.Preload("Application.Tags.Category")
+		err = db.First(m, id).Error
+		if err != nil {
+			return
+		}
+		if i > 0 {
+			r.write(",")
+		}
+		r.begin()
+		r.field("id").writeStr(strconv.Itoa(int(m.Application.ID)))
+		r.field("name").writeStr(m.Application.Name)
+		r.field("analysis").writeStr(strconv.Itoa(int(m.ID)))
+		aWriter := AnalysisWriter{ctx: r.ctx}
+		aWriter.encoder = r.encoder
+		err = aWriter.addIssues(m)
+		if err != nil {
+			return
+		}
+		err = aWriter.addDeps(m)
+		if err != nil {
+			return
+		}
+		err = r.addTags(m)
+		if err != nil {
+			return
+		}
+		r.end()
 	}
-	r.end()
 	r.write("]")
 	return
 }
@@ -2392,6 +3089,7 @@ type jsonEncoder struct {
 }
 
 func (r *jsonEncoder) begin() encoder {
+	r.fields = 0
 	r.write("{")
 	return r
 }
@@ -1,10 +1,36 @@
 package api
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/auth"
+	"github.com/konveyor/tackle2-hub/bundle"
+	"github.com/konveyor/tackle2-hub/migration"
+	v12 "github.com/konveyor/tackle2-hub/migration/v12/model"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/quota"
+	"github.com/konveyor/tackle2-hub/sbom"
+	"github.com/konveyor/tackle2-hub/tar"
+	tasking "github.com/konveyor/tackle2-hub/task"
 	"github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestAccepted(t *testing.T) {
@@ -72,3 +98,1161 @@ func TestFactKey(t *testing.T) {
 	g.Expect(key.Source()).To(gomega.Equal("test"))
 	g.Expect(key.Name()).To(gomega.Equal(""))
 }
+
+func TestRevisionDiff(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	a := &model.RuleSetRevision{
+		Revision:  1,
+		Name:      "A",
+		DependsOn: []byte("[]"),
+		Rules: []model.RevisionRule{
+			{RuleIDs: []byte(`["r1","r2"]`), Digest: "d1"},
+			{RuleIDs: []byte(`["r3"]`), Digest: "d3"},
+		},
+	}
+	b := &model.RuleSetRevision{
+		Revision:  2,
+		Name:      "A",
+		DependsOn: []byte("[1]"),
+		Rules: []model.RevisionRule{
+			{RuleIDs: []byte(`["r1","r2","r4"]`), Digest: "d2"},
+		},
+	}
+	diff := RevisionDiff{}
+	diff.With(a, b)
+	g.Expect(diff.From).To(gomega.Equal(uint(1)))
+	g.Expect(diff.Fields).To(gomega.Equal([]string{"dependsOn"}))
+	g.Expect(diff.Added).To(gomega.Equal([]string{"r4"}))
+	g.Expect(diff.Removed).To(gomega.Equal([]string{"r3"}))
+	g.Expect(diff.Modified).To(gomega.Equal([]string{"r1", "r2"}))
+}
+
+func TestOwnership(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db := testDB(t)
+	jeff := &model.Stakeholder{Name: "Jeff", Email: "Jeff@Example.com"}
+	other := &model.Stakeholder{Name: "Other", Email: "other@example.com"}
+	member := &model.Stakeholder{Name: "Member", Email: "member@example.com"}
+	g.Expect(db.Create(jeff).Error).To(gomega.BeNil())
+	g.Expect(db.Create(other).Error).To(gomega.BeNil())
+	g.Expect(db.Create(member).Error).To(gomega.BeNil())
+	group := &model.StakeholderGroup{
+		Name:         "team",
+		Username:     "jeff@example.com",
+		Stakeholders: []model.Stakeholder{*member},
+	}
+	g.Expect(db.Create(group).Error).To(gomega.BeNil())
+	service := &model.BusinessService{Name: "billing", StakeholderID: &jeff.ID}
+	g.Expect(db.Create(service).Error).To(gomega.BeNil())
+	apps := []*model.Application{
+		{Name: "owned", OwnerID: &jeff.ID},
+		{Name: "contributed", OwnerID: &other.ID, Contributors: []model.Stakeholder{*jeff}},
+		{Name: "service", BusinessServiceID: &service.ID},
+		{Name: "group", OwnerID: &member.ID},
+		{Name: "other", OwnerID: &other.ID},
+		{Name: "none"},
+	}
+	for _, m := range apps {
+		g.Expect(db.Create(m).Error).To(gomega.BeNil())
+	}
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	rtx := WithContext(ctx)
+	rtx.DB = db
+	rtx.User = "jeff@example.com"
+	rtx.Scopes = []auth.Scope{&auth.BaseScope{Resource: "applications", Method: "*"}}
+	ownership := Ownership{ctx: ctx}
+	//
+	// Not enforced.
+	Settings.Auth.Required = true
+	Settings.Auth.Ownership = false
+	defer func() {
+		Settings.Auth.Required = false
+	}()
+	g.Expect(ownership.Restricted()).To(gomega.BeFalse())
+	g.Expect(ownership.Permit(apps[4].ID)).To(gomega.BeNil())
+	//
+	// Enforced.
+	Settings.Auth.Ownership = true
+	g.Expect(ownership.Restricted()).To(gomega.BeTrue())
+	var names []string
+	q := ownership.Where(db.Model(&model.Application{}), "ID")
+	err := q.Order("ID").Pluck("Name", &names).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(names).To(gomega.Equal([]string{"owned", "contributed", "service", "group"}))
+	g.Expect(ownership.Permit(apps[0].ID)).To(gomega.BeNil())
+	err = ownership.Permit(apps[4].ID)
+	g.Expect(errors.Is(err, &Forbidden{})).To(gomega.BeTrue())
+	//
+	// Task (bucket).
+	owned := func(task *model.Task) (b bool) {
+		g.Expect(db.Create(task).Error).To(gomega.BeNil())
+		tctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		tctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		tctx.Params = gin.Params{{Key: ID, Value: strconv.Itoa(int(task.ID))}}
+		trtx := WithContext(tctx)
+		trtx.DB = db
+		trtx.User = rtx.User
+		trtx.Scopes = rtx.Scopes
+		OwnedTask(tctx)
+		b = !tctx.IsAborted()
+		return
+	}
+	g.Expect(owned(&model.Task{Name: "a", ApplicationID: &apps[0].ID})).To(gomega.BeTrue())
+	g.Expect(owned(&model.Task{Name: "b", ApplicationID: &apps[4].ID})).To(gomega.BeFalse())
+	g.Expect(owned(&model.Task{Name: "c"})).To(gomega.BeTrue())
+	//
+	// Task (application refs).
+	task := &Task{Application: &Ref{ID: apps[4].ID}}
+	err = task.permit(ctx)
+	g.Expect(errors.Is(err, &Forbidden{})).To(gomega.BeTrue())
+	taskGroup := &TaskGroup{Tasks: []Task{{Application: &Ref{ID: apps[0].ID}}, {}}}
+	g.Expect(taskGroup.permit(ctx)).To(gomega.BeNil())
+	taskGroup.Tasks = append(taskGroup.Tasks, *task)
+	err = taskGroup.permit(ctx)
+	g.Expect(errors.Is(err, &Forbidden{})).To(gomega.BeTrue())
+	//
+	// Granted all.
+	rtx.Scopes = append(rtx.Scopes, &auth.BaseScope{Resource: OwnershipScope, Method: "get"})
+	g.Expect(ownership.Restricted()).To(gomega.BeFalse())
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+	g.Expect(ownership.Restricted()).To(gomega.BeTrue())
+}
+
+func TestAuditLog(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db := testDB(t)
+	router := testRouter(db, AuditLog(db))
+	StakeholderHandler{}.AddRoutes(router)
+	send := func(method, path, body string) (status int) {
+		w := httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set(ContentType, "application/json")
+		router.ServeHTTP(w, request)
+		status = w.Code
+		return
+	}
+	//
+	// Create.
+	status := send(http.MethodPost, StakeholdersRoot, `{"name":"a","email":"a@x.com"}`)
+	g.Expect(status).To(gomega.Equal(http.StatusCreated))
+	//
+	// Update.
+	status = send(http.MethodPut, "/stakeholders/1", `{"name":"b","email":"a@x.com"}`)
+	g.Expect(status).To(gomega.Equal(http.StatusNoContent))
+	//
+	// Update (not valid).
+	status = send(http.MethodPut, "/stakeholders/1", `{}`)
+	g.Expect(status).To(gomega.Equal(http.StatusBadRequest))
+	//
+	// Delete.
+	status = send(http.MethodDelete, "/stakeholders/1", "")
+	g.Expect(status).To(gomega.Equal(http.StatusNoContent))
+	//
+	// Get (not audited).
+	status = send(http.MethodGet, "/stakeholders/1", "")
+	g.Expect(status).To(gomega.Equal(http.StatusNotFound))
+	var list []model.AuditEntry
+	err := db.Order("ID").Find(&list).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(list)).To(gomega.Equal(4))
+	entries := []AuditEntry{}
+	for i := range list {
+		r := AuditEntry{}
+		r.With(&list[i])
+		g.Expect(r.Kind).To(gomega.Equal("stakeholders"))
+		g.Expect(r.ResourceID).ToNot(gomega.BeNil())
+		g.Expect(*r.ResourceID).To(gomega.Equal(uint(1)))
+		entries = append(entries, r)
+	}
+	g.Expect(entries[0].Method).To(gomega.Equal(http.MethodPost))
+	g.Expect(entries[0].Outcome).To(gomega.Equal(AuditSucceeded))
+	g.Expect(entries[1].Route).To(gomega.Equal(StakeholderRoot))
+	g.Expect(entries[1].Changes).To(
+		gomega.Equal([]AuditChange{
+			{Kind: "Stakeholder", ID: "1", Field: "Name", Old: "a", New: "b"},
+		}))
+	g.Expect(entries[2].Status).To(gomega.Equal(http.StatusBadRequest))
+	g.Expect(entries[2].Outcome).To(gomega.Equal(AuditFailed))
+	g.Expect(entries[3].Method).To(gomega.Equal(http.MethodDelete))
+	g.Expect(entries[3].Status).To(gomega.Equal(http.StatusNoContent))
+}
+
+func TestRateLimiter(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	groups := ParseRateGroups("/analyses=1:2, /analyses/issues=0.5:1,bad")
+	g.Expect(groups).To(
+		gomega.Equal([]RateGroup{
+			{Route: "/analyses/issues", RateLimit: RateLimit{Rate: 0.5, Burst: 1}},
+			{Route: "/analyses", RateLimit: RateLimit{Rate: 1, Burst: 2}},
+		}))
+	limiter := &RateLimiter{Groups: groups}
+	//
+	// Default (unlimited).
+	for i := 0; i < 10; i++ {
+		_, delay := limiter.Reserve("jeff", "/applications")
+		g.Expect(delay).To(gomega.BeZero())
+	}
+	//
+	// Group burst exhausted.
+	group, delay := limiter.Reserve("jeff", "/analyses/issues")
+	g.Expect(group).To(gomega.Equal("/analyses/issues"))
+	g.Expect(delay).To(gomega.BeZero())
+	group, delay = limiter.Reserve("jeff", "/analyses/issues")
+	g.Expect(group).To(gomega.Equal("/analyses/issues"))
+	g.Expect(delay > time.Second).To(gomega.BeTrue())
+	//
+	// Per user.
+	_, delay = limiter.Reserve("ann", "/analyses/issues")
+	g.Expect(delay).To(gomega.BeZero())
+	//
+	// Middleware.
+	Limiter = &RateLimiter{Default: RateLimit{Rate: 1, Burst: 1}}
+	defer func() {
+		Limiter = nil
+	}()
+	router := gin.New()
+	router.GET(
+		"/things",
+		func(ctx *gin.Context) {
+			if RateLimited(ctx, "user:jeff") {
+				return
+			}
+			ctx.Status(http.StatusOK)
+		})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/things", nil))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/things", nil))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusTooManyRequests))
+	g.Expect(w.Header().Get("Retry-After")).To(gomega.Equal("1"))
+	//
+	// Keys.
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx.Request.RemoteAddr = "10.0.0.1:1234"
+	g.Expect(RateKey(ctx, auth.Result{User: "jeff"})).To(gomega.Equal("client:10.0.0.1"))
+	g.Expect(RateKey(ctx, auth.Result{User: "jeff", Token: 4})).To(gomega.Equal("token:4"))
+	Settings.Auth.Required = true
+	defer func() {
+		Settings.Auth.Required = false
+	}()
+	g.Expect(RateKey(ctx, auth.Result{User: "jeff"})).To(gomega.Equal("user:jeff"))
+	g.Expect(RateKey(ctx, auth.Result{User: "jeff", Token: 4})).To(gomega.Equal("token:4"))
+}
+
+func TestFileDedup(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db := testDB(t)
+	router := testRouter(db)
+	FileHandler{}.AddRoutes(router)
+	send := func(method, path, content string) (w *httptest.ResponseRecorder) {
+		w = httptest.NewRecorder()
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		if content != "" {
+			part, _ := writer.CreateFormFile(FileField, "f")
+			_, _ = part.Write([]byte(content))
+		}
+		_ = writer.Close()
+		request := httptest.NewRequest(method, path, body)
+		request.Header.Set(ContentType, writer.FormDataContentType())
+		request.Header.Set(Accept, "application/json")
+		router.ServeHTTP(w, request)
+		return
+	}
+	sum := sha256.Sum256([]byte("hello"))
+	digest := hex.EncodeToString(sum[:])
+	//
+	// Create (same content).
+	var files []File
+	for _, name := range []string{"a.txt", "b.txt"} {
+		w := send(http.MethodPost, "/files/"+name, "hello")
+		g.Expect(w.Code).To(gomega.Equal(http.StatusCreated))
+		r := File{}
+		err := json.Unmarshal(w.Body.Bytes(), &r)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(r.Digest).To(gomega.Equal(digest))
+		files = append(files, r)
+	}
+	g.Expect(files[0].ID).ToNot(gomega.Equal(files[1].ID))
+	g.Expect(files[0].Path).To(gomega.Equal(files[1].Path))
+	//
+	// Find by digest.
+	w := send(http.MethodGet, "/files?digest="+strings.ToUpper(digest), "")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	list := []File{}
+	err := json.Unmarshal(w.Body.Bytes(), &list)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(list)).To(gomega.Equal(2))
+	w = send(http.MethodGet, "/files?digest=none", "")
+	g.Expect(w.Body.String()).To(gomega.Equal("[]"))
+	//
+	// Delete (shared content retained).
+	w = send(http.MethodDelete, "/files/1", "")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	_, err = os.Stat(files[0].Path)
+	g.Expect(err).To(gomega.BeNil())
+	w = send(http.MethodDelete, "/files/2", "")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	_, err = os.Stat(files[0].Path)
+	g.Expect(os.IsNotExist(err)).To(gomega.BeTrue())
+}
+
+func TestQuota(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db := testDB(t)
+	saved := Settings.Hub
+	Settings.Hub.Cache.Path = t.TempDir()
+	Settings.Hub.Quota.Bucket = 1
+	Settings.Hub.Quota.Total = 2
+	defer func() {
+		Settings.Hub = saved
+		quota.Reset()
+	}()
+	quota.Reset()
+	err := os.WriteFile(path.Join(Settings.Hub.Cache.Path, "c"), []byte("cache"), 0644)
+	g.Expect(err).To(gomega.BeNil())
+	router := testRouter(db)
+	BucketHandler{}.AddRoutes(router)
+	FileHandler{}.AddRoutes(router)
+	UsageHandler{}.AddRoutes(router)
+	send := func(method, path string, content []byte) (w *httptest.ResponseRecorder) {
+		w = httptest.NewRecorder()
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		if content != nil {
+			part, _ := writer.CreateFormFile(FileField, "f")
+			_, _ = part.Write(content)
+		}
+		_ = writer.Close()
+		request := httptest.NewRequest(method, path, body)
+		request.Header.Set(ContentType, writer.FormDataContentType())
+		request.Header.Set(Accept, "application/json")
+		router.ServeHTTP(w, request)
+		return
+	}
+	app := &model.Application{Name: "test"}
+	err = db.Create(app).Error
+	g.Expect(err).To(gomega.BeNil())
+	bucket := "/buckets/" + strconv.Itoa(int(*app.BucketID))
+	mib := int(quota.MiB)
+	//
+	// Bucket quota.
+	w := send(http.MethodPut, bucket+"/a", bytes.Repeat([]byte("a"), mib/2))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	w = send(http.MethodPut, bucket+"/b", bytes.Repeat([]byte("b"), mib/2+1))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusRequestEntityTooLarge))
+	w = send(http.MethodPut, bucket+"/a", bytes.Repeat([]byte("a"), mib))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	//
+	// Total quota.
+	w = send(http.MethodPost, "/files/f1", bytes.Repeat([]byte("1"), mib/2))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusCreated))
+	w = send(http.MethodPost, "/files/f2", bytes.Repeat([]byte("2"), mib))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusRequestEntityTooLarge))
+	// reserved content counted until released.
+	reservation, err := quota.Check("", int64(mib/2))
+	g.Expect(err).To(gomega.BeNil())
+	_, err = quota.Check("", 1)
+	g.Expect(errors.Is(err, &quota.Exceeded{})).To(gomega.BeTrue())
+	err = reservation.Grow(-1)
+	g.Expect(err).To(gomega.BeNil())
+	reservation2, err := quota.Check("", 1)
+	g.Expect(err).To(gomega.BeNil())
+	reservation.Release()
+	reservation2.Release()
+	err = reservation.Grow(1)
+	g.Expect(err).To(gomega.BeNil())
+	reservation, err = quota.Check("", int64(mib/2))
+	g.Expect(err).To(gomega.BeNil())
+	reservation.Release()
+	n := int64(0)
+	db.Model(&model.File{}).Count(&n)
+	g.Expect(n).To(gomega.Equal(int64(1)))
+	// shared content not counted.
+	w = send(http.MethodPost, "/files/f3", bytes.Repeat([]byte("1"), mib/2))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusCreated))
+	//
+	// Usage.
+	w = send(http.MethodGet, "/usage", nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	r := Usage{}
+	err = json.Unmarshal(w.Body.Bytes(), &r)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(r.Application).To(gomega.Equal(quota.MiB))
+	g.Expect(r.File).To(gomega.Equal(quota.MiB / 2))
+	g.Expect(r.Cache).To(gomega.Equal(int64(5)))
+	g.Expect(r.Total).To(gomega.Equal(quota.MiB * 3 / 2))
+	g.Expect(r.Quota.Total).To(gomega.Equal(quota.MiB * 2))
+	//
+	// Shared content deleted when no longer referenced.
+	var files []model.File
+	err = db.Order("ID").Find(&files).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(files)).To(gomega.Equal(2))
+	g.Expect(files[0].ContentID).ToNot(gomega.BeNil())
+	g.Expect(files[0].ContentID).To(gomega.Equal(files[1].ContentID))
+	w = send(http.MethodDelete, "/files/"+strconv.Itoa(int(files[0].ID)), nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	_, err = os.Stat(files[1].Path)
+	g.Expect(err).To(gomega.BeNil())
+	w = send(http.MethodDelete, "/files/"+strconv.Itoa(int(files[1].ID)), nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	_, err = os.Stat(files[1].Path)
+	g.Expect(os.IsNotExist(err)).To(gomega.BeTrue())
+	db.Model(&model.FileContent{}).Count(&n)
+	g.Expect(n).To(gomega.Equal(int64(0)))
+}
+
+func TestBucketTransfer(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db := testDB(t)
+	router := testRouter(db)
+	BucketHandler{}.AddRoutes(router)
+	send := func(method, path string, header http.Header, content []byte) (w *httptest.ResponseRecorder) {
+		w = httptest.NewRecorder()
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		if content != nil {
+			part, _ := writer.CreateFormFile(FileField, "f")
+			_, _ = part.Write(content)
+		}
+		_ = writer.Close()
+		request := httptest.NewRequest(method, path, body)
+		for k := range header {
+			request.Header.Set(k, header.Get(k))
+		}
+		request.Header.Set(ContentType, writer.FormDataContentType())
+		router.ServeHTTP(w, request)
+		return
+	}
+	tarball := func(compression string, files map[string]string) []byte {
+		bfr := &bytes.Buffer{}
+		writer := tar.NewWriterWith(bfr, compression)
+		for p, content := range files {
+			content := content
+			_ = writer.AddStream(
+				p,
+				int64(len(content)),
+				func(w io.Writer) (err error) {
+					_, err = w.Write([]byte(content))
+					return
+				})
+		}
+		writer.Close()
+		return bfr.Bytes()
+	}
+	bucket := &model.Bucket{}
+	err := db.Create(bucket).Error
+	g.Expect(err).To(gomega.BeNil())
+	root := "/buckets/" + strconv.Itoa(int(bucket.ID))
+	//
+	// Put (zstd) directory.
+	w := send(
+		http.MethodPut,
+		root+"/dir",
+		http.Header{Directory: []string{DirectoryExpand}},
+		tarball(tar.Zstd, map[string]string{"/a": "hello world", "/b": "b"}))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	//
+	// Merge.
+	w = send(
+		http.MethodPut,
+		root+"/dir",
+		http.Header{Directory: []string{DirectoryMerge}},
+		tarball(tar.Gzip, map[string]string{"/b": "bb", "/c": "c"}))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	g.Expect(w.Header().Get(AcceptCompression)).To(gomega.Equal(tar.Supported))
+	//
+	// Manifest.
+	w = send(http.MethodGet, root+"/dir?manifest=true", nil, nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	manifest := []BucketEntry{}
+	err = json.Unmarshal(w.Body.Bytes(), &manifest)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(manifest)).To(gomega.Equal(3))
+	sum := sha256.Sum256([]byte("bb"))
+	g.Expect(manifest[1].Path).To(gomega.Equal("b"))
+	g.Expect(manifest[1].Size).To(gomega.Equal(int64(2)))
+	g.Expect(manifest[1].Digest).To(gomega.Equal(hex.EncodeToString(sum[:])))
+	//
+	// Get directory (Accept=application/json).
+	w = send(http.MethodGet, root+"/dir", http.Header{Accept: []string{"application/json"}}, nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	g.Expect(w.Header().Get(Directory)).To(gomega.Equal(DirectoryExpand))
+	g.Expect(w.Header().Get(Compression)).To(gomega.Equal(tar.Gzip))
+	//
+	// Get (negotiated) directory.
+	w = send(http.MethodGet, root+"/dir", http.Header{Compression: []string{"zstd,gzip"}}, nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	g.Expect(w.Header().Get(Compression)).To(gomega.Equal(tar.Zstd))
+	walked := make(map[string]string)
+	err = tar.NewReader().Walk(
+		w.Body,
+		func(path string, size int64, r io.Reader) (err error) {
+			b, err := io.ReadAll(r)
+			walked[path] = string(b)
+			return
+		})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(walked).To(gomega.Equal(map[string]string{"/a": "hello world", "/b": "bb", "/c": "c"}))
+	//
+	// Ranged reads.
+	for rng, expected := range map[string]string{
+		"bytes=0-4":  "hello",
+		"bytes=6-":   "world",
+		"bytes=-3":   "rld",
+		"bytes=6-99": "world",
+	} {
+		w = send(http.MethodGet, root+"/dir/a", http.Header{Range: []string{rng}}, nil)
+		g.Expect(w.Code).To(gomega.Equal(http.StatusPartialContent))
+		g.Expect(w.Body.String()).To(gomega.Equal(expected))
+	}
+	w = send(http.MethodGet, root+"/dir/a", http.Header{Range: []string{"bytes=0-4"}}, nil)
+	g.Expect(w.Header().Get(ContentRange)).To(gomega.Equal("bytes 0-4/11"))
+	w = send(http.MethodGet, root+"/dir/a", http.Header{Range: []string{"bytes=20-"}}, nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusRequestedRangeNotSatisfiable))
+	g.Expect(w.Header().Get(ContentRange)).To(gomega.Equal("bytes */11"))
+	w = send(http.MethodGet, root+"/dir/a", nil, nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	g.Expect(w.Header().Get(AcceptRanges)).To(gomega.Equal("bytes"))
+	g.Expect(w.Body.String()).To(gomega.Equal("hello world"))
+}
+
+func TestBucketSnapshot(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db := testDB(t)
+	router := testRouter(db)
+	ApplicationHandler{}.AddRoutes(router)
+	send := func(method, path string, content []byte) (w *httptest.ResponseRecorder) {
+		w = httptest.NewRecorder()
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		if content != nil {
+			part, _ := writer.CreateFormFile(FileField, "f")
+			_, _ = part.Write(content)
+		}
+		_ = writer.Close()
+		request := httptest.NewRequest(method, path, body)
+		request.Header.Set(ContentType, writer.FormDataContentType())
+		router.ServeHTTP(w, request)
+		return
+	}
+	application := &model.Application{Name: "a"}
+	err := db.Create(application).Error
+	g.Expect(err).To(gomega.BeNil())
+	root := "/applications/" + strconv.Itoa(int(application.ID))
+	w := send(http.MethodPut, root+"/bucket/a", []byte("a"))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	w = send(http.MethodPut, root+"/bucket/b", []byte("b"))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	//
+	// Create.
+	w = send(http.MethodPost, root+"/snapshots", nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusCreated))
+	snapshot := BucketSnapshot{}
+	err = json.Unmarshal(w.Body.Bytes(), &snapshot)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(snapshot.Reason).To(gomega.Equal(SnapshotManual))
+	sRoot := root + "/snapshots/" + strconv.Itoa(int(snapshot.ID))
+	//
+	// Change the bucket content.
+	w = send(http.MethodPut, root+"/bucket/b", []byte("bb"))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	w = send(http.MethodPut, root+"/bucket/c", []byte("c"))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	w = send(http.MethodDelete, root+"/bucket/a", nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	//
+	// Diff.
+	w = send(http.MethodGet, sRoot+"/diff", nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	diff := BucketDiff{}
+	err = json.Unmarshal(w.Body.Bytes(), &diff)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(diff.Added).To(gomega.Equal([]string{"c"}))
+	g.Expect(diff.Removed).To(gomega.Equal([]string{"a"}))
+	g.Expect(diff.Changed).To(gomega.Equal([]string{"b"}))
+	//
+	// Restore.
+	w = send(http.MethodPut, sRoot+"/restore", nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	w = send(http.MethodGet, sRoot+"/diff", nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	diff = BucketDiff{}
+	err = json.Unmarshal(w.Body.Bytes(), &diff)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(diff.Added).To(gomega.BeEmpty())
+	g.Expect(diff.Removed).To(gomega.BeEmpty())
+	g.Expect(diff.Changed).To(gomega.BeEmpty())
+	w = send(http.MethodGet, root+"/bucket/b", nil)
+	g.Expect(w.Body.String()).To(gomega.Equal("b"))
+	//
+	// List.
+	w = send(http.MethodGet, root+"/snapshots", nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	list := []BucketSnapshot{}
+	err = json.Unmarshal(w.Body.Bytes(), &list)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(list)).To(gomega.Equal(2))
+	g.Expect(list[1].Reason).To(gomega.Equal(SnapshotRestore))
+	//
+	// Snapshot before first written by a task.
+	task := &model.Task{Name: "t"}
+	err = db.Create(task).Error
+	g.Expect(err).To(gomega.BeNil())
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	rtx := WithContext(ctx)
+	rtx.DB = db
+	rtx.Task = task.ID
+	h := BucketOwner{}
+	var n int64
+	q := db.Model(&model.BucketSnapshot{})
+	q = q.Where("TaskID", task.ID)
+	err = h.snapshotBefore(ctx, *application.BucketID)
+	g.Expect(err).To(gomega.BeNil())
+	err = q.Count(&n).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(n).To(gomega.Equal(int64(0)))
+	analysis := AnalysisHandler{}
+	snapshotID, err := analysis.snapshot(ctx, application, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(snapshotID).To(gomega.BeNil())
+	err = q.Count(&n).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(n).To(gomega.Equal(int64(0)))
+	Settings.Hub.Bucket.Snapshot = true
+	defer func() {
+		Settings.Hub.Bucket.Snapshot = false
+	}()
+	for i := 0; i < 2; i++ {
+		err = h.snapshotBefore(ctx, *application.BucketID)
+		g.Expect(err).To(gomega.BeNil())
+	}
+	err = q.Count(&n).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(n).To(gomega.Equal(int64(1)))
+	snapshotID, err = analysis.snapshot(ctx, application, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(snapshotID).ToNot(gomega.BeNil())
+	//
+	// RuleSet revisions pinned by the task.
+	pinned := []RevisionRef{{ID: 1, Name: "a", Revision: 2}}
+	b, _ := json.Marshal(pinned)
+	err = db.Model(task).Update("RuleSets", b).Error
+	g.Expect(err).To(gomega.BeNil())
+	refs, err := analysis.ruleSets(ctx, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(refs).To(gomega.Equal(pinned))
+	//
+	// Delete.
+	w = send(http.MethodDelete, sRoot, nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	w = send(http.MethodGet, sRoot, nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNotFound))
+}
+
+func TestReaperRun(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db := testDB(t)
+	router := testRouter(db)
+	ReaperHandler{}.AddRoutes(router)
+	send := func(method, path string) (w *httptest.ResponseRecorder) {
+		w = httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, nil)
+		router.ServeHTTP(w, request)
+		return
+	}
+	expired := time.Now().Add(-time.Minute)
+	bucket := &model.Bucket{Expiration: &expired}
+	err := db.Create(bucket).Error
+	g.Expect(err).To(gomega.BeNil())
+	err = os.WriteFile(path.Join(bucket.Path, "a"), []byte("hello"), 0644)
+	g.Expect(err).To(gomega.BeNil())
+	//
+	// Dry run.
+	w := send(http.MethodPost, "/reaper?dryRun=true")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	run := ReaperRun{}
+	err = json.Unmarshal(w.Body.Bytes(), &run)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(run.DryRun).To(gomega.BeTrue())
+	g.Expect(run.ID).To(gomega.BeZero())
+	g.Expect(run.Actions).To(gomega.Equal(
+		[]ReaperAction{
+			{
+				Kind:   "bucket",
+				ID:     bucket.ID,
+				Action: "deleted",
+				Reason: "orphan",
+				Size:   5,
+			},
+		}))
+	g.Expect(run.Counts).To(gomega.Equal(map[string]int{"bucket.deleted": 1}))
+	err = db.First(&model.Bucket{}, bucket.ID).Error
+	g.Expect(err).To(gomega.BeNil())
+	_, err = os.Stat(bucket.Path)
+	g.Expect(err).To(gomega.BeNil())
+	//
+	// Run.
+	w = send(http.MethodPost, "/reaper")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	run = ReaperRun{}
+	err = json.Unmarshal(w.Body.Bytes(), &run)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(run.ID).ToNot(gomega.BeZero())
+	g.Expect(run.Reclaimed).To(gomega.Equal(int64(5)))
+	err = db.First(&model.Bucket{}, bucket.ID).Error
+	g.Expect(errors.Is(err, gorm.ErrRecordNotFound)).To(gomega.BeTrue())
+	_, err = os.Stat(bucket.Path)
+	g.Expect(os.IsNotExist(err)).To(gomega.BeTrue())
+	//
+	// History.
+	w = send(http.MethodGet, "/reaper/runs")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	list := []ReaperRun{}
+	err = json.Unmarshal(w.Body.Bytes(), &list)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(list)).To(gomega.Equal(1))
+	g.Expect(list[0].Manual).To(gomega.BeTrue())
+	g.Expect(list[0].Counts).To(gomega.Equal(map[string]int{"bucket.deleted": 1}))
+	g.Expect(list[0].Reclaimed).To(gomega.Equal(int64(5)))
+	g.Expect(list[0].Actions).To(gomega.BeEmpty())
+}
+
+func TestTaskLifecycle(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db := testDB(t)
+	router := testRouter(db)
+	SettingHandler{}.AddRoutes(router)
+	ReaperHandler{}.AddRoutes(router)
+	send := func(method, path, body string) (w *httptest.ResponseRecorder) {
+		w = httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set(ContentType, "application/json")
+		router.ServeHTTP(w, request)
+		return
+	}
+	//
+	// Validated.
+	for _, invalid := range []string{
+		`{"default":{"states":{"Running":{"release":10}}}}`,
+		`{"default":{"states":{"Unknown":{"delete":10}}}}`,
+		`{"addon":{"a":{"states":{"Failed":{"resources":["disk"]}}}}}`,
+		`{"addon":{"a":{"keep":-1}}}`,
+		`{"default":{"ttl":10}}`,
+	} {
+		w := send(http.MethodPost, "/settings/task.lifecycle", invalid)
+		g.Expect(w.Code).To(gomega.Equal(http.StatusBadRequest))
+	}
+	w := send(
+		http.MethodPost,
+		"/settings/task.lifecycle",
+		`{
+		  "addon": {
+		    "a": {"states": {"Succeeded": {"delete": 60}}, "keep": 1}
+		  },
+		  "group": {
+		    "1": {"states": {"Failed": {"release": 1, "resources": ["report"]}}}
+		  }
+		}`)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusCreated))
+	w = send(http.MethodPut, "/settings/task.lifecycle", `{"default":{"keep":"x"}}`)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusBadRequest))
+	//
+	// Tasks.
+	application := &model.Application{Name: "a"}
+	err := db.Create(application).Error
+	g.Expect(err).To(gomega.BeNil())
+	group := &model.TaskGroup{Name: "g"}
+	err = db.Create(group).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(group.ID).To(gomega.Equal(uint(1)))
+	create := func(m *model.Task, terminated time.Duration) {
+		m.ApplicationID = &application.ID
+		err := db.Create(m).Error
+		g.Expect(err).To(gomega.BeNil())
+		mark := time.Now().Add(-terminated)
+		err = db.Model(m).Update("Terminated", mark).Error
+		g.Expect(err).To(gomega.BeNil())
+	}
+	oldest := &model.Task{Name: "t1", Addon: "a", State: "Succeeded"}
+	create(oldest, 3*time.Hour)
+	older := &model.Task{Name: "t2", Addon: "a", State: "Succeeded"}
+	create(older, 2*time.Hour)
+	newest := &model.Task{Name: "t3", Addon: "a", State: "Succeeded"}
+	create(newest, 90*time.Minute)
+	ttl, _ := json.Marshal(TTL{Failed: 1})
+	failed := &model.Task{Name: "t4", Addon: "b", State: "Failed", TTL: ttl}
+	create(failed, 2*time.Minute)
+	grouped := &model.Task{Name: "t5", Addon: "b", State: "Failed", TaskGroupID: &group.ID}
+	create(grouped, 2*time.Minute)
+	err = db.Create(&model.TaskReport{TaskID: grouped.ID}).Error
+	g.Expect(err).To(gomega.BeNil())
+	ttl, _ = json.Marshal(TTL{Canceled: 1})
+	canceled := &model.Task{Name: "t6", Addon: "b", State: "Canceled", TTL: ttl}
+	create(canceled, 2*time.Minute)
+	released := &model.Task{Name: "t7", Addon: "b", State: "Canceled"}
+	create(released, 2*time.Hour)
+	ttl, _ = json.Marshal(TTL{Ready: 1})
+	ready := &model.Task{Name: "t8", Addon: "b", State: "Ready", TTL: ttl}
+	create(ready, 0)
+	err = db.Exec(
+		"UPDATE Task SET CreateTime = ? WHERE ID = ?",
+		time.Now().Add(-2*time.Minute),
+		ready.ID).Error
+	g.Expect(err).To(gomega.BeNil())
+	savedReaper := Settings.Hub.Task.Reaper
+	Settings.Hub.Task.Reaper.Failed = 60
+	defer func() {
+		Settings.Hub.Task.Reaper = savedReaper
+	}()
+	//
+	// Dry run.
+	w = send(http.MethodPost, "/reaper?dryRun=true", "")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	run := ReaperRun{}
+	err = json.Unmarshal(w.Body.Bytes(), &run)
+	g.Expect(err).To(gomega.BeNil())
+	actions := make(map[uint]string)
+	for _, action := range run.Actions {
+		if action.Kind == "task" {
+			actions[action.ID] = action.Action
+		}
+	}
+	g.Expect(actions).To(gomega.Equal(
+		map[uint]string{
+			oldest.ID:   "deleted",
+			older.ID:    "deleted",
+			failed.ID:   "deleted",
+			grouped.ID:  "released",
+			canceled.ID: "deleted",
+			released.ID: "released",
+			ready.ID:    "deleted",
+		}))
+	//
+	// Run.
+	w = send(http.MethodPost, "/reaper", "")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	var remaining []uint
+	err = db.Model(&model.Task{}).Order("ID").Pluck("ID", &remaining).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(remaining).To(gomega.Equal([]uint{newest.ID, grouped.ID, released.ID}))
+	var n int64
+	err = db.Model(&model.TaskReport{}).Count(&n).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(n).To(gomega.BeZero())
+	m := &model.Task{}
+	err = db.First(m, grouped.ID).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(m.BucketID).ToNot(gomega.BeNil())
+	m = &model.Task{}
+	err = db.First(m, released.ID).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(m.BucketID).To(gomega.BeNil())
+}
+
+func TestBundle(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db := testDB(t)
+	savedDB := Settings.DB.Path
+	Settings.DB.Path = path.Join(t.TempDir(), "hub.db")
+	defer func() {
+		Settings.DB.Path = savedDB
+	}()
+	b, _ := json.Marshal(migration.Version{Version: migration.MinimumVersion})
+	err := db.Create(&model.Setting{Key: migration.VersionKey, Value: b}).Error
+	g.Expect(err).To(gomega.BeNil())
+	router := testRouter(db)
+	BundleHandler{}.AddRoutes(router)
+	get := func(path string) (w *httptest.ResponseRecorder) {
+		w = httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, request)
+		return
+	}
+	post := func(content []byte) (w *httptest.ResponseRecorder) {
+		w = httptest.NewRecorder()
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile(FileField, "f")
+		_, _ = part.Write(content)
+		_ = writer.Close()
+		request := httptest.NewRequest(http.MethodPost, BundlesRoot, body)
+		request.Header.Set(ContentType, writer.FormDataContentType())
+		request.Header.Set(Accept, "application/json")
+		router.ServeHTTP(w, request)
+		return
+	}
+	a := &model.Application{Name: "A"}
+	err = db.Create(a).Error
+	g.Expect(err).To(gomega.BeNil())
+	err = db.Create(&model.Application{Name: "B"}).Error
+	g.Expect(err).To(gomega.BeNil())
+	//
+	// Export (application).
+	w := get("/applications/" + strconv.Itoa(int(a.ID)) + "/bundle")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	g.Expect(w.Header().Get("Content-Disposition")).To(gomega.ContainSubstring(".tar.gz"))
+	exported := w.Body.Bytes()
+	w = get("/applications/1000/bundle")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNotFound))
+	//
+	// Export (filtered).
+	var names []string
+	w = get(BundlesRoot + "?filter=name:B")
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	err = tar.NewReader().Walk(
+		w.Body,
+		func(path string, size int64, r io.Reader) (err error) {
+			names = append(names, path)
+			return
+		})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(names)).To(gomega.Equal(2))
+	g.Expect(names[1]).To(gomega.Equal(bundle.ManifestName))
+	//
+	// Import.
+	err = db.Delete(a).Error
+	g.Expect(err).To(gomega.BeNil())
+	w = post(exported)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusCreated))
+	report := BundleReport{}
+	err = json.Unmarshal(w.Body.Bytes(), &report)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(report.Applications)).To(gomega.Equal(1))
+	g.Expect(report.Applications[0].Source).To(gomega.Equal(a.ID))
+	g.Expect(report.Applications[0].ID).ToNot(gomega.Equal(a.ID))
+	g.Expect(report.Conflicts).To(gomega.BeEmpty())
+	w = post(exported)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusCreated))
+	report = BundleReport{}
+	err = json.Unmarshal(w.Body.Bytes(), &report)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(report.Applications).To(gomega.BeEmpty())
+	g.Expect(len(report.Conflicts)).To(gomega.Equal(1))
+	//
+	// Invalid.
+	w = post([]byte("not a bundle"))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusBadRequest))
+}
+
+func TestCacheVolume(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db := testDB(t)
+	saved := Settings.Hub.Cache
+	Settings.Hub.Cache.Path = t.TempDir()
+	Settings.Hub.Cache.Capacity = 1
+	defer func() {
+		Settings.Hub.Cache = saved
+	}()
+	router := testRouter(db)
+	CacheHandler{}.AddRoutes(router)
+	ReaperHandler{}.AddRoutes(router)
+	TaskHandler{}.AddRoutes(router)
+	send := func(method, path string, body []byte) (w *httptest.ResponseRecorder) {
+		w = httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, bytes.NewReader(body))
+		request.Header.Set(ContentType, "application/json")
+		router.ServeHTTP(w, request)
+		return
+	}
+	//
+	// Rule: read-write is exclusive, read-only is shared.
+	task := func(mounts ...model.CacheMount) (m *model.Task) {
+		m = &model.Task{}
+		m.Caches, _ = json.Marshal(mounts)
+		return
+	}
+	rule := tasking.RuleCache{}
+	rw := model.CacheMount{Name: "maven"}
+	ro := model.CacheMount{Name: "maven", ReadOnly: true}
+	other := model.CacheMount{Name: "npm"}
+	g.Expect(rule.Match(task(rw), task(rw))).To(gomega.BeTrue())
+	g.Expect(rule.Match(task(ro), task(rw))).To(gomega.BeTrue())
+	g.Expect(rule.Match(task(rw), task(ro))).To(gomega.BeTrue())
+	g.Expect(rule.Match(task(ro), task(ro))).To(gomega.BeFalse())
+	g.Expect(rule.Match(task(rw), task(other))).To(gomega.BeFalse())
+	//
+	// Rule: default volume is shared.
+	g.Expect(tasking.Mounts(task())).To(gomega.Equal([]model.CacheMount{{Name: tasking.DefaultCache}}))
+	g.Expect(rule.Match(task(), task())).To(gomega.BeFalse())
+	g.Expect(rule.Match(task(rw), task())).To(gomega.BeFalse())
+	//
+	// Validated.
+	for _, caches := range []string{
+		`[{"name":"../etc"}]`,
+		`[{"name":"maven"},{"name":"maven","readOnly":true}]`,
+		`[{"name":"default"}]`,
+	} {
+		body := `{"addon":"analyzer","data":{},"caches":` + caches + `}`
+		w := send(http.MethodPost, TasksRoot, []byte(body))
+		g.Expect(w.Code).To(gomega.Equal(http.StatusBadRequest))
+	}
+	body := `{"addon":"analyzer","data":{},"caches":[{"name":"maven","readOnly":true}]}`
+	w := send(http.MethodPost, TasksRoot, []byte(body))
+	g.Expect(w.Code).To(gomega.Equal(http.StatusCreated))
+	created := Task{}
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(created.Caches).To(gomega.Equal([]TaskCache{{Name: "maven", ReadOnly: true}}))
+	//
+	// Volumes: maven (leased), npm (least recently used), go.
+	leased := &model.Task{Name: "leased", Addon: "analyzer", State: tasking.Running}
+	err = db.Create(leased).Error
+	g.Expect(err).To(gomega.BeNil())
+	for i, name := range []string{"npm", "maven", "go"} {
+		size := 600 * 1024
+		if name == "go" {
+			size = 100 * 1024
+		}
+		err = os.MkdirAll(tasking.CachePath(name)+"/sub", 0755)
+		g.Expect(err).To(gomega.BeNil())
+		err = os.WriteFile(tasking.CachePath(name)+"/sub/a", make([]byte, size), 0644)
+		g.Expect(err).To(gomega.BeNil())
+		volume := &model.CacheVolume{
+			Name:     name,
+			LastUsed: time.Now().Add(time.Duration(i) * time.Minute),
+		}
+		err = db.Create(volume).Error
+		g.Expect(err).To(gomega.BeNil())
+		if name == "maven" {
+			err = db.Create(
+				&model.CacheLease{
+					CacheVolumeID: volume.ID,
+					TaskID:        leased.ID,
+				}).Error
+			g.Expect(err).To(gomega.BeNil())
+		}
+	}
+	//
+	// Evicted (LRU) until within capacity.
+	w = send(http.MethodPost, "/reaper", nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	run := ReaperRun{}
+	err = json.Unmarshal(w.Body.Bytes(), &run)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(run.Counts).To(gomega.Equal(map[string]int{"cache.evicted": 1}))
+	g.Expect(run.Reclaimed).To(gomega.Equal(int64(600 * 1024)))
+	_, err = os.Stat(tasking.CachePath("npm"))
+	g.Expect(os.IsNotExist(err)).To(gomega.BeTrue())
+	//
+	// Get.
+	w = send(http.MethodGet, CacheRoot, nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	cache := Cache{}
+	err = json.Unmarshal(w.Body.Bytes(), &cache)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(cache.Volumes)).To(gomega.Equal(3))
+	volumes := make(map[string]CacheVolume)
+	for _, volume := range cache.Volumes {
+		volumes[volume.Name] = volume
+	}
+	g.Expect(volumes["npm"].Size).To(gomega.BeZero())
+	g.Expect(volumes["npm"].Evicted).To(gomega.Equal(int64(1)))
+	g.Expect(volumes["maven"].Size).To(gomega.Equal(int64(600 * 1024)))
+	g.Expect(volumes["maven"].Leases).To(gomega.Equal(
+		[]CacheLease{
+			{Task: Ref{ID: leased.ID, Name: "leased"}},
+		}))
+	g.Expect(volumes["go"].Size).To(gomega.Equal(int64(100 * 1024)))
+	w = send(http.MethodGet, CacheRoot+"/go", nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	cache = Cache{}
+	err = json.Unmarshal(w.Body.Bytes(), &cache)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(cache.Volumes)).To(gomega.Equal(1))
+	g.Expect(cache.Volumes[0].Name).To(gomega.Equal("go"))
+	//
+	// Delete.
+	w = send(http.MethodDelete, CacheRoot+"/maven", nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusConflict))
+	w = send(http.MethodDelete, CacheRoot+"/maven/sub", nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusConflict))
+	_, err = os.Stat(tasking.CachePath("maven"))
+	g.Expect(err).To(gomega.BeNil())
+	w = send(http.MethodDelete, CacheRoot+"/go", nil)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusNoContent))
+	volume := &model.CacheVolume{}
+	err = db.First(volume, "Name", "go").Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(volume.Size).To(gomega.BeZero())
+}
+
+func TestImportDepsRollback(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db := testDB(t)
+	router := testRouter(db)
+	AnalysisHandler{}.AddRoutes(router)
+	application := &model.Application{Name: "A"}
+	err := db.Create(application).Error
+	g.Expect(err).To(gomega.BeNil())
+	prior := &model.Analysis{ApplicationID: application.ID}
+	err = db.Create(prior).Error
+	g.Expect(err).To(gomega.BeNil())
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="file"; filename="sbom.json"`)
+	header.Set(ContentType, sbom.CycloneDX)
+	part, _ := writer.CreatePart(header)
+	_, _ = part.Write([]byte("not an sbom"))
+	_ = writer.Close()
+	w := httptest.NewRecorder()
+	request := httptest.NewRequest(
+		http.MethodPost,
+		strings.Replace(AppAnalysisDepsRoot, ":"+ID, strconv.Itoa(int(application.ID)), 1),
+		body)
+	request.Header.Set(ContentType, writer.FormDataContentType())
+	request.Header.Set(Accept, "application/json")
+	router.ServeHTTP(w, request)
+	g.Expect(w.Code).To(gomega.Equal(http.StatusBadRequest))
+	var analyses []model.Analysis
+	err = db.Find(&analyses).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(analyses)).To(gomega.Equal(1))
+	g.Expect(analyses[0].Archived).To(gomega.BeFalse())
+}
+
+//
+// testDB returns a (migrated) test database.
+// The bucket path is a temporary directory restored on cleanup.
+func testDB(t *testing.T) (db *gorm.DB) {
+	g := gomega.NewGomegaWithT(t)
+	db, err := gorm.Open(
+		sqlite.Open(path.Join(t.TempDir(), "test.db")),
+		&gorm.Config{
+			NamingStrategy: &schema.NamingStrategy{
+				SingularTable: true,
+				NoLowerCase:   true,
+			},
+		})
+	g.Expect(err).To(gomega.BeNil())
+	err = db.AutoMigrate(v12.All()...)
+	g.Expect(err).To(gomega.BeNil())
+	saved := Settings.Hub.Bucket.Path
+	Settings.Hub.Bucket.Path = t.TempDir()
+	t.Cleanup(func() {
+		Settings.Hub.Bucket.Path = saved
+	})
+	return
+}
+
+//
+// testRouter returns a test router using the database.
+// The middleware is used before the error handler.
+func testRouter(db *gorm.DB, middleware ...gin.HandlerFunc) (router *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+	router = gin.New()
+	router.Use(Render())
+	router.Use(
+		func(ctx *gin.Context) {
+			rtx := WithContext(ctx)
+			rtx.DB = db
+		})
+	router.Use(middleware...)
+	router.Use(ErrorHandler())
+	return
+}
@@ -23,6 +23,10 @@ const (
 	ApplicationFactRoot  = ApplicationFactsRoot + "/:" + Key
 	AppBucketRoot        = ApplicationRoot + "/bucket"
 	AppBucketContentRoot = AppBucketRoot + "/*" + Wildcard
+	AppSnapshotsRoot     = ApplicationRoot + "/snapshots"
+	AppSnapshotRoot      = AppSnapshotsRoot + "/:" + ID2
+	AppSnapshotRestore   = AppSnapshotRoot + "/restore"
+	AppSnapshotDiff      = AppSnapshotRoot + "/diff"
 	AppStakeholdersRoot  = ApplicationRoot + "/stakeholders"
 	AppAssessmentsRoot   = ApplicationRoot + "/assessments"
 	AppAssessmentRoot    = AppAssessmentsRoot + "/:" + ID2
@@ -51,7 +55,7 @@ type ApplicationHandler struct {
 // AddRoutes adds routes.
 func (h ApplicationHandler) AddRoutes(e *gin.Engine) {
 	routeGroup := e.Group("/")
-	routeGroup.Use(Required("applications"), Transaction)
+	routeGroup.Use(Required("applications"), Transaction, OwnedApplication)
 	routeGroup.GET(ApplicationsRoot, h.List)
 	routeGroup.GET(ApplicationsRoot+"/", h.List)
 	routeGroup.POST(ApplicationsRoot, h.Create)
@@ -61,7 +65,7 @@ func (h ApplicationHandler) AddRoutes(e *gin.Engine) {
 	routeGroup.DELETE(ApplicationRoot, h.Delete)
 	// Tags
 	routeGroup = e.Group("/")
-	routeGroup.Use(Required("applications"))
+	routeGroup.Use(Required("applications"), OwnedApplication)
 	routeGroup.GET(ApplicationTagsRoot, h.TagList)
 	routeGroup.GET(ApplicationTagsRoot+"/", h.TagList)
 	routeGroup.POST(ApplicationTagsRoot, h.TagAdd)
@@ -69,7 +73,7 @@ func (h ApplicationHandler) AddRoutes(e *gin.Engine) {
 	routeGroup.PUT(ApplicationTagsRoot, h.TagReplace, Transaction)
 	// Facts
 	routeGroup = e.Group("/")
-	routeGroup.Use(Required("applications.facts"))
+	routeGroup.Use(Required("applications.facts"), OwnedApplication)
 	routeGroup.GET(ApplicationFactsRoot, h.FactGet)
 	routeGroup.GET(ApplicationFactsRoot+"/", h.FactGet)
 	routeGroup.POST(ApplicationFactsRoot, h.FactCreate)
@@ -79,19 +83,25 @@ func (h ApplicationHandler) AddRoutes(e *gin.Engine) {
 	routeGroup.PUT(ApplicationFactsRoot, h.FactPut, Transaction)
 	// Bucket
 	routeGroup = e.Group("/")
-	routeGroup.Use(Required("applications.bucket"))
+	routeGroup.Use(Required("applications.bucket"), OwnedApplication)
 	routeGroup.GET(AppBucketRoot, h.BucketGet)
 	routeGroup.GET(AppBucketContentRoot, h.BucketGet)
 	routeGroup.POST(AppBucketContentRoot, h.BucketPut)
 	routeGroup.PUT(AppBucketContentRoot, h.BucketPut)
 	routeGroup.DELETE(AppBucketContentRoot, h.BucketDelete)
+	routeGroup.GET(AppSnapshotsRoot, h.SnapshotList)
+	routeGroup.POST(AppSnapshotsRoot, h.SnapshotCreate)
+	routeGroup.GET(AppSnapshotRoot, h.SnapshotGet)
+	routeGroup.DELETE(AppSnapshotRoot, h.SnapshotDelete)
+	routeGroup.PUT(AppSnapshotRestore, h.SnapshotRestore)
+	routeGroup.GET(AppSnapshotDiff, h.SnapshotDiff)
 	// Stakeholders
 	routeGroup = e.Group("/")
-	routeGroup.Use(Required("applications.stakeholders"))
+	routeGroup.Use(Required("applications.stakeholders"), OwnedApplication)
 	routeGroup.PUT(AppStakeholdersRoot, h.StakeholdersUpdate)
 	// Assessments
 	routeGroup = e.Group("/")
-	routeGroup.Use(Required("applications.assessments"))
+	routeGroup.Use(Required("applications.assessments"), OwnedApplication)
 	routeGroup.GET(AppAssessmentsRoot, h.AssessmentList)
 	routeGroup.POST(AppAssessmentsRoot, h.AssessmentCreate)
 }
@@ -147,13 +157,16 @@ func (h ApplicationHandler) Get(ctx *gin.Context) {
 // List godoc
 // @summary List all applications.
 // @description List all applications.
+// @description When ownership is enforced, only owned applications are listed.
 // @tags applications
 // @produce json
 // @success 200 {object} []api.Application
 // @router /applications [get]
 func (h ApplicationHandler) List(ctx *gin.Context) {
 	var list []model.Application
+	ownership := Ownership{ctx: ctx}
 	db := h.preLoad(h.DB(ctx), clause.Associations)
+	db = ownership.Where(db, "ID")
 	result := db.Find(&list)
 	if result.Error != nil {
 		_ = ctx.Error(result.Error)
@@ -232,6 +245,12 @@ func (h ApplicationHandler) Create(ctx *gin.Context) {
 			return
 		}
 	}
+	ownership := Ownership{ctx: ctx}
+	err = ownership.Permit(m.ID)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 
 	questionnaire, err := assessment.NewQuestionnaireResolver(h.DB(ctx))
 	if err != nil {
@@ -292,6 +311,14 @@ func (h ApplicationHandler) DeleteList(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	ownership := Ownership{ctx: ctx}
+	for _, id := range ids {
+		err = ownership.Permit(id)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+	}
 	err = h.DB(ctx).Delete(
 		&model.Application{},
 		"id IN ?",
@@ -383,6 +410,9 @@ func (h ApplicationHandler) Update(ctx *gin.Context) {
 // @summary Get bucket content by ID and path.
 // @description Get bucket content by ID and path.
 // @description Returns index.html for directories when Accept=text/html else a tarball.
+// @description Returns the manifest for directories when ?manifest=true.
+// @description The tarball compression (gzip|zstd) is negotiated using the X-Compression header.
+// @description Files support (single) byte ranges.
 // @description ?filter=glob supports directory content filtering.
 // @tags applications
 // @produce octet-stream
@@ -391,6 +421,7 @@ func (h ApplicationHandler) Update(ctx *gin.Context) {
 // @param id path int true "Application ID"
 // @param wildcard path string true "Content path"
 // @param filter query string false "Filter"
+// @param manifest query bool false "Manifest"
 func (h ApplicationHandler) BucketGet(ctx *gin.Context) {
 	m := &model.Application{}
 	id := h.pk(ctx)
@@ -410,6 +441,8 @@ func (h ApplicationHandler) BucketGet(ctx *gin.Context) {
 // BucketPut godoc
 // @summary Upload bucket content by ID and path.
 // @description Upload bucket content by ID and path (handles both [post] and [put] requests).
+// @description X-Directory=expand replaces the directory; X-Directory=merge writes into the directory.
+// @description The bucket is snapshot before it is first written by a task.
 // @tags applications
 // @produce json
 // @success 204
@@ -428,6 +461,11 @@ func (h ApplicationHandler) BucketPut(ctx *gin.Context) {
 		h.Status(ctx, http.StatusNotFound)
 		return
 	}
+	err := h.snapshotBefore(ctx, *m.BucketID)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 
 	h.bucketPut(ctx, *m.BucketID)
 }
@@ -453,10 +491,131 @@ func (h ApplicationHandler) BucketDelete(ctx *gin.Context) {
 		h.Status(ctx, http.StatusNotFound)
 		return
 	}
+	err := h.snapshotBefore(ctx, *m.BucketID)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 
 	h.bucketDelete(ctx, *m.BucketID)
 }
 
+// SnapshotList godoc
+// @summary List bucket snapshots.
+// @description List bucket snapshots.
+// @tags applications
+// @produce json
+// @success 200 {object} []api.BucketSnapshot
+// @router /applications/{id}/snapshots [get]
+// @param id path int true "Application ID"
+func (h ApplicationHandler) SnapshotList(ctx *gin.Context) {
+	id, found := h.bucketID(ctx)
+	if found {
+		h.snapshotList(ctx, id)
+	}
+}
+
+// SnapshotCreate godoc
+// @summary Create a bucket snapshot.
+// @description Create a (manual) snapshot of the bucket content.
+// @tags applications
+// @produce json
+// @success 201 {object} api.BucketSnapshot
+// @router /applications/{id}/snapshots [post]
+// @param id path int true "Application ID"
+func (h ApplicationHandler) SnapshotCreate(ctx *gin.Context) {
+	id, found := h.bucketID(ctx)
+	if found {
+		h.snapshotCreate(ctx, id)
+	}
+}
+
+// SnapshotGet godoc
+// @summary Get a bucket snapshot.
+// @description Get a bucket snapshot.
+// @tags applications
+// @produce json
+// @success 200 {object} api.BucketSnapshot
+// @router /applications/{id}/snapshots/{sid} [get]
+// @param id path int true "Application ID"
+// @param sid path int true "Snapshot ID"
+func (h ApplicationHandler) SnapshotGet(ctx *gin.Context) {
+	id, found := h.bucketID(ctx)
+	if found {
+		h.snapshotGet(ctx, id)
+	}
+}
+
+// SnapshotDelete godoc
+// @summary Delete a bucket snapshot.
+// @description Delete a bucket snapshot.
+// @tags applications
+// @produce json
+// @success 204
+// @router /applications/{id}/snapshots/{sid} [delete]
+// @param id path int true "Application ID"
+// @param sid path int true "Snapshot ID"
+func (h ApplicationHandler) SnapshotDelete(ctx *gin.Context) {
+	id, found := h.bucketID(ctx)
+	if found {
+		h.snapshotDelete(ctx, id)
+	}
+}
+
+// SnapshotRestore godoc
+// @summary Restore a bucket snapshot.
+// @description Restore the bucket content from the snapshot.
+// @description The current content is snapshot before it is replaced.
+// @tags applications
+// @produce json
+// @success 204
+// @router /applications/{id}/snapshots/{sid}/restore [put]
+// @param id path int true "Application ID"
+// @param sid path int true "Snapshot ID"
+func (h ApplicationHandler) SnapshotRestore(ctx *gin.Context) {
+	id, found := h.bucketID(ctx)
+	if found {
+		h.snapshotRestore(ctx, id)
+	}
+}
+
+// SnapshotDiff godoc
+// @summary Diff a bucket snapshot.
+// @description Compare the snapshot with the current bucket content.
+// @description ?snapshot=id compares with another snapshot.
+// @tags applications
+// @produce json
+// @success 200 {object} api.BucketDiff
+// @router /applications/{id}/snapshots/{sid}/diff [get]
+// @param id path int true "Application ID"
+// @param sid path int true "Snapshot ID"
+// @param snapshot query int false "Snapshot ID"
+func (h ApplicationHandler) SnapshotDiff(ctx *gin.Context) {
+	id, found := h.bucketID(ctx)
+	if found {
+		h.snapshotDiff(ctx, id)
+	}
+}
+
+//
+// bucketID returns the application bucket ID.
+// Reports not found when the application has no bucket.
+func (h ApplicationHandler) bucketID(ctx *gin.Context) (id uint, found bool) {
+	m := &model.Application{}
+	result := h.DB(ctx).First(m, h.pk(ctx))
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	if !m.HasBucket() {
+		h.Status(ctx, http.StatusNotFound)
+		return
+	}
+	id = *m.BucketID
+	found = true
+	return
+}
+
 // TagList godoc
 // @summary List tag references.
 // @description List tag references.
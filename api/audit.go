@@ -0,0 +1,479 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	qf "github.com/konveyor/tackle2-hub/api/filter"
+	"github.com/konveyor/tackle2-hub/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//
+// Routes
+const (
+	AuditLogRoot   = "/auditlog"
+	AuditEntryRoot = AuditLogRoot + "/:" + ID
+)
+
+//
+// Audit outcomes.
+const (
+	AuditSucceeded = "succeeded"
+	AuditDenied    = "denied"
+	AuditFailed    = "failed"
+)
+
+//
+// AuditMasked fields are reported as changed without values.
+var AuditMasked = []string{
+	"Digest",
+	"Key",
+	"Password",
+	"Secret",
+	"Settings",
+}
+
+//
+// AuditIgnored fields are not reported as changed.
+var AuditIgnored = []string{
+	"CreateTime",
+	"CreateUser",
+	"ID",
+	"UpdateUser",
+}
+
+//
+// AuditHandler handles audit log routes.
+type AuditHandler struct {
+	BaseHandler
+}
+
+//
+// AddRoutes adds routes.
+func (h AuditHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("auditlog"))
+	routeGroup.GET(AuditLogRoot, h.List)
+	routeGroup.GET(AuditLogRoot+"/", h.List)
+	routeGroup.GET(AuditEntryRoot, h.Get)
+}
+
+// Get godoc
+// @summary Get an audit log entry by ID.
+// @description Get an audit log entry by ID.
+// @tags auditlog
+// @produce json
+// @success 200 {object} api.AuditEntry
+// @router /auditlog/{id} [get]
+// @param id path int true "Entry ID"
+func (h AuditHandler) Get(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.AuditEntry{}
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r := AuditEntry{}
+	r.With(m)
+
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// List godoc
+// @summary List audit log entries.
+// @description List audit log entries (newest first).
+// @description filters:
+// @description - id
+// @description - user
+// @description - method
+// @description - route
+// @description - path
+// @description - kind
+// @description - resourceId
+// @description - status
+// @description - outcome
+// @tags auditlog
+// @produce json
+// @success 200 {object} []api.AuditEntry
+// @router /auditlog [get]
+func (h AuditHandler) List(ctx *gin.Context) {
+	resources := []AuditEntry{}
+	// Filter
+	filter, err := qf.New(ctx,
+		[]qf.Assert{
+			{Field: "id", Kind: qf.LITERAL},
+			{Field: "user", Kind: qf.STRING},
+			{Field: "method", Kind: qf.STRING},
+			{Field: "route", Kind: qf.STRING},
+			{Field: "path", Kind: qf.STRING},
+			{Field: "kind", Kind: qf.STRING},
+			{Field: "resourceId", Kind: qf.LITERAL},
+			{Field: "status", Kind: qf.LITERAL},
+			{Field: "outcome", Kind: qf.STRING},
+		})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	// Sort
+	sort := Sort{}
+	err = sort.With(ctx, &model.AuditEntry{})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	// Find
+	db := h.DB(ctx)
+	db = db.Model(&model.AuditEntry{})
+	db = filter.Where(db)
+	db = sort.Sorted(db)
+	db = db.Order("ID DESC")
+	var list []model.AuditEntry
+	var m model.AuditEntry
+	page := Page{}
+	page.With(ctx)
+	cursor := Cursor{}
+	cursor.With(db, page)
+	defer func() {
+		cursor.Close()
+	}()
+	for cursor.Next(&m) {
+		if cursor.Error != nil {
+			_ = ctx.Error(cursor.Error)
+			return
+		}
+		list = append(list, m)
+	}
+	err = h.WithCount(ctx, cursor.Count())
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	// Render
+	for i := range list {
+		r := AuditEntry{}
+		r.With(&list[i])
+		resources = append(resources, r)
+	}
+
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+//
+// AuditLog returns middleware that records mutating requests
+// and identity decrypts in the audit log.
+// Must be installed after the DB is set in the context and
+// before the ErrorHandler so the final status is known.
+// Field-level changes are collected by a gorm (update) callback
+// registered on the DB.
+func AuditLog(db *gorm.DB) gin.HandlerFunc {
+	err := db.Callback().Update().Before("gorm:update").Register(
+		"audit:update",
+		func(tx *gorm.DB) {
+			auditor, cast := tx.Statement.Context.Value(auditKey{}).(*Auditor)
+			if cast {
+				auditor.Updated(tx)
+			}
+		})
+	if err != nil {
+		log.Error(err, "Audit callback not registered.")
+	}
+	return func(ctx *gin.Context) {
+		auditor := &Auditor{}
+		rtx := WithContext(ctx)
+		if rtx.DB != nil {
+			rtx.DB = rtx.DB.WithContext(
+				context.WithValue(
+					rtx.DB.Statement.Context,
+					auditKey{},
+					auditor))
+		}
+		ctx.Next()
+		if !auditor.Audited(ctx) {
+			return
+		}
+		m := auditor.Entry(ctx)
+		err := db.Create(m).Error
+		if err != nil {
+			log.Error(err, "Audit entry not created.")
+		}
+	}
+}
+
+//
+// auditKey is the (statement) context key for the auditor.
+type auditKey struct{}
+
+//
+// Auditor collects field-level changes made while
+// handling a request.
+type Auditor struct {
+	changes []AuditChange
+}
+
+//
+// Audited returns true when the request is to be audited.
+func (r *Auditor) Audited(ctx *gin.Context) (b bool) {
+	if ctx.FullPath() == "" {
+		return
+	}
+	switch ctx.Request.Method {
+	case http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete:
+		b = true
+	default:
+		b = ctx.GetBool(Decrypted)
+	}
+	return
+}
+
+//
+// Entry builds the audit entry for the (completed) request.
+func (r *Auditor) Entry(ctx *gin.Context) (m *model.AuditEntry) {
+	rtx := WithContext(ctx)
+	route := ctx.FullPath()
+	m = &model.AuditEntry{
+		User:   rtx.User,
+		Method: ctx.Request.Method,
+		Route:  route,
+		Path:   ctx.Request.URL.Path,
+		Status: rtx.Response.Status,
+	}
+	if m.Status == 0 {
+		m.Status = ctx.Writer.Status()
+	}
+	part := strings.Split(strings.Trim(route, "/"), "/")
+	m.Kind = part[0]
+	switch {
+	case m.Status == http.StatusUnauthorized,
+		m.Status == http.StatusForbidden:
+		m.Outcome = AuditDenied
+	case m.Status >= http.StatusBadRequest:
+		m.Outcome = AuditFailed
+	default:
+		m.Outcome = AuditSucceeded
+	}
+	n, err := strconv.Atoi(ctx.Param(ID))
+	if err == nil {
+		id := uint(n)
+		m.ResourceID = &id
+	} else {
+		m.ResourceID = r.created(rtx)
+	}
+	if m.Outcome == AuditSucceeded && len(r.changes) > 0 {
+		m.Changes, _ = json.Marshal(r.changes)
+	}
+	return
+}
+
+//
+// Updated collects the field-level changes made by an update.
+// The current row is fetched (within the transaction) and
+// compared to the updated values.
+func (r *Auditor) Updated(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil {
+		return
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil || stmt.ReflectValue.Kind() != reflect.Struct {
+		return
+	}
+	id, zero := pk.ValueOf(stmt.Context, stmt.ReflectValue)
+	if zero {
+		return
+	}
+	current := reflect.New(stmt.Schema.ModelType)
+	err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).
+		Where(pk.DBName, id).
+		Take(current.Interface()).Error
+	if err != nil {
+		return
+	}
+	updated := make(map[string]interface{})
+	switch dest := stmt.Dest.(type) {
+	case map[string]interface{}:
+		for name, v := range dest {
+			field := stmt.Schema.LookUpField(name)
+			if field == nil {
+				continue
+			}
+			updated[field.Name] = v
+		}
+	default:
+		v := reflect.Indirect(reflect.ValueOf(dest))
+		if v.Kind() != reflect.Struct || v.Type() != stmt.Schema.ModelType {
+			return
+		}
+		all := len(stmt.Selects) > 0 && stmt.Selects[0] == "*"
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || !field.Updatable {
+				continue
+			}
+			fv, zero := field.ValueOf(stmt.Context, v)
+			if zero && !all {
+				continue
+			}
+			updated[field.Name] = fv
+		}
+	}
+	for _, field := range stmt.Schema.Fields {
+		v, found := updated[field.Name]
+		if !found || r.ignored(field) {
+			continue
+		}
+		if _, expr := v.(clause.Expression); expr {
+			continue
+		}
+		before, _ := field.ValueOf(stmt.Context, current.Elem())
+		oldValue := auditValue(before)
+		newValue := auditValue(v)
+		if oldValue == newValue {
+			continue
+		}
+		change := AuditChange{
+			Kind:  stmt.Schema.Name,
+			ID:    fmt.Sprint(id),
+			Field: field.Name,
+			Old:   oldValue,
+			New:   newValue,
+		}
+		if r.masked(field) {
+			change.Old = ""
+			change.New = ""
+			change.Masked = true
+		}
+		r.changes = append(r.changes, change)
+	}
+}
+
+//
+// created returns the ID of the created resource (in the response).
+func (r *Auditor) created(rtx *Context) (id *uint) {
+	if rtx.Request.Method != http.MethodPost || rtx.Response.Body == nil {
+		return
+	}
+	b, err := json.Marshal(rtx.Response.Body)
+	if err != nil {
+		return
+	}
+	resource := struct {
+		ID uint `json:"id"`
+	}{}
+	err = json.Unmarshal(b, &resource)
+	if err == nil && resource.ID > 0 {
+		id = &resource.ID
+	}
+	return
+}
+
+//
+// ignored returns true when the field is not audited.
+func (r *Auditor) ignored(field *schema.Field) (b bool) {
+	for _, name := range AuditIgnored {
+		if field.Name == name {
+			b = true
+			break
+		}
+	}
+	return
+}
+
+//
+// masked returns true when the field value is masked.
+func (r *Auditor) masked(field *schema.Field) (b bool) {
+	for _, name := range AuditMasked {
+		if field.Name == name {
+			b = true
+			break
+		}
+	}
+	return
+}
+
+//
+// auditValue returns the (normalized) string value.
+func auditValue(in interface{}) (s string) {
+	v := reflect.ValueOf(in)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return
+	}
+	switch object := v.Interface().(type) {
+	case time.Time:
+		if !object.IsZero() {
+			s = object.UTC().Format(time.RFC3339)
+		}
+	case []byte:
+		s = string(object)
+	case bool:
+		if object {
+			s = "true"
+		} else {
+			s = "false"
+		}
+	default:
+		s = fmt.Sprint(object)
+	}
+	return
+}
+
+//
+// AuditChange field-level change.
+type AuditChange struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Field  string `json:"field"`
+	Old    string `json:"old,omitempty" yaml:",omitempty"`
+	New    string `json:"new,omitempty" yaml:",omitempty"`
+	Masked bool   `json:"masked,omitempty" yaml:",omitempty"`
+}
+
+//
+// AuditEntry REST resource.
+type AuditEntry struct {
+	ID         uint          `json:"id"`
+	CreateTime time.Time     `json:"createTime" yaml:"createTime"`
+	User       string        `json:"user"`
+	Method     string        `json:"method"`
+	Route      string        `json:"route"`
+	Path       string        `json:"path"`
+	Kind       string        `json:"kind"`
+	ResourceID *uint         `json:"resourceId,omitempty" yaml:"resourceId,omitempty"`
+	Status     int           `json:"status"`
+	Outcome    string        `json:"outcome"`
+	Changes    []AuditChange `json:"changes,omitempty" yaml:",omitempty"`
+}
+
+//
+// With updates the resource with the model.
+func (r *AuditEntry) With(m *model.AuditEntry) {
+	r.ID = m.ID
+	r.CreateTime = m.CreateTime
+	r.User = m.User
+	r.Method = m.Method
+	r.Route = m.Route
+	r.Path = m.Path
+	r.Kind = m.Kind
+	r.ResourceID = m.ResourceID
+	r.Status = m.Status
+	r.Outcome = m.Outcome
+	_ = json.Unmarshal(m.Changes, &r.Changes)
+}
@@ -123,7 +123,11 @@ func Required(scope string) func(*gin.Context) {
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
+		if result.Task == 0 && RateLimited(ctx, RateKey(ctx, result)) {
+			return
+		}
 		rtx.User = result.User
 		rtx.Scopes = result.Scopes
+		rtx.Task = result.Task
 	}
 }
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/backup"
+	"github.com/konveyor/tackle2-hub/tar"
+	"net/http"
+	"time"
+)
+
+//
+// Routes
+const (
+	BackupRoot = "/backup"
+)
+
+//
+// BackupHandler handles backup routes.
+type BackupHandler struct {
+	BaseHandler
+}
+
+//
+// AddRoutes adds routes.
+func (h BackupHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("backup"))
+	routeGroup.GET(BackupRoot, h.Get)
+}
+
+// Get godoc
+// @summary Get a backup archive.
+// @description Get a backup archive (tarball) of the database and the
+// @description stored (bucket and file) content. The archive includes a
+// @description manifest with the migration version and checksums.
+// @description The compression (gzip|zstd) is negotiated using the X-Compression header.
+// @description The archive is restored using: hub restore <path>.
+// @tags backup
+// @produce octet-stream
+// @success 200
+// @router /backup [get]
+func (h BackupHandler) Get(ctx *gin.Context) {
+	compression := tar.Negotiate(ctx.GetHeader(Compression))
+	name := "hub-" + time.Now().UTC().Format("20060102150405")
+	h.Attachment(ctx, name+tar.Extension(compression))
+	ctx.Writer.Header().Set(Compression, compression)
+	ctx.Status(http.StatusOK)
+	_, err := backup.Backup(h.DB(ctx), ctx.Writer, compression)
+	if err != nil {
+		Log.Error(err, "")
+		return
+	}
+}
@@ -14,9 +14,13 @@ import (
 	"github.com/konveyor/tackle2-hub/api/sort"
 	"github.com/konveyor/tackle2-hub/auth"
 	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/storage"
 	"gopkg.in/yaml.v2"
 	"gorm.io/gorm"
 	"io"
+	"mime"
+	"net/http"
+	pathlib "path"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"strconv"
 	"strings"
@@ -145,6 +149,15 @@ func (h *BaseHandler) CurrentUser(ctx *gin.Context) (user string) {
 	return
 }
 
+//
+// CurrentTask returns the task referenced by the (addon) token.
+// Returns zero (0) when the request is not made by a task.
+func (h *BaseHandler) CurrentTask(ctx *gin.Context) (id uint) {
+	rtx := WithContext(ctx)
+	id = rtx.Task
+	return
+}
+
 //
 // HasScope determines if the token has the specified scope.
 func (h *BaseHandler) HasScope(ctx *gin.Context, scope string) (b bool) {
@@ -296,6 +309,111 @@ func (h *BaseHandler) Attachment(ctx *gin.Context, name string) {
 		attachment)
 }
 
+//
+// Content streams stored content.
+// The content type is determined by the (path) extension
+// when not specified.
+// A (single) byte range is supported.
+func (h *BaseHandler) Content(ctx *gin.Context, path, contentType string) {
+	info, err := storage.Store.Stat(path)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	if info.Dir {
+		h.Status(ctx, http.StatusNotFound)
+		return
+	}
+	ctx.Header(AcceptRanges, "bytes")
+	status, offset, length := h.byteRange(ctx, info.Size)
+	var reader io.ReadCloser
+	switch status {
+	case http.StatusRequestedRangeNotSatisfiable:
+		ctx.Header(ContentRange, fmt.Sprintf("bytes */%d", info.Size))
+		h.Status(ctx, status)
+		return
+	case http.StatusPartialContent:
+		ctx.Header(
+			ContentRange,
+			fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, info.Size))
+		reader, err = storage.Store.GetRange(path, offset, length)
+	default:
+		reader, err = storage.Store.Get(path)
+	}
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	if contentType == "" {
+		contentType = mime.TypeByExtension(pathlib.Ext(path))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	ctx.DataFromReader(status, length, contentType, reader, nil)
+}
+
+//
+// byteRange returns the status, offset and length of
+// the requested (Range header) content.
+// Multiple ranges are not supported and the entire
+// content is returned.
+func (h *BaseHandler) byteRange(ctx *gin.Context, size int64) (status int, offset, length int64) {
+	status = http.StatusOK
+	length = size
+	header := ctx.GetHeader(Range)
+	if header == "" || strings.Contains(header, ",") {
+		return
+	}
+	status = http.StatusRequestedRangeNotSatisfiable
+	if !strings.HasPrefix(header, "bytes=") {
+		return
+	}
+	part := strings.SplitN(strings.TrimPrefix(header, "bytes="), "-", 2)
+	if len(part) != 2 {
+		return
+	}
+	begin := strings.TrimSpace(part[0])
+	end := strings.TrimSpace(part[1])
+	switch {
+	case begin == "":
+		n, err := strconv.ParseInt(end, 10, 64)
+		if err != nil || n <= 0 {
+			return
+		}
+		if n > size {
+			n = size
+		}
+		offset = size - n
+		length = n
+	default:
+		n, err := strconv.ParseInt(begin, 10, 64)
+		if err != nil || n < 0 || n >= size {
+			return
+		}
+		offset = n
+		last := size - 1
+		if end != "" {
+			n, err = strconv.ParseInt(end, 10, 64)
+			if err != nil || n < offset {
+				return
+			}
+			if n < last {
+				last = n
+			}
+		}
+		length = last - offset + 1
+	}
+	if length <= 0 {
+		return
+	}
+	status = http.StatusPartialContent
+	return
+}
+
 //
 // REST resource.
 type Resource struct {
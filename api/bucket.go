@@ -1,15 +1,19 @@
 package api
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/konveyor/tackle2-hub/model"
-	"github.com/konveyor/tackle2-hub/nas"
+	"github.com/konveyor/tackle2-hub/quota"
+	"github.com/konveyor/tackle2-hub/storage"
 	"github.com/konveyor/tackle2-hub/tar"
 	"io"
 	"net/http"
-	"os"
 	pathlib "path"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -21,6 +25,12 @@ const (
 	BucketContentRoot = BucketRoot + "/*" + Wildcard
 )
 
+//
+// Params
+const (
+	Manifest = "manifest"
+)
+
 //
 // BucketHandler handles bucket routes.
 type BucketHandler struct {
@@ -31,7 +41,7 @@ type BucketHandler struct {
 // AddRoutes adds routes.
 func (h BucketHandler) AddRoutes(e *gin.Engine) {
 	routeGroup := e.Group("/")
-	routeGroup.Use(Required("buckets"))
+	routeGroup.Use(Required("buckets"), OwnedBucket)
 	routeGroup.GET(BucketsRoot, h.List)
 	routeGroup.GET(BucketsRoot+"/", h.List)
 	routeGroup.POST(BucketsRoot, h.Create)
@@ -46,13 +56,30 @@ func (h BucketHandler) AddRoutes(e *gin.Engine) {
 // List godoc
 // @summary List all buckets.
 // @description List all buckets.
+// @description When ownership is enforced, buckets associated with
+// @description applications that are not owned are not listed.
 // @tags buckets
 // @produce json
 // @success 200 {object} []api.Bucket
 // @router /buckets [get]
 func (h BucketHandler) List(ctx *gin.Context) {
 	var list []model.Bucket
-	result := h.DB(ctx).Find(&list)
+	db := h.DB(ctx)
+	ownership := Ownership{ctx: ctx}
+	if ownership.Restricted() {
+		owned := ownership.AppIDs()
+		app := h.DB(ctx).Model(&model.Application{})
+		app = app.Select("BucketID")
+		app = app.Where("BucketID IS NOT NULL")
+		app = app.Where("ID NOT IN (?)", owned)
+		task := h.DB(ctx).Model(&model.Task{})
+		task = task.Select("BucketID")
+		task = task.Where("BucketID IS NOT NULL")
+		task = task.Where("ApplicationID NOT IN (?)", owned)
+		db = db.Where("ID NOT IN (?)", app)
+		db = db.Where("ID NOT IN (?)", task)
+	}
+	result := db.Find(&list)
 	if result.Error != nil {
 		_ = ctx.Error(result.Error)
 		return
@@ -119,7 +146,7 @@ func (h BucketHandler) Get(ctx *gin.Context) {
 
 // Delete godoc
 // @summary Delete a bucket.
-// @description Delete a bucket.
+// @description Delete a bucket and the bucket snapshots.
 // @tags buckets
 // @success 204
 // @router /buckets/{id} [delete]
@@ -132,13 +159,25 @@ func (h BucketHandler) Delete(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
-	err = nas.RmDir(m.Path)
+	snapshots := []model.BucketSnapshot{}
+	err = h.DB(ctx).Find(&snapshots, "BucketID", m.ID).Error
 	if err != nil {
-		if !os.IsNotExist(err) {
+		_ = ctx.Error(err)
+		return
+	}
+	for i := range snapshots {
+		err = storage.Store.Delete(snapshots[i].Path)
+		if err != nil {
 			_ = ctx.Error(err)
 			return
 		}
 	}
+	err = storage.Store.Delete(m.Path)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	quota.Reset()
 	err = h.DB(ctx).Delete(m).Error
 	if err != nil {
 		_ = ctx.Error(err)
@@ -152,9 +191,13 @@ func (h BucketHandler) Delete(ctx *gin.Context) {
 // @summary Get bucket content by ID and path.
 // @description Get bucket content by ID and path.
 // @description When path is FILE, returns file content.
+// @description When path is DIRECTORY and ?manifest=true returns the manifest.
 // @description When path is DIRECTORY and Accept=text/html returns index.html.
 // @description ?filter=glob supports directory content filtering.
 // @description Else returns a tarball.
+// @description The tarball compression (gzip|zstd) is negotiated using the X-Compression header.
+// @description The compression accepted for uploads is advertised using the X-Accept-Compression header.
+// @description Files support (single) byte ranges.
 // @tags buckets
 // @produce octet-stream
 // @success 200
@@ -162,6 +205,7 @@ func (h BucketHandler) Delete(ctx *gin.Context) {
 // @param id path int true "Task ID"
 // @param wildcard path string true "Content path"
 // @param filter query string false "Filter"
+// @param manifest query bool false "Manifest"
 func (h BucketHandler) BucketGet(ctx *gin.Context) {
 	h.bucketGet(ctx, h.pk(ctx))
 }
@@ -169,6 +213,8 @@ func (h BucketHandler) BucketGet(ctx *gin.Context) {
 // BucketPut godoc
 // @summary Upload bucket content by ID and path.
 // @description Upload bucket content by ID and path (handles both [post] and [put] requests).
+// @description X-Directory=expand replaces the directory; X-Directory=merge writes into the directory.
+// @description The compression accepted for uploads is advertised using the X-Accept-Compression header.
 // @tags buckets
 // @produce json
 // @success 204
@@ -208,6 +254,16 @@ func (r *Bucket) With(m *model.Bucket) {
 	r.Expiration = m.Expiration
 }
 
+//
+// BucketEntry REST resource.
+// Describes a file in the bucket manifest.
+type BucketEntry struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	Digest  string    `json:"digest"`
+	ModTime time.Time `json:"modTime"`
+}
+
 type BucketOwner struct {
 	BaseHandler
 }
@@ -215,6 +271,7 @@ type BucketOwner struct {
 //
 // bucketGet reads bucket content.
 // When path is DIRECTORY:
+//    ?manifest=true return body is the manifest.
 //    Accept=text/html return body is index.html.
 //    Else streams tarball.
 // When path is FILE:
@@ -227,29 +284,41 @@ func (h *BucketOwner) bucketGet(ctx *gin.Context, id uint) {
 		_ = ctx.Error(result.Error)
 		return
 	}
+	ctx.Writer.Header().Set(AcceptCompression, tar.Supported)
 	path := pathlib.Join(m.Path, ctx.Param(Wildcard))
-	st, err := os.Stat(path)
-	if os.IsNotExist(err) {
-		h.Status(ctx, http.StatusNotFound)
-		return
+	st, err := storage.Store.Stat(path)
+	if err != nil {
+		if !errors.Is(err, &storage.NotFound{}) {
+			_ = ctx.Error(err)
+			return
+		}
+		if path != pathlib.Clean(m.Path) {
+			h.Status(ctx, http.StatusNotFound)
+			return
+		}
+		st = storage.Info{Path: path, Dir: true}
 	}
-	if st.IsDir() {
+	if st.Dir {
 		filter := tar.NewFilter(path)
 		filter.Include(ctx.Query(Filter))
-		if h.Accepted(ctx, binding.MIMEHTML) {
-			h.getFile(ctx, m)
+		manifest, _ := strconv.ParseBool(ctx.Query(Manifest))
+		if manifest {
+			h.manifest(ctx, path, filter)
+		} else if h.Accepted(ctx, binding.MIMEHTML) {
+			h.getFile(ctx, pathlib.Join(path, "index.html"))
 		} else {
 			h.getDir(ctx, path, filter)
 		}
 	} else {
-		h.getFile(ctx, m)
+		h.getFile(ctx, path)
 	}
 }
 
 //
 // bucketPut write a file to the bucket.
 // The `Directory` header determines how the uploaded file is to be handled.
-// When `Directory`=Expand, the file (TARBALL) is extracted into the bucket.
+// When `Directory`=Expand, the file (TARBALL) replaces the directory.
+// When `Directory`=Merge, the file (TARBALL) is extracted into the directory.
 // Else the file is stored.
 func (h *BucketOwner) bucketPut(ctx *gin.Context, id uint) {
 	var err error
@@ -259,10 +328,15 @@ func (h *BucketOwner) bucketPut(ctx *gin.Context, id uint) {
 		_ = ctx.Error(result.Error)
 		return
 	}
-	if ctx.Request.Header.Get(Directory) == DirectoryExpand {
-		err = h.putDir(ctx, pathlib.Join(m.Path, ctx.Param(Wildcard)))
-	} else {
-		err = h.putFile(ctx, m)
+	ctx.Writer.Header().Set(AcceptCompression, tar.Supported)
+	path := pathlib.Join(m.Path, ctx.Param(Wildcard))
+	switch ctx.Request.Header.Get(Directory) {
+	case DirectoryExpand:
+		err = h.putDir(ctx, m, path, false)
+	case DirectoryMerge:
+		err = h.putDir(ctx, m, path, true)
+	default:
+		err = h.putFile(ctx, m, path)
 	}
 	if err != nil {
 		_ = ctx.Error(err)
@@ -282,17 +356,23 @@ func (h *BucketOwner) bucketDelete(ctx *gin.Context, id uint) {
 	}
 	rPath := ctx.Param(Wildcard)
 	path := pathlib.Join(m.Path, rPath)
-	err := nas.RmDir(path)
+	err := storage.Store.Delete(path)
 	if err != nil {
 		_ = ctx.Error(err)
 		return
 	}
+	quota.Reset()
 	h.Status(ctx, http.StatusNoContent)
 }
 
 //
 // putDir write a directory into bucket.
-func (h *BucketOwner) putDir(ctx *gin.Context, output string) (err error) {
+// The directory is replaced by the (tarball) content.
+// When merged, the (tarball) content is written into the
+// directory and other content is retained.
+// The upload is read once and the quota is checked (reserved)
+// as each file is written.
+func (h *BucketOwner) putDir(ctx *gin.Context, m *model.Bucket, output string, merge bool) (err error) {
 	file, err := ctx.FormFile(FileField)
 	if err != nil {
 		err = &BadRequestError{err.Error()}
@@ -307,47 +387,168 @@ func (h *BucketOwner) putDir(ctx *gin.Context, output string) (err error) {
 	defer func() {
 		_ = fileReader.Close()
 	}()
-	err = nas.RmDir(output)
+	current := int64(0)
+	if !merge {
+		current, err = quota.Size(output)
+		if err != nil {
+			return
+		}
+	}
+	reservation, err := quota.Check(m.Path, -current)
+	if err != nil {
+		return
+	}
+	defer func() {
+		reservation.Release()
+		quota.Reset()
+	}()
+	if !merge {
+		err = storage.Store.Delete(output)
+		if err != nil {
+			return
+		}
+	}
+	err = storage.Store.MkDir(output)
 	if err != nil {
 		return
 	}
+	var putErr error
 	tarReader := tar.NewReader()
-	err = tarReader.Extract(output, fileReader)
+	err = tarReader.Walk(
+		fileReader,
+		func(path string, size int64, reader io.Reader) (err error) {
+			path = pathlib.Join(output, path)
+			delta := size
+			if merge {
+				st, nErr := storage.Store.Stat(path)
+				if nErr == nil && !st.Dir {
+					delta -= st.Size
+				}
+			}
+			err = reservation.Grow(delta)
+			if err == nil {
+				err = storage.Store.Put(path, reader, size)
+			}
+			putErr = err
+			return
+		})
+	if err != nil && putErr == nil {
+		err = &BadRequestError{err.Error()}
+	}
 	return
 }
 
 //
 // getDir reads a directory from the bucket.
+// Streams a tarball of the (filtered) directory content.
+// The compression is negotiated using the `Compression` header.
 func (h *BucketOwner) getDir(ctx *gin.Context, input string, filter tar.Filter) {
-	tarWriter := tar.NewWriter(ctx.Writer)
-	tarWriter.Filter = filter
+	list, err := storage.Store.List(input)
+	if err != nil && !errors.Is(err, &storage.NotFound{}) {
+		_ = ctx.Error(err)
+		return
+	}
+	sort.Slice(
+		list,
+		func(i, j int) bool {
+			return list[i].Path < list[j].Path
+		})
+	compression := tar.Negotiate(ctx.GetHeader(Compression))
+	tarWriter := tar.NewWriterWith(ctx.Writer, compression)
 	defer func() {
 		tarWriter.Close()
 	}()
-	err := tarWriter.AssertDir(input)
-	if err != nil {
+	h.Attachment(ctx, pathlib.Base(input)+tar.Extension(compression))
+	ctx.Writer.Header().Set(Directory, DirectoryExpand)
+	ctx.Writer.Header().Set(Compression, compression)
+	ctx.Status(http.StatusOK)
+	added := make(map[string]bool)
+	for i := range list {
+		info := list[i]
+		if !filter.Match(info.Path) {
+			continue
+		}
+		rPath := strings.TrimPrefix(info.Path, input)
+		var dirs []string
+		for d := pathlib.Dir(rPath); d != "/" && d != "." && !added[d]; d = pathlib.Dir(d) {
+			dirs = append([]string{d}, dirs...)
+			added[d] = true
+		}
+		for _, d := range dirs {
+			err = tarWriter.AddDirEntry(d, info.ModTime)
+			if err != nil {
+				Log.Error(err, "")
+				return
+			}
+		}
+		err = tarWriter.AddStream(
+			rPath,
+			info.Size,
+			func(w io.Writer) (err error) {
+				reader, err := storage.Store.Get(info.Path)
+				if err != nil {
+					return
+				}
+				defer func() {
+					_ = reader.Close()
+				}()
+				_, err = io.CopyN(w, reader, info.Size)
+				return
+			})
+		if err != nil {
+			Log.Error(err, "")
+			return
+		}
+	}
+}
+
+//
+// manifest lists the (filtered) directory content.
+// Clients compare the manifest to determine which
+// files need to be sent or received.
+func (h *BucketOwner) manifest(ctx *gin.Context, input string, filter tar.Filter) {
+	list, err := storage.Store.List(input)
+	if err != nil && !errors.Is(err, &storage.NotFound{}) {
 		_ = ctx.Error(err)
 		return
 	}
-	h.Attachment(ctx, pathlib.Base(input)+".tar.gz")
-	ctx.Writer.Header().Set(Directory, DirectoryExpand)
-	ctx.Status(http.StatusOK)
-	_ = tarWriter.AddDir(input)
-	return
+	sort.Slice(
+		list,
+		func(i, j int) bool {
+			return list[i].Path < list[j].Path
+		})
+	resources := []BucketEntry{}
+	for _, info := range list {
+		if !filter.Match(info.Path) {
+			continue
+		}
+		digest, err := storage.Digest(info)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		resources = append(
+			resources,
+			BucketEntry{
+				Path:    strings.TrimPrefix(info.Path, input+"/"),
+				Size:    info.Size,
+				Digest:  digest,
+				ModTime: info.ModTime,
+			})
+	}
+	h.Respond(ctx, http.StatusOK, resources)
 }
 
 //
 // getFile reads a file from the bucket.
-func (h *BucketOwner) getFile(ctx *gin.Context, m *model.Bucket) {
-	rPath := ctx.Param(Wildcard)
-	path := pathlib.Join(m.Path, rPath)
-	ctx.File(path)
+func (h *BucketOwner) getFile(ctx *gin.Context, path string) {
+	h.Content(ctx, path, "")
 }
 
 //
 // putFile writes a file to the bucket.
-func (h *BucketOwner) putFile(ctx *gin.Context, m *model.Bucket) (err error) {
-	path := pathlib.Join(m.Path, ctx.Param(Wildcard))
+// The quota is checked before the file is written.
+func (h *BucketOwner) putFile(ctx *gin.Context, m *model.Bucket, path string) (err error) {
 	input, err := ctx.FormFile(FileField)
 	if err != nil {
 		err = &BadRequestError{err.Error()}
@@ -363,21 +564,27 @@ func (h *BucketOwner) putFile(ctx *gin.Context, m *model.Bucket) (err error) {
 	defer func() {
 		_ = reader.Close()
 	}()
-	err = os.MkdirAll(pathlib.Dir(path), 0777)
-	if err != nil {
-		return
+	current := int64(0)
+	st, err := storage.Store.Stat(path)
+	if err == nil {
+		if !st.Dir {
+			current = st.Size
+		}
+	} else {
+		if !errors.Is(err, &storage.NotFound{}) {
+			return
+		}
 	}
-	writer, err := os.Create(path)
+	delta := input.Size - current
+	reservation, err := quota.Check(m.Path, delta)
 	if err != nil {
 		return
 	}
-	defer func() {
-		_ = writer.Close()
-	}()
-	_, err = io.Copy(writer, reader)
+	defer reservation.Release()
+	err = storage.Store.Put(path, reader, input.Size)
 	if err != nil {
 		return
 	}
-	err = os.Chmod(path, 0666)
+	reservation.Commit()
 	return
 }
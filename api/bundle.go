@@ -0,0 +1,267 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	qf "github.com/konveyor/tackle2-hub/api/filter"
+	"github.com/konveyor/tackle2-hub/bundle"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/tar"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+//
+// Routes
+const (
+	BundlesRoot   = "/bundles"
+	AppBundleRoot = ApplicationRoot + "/bundle"
+)
+
+//
+// BundleHandler handles application bundle routes.
+type BundleHandler struct {
+	BaseHandler
+}
+
+//
+// AddRoutes adds routes.
+func (h BundleHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("bundles"))
+	routeGroup.GET(BundlesRoot, h.List)
+	routeGroup.GET(BundlesRoot+"/", h.List)
+	routeGroup.POST(BundlesRoot, h.Import)
+	routeGroup = e.Group("/")
+	routeGroup.Use(Required("bundles"), OwnedApplication)
+	routeGroup.GET(AppBundleRoot, h.Get)
+}
+
+// Get godoc
+// @summary Export an application bundle.
+// @description Export an application bundle (tarball). The bundle is a portable archive
+// @description of the application with: tags, facts, identity (references), assessments,
+// @description review, analyses and bucket content. Related resources are referenced by name
+// @description (or UUID). Identity credentials are never exported.
+// @description The compression (gzip|zstd) is negotiated using the X-Compression header.
+// @tags bundles
+// @produce octet-stream
+// @success 200
+// @router /applications/{id}/bundle [get]
+// @param id path int true "Application ID"
+func (h BundleHandler) Get(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.Application{}
+	err := h.DB(ctx).Select("ID").First(m, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	h.export(ctx, id)
+}
+
+// List godoc
+// @summary Export a bundle of (filtered) applications.
+// @description Export a bundle (tarball) of the (filtered) applications.
+// @description See: GET /applications/{id}/bundle.
+// @description filters:
+// @description - id
+// @description - name
+// @description - businessService.id
+// @description - businessService.name
+// @description - tag.id
+// @tags bundles
+// @produce octet-stream
+// @success 200
+// @router /bundles [get]
+func (h BundleHandler) List(ctx *gin.Context) {
+	filter, err := qf.New(ctx,
+		[]qf.Assert{
+			{Field: "id", Kind: qf.LITERAL},
+			{Field: "name", Kind: qf.STRING},
+			{Field: "businessService.id", Kind: qf.LITERAL},
+			{Field: "businessService.name", Kind: qf.STRING},
+			{Field: "tag.id", Kind: qf.LITERAL, And: true},
+		})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	db := h.DB(ctx)
+	db = db.Model(&model.Application{})
+	db = db.Select("ID")
+	ownership := Ownership{ctx: ctx}
+	db = ownership.Where(db, "ID")
+	db = filter.Where(db)
+	tagFilter := filter.Resource("tag")
+	if f, found := tagFilter.Field("id"); found {
+		if f.Value.Operator(qf.AND) {
+			var qs []*gorm.DB
+			for _, f = range f.Expand() {
+				f = f.As("TagID")
+				iq := h.DB(ctx)
+				iq = iq.Model(&model.ApplicationTag{})
+				iq = iq.Select("ApplicationID ID")
+				iq = f.Where(iq)
+				qs = append(qs, iq)
+			}
+			db = db.Where("ID IN (?)", model.Intersect(qs...))
+		} else {
+			f = f.As("TagID")
+			iq := h.DB(ctx)
+			iq = iq.Model(&model.ApplicationTag{})
+			iq = iq.Select("ApplicationID ID")
+			iq = f.Where(iq)
+			db = db.Where("ID IN (?)", iq)
+		}
+	}
+	bsFilter := filter.Resource("businessService")
+	if !bsFilter.Empty() {
+		iq := h.DB(ctx)
+		iq = iq.Model(&model.BusinessService{})
+		iq = iq.Select("ID")
+		iq = bsFilter.Where(iq)
+		db = db.Where("BusinessServiceID IN (?)", iq)
+	}
+	var ids []uint
+	err = db.Order("ID").Pluck("ID", &ids).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	h.export(ctx, ids...)
+}
+
+// Import godoc
+// @summary Import a bundle.
+// @description Import the applications in a bundle (tarball). The bundle is validated
+// @description (checksums) before the applications are imported. Each application is
+// @description assigned a new ID. Related resources are resolved by UUID or name:
+// @description - tags, tag categories, stakeholders (email), stakeholder groups and business
+// @description services are created when not found.
+// @description - identities (name and kind) and questionnaires are not created. Not resolved
+// @description references are reported as conflicts and omitted.
+// @description Applications that already exist (name) are reported as conflicts and skipped.
+// @description Each application (and bucket content) is imported in a transaction. Applications
+// @description that cannot be imported (e.g. quota exceeded) are reported as conflicts.
+// @description Form fields:
+// @description - file: the bundle.
+// @tags bundles
+// @accept multipart/form-data
+// @produce json
+// @success 201 {object} api.BundleReport
+// @router /bundles [post]
+func (h BundleHandler) Import(ctx *gin.Context) {
+	input, err := ctx.FormFile(FileField)
+	if err != nil {
+		err = &BadRequestError{err.Error()}
+		_ = ctx.Error(err)
+		return
+	}
+	reader, err := input.Open()
+	if err != nil {
+		err = &BadRequestError{err.Error()}
+		_ = ctx.Error(err)
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	report, err := bundle.Import(h.DB(ctx), reader, h.CurrentUser(ctx))
+	if err != nil {
+		if errors.Is(err, &bundle.Invalid{}) {
+			err = &BadRequestError{err.Error()}
+		}
+		_ = ctx.Error(err)
+		return
+	}
+	r := BundleReport{}
+	r.With(&report)
+	h.Respond(ctx, http.StatusCreated, r)
+}
+
+//
+// export writes the bundle.
+func (h *BundleHandler) export(ctx *gin.Context, ids ...uint) {
+	compression := tar.Negotiate(ctx.GetHeader(Compression))
+	name := "bundle-" + time.Now().UTC().Format("20060102150405")
+	h.Attachment(ctx, name+tar.Extension(compression))
+	ctx.Writer.Header().Set(Compression, compression)
+	ctx.Status(http.StatusOK)
+	_, err := bundle.Export(h.DB(ctx), ctx.Writer, compression, ids...)
+	if err != nil {
+		Log.Error(err, "")
+		return
+	}
+}
+
+//
+// BundleReport REST resource.
+type BundleReport struct {
+	Applications []BundleImported `json:"applications"`
+	Created      []BundleResolved `json:"created"`
+	Conflicts    []BundleConflict `json:"conflicts"`
+}
+
+//
+// With updates the resource with the report.
+func (r *BundleReport) With(report *bundle.Report) {
+	r.Applications = []BundleImported{}
+	for _, imported := range report.Applications {
+		r.Applications = append(
+			r.Applications,
+			BundleImported{
+				Name:   imported.Name,
+				Source: imported.Source,
+				ID:     imported.ID,
+			})
+	}
+	r.Created = []BundleResolved{}
+	for _, resolved := range report.Created {
+		r.Created = append(
+			r.Created,
+			BundleResolved{
+				Kind: resolved.Kind,
+				Name: resolved.Name,
+				ID:   resolved.ID,
+			})
+	}
+	r.Conflicts = []BundleConflict{}
+	for _, conflict := range report.Conflicts {
+		r.Conflicts = append(
+			r.Conflicts,
+			BundleConflict{
+				Application: conflict.Application,
+				Kind:        conflict.Kind,
+				Name:        conflict.Name,
+				Reason:      conflict.Reason,
+			})
+	}
+}
+
+//
+// BundleImported application imported.
+// The source is the ID in the exporting hub.
+type BundleImported struct {
+	Name   string `json:"name"`
+	Source uint   `json:"source"`
+	ID     uint   `json:"id"`
+}
+
+//
+// BundleResolved resource created by the import.
+type BundleResolved struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	ID   uint   `json:"id"`
+}
+
+//
+// BundleConflict reported by the import.
+type BundleConflict struct {
+	Application string `json:"application"`
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Reason      string `json:"reason"`
+}
@@ -1,14 +1,18 @@
 package api
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/model"
 	"github.com/konveyor/tackle2-hub/nas"
+	"gorm.io/gorm"
 	"net/http"
 	"os"
 	"os/exec"
 	pathlib "path"
 	"strings"
+	"time"
 )
 
 //
@@ -37,6 +41,9 @@ func (h CacheHandler) AddRoutes(e *gin.Engine) {
 // Get godoc
 // @summary Get the cache.
 // @description Get the cache.
+// @description The cache includes the (named) cache volumes with statistics and
+// @description the leases granted to tasks. When the DIR is a cache volume, only
+// @description the volume is included.
 // @tags cache
 // @produce json
 // @success 200 {object} api.Cache
@@ -53,6 +60,11 @@ func (h CacheHandler) Get(ctx *gin.Context) {
 		}
 		return
 	}
+	r.Volumes, err = h.volumes(ctx, volumeName(dir))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 
 	h.Respond(ctx, http.StatusOK, r)
 }
@@ -60,9 +72,11 @@ func (h CacheHandler) Get(ctx *gin.Context) {
 // Delete godoc
 // @summary Delete a directory within the cache.
 // @description Delete a directory within the cache.
+// @description A cache volume (or directory within) leased to a task cannot be deleted.
 // @tags cache
 // @produce json
 // @success 204
+// @failure 409
 // @router /cache [delete]
 func (h CacheHandler) Delete(ctx *gin.Context) {
 	dir := ctx.Param(Wildcard)
@@ -70,10 +84,27 @@ func (h CacheHandler) Delete(ctx *gin.Context) {
 		h.Status(ctx, http.StatusForbidden)
 		return
 	}
+	name := volumeName(dir)
+	volume := &model.CacheVolume{}
+	err := h.DB(ctx).Preload("Leases").First(volume, "Name", name).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = ctx.Error(err)
+			return
+		}
+		volume = nil
+	}
+	if volume != nil && len(volume.Leases) > 0 {
+		_ = ctx.Error(
+			&Conflict{
+				Reason: "Cache: '" + name + "' leased.",
+			})
+		return
+	}
 	path := pathlib.Join(
 		Settings.Cache.Path,
 		dir)
-	_, err := os.Stat(path)
+	_, err = os.Stat(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			h.Status(ctx, http.StatusNoContent)
@@ -87,6 +118,13 @@ func (h CacheHandler) Delete(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	if volume != nil && pathlib.Clean(dir) == "/"+name {
+		err = h.DB(ctx).Model(volume).Update("Size", 0).Error
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+	}
 
 	h.Status(ctx, http.StatusNoContent)
 }
@@ -143,11 +181,87 @@ func (h *CacheHandler) cache(dir string) (cache *Cache, err error) {
 	return
 }
 
+//
+// volumes returns the cache volumes.
+// When named, only the named volume is included.
+func (h *CacheHandler) volumes(ctx *gin.Context, name string) (list []CacheVolume, err error) {
+	var volumes []model.CacheVolume
+	db := h.DB(ctx).Preload("Leases.Task")
+	if name != "" {
+		db = db.Where("Name", name)
+	}
+	err = db.Order("Name").Find(&volumes).Error
+	if err != nil {
+		return
+	}
+	list = []CacheVolume{}
+	for i := range volumes {
+		r := CacheVolume{}
+		r.With(&volumes[i])
+		list = append(list, r)
+	}
+	return
+}
+
+//
+// volumeName returns the cache volume name (first segment) of the DIR.
+func volumeName(dir string) (name string) {
+	name = strings.TrimPrefix(pathlib.Clean("/"+dir), "/")
+	name = strings.SplitN(name, "/", 2)[0]
+	return
+}
+
 //
 // Cache REST resource.
 type Cache struct {
-	Path     string `json:"path"`
-	Capacity string `json:"capacity"`
-	Used     string `json:"used"`
-	Exists   bool   `json:"exists"`
+	Path     string        `json:"path"`
+	Capacity string        `json:"capacity"`
+	Used     string        `json:"used"`
+	Exists   bool          `json:"exists"`
+	Volumes  []CacheVolume `json:"volumes"`
+}
+
+//
+// CacheVolume REST resource.
+type CacheVolume struct {
+	ID       uint         `json:"id"`
+	Name     string       `json:"name"`
+	Size     int64        `json:"size"`
+	Hits     int64        `json:"hits"`
+	Misses   int64        `json:"misses"`
+	Evicted  int64        `json:"evicted"`
+	LastUsed time.Time    `json:"lastUsed"`
+	Leases   []CacheLease `json:"leases"`
+}
+
+//
+// With updates the resource with the model.
+func (r *CacheVolume) With(m *model.CacheVolume) {
+	r.ID = m.ID
+	r.Name = m.Name
+	r.Size = m.Size
+	r.Hits = m.Hits
+	r.Misses = m.Misses
+	r.Evicted = m.Evicted
+	r.LastUsed = m.LastUsed
+	r.Leases = []CacheLease{}
+	for _, lease := range m.Leases {
+		ref := Ref{ID: lease.TaskID}
+		if lease.Task != nil {
+			ref.Name = lease.Task.Name
+		}
+		r.Leases = append(
+			r.Leases,
+			CacheLease{
+				Task:     ref,
+				ReadOnly: lease.ReadOnly,
+			})
+	}
+}
+
+//
+// CacheLease REST resource.
+type CacheLease struct {
+	Task     Ref  `json:"task"`
+	ReadOnly bool `json:"readOnly,omitempty"`
 }
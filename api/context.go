@@ -18,6 +18,8 @@ type Context struct {
 	User string
 	// Scope
 	Scopes []auth.Scope
+	// Task referenced by an addon token.
+	Task uint
 	// k8s Client
 	Client client.Client
 	// Response
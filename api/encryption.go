@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/secret"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+//
+// Routes
+const (
+	EncryptionRoot       = "/encryption"
+	EncryptionRotateRoot = EncryptionRoot + "/rotate"
+)
+
+//
+// EncryptionHandler handles encryption routes.
+type EncryptionHandler struct {
+	BaseHandler
+}
+
+//
+// AddRoutes adds routes.
+func (h EncryptionHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("encryption"))
+	routeGroup.POST(EncryptionRotateRoot, h.Rotate)
+}
+
+// Rotate godoc
+// @summary Rotate the encryption key.
+// @description Re-encrypt the sensitive fields of all identities and the secrets
+// @description of all webhooks using the current key (passphrase). Fields encrypted
+// @description using previous keys (ENCRYPTION_PASSPHRASE_PREVIOUS) or not versioned
+// @description are rotated.
+// @description Performed in a single transaction. When any identity (or webhook) fails,
+// @description the transaction is rolled back and 422 is returned with the report.
+// @tags encryption
+// @produce json
+// @success 200 {object} api.KeyRotation
+// @failure 422 {object} api.KeyRotation
+// @router /encryption/rotate [post]
+func (h EncryptionHandler) Rotate(ctx *gin.Context) {
+	var report *secret.Rotation
+	err := h.DB(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		report, err = secret.Rotate(tx)
+		return
+	})
+	r := KeyRotation{}
+	if report != nil {
+		r.With(report)
+	}
+	if err != nil {
+		if errors.Is(err, &secret.RotationError{}) {
+			h.Respond(ctx, http.StatusUnprocessableEntity, r)
+		} else {
+			_ = ctx.Error(err)
+		}
+		return
+	}
+
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+//
+// KeyRotation REST resource.
+type KeyRotation struct {
+	KeyID   string           `json:"keyId" yaml:"keyId"`
+	Total   int              `json:"total"`
+	Rotated int              `json:"rotated"`
+	Skipped int              `json:"skipped"`
+	Failed  []RotationFailed `json:"failed,omitempty" yaml:",omitempty"`
+}
+
+//
+// With updates the resource with the report.
+func (r *KeyRotation) With(m *secret.Rotation) {
+	r.KeyID = m.KeyID
+	r.Total = m.Total
+	r.Rotated = m.Rotated
+	r.Skipped = m.Skipped
+	for _, f := range m.Failed {
+		failed := RotationFailed{Error: f.Error}
+		ref := &Ref{ID: f.ID, Name: f.Name}
+		switch f.Kind {
+		case secret.KindWebhook:
+			failed.Webhook = ref
+		default:
+			failed.Identity = ref
+		}
+		r.Failed = append(r.Failed, failed)
+	}
+}
+
+//
+// RotationFailed identity (or webhook) not rotated.
+type RotationFailed struct {
+	Identity *Ref   `json:"identity,omitempty" yaml:",omitempty"`
+	Webhook  *Ref   `json:"webhook,omitempty" yaml:",omitempty"`
+	Error    string `json:"error"`
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/konveyor/tackle2-hub/api/filter"
 	"github.com/konveyor/tackle2-hub/api/sort"
 	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/quota"
 	"github.com/mattn/go-sqlite3"
 	"gorm.io/gorm"
 	"net/http"
@@ -80,6 +81,21 @@ func (r *Forbidden) Is(err error) (matched bool) {
 	return
 }
 
+//
+// Conflict reports conflicts with the current state of a resource.
+type Conflict struct {
+	Reason string
+}
+
+func (r *Conflict) Error() string {
+	return r.Reason
+}
+
+func (r *Conflict) Is(err error) (matched bool) {
+	_, matched = err.(*Conflict)
+	return
+}
+
 //
 // ErrorHandler handles error conditions from lower handlers.
 func ErrorHandler() gin.HandlerFunc {
@@ -127,7 +143,8 @@ func ErrorHandler() gin.HandlerFunc {
 			return
 		}
 
-		if errors.Is(err, model.DependencyCyclicError{}) {
+		if errors.Is(err, model.DependencyCyclicError{}) ||
+			errors.Is(err, &Conflict{}) {
 			rtx.Respond(
 				http.StatusConflict,
 				gin.H{
@@ -136,6 +153,15 @@ func ErrorHandler() gin.HandlerFunc {
 			return
 		}
 
+		if errors.Is(err, &quota.Exceeded{}) {
+			rtx.Respond(
+				http.StatusRequestEntityTooLarge,
+				gin.H{
+					"error": err.Error(),
+				})
+			return
+		}
+
 		if errors.Is(err, &TrackerError{}) {
 			rtx.Respond(
 				http.StatusServiceUnavailable,
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/event"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//
+// Routes
+const (
+	EventsRoot = "/events"
+)
+
+//
+// Params.
+const (
+	Since = "since"
+)
+
+//
+// LastEventID header.
+const LastEventID = "Last-Event-ID"
+
+//
+// KeepAlive interval for the event stream.
+var KeepAlive = time.Second * 15
+
+//
+// EventHandler handles event routes.
+type EventHandler struct {
+	BaseHandler
+}
+
+//
+// AddRoutes adds routes.
+func (h EventHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("events"))
+	routeGroup.GET(EventsRoot, h.Stream)
+	routeGroup.GET(EventsRoot+"/", h.Stream)
+}
+
+// Stream godoc
+// @summary Stream hub events.
+// @description Stream (server-sent) events reporting changes to hub resources.
+// @description Events are named: <kind>.<action>. Actions: created|updated|deleted|state.
+// @description Events after the Last-Event-ID header (or since param) are replayed when retained.
+// @description Event IDs are seeded using the hub start time and increase across restarts.
+// @tags events
+// @produce text/event-stream
+// @success 200 {object} api.Event
+// @router /events [get]
+// @param kind query []string false "Resource kinds (default: all)."
+// @param since query int false "Replay events after the event ID."
+func (h EventHandler) Stream(ctx *gin.Context) {
+	since, _ := strconv.ParseUint(ctx.GetHeader(LastEventID), 10, 64)
+	if s := ctx.Query(Since); s != "" {
+		since, _ = strconv.ParseUint(s, 10, 64)
+	}
+	sub, replay := event.Bus.Subscribe(since, ctx.QueryArray(Kind)...)
+	defer event.Bus.Unsubscribe(sub)
+	h.Status(ctx, http.StatusOK)
+	ctx.Header(ContentType, "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	for i := range replay {
+		h.write(ctx.Writer, &replay[i])
+	}
+	ctx.Writer.Flush()
+	ticker := time.NewTicker(KeepAlive)
+	defer ticker.Stop()
+	ctx.Stream(func(w io.Writer) (next bool) {
+		select {
+		case e, open := <-sub.Events:
+			if open {
+				h.write(w, &e)
+				next = true
+			}
+		case <-ticker.C:
+			_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+			next = true
+		case <-ctx.Request.Context().Done():
+		}
+		return
+	})
+}
+
+//
+// write the event.
+func (h EventHandler) write(w io.Writer, e *event.Event) {
+	r := Event{}
+	r.With(e)
+	b, _ := json.Marshal(r)
+	_, _ = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Name(), b)
+}
+
+//
+// Events collects the events emitted while handling the
+// request. The events are published after the request
+// (transaction) has succeeded. Otherwise, discarded.
+func Events() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		rtx := WithContext(ctx)
+		if rtx.DB == nil {
+			ctx.Next()
+			return
+		}
+		cx, collector := event.WithCollector(rtx.DB.Statement.Context)
+		rtx.DB = rtx.DB.WithContext(cx)
+		ctx.Next()
+		if len(ctx.Errors) > 0 || ctx.Writer.Status() >= http.StatusBadRequest {
+			collector.Discard()
+		} else {
+			collector.Publish()
+		}
+	}
+}
+
+//
+// Event REST resource.
+type Event struct {
+	ID       uint64    `json:"id"`
+	Time     time.Time `json:"time"`
+	Kind     string    `json:"kind"`
+	Action   string    `json:"action"`
+	Resource uint      `json:"resource"`
+	State    string    `json:"state,omitempty"`
+}
+
+//
+// With updates the resource with the event.
+func (r *Event) With(e *event.Event) {
+	r.ID = e.ID
+	r.Time = e.Time
+	r.Kind = e.Kind
+	r.Action = e.Action
+	r.Resource = e.Resource
+	r.State = e.State
+}
@@ -1,13 +1,20 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"github.com/gin-gonic/gin"
+	liberr "github.com/jortel/go-utils/error"
 	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/quota"
+	"github.com/konveyor/tackle2-hub/reaper"
+	"github.com/konveyor/tackle2-hub/storage"
 	"io"
 	"mime"
+	"mime/multipart"
 	"net/http"
-	"os"
 	pathlib "path"
+	"strings"
 	"time"
 )
 
@@ -18,6 +25,12 @@ const (
 	FileRoot  = FilesRoot + "/:" + ID
 )
 
+//
+// Params.
+const (
+	Digest = "digest"
+)
+
 //
 // FileHandler handles file routes.
 type FileHandler struct {
@@ -40,13 +53,20 @@ func (h FileHandler) AddRoutes(e *gin.Engine) {
 // List godoc
 // @summary List all files.
 // @description List all files.
+// @description The digest param (SHA-256) may be used to find files by content.
 // @tags file
 // @produce json
 // @success 200 {object} []api.File
 // @router /files [get]
+// @param digest query string false "Content digest (SHA-256)"
 func (h FileHandler) List(ctx *gin.Context) {
 	var list []model.File
-	result := h.DB(ctx).Find(&list)
+	db := h.DB(ctx)
+	digest := ctx.Query(Digest)
+	if digest != "" {
+		db = db.Where("Digest", strings.ToLower(digest))
+	}
+	result := db.Find(&list)
 	if result.Error != nil {
 		_ = ctx.Error(result.Error)
 		return
@@ -64,6 +84,8 @@ func (h FileHandler) List(ctx *gin.Context) {
 // Create godoc
 // @summary Create a file.
 // @description Create a file.
+// @description The content is stored by digest (SHA-256) and shared
+// @description by files with the same content.
 // @tags file
 // @accept json
 // @produce json
@@ -78,43 +100,20 @@ func (h FileHandler) Create(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
-	m := &model.File{}
-	m.Name = ctx.Param(ID)
-	m.CreateUser = h.BaseHandler.CurrentUser(ctx)
-	result := h.DB(ctx).Create(&m)
-	if result.Error != nil {
-		_ = ctx.Error(result.Error)
-		return
-	}
-	defer func() {
-		if err != nil {
-			h.Status(ctx, http.StatusInternalServerError)
-			_ = h.DB(ctx).Delete(&m)
-			return
-		}
-	}()
-	reader, err := input.Open()
+	digest, err := h.digest(input)
 	if err != nil {
-		err = &BadRequestError{err.Error()}
 		_ = ctx.Error(err)
 		return
 	}
-	defer func() {
-		_ = reader.Close()
-	}()
-	writer, err := os.Create(m.Path)
-	if err != nil {
-		return
-	}
-	defer func() {
-		_ = writer.Close()
-	}()
-	_, err = io.Copy(writer, reader)
-	if err != nil {
-		return
-	}
-	err = os.Chmod(m.Path, 0666)
+	unlock := storage.Lock(digest)
+	m, err := h.create(ctx, input, digest)
+	unlock()
 	if err != nil {
+		_ = ctx.Error(err)
+		if m.ID != 0 {
+			fileReaper := reaper.FileReaper{DB: h.DB(ctx)}
+			_ = fileReaper.Delete(m)
+		}
 		return
 	}
 	r := File{}
@@ -143,17 +142,14 @@ func (h FileHandler) Get(ctx *gin.Context) {
 		r.With(m)
 		h.Respond(ctx, http.StatusOK, r)
 	} else {
-		header := ctx.Writer.Header()
-		header[ContentType] = []string{
-			mime.TypeByExtension(pathlib.Ext(m.Name)),
-		}
-		ctx.File(m.Path)
+		h.Content(ctx, m.Path, mime.TypeByExtension(pathlib.Ext(m.Name)))
 	}
 }
 
 // Delete godoc
 // @summary Delete a file.
 // @description Delete a file.
+// @description The content is deleted when not shared by other files.
 // @tags file
 // @success 204
 // @router /files/{id} [delete]
@@ -166,14 +162,8 @@ func (h FileHandler) Delete(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
-	err = os.Remove(m.Path)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			_ = ctx.Error(err)
-			return
-		}
-	}
-	err = h.DB(ctx).Delete(m).Error
+	fileReaper := reaper.FileReaper{DB: h.DB(ctx)}
+	err = fileReaper.Delete(m)
 	if err != nil {
 		_ = ctx.Error(err)
 		return
@@ -182,12 +172,94 @@ func (h FileHandler) Delete(ctx *gin.Context) {
 	h.Status(ctx, http.StatusNoContent)
 }
 
+//
+// digest returns the (SHA-256) digest of the uploaded file.
+func (h FileHandler) digest(input *multipart.FileHeader) (digest string, err error) {
+	reader, err := input.Open()
+	if err != nil {
+		err = &BadRequestError{err.Error()}
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	hash := sha256.New()
+	_, err = io.Copy(hash, reader)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	digest = hex.EncodeToString(hash.Sum(nil))
+	return
+}
+
+//
+// create the file and store the content.
+// The caller must hold the (content) lock.
+func (h FileHandler) create(ctx *gin.Context, input *multipart.FileHeader, digest string) (m *model.File, err error) {
+	m = &model.File{}
+	var shared int64
+	err = h.DB(ctx).Model(&model.FileContent{}).Where("Digest", digest).Count(&shared).Error
+	if err != nil {
+		return
+	}
+	delta := int64(0)
+	if shared == 0 {
+		delta = input.Size
+	}
+	reservation, err := quota.Check("", delta)
+	if err != nil {
+		return
+	}
+	defer reservation.Release()
+	m.Name = ctx.Param(ID)
+	m.Digest = digest
+	m.CreateUser = h.BaseHandler.CurrentUser(ctx)
+	err = h.DB(ctx).Create(m).Error
+	if err != nil {
+		return
+	}
+	reader, err := input.Open()
+	if err != nil {
+		err = &BadRequestError{err.Error()}
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	err = h.store(m, reader, input.Size, reservation)
+	return
+}
+
+//
+// store the file content.
+// Shared content already stored is not stored again.
+// The reservation is grown as needed and committed when
+// the content is stored.
+func (h FileHandler) store(m *model.File, reader io.Reader, size int64, reservation *quota.Reservation) (err error) {
+	info, err := storage.Store.Stat(m.Path)
+	if err == nil && !info.Dir && info.Size == size {
+		return
+	}
+	err = reservation.Grow(size - reservation.Delta())
+	if err != nil {
+		return
+	}
+	err = storage.Store.Put(m.Path, reader, size)
+	if err != nil {
+		return
+	}
+	reservation.Commit()
+	return
+}
+
 //
 // File REST resource.
 type File struct {
 	Resource   `yaml:",inline"`
 	Name       string     `json:"name"`
 	Path       string     `json:"path"`
+	Digest     string     `json:"digest,omitempty" yaml:",omitempty"`
 	Expiration *time.Time `json:"expiration,omitempty"`
 }
 
@@ -197,5 +269,6 @@ func (r *File) With(m *model.File) {
 	r.Resource.With(&m.Model)
 	r.Name = m.Name
 	r.Path = m.Path
+	r.Digest = m.Digest
 	r.Expiration = m.Expiration
 }
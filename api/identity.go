@@ -2,16 +2,23 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/credential"
 	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/secret"
+	"gorm.io/gorm"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 //
 // Routes
 const (
-	IdentitiesRoot = "/identities"
-	IdentityRoot   = IdentitiesRoot + "/:" + ID
+	IdentitiesRoot       = "/identities"
+	IdentityRoot         = IdentitiesRoot + "/:" + ID
+	IdentityUsageRoot    = IdentityRoot + "/usage"
+	IdentityValidateRoot = IdentityRoot + "/validate"
 )
 
 //
@@ -19,6 +26,7 @@ const (
 const (
 	Decrypted = "decrypted"
 	AppId     = "application"
+	Force     = "force"
 )
 
 //
@@ -36,6 +44,8 @@ func (h IdentityHandler) AddRoutes(e *gin.Engine) {
 	routeGroup.GET(IdentityRoot, h.setDecrypted, h.Get)
 	routeGroup.PUT(IdentityRoot, h.Update)
 	routeGroup.DELETE(IdentityRoot, h.Delete)
+	routeGroup.GET(IdentityUsageRoot, h.Usage)
+	routeGroup.POST(IdentityValidateRoot, h.Validate)
 }
 
 // Get godoc
@@ -57,7 +67,7 @@ func (h IdentityHandler) Get(ctx *gin.Context) {
 	r := Identity{}
 	decrypted := ctx.GetBool(Decrypted)
 	if decrypted {
-		err := m.Decrypt()
+		err := secret.Decrypt(m)
 		if err != nil {
 			h.Status(ctx, http.StatusInternalServerError)
 			return
@@ -99,7 +109,7 @@ func (h IdentityHandler) List(ctx *gin.Context) {
 		r := Identity{}
 		m := &list[i]
 		if decrypted {
-			err := m.Decrypt()
+			err := secret.Decrypt(m)
 			if err != nil {
 				h.Status(ctx, http.StatusInternalServerError)
 				return
@@ -131,7 +141,7 @@ func (h IdentityHandler) Create(ctx *gin.Context) {
 	m := r.Model()
 	m.CreateUser = h.BaseHandler.CurrentUser(ctx)
 	ref := &model.Identity{}
-	err = m.Encrypt(ref)
+	err = secret.Encrypt(m, ref)
 	if err != nil {
 		_ = ctx.Error(err)
 		return
@@ -149,10 +159,13 @@ func (h IdentityHandler) Create(ctx *gin.Context) {
 // Delete godoc
 // @summary Delete an identity.
 // @description Delete an identity.
+// @description Returns 409 when the identity is referenced by applications,
+// @description proxies, trackers or rulesets unless forced.
 // @tags identities
 // @success 204
 // @router /identities/{id} [delete]
 // @param id path int true "Identity ID"
+// @param force query bool false "Delete when referenced"
 func (h IdentityHandler) Delete(ctx *gin.Context) {
 	id := h.pk(ctx)
 	identity := &model.Identity{}
@@ -161,11 +174,31 @@ func (h IdentityHandler) Delete(ctx *gin.Context) {
 		_ = ctx.Error(result.Error)
 		return
 	}
+	force, _ := strconv.ParseBool(ctx.Query(Force))
+	if !force {
+		usage := IdentityUsage{}
+		err := usage.With(h.DB(ctx), id)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		if usage.Used() {
+			_ = ctx.Error(
+				&Conflict{
+					Reason: "Identity referenced by: " + usage.String() + ".",
+				})
+			return
+		}
+	}
 	result = h.DB(ctx).Delete(identity)
 	if result.Error != nil {
 		_ = ctx.Error(result.Error)
 		return
 	}
+	err := secret.Delete(identity)
+	if err != nil {
+		log.Error(err, "Identity secret not deleted.", "secret", identity.Secret)
+	}
 
 	h.Status(ctx, http.StatusNoContent)
 }
@@ -194,15 +227,25 @@ func (h IdentityHandler) Update(ctx *gin.Context) {
 		return
 	}
 	m := r.Model()
-	err = m.Encrypt(ref)
+	err = secret.Encrypt(m, ref)
 	if err != nil {
 		_ = ctx.Error(err)
 		return
 	}
 	m.ID = id
 	m.UpdateUser = h.BaseHandler.CurrentUser(ctx)
+	fields := h.fields(m)
+	if m.User == ref.User &&
+		m.Password == ref.Password &&
+		m.Key == ref.Key &&
+		m.Settings == ref.Settings {
+		delete(fields, "VerifyState")
+		delete(fields, "VerifyMessage")
+	} else {
+		fields["LastVerified"] = nil
+	}
 	db := h.DB(ctx).Model(m)
-	err = db.Updates(h.fields(m)).Error
+	err = db.Updates(fields).Error
 	if err != nil {
 		_ = ctx.Error(err)
 		return
@@ -211,6 +254,79 @@ func (h IdentityHandler) Update(ctx *gin.Context) {
 	h.Status(ctx, http.StatusNoContent)
 }
 
+// Usage godoc
+// @summary List the resources referencing an identity.
+// @description List the applications, proxies, trackers and rulesets
+// @description referencing an identity.
+// @tags identities
+// @produce json
+// @success 200 {object} api.IdentityUsage
+// @router /identities/{id}/usage [get]
+// @param id path int true "Identity ID"
+func (h IdentityHandler) Usage(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.Identity{}
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r := IdentityUsage{}
+	err := r.With(h.DB(ctx), id)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// Validate godoc
+// @summary Validate identity credentials.
+// @description Request validation of the credentials against the repository
+// @description of the first application referencing the identity.
+// @description Performed asynchronously (queued). The result is reported in
+// @description the identity verification.
+// @tags identities
+// @success 202
+// @router /identities/{id}/validate [post]
+// @param id path int true "Identity ID"
+func (h IdentityHandler) Validate(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.Identity{}
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	repository, err := credential.FindRepository(h.DB(ctx), id)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	if repository.URL == "" && m.Kind != credential.Maven {
+		_ = ctx.Error(
+			&BadRequestError{
+				Reason: "not referenced by an application with a repository.",
+			})
+		return
+	}
+	db := h.DB(ctx).Model(m)
+	db = db.Where("VerifyState IS NULL OR VerifyState != ?", credential.Running)
+	err = db.UpdateColumns(
+		map[string]interface{}{
+			"VerifyState":   credential.Pending,
+			"VerifyMessage": "",
+		}).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	credential.Request()
+
+	h.Status(ctx, http.StatusAccepted)
+}
+
 //
 // Set `decrypted` in the context.
 // Results in 403 when the token does not have the required scope.
@@ -239,6 +355,9 @@ type Identity struct {
 	Password    string `json:"password"`
 	Key         string `json:"key"`
 	Settings    string `json:"settings"`
+	Secret      string `json:"secret,omitempty" yaml:",omitempty"`
+	// Verification (read-only).
+	Verification *IdentityVerification `json:"verification,omitempty" yaml:",omitempty"`
 }
 
 //
@@ -252,6 +371,15 @@ func (r *Identity) With(m *model.Identity) {
 	r.Password = m.Password
 	r.Key = m.Key
 	r.Settings = m.Settings
+	r.Secret = m.Secret
+	r.Verification = nil
+	if m.VerifyState != "" {
+		r.Verification = &IdentityVerification{
+			State:   m.VerifyState,
+			Message: m.VerifyMessage,
+			Time:    m.LastVerified,
+		}
+	}
 }
 
 //
@@ -270,3 +398,91 @@ func (r *Identity) Model() (m *model.Identity) {
 
 	return
 }
+
+//
+// IdentityVerification credentials verification.
+type IdentityVerification struct {
+	State   string     `json:"state"`
+	Message string     `json:"message,omitempty" yaml:",omitempty"`
+	Time    *time.Time `json:"time,omitempty" yaml:",omitempty"`
+}
+
+//
+// IdentityUsage resources referencing an identity.
+type IdentityUsage struct {
+	Applications []Ref `json:"applications"`
+	Proxies      []Ref `json:"proxies"`
+	Trackers     []Ref `json:"trackers"`
+	RuleSets     []Ref `json:"ruleSets" yaml:"ruleSets"`
+}
+
+//
+// With updates the resource with the resources referencing the identity.
+func (r *IdentityUsage) With(db *gorm.DB, id uint) (err error) {
+	r.Applications = []Ref{}
+	r.Proxies = []Ref{}
+	r.Trackers = []Ref{}
+	r.RuleSets = []Ref{}
+	q := db.Table("ApplicationIdentity ai")
+	q = q.Joins("JOIN Application a ON a.ID = ai.ApplicationID")
+	q = q.Select("a.ID", "a.Name")
+	q = q.Where("ai.IdentityID", id)
+	err = q.Order("a.ID").Scan(&r.Applications).Error
+	if err != nil {
+		return
+	}
+	q = db.Model(&model.Proxy{})
+	q = q.Select("ID", "Kind AS Name")
+	q = q.Where("IdentityID", id)
+	err = q.Order("ID").Scan(&r.Proxies).Error
+	if err != nil {
+		return
+	}
+	q = db.Model(&model.Tracker{})
+	q = q.Select("ID", "Name")
+	q = q.Where("IdentityID", id)
+	err = q.Order("ID").Scan(&r.Trackers).Error
+	if err != nil {
+		return
+	}
+	q = db.Model(&model.RuleSet{})
+	q = q.Select("ID", "Name")
+	q = q.Where("IdentityID", id)
+	err = q.Order("ID").Scan(&r.RuleSets).Error
+	if err != nil {
+		return
+	}
+	return
+}
+
+//
+// Used returns true when referenced.
+func (r *IdentityUsage) Used() (b bool) {
+	b = len(r.Applications) > 0 ||
+		len(r.Proxies) > 0 ||
+		len(r.Trackers) > 0 ||
+		len(r.RuleSets) > 0
+	return
+}
+
+//
+// String returns a summary of the references.
+func (r *IdentityUsage) String() (s string) {
+	var part []string
+	counts := []struct {
+		kind string
+		n    int
+	}{
+		{kind: "applications", n: len(r.Applications)},
+		{kind: "proxies", n: len(r.Proxies)},
+		{kind: "trackers", n: len(r.Trackers)},
+		{kind: "rulesets", n: len(r.RuleSets)},
+	}
+	for _, c := range counts {
+		if c.n > 0 {
+			part = append(part, strconv.Itoa(c.n)+" "+c.kind)
+		}
+	}
+	s = strings.Join(part, ", ")
+	return
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/auth"
+	"github.com/konveyor/tackle2-hub/model"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+)
+
+//
+// OwnershipScope grants access to all applications
+// when ownership is enforced.
+const OwnershipScope = "applications.all"
+
+//
+// Ownership provides row-level authorization of applications.
+// When enforced (AUTH_OWNERSHIP), a user is restricted to applications:
+//   - owned by a stakeholder matched to the user.
+//   - contributed to by a stakeholder matched to the user.
+//   - belonging to a business service owned by a stakeholder
+//     matched to the user.
+//
+// A stakeholder is matched to the user by email, or by membership
+// in a stakeholder group with a matching username.
+// Users granted the applications.all scope (for the request method)
+// are not restricted.
+type Ownership struct {
+	ctx *gin.Context
+}
+
+//
+// Restricted returns true when the user is restricted to
+// owned applications.
+func (r *Ownership) Restricted() (b bool) {
+	if !Settings.Auth.Required || !Settings.Auth.Ownership {
+		return
+	}
+	rtx := WithContext(r.ctx)
+	b = !auth.ScopeCovered(
+		rtx.Scopes,
+		OwnershipScope+":"+strings.ToLower(r.ctx.Request.Method))
+	return
+}
+
+//
+// AppIDs returns a query for the IDs of applications owned by the user.
+func (r *Ownership) AppIDs() (q *gorm.DB) {
+	db := WithContext(r.ctx).DB
+	user := WithContext(r.ctx).User
+	stakeholders := db.Model(&model.Stakeholder{})
+	stakeholders = stakeholders.Select("ID")
+	stakeholders = stakeholders.Where("LOWER(Email) = LOWER(?)", user)
+	members := db.Table("StakeholderGroupStakeholder m")
+	members = members.Joins("JOIN StakeholderGroup g ON g.ID = m.StakeholderGroupID")
+	members = members.Select("m.StakeholderID")
+	members = members.Where("g.Username = ?", user)
+	contributes := db.Table("ApplicationContributors")
+	contributes = contributes.Select("ApplicationID")
+	contributes = contributes.Where(
+		"StakeholderID IN (?) OR StakeholderID IN (?)",
+		stakeholders,
+		members)
+	services := db.Model(&model.BusinessService{})
+	services = services.Select("ID")
+	services = services.Where(
+		"StakeholderID IN (?) OR StakeholderID IN (?)",
+		stakeholders,
+		members)
+	q = db.Model(&model.Application{})
+	q = q.Select("ID")
+	q = q.Where("OwnerID IN (?)", stakeholders)
+	q = q.Or("OwnerID IN (?)", members)
+	q = q.Or("ID IN (?)", contributes)
+	q = q.Or("BusinessServiceID IN (?)", services)
+	return
+}
+
+//
+// Where restricts the query (of applications) to owned applications.
+// The field is the application ID column.
+func (r *Ownership) Where(db *gorm.DB, field string) (q *gorm.DB) {
+	q = db
+	if r.Restricted() {
+		q = q.Where(field+" IN (?)", r.AppIDs())
+	}
+	return
+}
+
+//
+// Permit access to the application.
+// Returns Forbidden when the application is not owned.
+func (r *Ownership) Permit(id uint) (err error) {
+	if !r.Restricted() {
+		return
+	}
+	var n int64
+	db := WithContext(r.ctx).DB
+	db = db.Table("(?) owned", r.AppIDs())
+	err = db.Where("ID", id).Count(&n).Error
+	if err != nil {
+		return
+	}
+	if n == 0 {
+		err = &Forbidden{
+			Reason: "Application (" + strconv.Itoa(int(id)) + "): not owned.",
+		}
+	}
+	return
+}
+
+//
+// OwnedApplication enforces ownership of the application
+// referenced by the ID param.
+func OwnedApplication(ctx *gin.Context) {
+	ownedBy(
+		ctx,
+		func(db *gorm.DB, id uint) (appId *uint, err error) {
+			appId = &id
+			return
+		})
+}
+
+//
+// OwnedAnalysis enforces ownership of the application
+// associated with the analysis referenced by the ID param.
+func OwnedAnalysis(ctx *gin.Context) {
+	ownedBy(
+		ctx,
+		func(db *gorm.DB, id uint) (appId *uint, err error) {
+			m := &model.Analysis{}
+			err = db.Select("ID", "ApplicationID").First(m, id).Error
+			if err == nil {
+				appId = &m.ApplicationID
+			}
+			return
+		})
+}
+
+//
+// OwnedIssue enforces ownership of the application
+// associated with the issue referenced by the ID param.
+func OwnedIssue(ctx *gin.Context) {
+	ownedBy(
+		ctx,
+		func(db *gorm.DB, id uint) (appId *uint, err error) {
+			q := db.Table("Issue i")
+			q = q.Joins("JOIN Analysis a ON a.ID = i.AnalysisID")
+			q = q.Select("a.ApplicationID")
+			q = q.Where("i.ID = ?", id)
+			appId, err = first(q)
+			return
+		})
+}
+
+//
+// OwnedBucket enforces ownership of the application
+// associated with the bucket referenced by the ID param.
+// The bucket may be associated with an application or with
+// a task for an application.
+// Other buckets are not restricted.
+func OwnedBucket(ctx *gin.Context) {
+	ownedBy(
+		ctx,
+		func(db *gorm.DB, id uint) (appId *uint, err error) {
+			app := db.Model(&model.Application{})
+			app = app.Select("ID")
+			app = app.Where("BucketID", id)
+			task := db.Model(&model.Task{})
+			task = task.Select("ApplicationID")
+			task = task.Where("BucketID", id)
+			task = task.Where("ApplicationID IS NOT NULL")
+			appId, err = first(db.Raw("? UNION ?", app, task))
+			return
+		})
+}
+
+//
+// OwnedTask enforces ownership of the application
+// associated with the task referenced by the ID param.
+// Tasks not associated with an application are not restricted.
+func OwnedTask(ctx *gin.Context) {
+	ownedBy(
+		ctx,
+		func(db *gorm.DB, id uint) (appId *uint, err error) {
+			m := &model.Task{}
+			err = db.Select("ID", "ApplicationID").First(m, id).Error
+			if err == nil {
+				appId = m.ApplicationID
+			}
+			return
+		})
+}
+
+//
+// first returns the first ID selected by the query.
+func first(q *gorm.DB) (id *uint, err error) {
+	var list []uint
+	err = q.Scan(&list).Error
+	if err == nil && len(list) > 0 {
+		id = &list[0]
+	}
+	return
+}
+
+//
+// ownedBy enforces ownership of the application resolved
+// using the ID param. Requests without the param are ignored.
+func ownedBy(ctx *gin.Context, resolve func(db *gorm.DB, id uint) (appId *uint, err error)) {
+	ownership := Ownership{ctx: ctx}
+	param := ctx.Param(ID)
+	if param == "" || !ownership.Restricted() {
+		return
+	}
+	id, _ := strconv.Atoi(param)
+	rtx := WithContext(ctx)
+	appId, err := resolve(rtx.DB, uint(id))
+	if err == nil && appId != nil {
+		err = ownership.Permit(*appId)
+	}
+	if err != nil {
+		_ = ctx.Error(err)
+		ctx.Abort()
+	}
+}
@@ -38,12 +38,17 @@ const (
 //
 // Headers
 const (
-	Accept        = "Accept"
-	Authorization = "Authorization"
-	ContentLength = "Content-Length"
-	ContentType   = "Content-Type"
-	Directory     = "X-Directory"
-	Total         = "X-Total"
+	Accept            = "Accept"
+	AcceptCompression = "X-Accept-Compression"
+	AcceptRanges      = "Accept-Ranges"
+	Authorization     = "Authorization"
+	Compression       = "X-Compression"
+	ContentLength     = "Content-Length"
+	ContentRange      = "Content-Range"
+	ContentType       = "Content-Type"
+	Directory         = "X-Directory"
+	Range             = "Range"
+	Total             = "X-Total"
 )
 
 //
@@ -62,6 +67,7 @@ var BindMIMEs = []string{binding.MIMEJSON, binding.MIMEYAML}
 const (
 	DirectoryArchive = "archive"
 	DirectoryExpand  = "expand"
+	DirectoryMerge   = "merge"
 )
 
 //
@@ -70,6 +76,7 @@ func All() []Handler {
 	return []Handler{
 		&AddonHandler{},
 		&AdoptionPlanHandler{},
+		&AdvisoryHandler{},
 		&AnalysisHandler{},
 		&ApplicationHandler{},
 		&AuthHandler{},
@@ -83,6 +90,7 @@ func All() []Handler {
 		&ReviewHandler{},
 		&RuleSetHandler{},
 		&SchemaHandler{},
+		&ServiceAccountHandler{},
 		&SettingHandler{},
 		&StakeholderHandler{},
 		&StakeholderGroupHandler{},
@@ -90,6 +98,7 @@ func All() []Handler {
 		&TagCategoryHandler{},
 		&TaskHandler{},
 		&TaskGroupHandler{},
+		&TokenHandler{},
 		&TicketHandler{},
 		&TrackerHandler{},
 		&BucketHandler{},
@@ -100,6 +109,16 @@ func All() []Handler {
 		&QuestionnaireHandler{},
 		&AssessmentHandler{},
 		&ArchetypeHandler{},
+		&AuditHandler{},
+		&EncryptionHandler{},
+		&RoleHandler{},
+		&UserHandler{},
+		&EventHandler{},
+		&WebhookHandler{},
+		&UsageHandler{},
+		&BackupHandler{},
+		&BundleHandler{},
+		&ReaperHandler{},
 	}
 }
 
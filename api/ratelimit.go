@@ -0,0 +1,207 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/auth"
+	"github.com/konveyor/tackle2-hub/metrics"
+	"golang.org/x/time/rate"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//
+// Limiter (per token, user or client) rate limiter.
+// Nil when rate limiting is not enabled.
+var Limiter *RateLimiter
+
+//
+// RateIdle is the interval after which (idle) buckets are pruned.
+const RateIdle = time.Minute * 10
+
+//
+// RateLimit (token bucket) limit.
+type RateLimit struct {
+	// Rate (sustained) requests per second.
+	// Zero (0) = unlimited.
+	Rate float64
+	// Burst requests.
+	Burst int
+}
+
+//
+// RateGroup route group limit.
+type RateGroup struct {
+	RateLimit
+	// Route prefix.
+	Route string
+}
+
+//
+// NewRateLimiter returns a rate limiter.
+// Returns nil when no limits are defined.
+func NewRateLimiter() (r *RateLimiter) {
+	limiter := &RateLimiter{
+		Default: RateLimit{
+			Rate:  Settings.RateLimit.Rate,
+			Burst: Settings.RateLimit.Burst,
+		},
+		Groups: ParseRateGroups(Settings.RateLimit.Groups),
+	}
+	if limiter.Default.Rate > 0 || len(limiter.Groups) > 0 {
+		r = limiter
+	}
+	return
+}
+
+//
+// RateLimiter limits requests per user and route group.
+// Requests are matched to the group with the longest route
+// prefix. Requests not matched to a group are limited by
+// the default limit.
+type RateLimiter struct {
+	// Default limit.
+	Default RateLimit
+	// Groups route group limits.
+	Groups []RateGroup
+	// buckets indexed by user and group.
+	buckets map[string]*rateBucket
+	// pruned timestamp.
+	pruned time.Time
+	mutex  sync.Mutex
+}
+
+//
+// rateBucket token bucket.
+type rateBucket struct {
+	limiter *rate.Limiter
+	used    time.Time
+}
+
+//
+// Reserve a request.
+// Returns the group and the delay (duration) after which the
+// request will be permitted. Zero delay when permitted.
+func (r *RateLimiter) Reserve(user, route string) (group string, delay time.Duration) {
+	limit := r.Default
+	for _, g := range r.Groups {
+		if strings.HasPrefix(route, g.Route) {
+			group = g.Route
+			limit = g.RateLimit
+			break
+		}
+	}
+	if limit.Rate <= 0 {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := time.Now()
+	r.prune(now)
+	key := user + "|" + group
+	bucket, found := r.buckets[key]
+	if !found {
+		burst := limit.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		bucket = &rateBucket{
+			limiter: rate.NewLimiter(rate.Limit(limit.Rate), burst),
+		}
+		r.buckets[key] = bucket
+	}
+	bucket.used = now
+	reservation := bucket.limiter.ReserveN(now, 1)
+	delay = reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.CancelAt(now)
+	}
+	return
+}
+
+//
+// prune idle buckets.
+func (r *RateLimiter) prune(now time.Time) {
+	if r.buckets == nil {
+		r.buckets = make(map[string]*rateBucket)
+	}
+	if now.Sub(r.pruned) < RateIdle {
+		return
+	}
+	r.pruned = now
+	for key, bucket := range r.buckets {
+		if now.Sub(bucket.used) > RateIdle {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+//
+// RateLimited returns true when the request has been rejected
+// (429) by the rate limiter. The Retry-After header is set to
+// the (seconds) delay after which the request will be permitted.
+// Requests are limited by the key. See: RateKey().
+func RateLimited(ctx *gin.Context, key string) (limited bool) {
+	if Limiter == nil {
+		return
+	}
+	group, delay := Limiter.Reserve(key, ctx.FullPath())
+	if delay == 0 {
+		return
+	}
+	limited = true
+	seconds := int(math.Ceil(delay.Seconds()))
+	ctx.Header("Retry-After", strconv.Itoa(seconds))
+	ctx.AbortWithStatus(http.StatusTooManyRequests)
+	if group == "" {
+		group = "default"
+	}
+	metrics.RequestsThrottled.WithLabelValues(group).Inc()
+	return
+}
+
+//
+// RateKey returns the key used to limit the request.
+// Requests are limited by the API token when used. Otherwise, by
+// the user when authenticated or by the client (IP) address.
+func RateKey(ctx *gin.Context, result auth.Result) (key string) {
+	switch {
+	case result.Token != 0:
+		key = "token:" + strconv.Itoa(int(result.Token))
+	case Settings.Auth.Required:
+		key = "user:" + result.User
+	default:
+		key = "client:" + ctx.ClientIP()
+	}
+	return
+}
+
+//
+// ParseRateGroups parses route group limits.
+// Format: <route>=<rate>:<burst>[,<route>=<rate>:<burst>].
+// Sorted by route length (descending) for longest prefix match.
+func ParseRateGroups(s string) (groups []RateGroup) {
+	for _, entry := range strings.Split(s, ",") {
+		part := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(part) != 2 || part[0] == "" {
+			continue
+		}
+		g := RateGroup{Route: part[0]}
+		limit := strings.SplitN(part[1], ":", 2)
+		g.Rate, _ = strconv.ParseFloat(limit[0], 64)
+		if len(limit) > 1 {
+			g.Burst, _ = strconv.Atoi(limit[1])
+		} else {
+			g.Burst = Settings.RateLimit.Burst
+		}
+		groups = append(groups, g)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].Route) > len(groups[j].Route)
+	})
+	return
+}
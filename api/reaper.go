@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/reaper"
+	"net/http"
+	"strconv"
+)
+
+//
+// Routes
+const (
+	ReaperRoot     = "/reaper"
+	ReaperRunsRoot = ReaperRoot + "/runs"
+	ReaperRunRoot  = ReaperRunsRoot + "/:" + ID
+)
+
+//
+// Params.
+const (
+	DryRun = "dryRun"
+)
+
+//
+// ReaperHandler handles reaper routes.
+type ReaperHandler struct {
+	BaseHandler
+}
+
+//
+// AddRoutes adds routes.
+func (h ReaperHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("reaper"))
+	routeGroup.POST(ReaperRoot, h.Run)
+	routeGroup.GET(ReaperRunsRoot, h.List)
+	routeGroup.GET(ReaperRunsRoot+"/", h.List)
+	routeGroup.GET(ReaperRunRoot, h.Get)
+}
+
+// Run godoc
+// @summary Run the reapers.
+// @description Run (trigger) the reapers and report the actions taken.
+// @description A dry-run (dryRun=true) reports the tasks, groups, buckets, files and
+// @description snapshots that would be released or deleted (and why) but nothing is changed.
+// @description Reasons: ttl|orphan|submitted|pressure|retention.
+// @description The run (not dry-run) is recorded in the history.
+// @tags reaper
+// @produce json
+// @success 200 {object} api.ReaperRun
+// @router /reaper [post]
+// @param dryRun query bool false "Dry run"
+func (h ReaperHandler) Run(ctx *gin.Context) {
+	dryRun, _ := strconv.ParseBool(ctx.Query(DryRun))
+	report := &reaper.Report{
+		DryRun: dryRun,
+		Manual: true,
+	}
+	err := reaper.Execute(h.DB(ctx), h.Client(ctx), report)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := ReaperRun{}
+	r.WithReport(report)
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// Get godoc
+// @summary Get a (recorded) reaper run by ID.
+// @description Get a (recorded) reaper run by ID.
+// @tags reaper
+// @produce json
+// @success 200 {object} api.ReaperRun
+// @router /reaper/runs/{id} [get]
+// @param id path int true "Run ID"
+func (h ReaperHandler) Get(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.ReaperRun{}
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r := ReaperRun{}
+	r.With(m)
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// List godoc
+// @summary List the (recorded) reaper runs.
+// @description List the (recorded) reaper runs (history) with counts and bytes reclaimed.
+// @description Scheduled runs are recorded only when something was done.
+// @tags reaper
+// @produce json
+// @success 200 {object} []api.ReaperRun
+// @router /reaper/runs [get]
+func (h ReaperHandler) List(ctx *gin.Context) {
+	var list []model.ReaperRun
+	result := h.DB(ctx).Order("ID DESC").Find(&list)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	resources := []ReaperRun{}
+	for i := range list {
+		r := ReaperRun{}
+		r.With(&list[i])
+		resources = append(resources, r)
+	}
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+//
+// ReaperRun REST resource.
+type ReaperRun struct {
+	Resource  `yaml:",inline"`
+	Manual    bool           `json:"manual"`
+	DryRun    bool           `json:"dryRun,omitempty" yaml:"dryRun,omitempty"`
+	Duration  int64          `json:"duration"`
+	Counts    map[string]int `json:"counts"`
+	Reclaimed int64          `json:"reclaimed"`
+	Actions   []ReaperAction `json:"actions,omitempty" yaml:",omitempty"`
+}
+
+//
+// With updates the resource with the model.
+func (r *ReaperRun) With(m *model.ReaperRun) {
+	r.Resource.With(&m.Model)
+	r.Manual = m.Manual
+	r.Duration = m.Duration
+	_ = json.Unmarshal(m.Counts, &r.Counts)
+	if r.Counts == nil {
+		r.Counts = make(map[string]int)
+	}
+	r.Reclaimed = m.Reclaimed
+}
+
+//
+// WithReport updates the resource with the report.
+func (r *ReaperRun) WithReport(report *reaper.Report) {
+	r.ID = report.ID
+	r.CreateTime = report.Started
+	r.Manual = report.Manual
+	r.DryRun = report.DryRun
+	r.Duration = report.Duration.Milliseconds()
+	r.Counts = make(map[string]int)
+	for k, n := range report.Counts {
+		r.Counts[k] = n
+	}
+	r.Reclaimed = report.Reclaimed
+	r.Actions = []ReaperAction{}
+	for _, action := range report.Actions {
+		r.Actions = append(
+			r.Actions,
+			ReaperAction{
+				Kind:   action.Kind,
+				ID:     action.ID,
+				Action: action.Action,
+				Reason: action.Reason,
+				Size:   action.Size,
+			})
+	}
+}
+
+//
+// ReaperAction taken (or would be taken) by the reaper.
+type ReaperAction struct {
+	Kind   string `json:"kind"`
+	ID     uint   `json:"id"`
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+	Size   int64  `json:"size,omitempty" yaml:",omitempty"`
+}
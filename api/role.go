@@ -0,0 +1,274 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/auth"
+	"github.com/konveyor/tackle2-hub/model"
+	"gorm.io/gorm/clause"
+	"net/http"
+)
+
+//
+// Routes
+const (
+	RolesRoot = "/roles"
+	RoleRoot  = RolesRoot + "/:" + ID
+)
+
+//
+// RoleHandler handles role routes.
+type RoleHandler struct {
+	BaseHandler
+}
+
+//
+// AddRoutes adds routes.
+func (h RoleHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("roles"), ReloadRBAC, Transaction)
+	routeGroup.GET(RolesRoot, h.List)
+	routeGroup.GET(RolesRoot+"/", h.List)
+	routeGroup.POST(RolesRoot, h.Create)
+	routeGroup.GET(RoleRoot, h.Get)
+	routeGroup.PUT(RoleRoot, h.Update)
+	routeGroup.DELETE(RoleRoot, h.Delete)
+}
+
+// Get godoc
+// @summary Get a role by ID.
+// @description Get a role by ID.
+// @tags roles
+// @produce json
+// @success 200 {object} api.Role
+// @router /roles/{id} [get]
+// @param id path int true "Role ID"
+func (h RoleHandler) Get(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.Role{}
+	db := h.preLoad(h.DB(ctx), clause.Associations)
+	result := db.First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r := Role{}
+	r.With(m)
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// List godoc
+// @summary List all roles.
+// @description List all roles.
+// @tags roles
+// @produce json
+// @success 200 {object} []api.Role
+// @router /roles [get]
+func (h RoleHandler) List(ctx *gin.Context) {
+	var list []model.Role
+	db := h.preLoad(h.DB(ctx), clause.Associations)
+	result := db.Find(&list)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	resources := []Role{}
+	for i := range list {
+		r := Role{}
+		r.With(&list[i])
+		resources = append(resources, r)
+	}
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+// Create godoc
+// @summary Create a role.
+// @description Create a (custom) role.
+// @description The granted scopes must be a subset of the scopes granted to the user.
+// @tags roles
+// @accept json
+// @produce json
+// @success 201 {object} api.Role
+// @router /roles [post]
+// @param role body api.Role true "Role data"
+func (h RoleHandler) Create(ctx *gin.Context) {
+	r := &Role{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	rtx := WithContext(ctx)
+	err = validScopes(r.Scopes(), rtx.Scopes)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := r.Model()
+	m.CreateUser = h.CurrentUser(ctx)
+	result := h.DB(ctx).Omit(clause.Associations).Create(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r.With(m)
+	h.Respond(ctx, http.StatusCreated, r)
+}
+
+// Update godoc
+// @summary Update a role.
+// @description Update a (custom) role.
+// @description Builtin roles cannot be updated.
+// @description The granted scopes must be a subset of the scopes granted to the user.
+// @tags roles
+// @accept json
+// @success 204
+// @router /roles/{id} [put]
+// @param id path int true "Role ID"
+// @param role body api.Role true "Role data"
+func (h RoleHandler) Update(ctx *gin.Context) {
+	id := h.pk(ctx)
+	r := &Role{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	current := &model.Role{}
+	result := h.DB(ctx).First(current, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	if current.Builtin {
+		_ = ctx.Error(&Forbidden{Reason: "role: " + current.Name + " is builtin."})
+		return
+	}
+	rtx := WithContext(ctx)
+	err = validScopes(r.Scopes(), rtx.Scopes)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := r.Model()
+	m.ID = id
+	m.UpdateUser = h.CurrentUser(ctx)
+	db := h.DB(ctx).Model(m)
+	db = db.Omit(clause.Associations, "Builtin")
+	result = db.Updates(h.fields(m))
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	h.Status(ctx, http.StatusNoContent)
+}
+
+// Delete godoc
+// @summary Delete a role.
+// @description Delete a (custom) role and the user bindings.
+// @description Builtin roles cannot be deleted.
+// @tags roles
+// @success 204
+// @router /roles/{id} [delete]
+// @param id path int true "Role ID"
+func (h RoleHandler) Delete(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.Role{}
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	if m.Builtin {
+		_ = ctx.Error(&Forbidden{Reason: "role: " + m.Name + " is builtin."})
+		return
+	}
+	result = h.DB(ctx).Delete(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	h.Status(ctx, http.StatusNoContent)
+}
+
+//
+// ReloadRBAC reloads the RBAC registry after roles or users
+// have been changed (committed). Must precede the Transaction.
+func ReloadRBAC(ctx *gin.Context) {
+	rtx := WithContext(ctx)
+	db := rtx.DB
+	ctx.Next()
+	switch ctx.Request.Method {
+	case http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete:
+		if len(ctx.Errors) > 0 {
+			return
+		}
+		err := auth.RBAC.Reload(db)
+		if err != nil {
+			_ = ctx.Error(err)
+		}
+	}
+}
+
+//
+// Role REST resource.
+type Role struct {
+	Resource    `yaml:",inline"`
+	Name        string         `json:"name" binding:"required"`
+	Description string         `json:"description"`
+	Builtin     bool           `json:"builtin,omitempty" yaml:",omitempty"`
+	Resources   []RoleResource `json:"resources"`
+	Users       []Ref          `json:"users" yaml:",omitempty"`
+}
+
+//
+// RoleResource resource (name) and the verbs granted by a role.
+type RoleResource struct {
+	Name  string   `json:"name" binding:"required"`
+	Verbs []string `json:"verbs"`
+}
+
+//
+// With updates the resource with the model.
+func (r *Role) With(m *model.Role) {
+	r.Resource.With(&m.Model)
+	r.Name = m.Name
+	r.Description = m.Description
+	r.Builtin = m.Builtin
+	r.Resources = []RoleResource{}
+	_ = json.Unmarshal(m.Resources, &r.Resources)
+	r.Users = []Ref{}
+	for _, user := range m.Users {
+		r.Users = append(r.Users, Ref{ID: user.ID, Name: user.Name})
+	}
+}
+
+//
+// Model builds a model.
+func (r *Role) Model() (m *model.Role) {
+	m = &model.Role{
+		Name:        r.Name,
+		Description: r.Description,
+	}
+	if r.Resources == nil {
+		r.Resources = []RoleResource{}
+	}
+	m.Resources, _ = json.Marshal(r.Resources)
+	m.ID = r.ID
+	return
+}
+
+//
+// Scopes granted by the role.
+// Format: <resource>:<verb>.
+func (r *Role) Scopes() (scopes []string) {
+	for _, resource := range r.Resources {
+		for _, verb := range resource.Verbs {
+			scopes = append(scopes, resource.Name+":"+verb)
+		}
+	}
+	return
+}
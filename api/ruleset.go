@@ -2,19 +2,27 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/gin-gonic/gin"
 	qf "github.com/konveyor/tackle2-hub/api/filter"
 	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/rule"
+	"github.com/konveyor/tackle2-hub/storage"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"net/http"
+	"sort"
+	"strconv"
 )
 
 //
 // Routes
 const (
-	RuleSetsRoot = "/rulesets"
-	RuleSetRoot  = RuleSetsRoot + "/:" + ID
+	RuleSetsRoot         = "/rulesets"
+	RuleSetRoot          = RuleSetsRoot + "/:" + ID
+	RuleSetsValidateRoot = RuleSetsRoot + "/validate"
+	RuleSetRevisionsRoot = RuleSetRoot + "/revisions"
+	RuleSetRevisionRoot  = RuleSetRevisionsRoot + "/:" + ID2
 )
 
 //
@@ -29,9 +37,12 @@ func (h RuleSetHandler) AddRoutes(e *gin.Engine) {
 	routeGroup.GET(RuleSetsRoot, h.List)
 	routeGroup.GET(RuleSetsRoot+"/", h.List)
 	routeGroup.POST(RuleSetsRoot, h.Create)
+	routeGroup.POST(RuleSetsValidateRoot, h.Validate)
 	routeGroup.GET(RuleSetRoot, h.Get)
 	routeGroup.PUT(RuleSetRoot, h.Update)
 	routeGroup.DELETE(RuleSetRoot, h.Delete)
+	routeGroup.GET(RuleSetRevisionsRoot, h.RevisionList)
+	routeGroup.GET(RuleSetRevisionRoot, h.RevisionGet)
 }
 
 // Get godoc
@@ -104,10 +115,14 @@ func (h RuleSetHandler) List(ctx *gin.Context) {
 // Create godoc
 // @summary Create a ruleset.
 // @description Create a ruleset.
+// @description The rule files are parsed and validated. Labels, descriptions
+// @description and ruleIDs are extracted from the files.
+// @description Invalid rules are reported (400) as RuleSetValidation.
 // @tags rulesets
 // @accept json
 // @produce json
 // @success 201 {object} RuleSet
+// @failure 400 {object} RuleSetValidation
 // @router /rulesets [post]
 // @param ruleBundle body RuleSet true "RuleSet data"
 func (h RuleSetHandler) Create(ctx *gin.Context) {
@@ -117,6 +132,15 @@ func (h RuleSetHandler) Create(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	report, err := h.validate(ctx, ruleset)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	if !report.Valid {
+		h.Respond(ctx, http.StatusBadRequest, report)
+		return
+	}
 	err = h.create(ctx, ruleset)
 	if err != nil {
 		_ = ctx.Error(err)
@@ -147,9 +171,12 @@ func (h RuleSetHandler) Delete(ctx *gin.Context) {
 // Update godoc
 // @summary Update a ruleset.
 // @description Update a ruleset.
+// @description The rule files are parsed and validated.
+// @description Invalid rules are reported (400) as RuleSetValidation.
 // @tags rulesets
 // @accept json
 // @success 204
+// @failure 400 {object} RuleSetValidation
 // @router /rulesets/{id} [put]
 // @param id path int true "RuleSet ID"
 // @param ruleBundle body RuleSet true "RuleSet data"
@@ -162,6 +189,15 @@ func (h RuleSetHandler) Update(ctx *gin.Context) {
 		return
 	}
 	r.ID = id
+	report, err := h.validate(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	if !report.Valid {
+		h.Respond(ctx, http.StatusBadRequest, report)
+		return
+	}
 	err = h.update(ctx, r)
 	if err != nil {
 		_ = ctx.Error(err)
@@ -171,6 +207,127 @@ func (h RuleSetHandler) Update(ctx *gin.Context) {
 	h.Status(ctx, http.StatusNoContent)
 }
 
+// Validate godoc
+// @summary Validate a ruleset.
+// @description Validate (dry-run) a ruleset. The rule files are parsed
+// @description and validated against the analyzer rule schema.
+// @description Duplicate ruleIDs within the ruleset and defined by other
+// @description rulesets are reported as warnings.
+// @description When the ID is specified, the ruleset is excluded from
+// @description the duplicate ruleID search.
+// @tags rulesets
+// @accept json
+// @produce json
+// @success 200 {object} RuleSetValidation
+// @router /rulesets/validate [post]
+// @param ruleBundle body RuleSet true "RuleSet data"
+func (h RuleSetHandler) Validate(ctx *gin.Context) {
+	r := &RuleSet{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	report, err := h.validate(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	h.Respond(ctx, http.StatusOK, report)
+}
+
+// RevisionList godoc
+// @summary List ruleset revisions.
+// @description List the (immutable) revisions of a ruleset.
+// @description Each revision includes the diff from the previous revision.
+// @tags rulesets
+// @produce json
+// @success 200 {object} []RuleSetRevision
+// @router /rulesets/{id}/revisions [get]
+// @param id path int true "RuleSet ID"
+func (h RuleSetHandler) RevisionList(ctx *gin.Context) {
+	id := h.pk(ctx)
+	err := h.DB(ctx).First(&model.RuleSet{}, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	var list []model.RuleSetRevision
+	db := h.DB(ctx).Preload("Rules.File")
+	db = db.Where("RuleSetID", id)
+	db = db.Order("Revision")
+	err = db.Find(&list).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	resources := []RuleSetRevision{}
+	for i := range list {
+		r := RuleSetRevision{}
+		r.With(&list[i])
+		if i > 0 {
+			r.Diff = &RevisionDiff{}
+			r.Diff.With(&list[i-1], &list[i])
+		}
+		resources = append(resources, r)
+	}
+
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+// RevisionGet godoc
+// @summary Get a ruleset revision.
+// @description Get a ruleset revision.
+// @description The diff is from the previous revision unless
+// @description specified by the `from` parameter.
+// @tags rulesets
+// @produce json
+// @success 200 {object} RuleSetRevision
+// @router /rulesets/{id}/revisions/{revision} [get]
+// @param id path int true "RuleSet ID"
+// @param revision path int true "Revision"
+// @param from query int false "Diff from revision"
+func (h RuleSetHandler) RevisionGet(ctx *gin.Context) {
+	id := h.pk(ctx)
+	revision, _ := strconv.Atoi(ctx.Param(ID2))
+	m := &model.RuleSetRevision{}
+	db := h.DB(ctx).Preload("Rules.File")
+	db = db.Where("RuleSetID", id)
+	db = db.Where("Revision", revision)
+	err := db.First(m).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := RuleSetRevision{}
+	r.With(m)
+	from := revision - 1
+	if s := ctx.Query("from"); s != "" {
+		from, err = strconv.Atoi(s)
+		if err != nil {
+			err = &BadRequestError{"from: must be a revision number."}
+			_ = ctx.Error(err)
+			return
+		}
+	}
+	if from > 0 {
+		base := &model.RuleSetRevision{}
+		db = h.DB(ctx).Preload("Rules.File")
+		db = db.Where("RuleSetID", id)
+		db = db.Where("Revision", from)
+		err = db.First(base).Error
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		r.Diff = &RevisionDiff{}
+		r.Diff.With(base, m)
+	}
+
+	h.Respond(ctx, http.StatusOK, r)
+}
+
 func (h *RuleSetHandler) ruleSetIDs(ctx *gin.Context, f qf.Filter) (q *gorm.DB) {
 	q = h.DB(ctx)
 	q = q.Model(&model.RuleSet{})
@@ -202,6 +359,107 @@ func (h *RuleSetHandler) ruleSetIDs(ctx *gin.Context, f qf.Filter) (q *gorm.DB)
 	return
 }
 
+//
+// validate the ruleset.
+// The rule files are parsed and validated. The labels, description
+// and ruleIDs are extracted and used to update the rules.
+func (h *RuleSetHandler) validate(ctx *gin.Context, r *RuleSet) (report RuleSetValidation, err error) {
+	report.Valid = true
+	var files []*rule.File
+	fileIDs := make(map[*rule.File]uint)
+	for i := range r.Rules {
+		ref := r.Rules[i].File
+		if ref == nil {
+			continue
+		}
+		m := &model.File{}
+		err = h.DB(ctx).First(m, ref.ID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = nil
+				report.Valid = false
+				report.Issues = append(
+					report.Issues,
+					RuleIssue{
+						File:     *ref,
+						Severity: rule.Error,
+						Message:  "file not found.",
+					})
+				continue
+			}
+			return
+		}
+		var content []byte
+		content, err = storage.ReadFile(m.Path)
+		if err != nil {
+			return
+		}
+		f := rule.Parse(m.Name, content)
+		files = append(files, f)
+		fileIDs[f] = m.ID
+		r.Rules[i].WithFile(f)
+		if f.RuleSet && r.Description == "" {
+			r.Description = f.Description
+		}
+	}
+	rule.Lint(files)
+	owners, err := h.ruleOwners(ctx, r.ID)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		for _, id := range f.RuleIDs {
+			owner, found := owners[id]
+			if found {
+				f.Issues = append(
+					f.Issues,
+					rule.Issue{
+						File:     f.Name,
+						RuleID:   id,
+						Field:    "ruleID",
+						Severity: rule.Warning,
+						Message:  "duplicate ruleID; also defined in ruleset: " + owner + ".",
+					})
+			}
+		}
+		if !f.Valid() {
+			report.Valid = false
+		}
+		for _, issue := range f.Issues {
+			ri := RuleIssue{}
+			ri.With(fileIDs[f], &issue)
+			report.Issues = append(report.Issues, ri)
+		}
+	}
+	return
+}
+
+//
+// ruleOwners returns the names of (other) rulesets
+// indexed by the ruleIDs they define.
+func (h *RuleSetHandler) ruleOwners(ctx *gin.Context, id uint) (owners map[string]string, err error) {
+	type M struct {
+		RuleID  string
+		RuleSet string
+	}
+	var list []M
+	db := h.DB(ctx)
+	db = db.Table("Rule r")
+	db = db.Joins("JOIN RuleSet s ON s.ID = r.RuleSetID")
+	db = db.Joins(",json_each(r.RuleIDs) j")
+	db = db.Select("j.value RuleID", "s.Name RuleSet")
+	db = db.Where("r.RuleSetID != ?", id)
+	err = db.Scan(&list).Error
+	if err != nil {
+		return
+	}
+	owners = make(map[string]string)
+	for _, m := range list {
+		owners[m.RuleID] = m.RuleSet
+	}
+	return
+}
+
 //
 // create the ruleset.
 func (h *RuleSetHandler) create(ctx *gin.Context, r *RuleSet) (err error) {
@@ -210,6 +468,10 @@ func (h *RuleSetHandler) create(ctx *gin.Context, r *RuleSet) (err error) {
 	if err != nil {
 		return
 	}
+	_, err = rule.Revise(h.DB(ctx), m.ID)
+	if err != nil {
+		return
+	}
 	db := h.preLoad(
 		h.DB(ctx),
 		clause.Associations,
@@ -276,6 +538,61 @@ func (h *RuleSetHandler) update(ctx *gin.Context, r *RuleSet) (err error) {
 			return
 		}
 	}
+	_, err = rule.Revise(h.DB(ctx), m.ID)
+	if err != nil {
+		return
+	}
+	return
+}
+
+//
+// pin resolves the ruleset revisions.
+// An unspecified revision is resolved to the latest
+// revision of the ruleset. Revisions of repository-sourced
+// rulesets must reference a commit (reproducible).
+func pin(db *gorm.DB, refs []RevisionRef) (err error) {
+	for i := range refs {
+		ref := &refs[i]
+		m := &model.RuleSetRevision{}
+		if ref.Revision == 0 {
+			err = db.First(&model.RuleSet{}, ref.ID).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					err = &BadRequestError{
+						Reason: "ruleSet (id=" + strconv.Itoa(int(ref.ID)) + ") not found.",
+					}
+				}
+				return
+			}
+			m, err = rule.Revise(db, ref.ID)
+			if err != nil {
+				return
+			}
+		} else {
+			db := db.Where("RuleSetID", ref.ID)
+			db = db.Where("Revision", ref.Revision)
+			err = db.First(m).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					err = &BadRequestError{
+						Reason: "ruleSet (id=" + strconv.Itoa(int(ref.ID)) +
+							") revision: " + strconv.Itoa(int(ref.Revision)) + " not found.",
+					}
+				}
+				return
+			}
+		}
+		if !rule.Reproducible(m) {
+			err = &BadRequestError{
+				Reason: "ruleSet (id=" + strconv.Itoa(int(ref.ID)) +
+					") revision: " + strconv.Itoa(int(m.Revision)) +
+					" not reproducible: repository must reference a commit.",
+			}
+			return
+		}
+		ref.Name = m.Name
+		ref.Revision = m.Revision
+	}
 	return
 }
 
@@ -384,6 +701,7 @@ type Rule struct {
 	Name        string   `json:"name,omitempty"`
 	Description string   `json:"description,omitempty"`
 	Labels      []string `json:"labels,omitempty"`
+	RuleIDs     []string `json:"ruleIDs,omitempty" yaml:"ruleIDs,omitempty"`
 	File        *Ref     `json:"file,omitempty"`
 }
 
@@ -392,7 +710,9 @@ type Rule struct {
 func (r *Rule) With(m *model.Rule) {
 	r.Resource.With(&m.Model)
 	r.Name = m.Name
+	r.Description = m.Description
 	_ = json.Unmarshal(m.Labels, &r.Labels)
+	_ = json.Unmarshal(m.RuleIDs, &r.RuleIDs)
 	r.File = r.refPtr(m.FileID, m.File)
 }
 
@@ -402,9 +722,197 @@ func (r *Rule) Model() (m *model.Rule) {
 	m = &model.Rule{}
 	m.ID = r.ID
 	m.Name = r.Name
+	m.Description = r.Description
 	if r.Labels != nil {
 		m.Labels, _ = json.Marshal(r.Labels)
 	}
+	if r.RuleIDs != nil {
+		m.RuleIDs, _ = json.Marshal(r.RuleIDs)
+	}
 	m.FileID = r.idPtr(r.File)
 	return
 }
+
+//
+// WithFile updates the resource with the parsed rules file.
+// Extracted labels are merged and the description is
+// set when not specified.
+func (r *Rule) WithFile(f *rule.File) {
+	r.RuleIDs = f.RuleIDs
+	if r.Description == "" {
+		r.Description = f.Description
+	}
+	for _, label := range f.Labels {
+		found := false
+		for _, l := range r.Labels {
+			if l == label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.Labels = append(r.Labels, label)
+		}
+	}
+}
+
+//
+// RuleSetRevision REST resource.
+type RuleSetRevision struct {
+	Resource    `yaml:",inline"`
+	RuleSet     Ref           `json:"ruleSet" yaml:"ruleSet"`
+	Revision    uint          `json:"revision"`
+	Digest      string        `json:"digest"`
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty" yaml:",omitempty"`
+	Rules       []Rule        `json:"rules"`
+	Repository  *Repository   `json:"repository,omitempty" yaml:",omitempty"`
+	Commit      string        `json:"commit,omitempty" yaml:",omitempty"`
+	Identity    *Ref          `json:"identity,omitempty" yaml:",omitempty"`
+	DependsOn   []Ref         `json:"dependsOn" yaml:"dependsOn"`
+	Diff        *RevisionDiff `json:"diff,omitempty" yaml:",omitempty"`
+}
+
+//
+// With updates the resource with the model.
+func (r *RuleSetRevision) With(m *model.RuleSetRevision) {
+	r.Resource.With(&m.Model)
+	r.RuleSet = Ref{ID: m.RuleSetID, Name: m.Name}
+	r.Revision = m.Revision
+	r.Digest = m.Digest
+	r.Name = m.Name
+	r.Description = m.Description
+	r.Identity = r.refPtr(m.IdentityID, nil)
+	_ = json.Unmarshal(m.Repository, &r.Repository)
+	r.Commit = m.Commit
+	r.Rules = []Rule{}
+	for i := range m.Rules {
+		rm := &m.Rules[i]
+		rule := Rule{
+			Name:        rm.Name,
+			Description: rm.Description,
+		}
+		rule.Resource.With(&rm.Model)
+		_ = json.Unmarshal(rm.Labels, &rule.Labels)
+		_ = json.Unmarshal(rm.RuleIDs, &rule.RuleIDs)
+		rule.File = r.refPtr(rm.FileID, rm.File)
+		r.Rules = append(r.Rules, rule)
+	}
+	r.DependsOn = []Ref{}
+	var ids []uint
+	_ = json.Unmarshal(m.DependsOn, &ids)
+	for _, id := range ids {
+		r.DependsOn = append(r.DependsOn, Ref{ID: id})
+	}
+}
+
+//
+// RevisionDiff REST resource.
+// Fields: ruleset fields changed (name|description|repository|identity|dependsOn).
+// Added: ruleIDs added.
+// Removed: ruleIDs removed.
+// Modified: ruleIDs defined by rule files with changed content.
+type RevisionDiff struct {
+	From     uint     `json:"from"`
+	Fields   []string `json:"fields,omitempty" yaml:",omitempty"`
+	Added    []string `json:"added,omitempty" yaml:",omitempty"`
+	Removed  []string `json:"removed,omitempty" yaml:",omitempty"`
+	Modified []string `json:"modified,omitempty" yaml:",omitempty"`
+}
+
+//
+// With updates the resource with the diff between revisions.
+func (r *RevisionDiff) With(a, b *model.RuleSetRevision) {
+	r.From = a.Revision
+	if a.Name != b.Name {
+		r.Fields = append(r.Fields, "name")
+	}
+	if a.Description != b.Description {
+		r.Fields = append(r.Fields, "description")
+	}
+	if string(a.Repository) != string(b.Repository) {
+		r.Fields = append(r.Fields, "repository")
+	}
+	if r.idOf(a.IdentityID) != r.idOf(b.IdentityID) {
+		r.Fields = append(r.Fields, "identity")
+	}
+	if string(a.DependsOn) != string(b.DependsOn) {
+		r.Fields = append(r.Fields, "dependsOn")
+	}
+	digests := func(m *model.RuleSetRevision) (ids map[string]string) {
+		ids = make(map[string]string)
+		for _, rule := range m.Rules {
+			var ruleIDs []string
+			_ = json.Unmarshal(rule.RuleIDs, &ruleIDs)
+			for _, id := range ruleIDs {
+				ids[id] = rule.Digest
+			}
+		}
+		return
+	}
+	before := digests(a)
+	after := digests(b)
+	for id, d := range after {
+		d2, found := before[id]
+		switch {
+		case !found:
+			r.Added = append(r.Added, id)
+		case d != d2:
+			r.Modified = append(r.Modified, id)
+		}
+	}
+	for id := range before {
+		if _, found := after[id]; !found {
+			r.Removed = append(r.Removed, id)
+		}
+	}
+	sort.Strings(r.Added)
+	sort.Strings(r.Removed)
+	sort.Strings(r.Modified)
+}
+
+//
+// idOf returns the ID or 0 when nil.
+func (r *RevisionDiff) idOf(id *uint) (n uint) {
+	if id != nil {
+		n = *id
+	}
+	return
+}
+
+//
+// RevisionRef references a ruleset revision.
+// The ID is the ruleset ID.
+type RevisionRef struct {
+	ID       uint   `json:"id" binding:"required"`
+	Name     string `json:"name,omitempty" yaml:",omitempty"`
+	Revision uint   `json:"revision,omitempty" yaml:",omitempty"`
+}
+
+//
+// RuleSetValidation REST resource.
+type RuleSetValidation struct {
+	Valid  bool        `json:"valid"`
+	Issues []RuleIssue `json:"issues,omitempty" yaml:",omitempty"`
+}
+
+//
+// RuleIssue REST resource.
+// Severity: error|warning.
+type RuleIssue struct {
+	File     Ref    `json:"file"`
+	RuleID   string `json:"ruleID,omitempty" yaml:"ruleID,omitempty"`
+	Field    string `json:"field,omitempty" yaml:",omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+//
+// With updates the resource with the issue.
+func (r *RuleIssue) With(fileID uint, m *rule.Issue) {
+	r.File = Ref{ID: fileID, Name: m.File}
+	r.RuleID = m.RuleID
+	r.Field = m.Field
+	r.Severity = m.Severity
+	r.Message = m.Message
+}
@@ -0,0 +1,335 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/auth"
+	"github.com/konveyor/tackle2-hub/model"
+	"gorm.io/gorm/clause"
+	"net/http"
+)
+
+//
+// Routes
+const (
+	ServiceAccountsRoot      = "/serviceaccounts"
+	ServiceAccountRoot       = ServiceAccountsRoot + "/:" + ID
+	ServiceAccountTokensRoot = ServiceAccountRoot + "/tokens"
+	ServiceAccountTokenRoot  = ServiceAccountTokensRoot + "/:" + ID2
+)
+
+//
+// ServiceAccountHandler handles service account routes.
+type ServiceAccountHandler struct {
+	BaseHandler
+}
+
+//
+// AddRoutes adds routes.
+func (h ServiceAccountHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("serviceaccounts"), Transaction)
+	routeGroup.GET(ServiceAccountsRoot, h.List)
+	routeGroup.GET(ServiceAccountsRoot+"/", h.List)
+	routeGroup.POST(ServiceAccountsRoot, h.Create)
+	routeGroup.GET(ServiceAccountRoot, h.Get)
+	routeGroup.PUT(ServiceAccountRoot, h.Update)
+	routeGroup.DELETE(ServiceAccountRoot, h.Delete)
+	routeGroup.GET(ServiceAccountTokensRoot, h.TokenList)
+	routeGroup.POST(ServiceAccountTokensRoot, h.TokenCreate)
+	routeGroup.DELETE(ServiceAccountTokenRoot, h.TokenDelete)
+}
+
+// Get godoc
+// @summary Get a service account by ID.
+// @description Get a service account by ID.
+// @tags serviceaccounts
+// @produce json
+// @success 200 {object} api.ServiceAccount
+// @router /serviceaccounts/{id} [get]
+// @param id path int true "Service account ID"
+func (h ServiceAccountHandler) Get(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.ServiceAccount{}
+	db := h.preLoad(h.DB(ctx), clause.Associations)
+	result := db.First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r := ServiceAccount{}
+	r.With(m)
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// List godoc
+// @summary List all service accounts.
+// @description List all service accounts.
+// @tags serviceaccounts
+// @produce json
+// @success 200 {object} []api.ServiceAccount
+// @router /serviceaccounts [get]
+func (h ServiceAccountHandler) List(ctx *gin.Context) {
+	var list []model.ServiceAccount
+	db := h.preLoad(h.DB(ctx), clause.Associations)
+	result := db.Find(&list)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	resources := []ServiceAccount{}
+	for i := range list {
+		r := ServiceAccount{}
+		r.With(&list[i])
+		resources = append(resources, r)
+	}
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+// Create godoc
+// @summary Create a service account.
+// @description Create a service account.
+// @description The scopes must be a subset of the scopes granted to the user.
+// @tags serviceaccounts
+// @accept json
+// @produce json
+// @success 201 {object} api.ServiceAccount
+// @router /serviceaccounts [post]
+// @param account body api.ServiceAccount true "Service account data"
+func (h ServiceAccountHandler) Create(ctx *gin.Context) {
+	r := &ServiceAccount{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	rtx := WithContext(ctx)
+	err = validScopes(r.Scopes, rtx.Scopes)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := r.Model()
+	m.CreateUser = h.CurrentUser(ctx)
+	result := h.DB(ctx).Omit(clause.Associations).Create(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r.With(m)
+	h.Respond(ctx, http.StatusCreated, r)
+}
+
+// Update godoc
+// @summary Update a service account.
+// @description Update a service account.
+// @description The scopes must be a subset of the scopes granted to the user.
+// @description Token scopes are limited to the account scopes when authenticated.
+// @tags serviceaccounts
+// @accept json
+// @success 204
+// @router /serviceaccounts/{id} [put]
+// @param id path int true "Service account ID"
+// @param account body api.ServiceAccount true "Service account data"
+func (h ServiceAccountHandler) Update(ctx *gin.Context) {
+	id := h.pk(ctx)
+	r := &ServiceAccount{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	rtx := WithContext(ctx)
+	err = validScopes(r.Scopes, rtx.Scopes)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := r.Model()
+	m.ID = id
+	m.UpdateUser = h.CurrentUser(ctx)
+	db := h.DB(ctx).Model(m)
+	db = db.Omit(clause.Associations)
+	result := db.Updates(h.fields(m))
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	h.Status(ctx, http.StatusNoContent)
+}
+
+// Delete godoc
+// @summary Delete a service account.
+// @description Delete a service account and revoke its tokens.
+// @tags serviceaccounts
+// @success 204
+// @router /serviceaccounts/{id} [delete]
+// @param id path int true "Service account ID"
+func (h ServiceAccountHandler) Delete(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.ServiceAccount{}
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	result = h.DB(ctx).Delete(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	h.Status(ctx, http.StatusNoContent)
+}
+
+// TokenList godoc
+// @summary List the API tokens issued to a service account.
+// @description List the API tokens issued to a service account.
+// @tags serviceaccounts
+// @produce json
+// @success 200 {object} []api.APIToken
+// @router /serviceaccounts/{id}/tokens [get]
+// @param id path int true "Service account ID"
+func (h ServiceAccountHandler) TokenList(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.ServiceAccount{}
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	var list []model.APIToken
+	db := h.DB(ctx).Preload("ServiceAccount")
+	result = db.Find(&list, "ServiceAccountID", id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	resources := []APIToken{}
+	for i := range list {
+		r := APIToken{}
+		r.With(&list[i])
+		resources = append(resources, r)
+	}
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+// TokenCreate godoc
+// @summary Issue an API token to a service account.
+// @description Issue an API token to a service account.
+// @description The scopes must be a subset of the account scopes.
+// @description The token (secret) is returned only in the response.
+// @tags serviceaccounts
+// @accept json
+// @produce json
+// @success 201 {object} api.APIToken
+// @router /serviceaccounts/{id}/tokens [post]
+// @param id path int true "Service account ID"
+// @param token body api.APIToken true "Token data"
+func (h ServiceAccountHandler) TokenCreate(ctx *gin.Context) {
+	id := h.pk(ctx)
+	account := &model.ServiceAccount{}
+	result := h.DB(ctx).First(account, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r := &APIToken{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	var granted []auth.Scope
+	var scopes []string
+	_ = json.Unmarshal(account.Scopes, &scopes)
+	for _, s := range scopes {
+		scope := &auth.BaseScope{}
+		scope.With(s)
+		granted = append(granted, scope)
+	}
+	err = r.Validate(granted)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := r.Model()
+	m.ServiceAccountID = &account.ID
+	m.ServiceAccount = account
+	m.CreateUser = h.CurrentUser(ctx)
+	err = r.issue(m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	result = h.DB(ctx).Omit(clause.Associations).Create(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r.With(m)
+	h.Respond(ctx, http.StatusCreated, r)
+}
+
+// TokenDelete godoc
+// @summary Revoke (delete) an API token issued to a service account.
+// @description Revoke (delete) an API token issued to a service account.
+// @tags serviceaccounts
+// @success 204
+// @router /serviceaccounts/{id}/tokens/{id2} [delete]
+// @param id path int true "Service account ID"
+// @param id2 path int true "Token ID"
+func (h ServiceAccountHandler) TokenDelete(ctx *gin.Context) {
+	id := h.pk(ctx)
+	id2 := ctx.Param(ID2)
+	m := &model.APIToken{}
+	result := h.DB(ctx).First(m, "ServiceAccountID = ? AND ID = ?", id, id2)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	result = h.DB(ctx).Delete(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	h.Status(ctx, http.StatusNoContent)
+}
+
+//
+// ServiceAccount REST resource.
+type ServiceAccount struct {
+	Resource    `yaml:",inline"`
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Scopes      []string `json:"scopes"`
+	Tokens      []Ref    `json:"tokens" yaml:",omitempty"`
+}
+
+//
+// With updates the resource with the model.
+func (r *ServiceAccount) With(m *model.ServiceAccount) {
+	r.Resource.With(&m.Model)
+	r.Name = m.Name
+	r.Description = m.Description
+	r.Scopes = []string{}
+	_ = json.Unmarshal(m.Scopes, &r.Scopes)
+	r.Tokens = []Ref{}
+	for _, token := range m.Tokens {
+		r.Tokens = append(r.Tokens, Ref{ID: token.ID, Name: token.Name})
+	}
+}
+
+//
+// Model builds a model.
+func (r *ServiceAccount) Model() (m *model.ServiceAccount) {
+	m = &model.ServiceAccount{
+		Name:        r.Name,
+		Description: r.Description,
+	}
+	if r.Scopes == nil {
+		r.Scopes = []string{}
+	}
+	m.Scopes, _ = json.Marshal(r.Scopes)
+	m.ID = r.ID
+	return
+}
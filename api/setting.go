@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/secret"
+	"github.com/konveyor/tackle2-hub/task"
 	"net/http"
 	"strings"
 )
@@ -47,6 +49,10 @@ func (h SettingHandler) AddRoutes(e *gin.Engine) {
 func (h SettingHandler) Get(ctx *gin.Context) {
 	setting := &model.Setting{}
 	key := ctx.Param(Key)
+	if key == secret.SaltKey {
+		h.Status(ctx, http.StatusNotFound)
+		return
+	}
 	result := h.DB(ctx).Where(&model.Setting{Key: key}).First(setting)
 	if result.Error != nil {
 		_ = ctx.Error(result.Error)
@@ -67,7 +73,7 @@ func (h SettingHandler) Get(ctx *gin.Context) {
 // @router /settings [get]
 func (h SettingHandler) List(ctx *gin.Context) {
 	var list []model.Setting
-	result := h.DB(ctx).Find(&list)
+	result := h.DB(ctx).Find(&list, "Key != ?", secret.SaltKey)
 	if result.Error != nil {
 		_ = ctx.Error(result.Error)
 		return
@@ -85,6 +91,7 @@ func (h SettingHandler) List(ctx *gin.Context) {
 // Create godoc
 // @summary Create a setting.
 // @description Create a setting.
+// @description The task.lifecycle (task lifecycle policies) setting is validated.
 // @tags settings
 // @accept json
 // @produce json
@@ -110,6 +117,11 @@ func (h SettingHandler) Create(ctx *gin.Context) {
 	}
 
 	m := setting.Model()
+	err = h.validate(m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	m.CreateUser = h.BaseHandler.CurrentUser(ctx)
 	result := h.DB(ctx).Create(&m)
 	if result.Error != nil {
@@ -124,6 +136,7 @@ func (h SettingHandler) Create(ctx *gin.Context) {
 // CreateByKey godoc
 // @summary Create a setting.
 // @description Create a setting.
+// @description The task.lifecycle (task lifecycle policies) setting is validated.
 // @tags settings
 // @accept json
 // @success 201
@@ -150,6 +163,11 @@ func (h SettingHandler) CreateByKey(ctx *gin.Context) {
 		return
 	}
 	m := setting.Model()
+	err = h.validate(m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	m.CreateUser = h.BaseHandler.CurrentUser(ctx)
 	result := h.DB(ctx).Create(&m)
 	if result.Error != nil {
@@ -163,6 +181,7 @@ func (h SettingHandler) CreateByKey(ctx *gin.Context) {
 // Update godoc
 // @summary Update a setting.
 // @description Update a setting.
+// @description The task.lifecycle (task lifecycle policies) setting is validated.
 // @tags settings
 // @accept json
 // @produce json
@@ -190,6 +209,11 @@ func (h SettingHandler) Update(ctx *gin.Context) {
 	}
 
 	m := updates.Model()
+	err = h.validate(m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	m.UpdateUser = h.BaseHandler.CurrentUser(ctx)
 	db := h.DB(ctx).Model(m)
 	db = db.Where("key", key)
@@ -229,6 +253,21 @@ func (h SettingHandler) Delete(ctx *gin.Context) {
 	h.Status(ctx, http.StatusNoContent)
 }
 
+//
+// validate the setting value.
+// Settings used by the hub are validated.
+func (h SettingHandler) validate(m *model.Setting) (err error) {
+	switch m.Key {
+	case task.LifecycleKey:
+		lifecycle := task.Lifecycle{}
+		err = lifecycle.With(m.Value)
+		if err != nil {
+			err = &BadRequestError{Reason: err.Error()}
+		}
+	}
+	return
+}
+
 //
 // Setting REST Resource
 type Setting struct {
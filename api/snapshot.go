@@ -0,0 +1,368 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/quota"
+	"github.com/konveyor/tackle2-hub/storage"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//
+// Params.
+const (
+	Snapshot = "snapshot"
+)
+
+//
+// Snapshot reasons.
+const (
+	SnapshotManual   = "manual"
+	SnapshotTask     = "task"
+	SnapshotAnalysis = "analysis"
+	SnapshotRestore  = "restore"
+)
+
+//
+// snapshotList lists the bucket snapshots.
+func (h *BucketOwner) snapshotList(ctx *gin.Context, id uint) {
+	var list []model.BucketSnapshot
+	db := h.DB(ctx).Preload("Task")
+	err := db.Find(&list, "BucketID", id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	resources := []BucketSnapshot{}
+	for i := range list {
+		r := BucketSnapshot{}
+		r.With(&list[i])
+		resources = append(resources, r)
+	}
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+//
+// snapshotCreate creates a (manual) bucket snapshot.
+func (h *BucketOwner) snapshotCreate(ctx *gin.Context, id uint) {
+	bucket := &model.Bucket{}
+	err := h.DB(ctx).First(bucket, id).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m, err := h.snapshot(ctx, bucket, SnapshotManual)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := BucketSnapshot{}
+	r.With(m)
+	h.Respond(ctx, http.StatusCreated, r)
+}
+
+//
+// snapshotGet gets a bucket snapshot.
+func (h *BucketOwner) snapshotGet(ctx *gin.Context, id uint) {
+	m, err := h.snapshotFind(ctx, id, ctx.Param(ID2))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := BucketSnapshot{}
+	r.With(m)
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+//
+// snapshotDelete deletes a bucket snapshot.
+func (h *BucketOwner) snapshotDelete(ctx *gin.Context, id uint) {
+	m, err := h.snapshotFind(ctx, id, ctx.Param(ID2))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	err = storage.Store.Delete(m.Path)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	quota.Reset()
+	err = h.DB(ctx).Delete(m).Error
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	h.Status(ctx, http.StatusNoContent)
+}
+
+//
+// snapshotRestore restores the bucket content from the snapshot.
+// The current content is snapshot before it is replaced.
+func (h *BucketOwner) snapshotRestore(ctx *gin.Context, id uint) {
+	m, err := h.snapshotFind(ctx, id, ctx.Param(ID2))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	bucket := m.Bucket
+	current, err := quota.Size(bucket.Path)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	size, err := quota.Size(m.Path)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	reservation, err := quota.Check(bucket.Path, size-current)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	defer func() {
+		reservation.Release()
+		quota.Reset()
+	}()
+	if current > 0 {
+		_, err = h.snapshot(ctx, bucket, SnapshotRestore)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+	}
+	err = storage.Store.Delete(bucket.Path)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	err = storage.Store.MkDir(bucket.Path)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	err = storage.Copy(m.Path, bucket.Path)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	h.Status(ctx, http.StatusNoContent)
+}
+
+//
+// snapshotDiff compares the snapshot with the current bucket
+// content or with another snapshot when ?snapshot=id specified.
+func (h *BucketOwner) snapshotDiff(ctx *gin.Context, id uint) {
+	m, err := h.snapshotFind(ctx, id, ctx.Param(ID2))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	path := m.Bucket.Path
+	if s := ctx.Query(Snapshot); s != "" {
+		other, err := h.snapshotFind(ctx, id, s)
+		if err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		path = other.Path
+	}
+	r := BucketDiff{
+		Added:   []string{},
+		Removed: []string{},
+		Changed: []string{},
+	}
+	err = r.With(h, m.Path, path)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+//
+// snapshotBefore snapshots the bucket before it is first
+// written by a task when enabled. Empty buckets are not snapshot.
+func (h *BucketOwner) snapshotBefore(ctx *gin.Context, id uint) (err error) {
+	if !Settings.Hub.Bucket.Snapshot {
+		return
+	}
+	task := h.CurrentTask(ctx)
+	if task == 0 {
+		return
+	}
+	var n int64
+	db := h.DB(ctx).Model(&model.BucketSnapshot{})
+	db = db.Where("BucketID", id)
+	db = db.Where("TaskID", task)
+	err = db.Count(&n).Error
+	if err != nil || n > 0 {
+		return
+	}
+	bucket := &model.Bucket{}
+	err = h.DB(ctx).First(bucket, id).Error
+	if err != nil {
+		return
+	}
+	size, err := quota.Size(bucket.Path)
+	if err != nil || size == 0 {
+		return
+	}
+	_, err = h.snapshot(ctx, bucket, SnapshotTask)
+	if errors.Is(err, &quota.Exceeded{}) {
+		Log.Info(
+			"Snapshot skipped.",
+			"bucket",
+			bucket.ID,
+			"reason",
+			err.Error())
+		err = nil
+	}
+	return
+}
+
+//
+// snapshotFind returns the bucket snapshot.
+func (h *BucketOwner) snapshotFind(ctx *gin.Context, id uint, s string) (m *model.BucketSnapshot, err error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		err = &BadRequestError{Reason: Snapshot + " must be an integer."}
+		return
+	}
+	m = &model.BucketSnapshot{}
+	db := h.DB(ctx).Preload("Bucket").Preload("Task")
+	err = db.First(m, "ID = ? AND BucketID = ?", n, id).Error
+	return
+}
+
+//
+// snapshot copies the bucket content into a new snapshot.
+func (h *BucketOwner) snapshot(ctx *gin.Context, bucket *model.Bucket, reason string) (m *model.BucketSnapshot, err error) {
+	size, err := quota.Size(bucket.Path)
+	if err != nil {
+		return
+	}
+	reservation, err := quota.Check("", size)
+	if err != nil {
+		return
+	}
+	defer reservation.Release()
+	m = &model.BucketSnapshot{
+		Reason:   reason,
+		BucketID: bucket.ID,
+		Bucket:   bucket,
+	}
+	m.CreateUser = h.CurrentUser(ctx)
+	task := h.CurrentTask(ctx)
+	if task > 0 {
+		m.TaskID = &task
+	}
+	db := h.DB(ctx)
+	err = db.Omit("Bucket", "Task").Create(m).Error
+	if err != nil {
+		return
+	}
+	err = storage.Copy(bucket.Path, m.Path)
+	if err != nil {
+		_ = storage.Store.Delete(m.Path)
+		_ = db.Delete(m)
+		return
+	}
+	reservation.Commit()
+	return
+}
+
+//
+// BucketSnapshot REST resource.
+type BucketSnapshot struct {
+	Resource   `yaml:",inline"`
+	Path       string     `json:"path"`
+	Reason     string     `json:"reason"`
+	Task       *Ref       `json:"task,omitempty" yaml:",omitempty"`
+	Expiration *time.Time `json:"expiration,omitempty" yaml:",omitempty"`
+}
+
+//
+// With updates the resource with the model.
+func (r *BucketSnapshot) With(m *model.BucketSnapshot) {
+	r.Resource.With(&m.Model)
+	r.Path = m.Path
+	r.Reason = m.Reason
+	r.Task = r.refPtr(m.TaskID, m.Task)
+	r.Expiration = m.Expiration
+}
+
+//
+// BucketDiff REST resource.
+// Lists (relative) paths added, removed or changed.
+type BucketDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+}
+
+//
+// With updates the resource with the difference between
+// the content of the old and new directories.
+func (r *BucketDiff) With(h *BucketOwner, old, new string) (err error) {
+	index := func(root string) (mp map[string]storage.Info, err error) {
+		mp = make(map[string]storage.Info)
+		list, err := storage.Store.List(root)
+		if err != nil {
+			if errors.Is(err, &storage.NotFound{}) {
+				err = nil
+			}
+			return
+		}
+		for _, info := range list {
+			mp[strings.TrimPrefix(info.Path, root+"/")] = info
+		}
+		return
+	}
+	oldIndex, err := index(old)
+	if err != nil {
+		return
+	}
+	newIndex, err := index(new)
+	if err != nil {
+		return
+	}
+	for path, info := range newIndex {
+		oldInfo, found := oldIndex[path]
+		if !found {
+			r.Added = append(r.Added, path)
+			continue
+		}
+		changed := oldInfo.Size != info.Size
+		if !changed {
+			var oldDigest, newDigest string
+			oldDigest, err = storage.Digest(oldInfo)
+			if err != nil {
+				return
+			}
+			newDigest, err = storage.Digest(info)
+			if err != nil {
+				return
+			}
+			changed = oldDigest != newDigest
+		}
+		if changed {
+			r.Changed = append(r.Changed, path)
+		}
+	}
+	for path := range oldIndex {
+		if _, found := newIndex[path]; !found {
+			r.Removed = append(r.Removed, path)
+		}
+	}
+	sort.Strings(r.Added)
+	sort.Strings(r.Removed)
+	sort.Strings(r.Changed)
+	return
+}
@@ -20,6 +20,10 @@ const (
 	TaskReportRoot        = TaskRoot + "/report"
 	TaskBucketRoot        = TaskRoot + "/bucket"
 	TaskBucketContentRoot = TaskBucketRoot + "/*" + Wildcard
+	TaskSnapshotsRoot     = TaskRoot + "/snapshots"
+	TaskSnapshotRoot      = TaskSnapshotsRoot + "/:" + ID2
+	TaskSnapshotRestore   = TaskSnapshotRoot + "/restore"
+	TaskSnapshotDiff      = TaskSnapshotRoot + "/diff"
 	TaskSubmitRoot        = TaskRoot + "/submit"
 	TaskCancelRoot        = TaskRoot + "/cancel"
 )
@@ -38,7 +42,7 @@ type TaskHandler struct {
 // AddRoutes adds routes.
 func (h TaskHandler) AddRoutes(e *gin.Engine) {
 	routeGroup := e.Group("/")
-	routeGroup.Use(Required("tasks"))
+	routeGroup.Use(Required("tasks"), OwnedTask)
 	routeGroup.GET(TasksRoot, h.List)
 	routeGroup.GET(TasksRoot+"/", h.List)
 	routeGroup.POST(TasksRoot, h.Create)
@@ -50,15 +54,21 @@ func (h TaskHandler) AddRoutes(e *gin.Engine) {
 	routeGroup.PUT(TaskCancelRoot, h.Cancel)
 	// Bucket
 	routeGroup = e.Group("/")
-	routeGroup.Use(Required("tasks.bucket"))
+	routeGroup.Use(Required("tasks.bucket"), OwnedTask)
 	routeGroup.GET(TaskBucketRoot, h.BucketGet)
 	routeGroup.GET(TaskBucketContentRoot, h.BucketGet)
 	routeGroup.POST(TaskBucketContentRoot, h.BucketPut)
 	routeGroup.PUT(TaskBucketContentRoot, h.BucketPut)
 	routeGroup.DELETE(TaskBucketContentRoot, h.BucketDelete)
+	routeGroup.GET(TaskSnapshotsRoot, h.SnapshotList)
+	routeGroup.POST(TaskSnapshotsRoot, h.SnapshotCreate)
+	routeGroup.GET(TaskSnapshotRoot, h.SnapshotGet)
+	routeGroup.DELETE(TaskSnapshotRoot, h.SnapshotDelete)
+	routeGroup.PUT(TaskSnapshotRestore, h.SnapshotRestore)
+	routeGroup.GET(TaskSnapshotDiff, h.SnapshotDiff)
 	// Report
 	routeGroup = e.Group("/")
-	routeGroup.Use(Required("tasks.report"))
+	routeGroup.Use(Required("tasks.report"), OwnedTask)
 	routeGroup.POST(TaskReportRoot, h.CreateReport)
 	routeGroup.PUT(TaskReportRoot, h.UpdateReport)
 	routeGroup.DELETE(TaskReportRoot, h.DeleteReport)
@@ -90,6 +100,8 @@ func (h TaskHandler) Get(ctx *gin.Context) {
 // List godoc
 // @summary List all tasks.
 // @description List all tasks.
+// @description When ownership is enforced, tasks associated with
+// @description applications that are not owned are not listed.
 // @tags tasks
 // @produce json
 // @success 200 {object} []api.Task
@@ -101,6 +113,12 @@ func (h TaskHandler) List(ctx *gin.Context) {
 	if locator != "" {
 		db = db.Where("locator", locator)
 	}
+	ownership := Ownership{ctx: ctx}
+	if ownership.Restricted() {
+		db = db.Where(
+			"ApplicationID IS NULL OR ApplicationID IN (?)",
+			ownership.AppIDs())
+	}
 	db = db.Preload(clause.Associations)
 	result := db.Find(&list)
 	if result.Error != nil {
@@ -146,7 +164,22 @@ func (h TaskHandler) Create(ctx *gin.Context) {
 			})
 		return
 	}
+	err = r.permit(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	err = pin(h.DB(ctx), r.RuleSets)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	m := r.Model()
+	err = tasking.ValidateCaches(tasking.Caches(m))
+	if err != nil {
+		_ = ctx.Error(&BadRequestError{err.Error()})
+		return
+	}
 	m.CreateUser = h.BaseHandler.CurrentUser(ctx)
 	result := h.DB(ctx).Create(&m)
 	if result.Error != nil {
@@ -217,7 +250,22 @@ func (h TaskHandler) Update(ctx *gin.Context) {
 			})
 		return
 	}
+	err = r.permit(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	err = pin(h.DB(ctx), r.RuleSets)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	m := r.Model()
+	err = tasking.ValidateCaches(tasking.Caches(m))
+	if err != nil {
+		_ = ctx.Error(&BadRequestError{err.Error()})
+		return
+	}
 	m.Reset()
 	db := h.DB(ctx).Model(m)
 	db = db.Where("id", id)
@@ -312,6 +360,9 @@ func (h TaskHandler) Cancel(ctx *gin.Context) {
 // @summary Get bucket content by ID and path.
 // @description Get bucket content by ID and path.
 // @description Returns index.html for directories when Accept=text/html else a tarball.
+// @description Returns the manifest for directories when ?manifest=true.
+// @description The tarball compression (gzip|zstd) is negotiated using the X-Compression header.
+// @description Files support (single) byte ranges.
 // @description ?filter=glob supports directory content filtering.
 // @tags tasks
 // @produce octet-stream
@@ -320,6 +371,7 @@ func (h TaskHandler) Cancel(ctx *gin.Context) {
 // @param id path int true "Task ID"
 // @param wildcard path string true "Content path"
 // @param filter query string false "Filter"
+// @param manifest query bool false "Manifest"
 func (h TaskHandler) BucketGet(ctx *gin.Context) {
 	m := &model.Task{}
 	id := h.pk(ctx)
@@ -339,6 +391,7 @@ func (h TaskHandler) BucketGet(ctx *gin.Context) {
 // BucketPut godoc
 // @summary Upload bucket content by ID and path.
 // @description Upload bucket content by ID and path (handles both [post] and [put] requests).
+// @description X-Directory=expand replaces the directory; X-Directory=merge writes into the directory.
 // @tags tasks
 // @produce json
 // @success 204
@@ -386,6 +439,122 @@ func (h TaskHandler) BucketDelete(ctx *gin.Context) {
 	h.bucketDelete(ctx, *m.BucketID)
 }
 
+// SnapshotList godoc
+// @summary List bucket snapshots.
+// @description List bucket snapshots.
+// @tags tasks
+// @produce json
+// @success 200 {object} []api.BucketSnapshot
+// @router /tasks/{id}/snapshots [get]
+// @param id path int true "Task ID"
+func (h TaskHandler) SnapshotList(ctx *gin.Context) {
+	id, found := h.bucketID(ctx)
+	if found {
+		h.snapshotList(ctx, id)
+	}
+}
+
+// SnapshotCreate godoc
+// @summary Create a bucket snapshot.
+// @description Create a (manual) snapshot of the bucket content.
+// @tags tasks
+// @produce json
+// @success 201 {object} api.BucketSnapshot
+// @router /tasks/{id}/snapshots [post]
+// @param id path int true "Task ID"
+func (h TaskHandler) SnapshotCreate(ctx *gin.Context) {
+	id, found := h.bucketID(ctx)
+	if found {
+		h.snapshotCreate(ctx, id)
+	}
+}
+
+// SnapshotGet godoc
+// @summary Get a bucket snapshot.
+// @description Get a bucket snapshot.
+// @tags tasks
+// @produce json
+// @success 200 {object} api.BucketSnapshot
+// @router /tasks/{id}/snapshots/{sid} [get]
+// @param id path int true "Task ID"
+// @param sid path int true "Snapshot ID"
+func (h TaskHandler) SnapshotGet(ctx *gin.Context) {
+	id, found := h.bucketID(ctx)
+	if found {
+		h.snapshotGet(ctx, id)
+	}
+}
+
+// SnapshotDelete godoc
+// @summary Delete a bucket snapshot.
+// @description Delete a bucket snapshot.
+// @tags tasks
+// @produce json
+// @success 204
+// @router /tasks/{id}/snapshots/{sid} [delete]
+// @param id path int true "Task ID"
+// @param sid path int true "Snapshot ID"
+func (h TaskHandler) SnapshotDelete(ctx *gin.Context) {
+	id, found := h.bucketID(ctx)
+	if found {
+		h.snapshotDelete(ctx, id)
+	}
+}
+
+// SnapshotRestore godoc
+// @summary Restore a bucket snapshot.
+// @description Restore the bucket content from the snapshot.
+// @description The current content is snapshot before it is replaced.
+// @tags tasks
+// @produce json
+// @success 204
+// @router /tasks/{id}/snapshots/{sid}/restore [put]
+// @param id path int true "Task ID"
+// @param sid path int true "Snapshot ID"
+func (h TaskHandler) SnapshotRestore(ctx *gin.Context) {
+	id, found := h.bucketID(ctx)
+	if found {
+		h.snapshotRestore(ctx, id)
+	}
+}
+
+// SnapshotDiff godoc
+// @summary Diff a bucket snapshot.
+// @description Compare the snapshot with the current bucket content.
+// @description ?snapshot=id compares with another snapshot.
+// @tags tasks
+// @produce json
+// @success 200 {object} api.BucketDiff
+// @router /tasks/{id}/snapshots/{sid}/diff [get]
+// @param id path int true "Task ID"
+// @param sid path int true "Snapshot ID"
+// @param snapshot query int false "Snapshot ID"
+func (h TaskHandler) SnapshotDiff(ctx *gin.Context) {
+	id, found := h.bucketID(ctx)
+	if found {
+		h.snapshotDiff(ctx, id)
+	}
+}
+
+//
+// bucketID returns the task bucket ID.
+// Reports not found when the task has no bucket.
+func (h TaskHandler) bucketID(ctx *gin.Context) (id uint, found bool) {
+	m := &model.Task{}
+	result := h.DB(ctx).First(m, h.pk(ctx))
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	if !m.HasBucket() {
+		h.Status(ctx, http.StatusNotFound)
+		return
+	}
+	id = *m.BucketID
+	found = true
+	return
+}
+
 // CreateReport godoc
 // @summary Create a task report.
 // @description Update a task report.
@@ -492,11 +661,13 @@ func (h *TaskHandler) omitted(db *gorm.DB) (out *gorm.DB) {
 // TTL time-to-live.
 type TTL struct {
 	Created   int `json:"created,omitempty"`
-	Pending   int `json:"pending,omitempty"`
 	Postponed int `json:"postponed,omitempty"`
+	Ready     int `json:"ready,omitempty"`
+	Pending   int `json:"pending,omitempty"`
 	Running   int `json:"running,omitempty"`
 	Succeeded int `json:"succeeded,omitempty"`
 	Failed    int `json:"failed,omitempty"`
+	Canceled  int `json:"canceled,omitempty"`
 }
 
 //
@@ -506,30 +677,40 @@ type TaskError struct {
 	Description string `json:"description"`
 }
 
+//
+// TaskCache (named) cache volume requested by the task.
+// Mounted read-write (exclusive) unless read-only.
+type TaskCache struct {
+	Name     string `json:"name"`
+	ReadOnly bool   `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+}
+
 //
 // Task REST resource.
 type Task struct {
 	Resource    `yaml:",inline"`
-	Name        string      `json:"name"`
-	Locator     string      `json:"locator,omitempty" yaml:",omitempty"`
-	Priority    int         `json:"priority,omitempty" yaml:",omitempty"`
-	Variant     string      `json:"variant,omitempty" yaml:",omitempty"`
-	Policy      string      `json:"policy,omitempty" yaml:",omitempty"`
-	TTL         *TTL        `json:"ttl,omitempty" yaml:",omitempty"`
-	Addon       string      `json:"addon,omitempty" binding:"required" yaml:",omitempty"`
-	Data        interface{} `json:"data" swaggertype:"object" binding:"required"`
-	Application *Ref        `json:"application,omitempty" yaml:",omitempty"`
-	State       string      `json:"state"`
-	Image       string      `json:"image,omitempty" yaml:",omitempty"`
-	Pod         string      `json:"pod,omitempty" yaml:",omitempty"`
-	Retries     int         `json:"retries,omitempty" yaml:",omitempty"`
-	Started     *time.Time  `json:"started,omitempty" yaml:",omitempty"`
-	Terminated  *time.Time  `json:"terminated,omitempty" yaml:",omitempty"`
-	Canceled    bool        `json:"canceled,omitempty" yaml:",omitempty"`
-	Bucket      *Ref        `json:"bucket,omitempty" yaml:",omitempty"`
-	Purged      bool        `json:"purged,omitempty" yaml:",omitempty"`
-	Errors      []TaskError `json:"errors,omitempty" yaml:",omitempty"`
-	Activity    []string    `json:"activity,omitempty" yaml:",omitempty"`
+	Name        string        `json:"name"`
+	Locator     string        `json:"locator,omitempty" yaml:",omitempty"`
+	Priority    int           `json:"priority,omitempty" yaml:",omitempty"`
+	Variant     string        `json:"variant,omitempty" yaml:",omitempty"`
+	Policy      string        `json:"policy,omitempty" yaml:",omitempty"`
+	TTL         *TTL          `json:"ttl,omitempty" yaml:",omitempty"`
+	Caches      []TaskCache   `json:"caches,omitempty" yaml:",omitempty"`
+	Addon       string        `json:"addon,omitempty" binding:"required" yaml:",omitempty"`
+	Data        interface{}   `json:"data" swaggertype:"object" binding:"required"`
+	RuleSets    []RevisionRef `json:"ruleSets,omitempty" yaml:"ruleSets,omitempty"`
+	Application *Ref          `json:"application,omitempty" yaml:",omitempty"`
+	State       string        `json:"state"`
+	Image       string        `json:"image,omitempty" yaml:",omitempty"`
+	Pod         string        `json:"pod,omitempty" yaml:",omitempty"`
+	Retries     int           `json:"retries,omitempty" yaml:",omitempty"`
+	Started     *time.Time    `json:"started,omitempty" yaml:",omitempty"`
+	Terminated  *time.Time    `json:"terminated,omitempty" yaml:",omitempty"`
+	Canceled    bool          `json:"canceled,omitempty" yaml:",omitempty"`
+	Bucket      *Ref          `json:"bucket,omitempty" yaml:",omitempty"`
+	Purged      bool          `json:"purged,omitempty" yaml:",omitempty"`
+	Errors      []TaskError   `json:"errors,omitempty" yaml:",omitempty"`
+	Activity    []string      `json:"activity,omitempty" yaml:",omitempty"`
 }
 
 //
@@ -552,9 +733,15 @@ func (r *Task) With(m *model.Task) {
 	r.Retries = m.Retries
 	r.Canceled = m.Canceled
 	_ = json.Unmarshal(m.Data, &r.Data)
+	if m.RuleSets != nil {
+		_ = json.Unmarshal(m.RuleSets, &r.RuleSets)
+	}
 	if m.TTL != nil {
 		_ = json.Unmarshal(m.TTL, &r.TTL)
 	}
+	if m.Caches != nil {
+		_ = json.Unmarshal(m.Caches, &r.Caches)
+	}
 	if m.Errors != nil {
 		_ = json.Unmarshal(m.Errors, &r.Errors)
 	}
@@ -588,9 +775,28 @@ func (r *Task) Model() (m *model.Task) {
 	}
 	m.Data, _ = json.Marshal(StrMap(r.Data))
 	m.ID = r.ID
+	if r.RuleSets != nil {
+		m.RuleSets, _ = json.Marshal(r.RuleSets)
+	}
 	if r.TTL != nil {
 		m.TTL, _ = json.Marshal(r.TTL)
 	}
+	if r.Caches != nil {
+		m.Caches, _ = json.Marshal(r.Caches)
+	}
+	return
+}
+
+//
+// permit the application referenced by the task.
+// Returns Forbidden when ownership is enforced and the
+// application is not owned.
+func (r *Task) permit(ctx *gin.Context) (err error) {
+	if r.Application == nil {
+		return
+	}
+	ownership := Ownership{ctx: ctx}
+	err = ownership.Permit(r.Application.ID)
 	return
 }
 
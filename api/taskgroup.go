@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/konveyor/tackle2-hub/model"
 	tasking "github.com/konveyor/tackle2-hub/task"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	"net/http"
@@ -112,8 +113,23 @@ func (h TaskGroupHandler) Create(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	err = r.permit(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	err = r.pin(h.DB(ctx))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	db := h.DB(ctx)
 	m := r.Model()
+	err = validateCaches(m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	switch r.State {
 	case "":
 		m.State = tasking.Created
@@ -167,7 +183,29 @@ func (h TaskGroupHandler) Update(ctx *gin.Context) {
 		_ = ctx.Error(err)
 		return
 	}
+	existing := &TaskGroup{}
+	existing.With(current)
+	err = existing.permit(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	err = updated.permit(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	err = updated.pin(h.DB(ctx))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	m := updated.Model()
+	err = validateCaches(m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
 	m.ID = current.ID
 	m.UpdateUser = h.BaseHandler.CurrentUser(ctx)
 	db := h.DB(ctx).Model(m)
@@ -265,6 +303,10 @@ func (h TaskGroupHandler) Submit(ctx *gin.Context) {
 			}
 			r.With(m)
 		}
+		err = r.permit(ctx)
+		if err != nil {
+			return
+		}
 		r.State = tasking.Ready
 		return
 	}
@@ -280,6 +322,9 @@ func (h TaskGroupHandler) Submit(ctx *gin.Context) {
 // @summary Get bucket content by ID and path.
 // @description Get bucket content by ID and path.
 // @description Returns index.html for directories when Accept=text/html else a tarball.
+// @description Returns the manifest for directories when ?manifest=true.
+// @description The tarball compression (gzip|zstd) is negotiated using the X-Compression header.
+// @description Files support (single) byte ranges.
 // @description ?filter=glob supports directory content filtering.
 // @tags taskgroups
 // @produce octet-stream
@@ -288,6 +333,7 @@ func (h TaskGroupHandler) Submit(ctx *gin.Context) {
 // @param id path int true "TaskGroup ID"
 // @param wildcard path string true "Content path"
 // @param filter query string false "Filter"
+// @param manifest query bool false "Manifest"
 func (h TaskGroupHandler) BucketGet(ctx *gin.Context) {
 	m := &model.TaskGroup{}
 	id := h.pk(ctx)
@@ -307,6 +353,7 @@ func (h TaskGroupHandler) BucketGet(ctx *gin.Context) {
 // BucketPut godoc
 // @summary Upload bucket content by ID and path.
 // @description Upload bucket content by ID and path (handles both [post] and [put] requests).
+// @description X-Directory=expand replaces the directory; X-Directory=merge writes into the directory.
 // @tags taskgroups
 // @produce json
 // @success 204
@@ -358,12 +405,13 @@ func (h TaskGroupHandler) BucketDelete(ctx *gin.Context) {
 // TaskGroup REST resource.
 type TaskGroup struct {
 	Resource `yaml:",inline"`
-	Name     string      `json:"name"`
-	Addon    string      `json:"addon"`
-	Data     interface{} `json:"data" swaggertype:"object" binding:"required"`
-	Bucket   *Ref        `json:"bucket,omitempty"`
-	State    string      `json:"state"`
-	Tasks    []Task      `json:"tasks"`
+	Name     string        `json:"name"`
+	Addon    string        `json:"addon"`
+	Data     interface{}   `json:"data" swaggertype:"object" binding:"required"`
+	RuleSets []RevisionRef `json:"ruleSets,omitempty" yaml:"ruleSets,omitempty"`
+	Bucket   *Ref          `json:"bucket,omitempty"`
+	State    string        `json:"state"`
+	Tasks    []Task        `json:"tasks"`
 }
 
 //
@@ -376,6 +424,9 @@ func (r *TaskGroup) With(m *model.TaskGroup) {
 	r.Bucket = r.refPtr(m.BucketID, m.Bucket)
 	r.Tasks = []Task{}
 	_ = json.Unmarshal(m.Data, &r.Data)
+	if m.RuleSets != nil {
+		_ = json.Unmarshal(m.RuleSets, &r.RuleSets)
+	}
 	switch m.State {
 	case "", tasking.Created:
 		_ = json.Unmarshal(m.List, &r.Tasks)
@@ -390,6 +441,48 @@ func (r *TaskGroup) With(m *model.TaskGroup) {
 	}
 }
 
+//
+// pin resolves the ruleset revisions pinned by
+// the group and the member tasks.
+func (r *TaskGroup) pin(db *gorm.DB) (err error) {
+	err = pin(db, r.RuleSets)
+	if err != nil {
+		return
+	}
+	for i := range r.Tasks {
+		err = pin(db, r.Tasks[i].RuleSets)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+//
+// permit the applications referenced by the member tasks.
+func (r *TaskGroup) permit(ctx *gin.Context) (err error) {
+	for i := range r.Tasks {
+		err = r.Tasks[i].permit(ctx)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+//
+// validateCaches validates the caches requested by the member tasks.
+func validateCaches(m *model.TaskGroup) (err error) {
+	for i := range m.Tasks {
+		err = tasking.ValidateCaches(tasking.Caches(&m.Tasks[i]))
+		if err != nil {
+			err = &BadRequestError{err.Error()}
+			return
+		}
+	}
+	return
+}
+
 //
 // Model builds a model.
 func (r *TaskGroup) Model() (m *model.TaskGroup) {
@@ -401,6 +494,9 @@ func (r *TaskGroup) Model() (m *model.TaskGroup) {
 	m.ID = r.ID
 	m.Data, _ = json.Marshal(StrMap(r.Data))
 	m.List, _ = json.Marshal(r.Tasks)
+	if r.RuleSets != nil {
+		m.RuleSets, _ = json.Marshal(r.RuleSets)
+	}
 	if r.Bucket != nil {
 		m.BucketID = &r.Bucket.ID
 	}
@@ -0,0 +1,261 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/auth"
+	"github.com/konveyor/tackle2-hub/model"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//
+// Routes
+const (
+	TokensRoot = "/tokens"
+	TokenRoot  = TokensRoot + "/:" + ID
+)
+
+//
+// TokenHandler handles (personal) API token routes.
+// Tokens are owned by the user that created them and
+// may only be listed and revoked by the owner.
+type TokenHandler struct {
+	BaseHandler
+}
+
+//
+// AddRoutes adds routes.
+func (h TokenHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("tokens"), Transaction)
+	routeGroup.GET(TokensRoot, h.List)
+	routeGroup.GET(TokensRoot+"/", h.List)
+	routeGroup.POST(TokensRoot, h.Create)
+	routeGroup.GET(TokenRoot, h.Get)
+	routeGroup.DELETE(TokenRoot, h.Delete)
+}
+
+// Get godoc
+// @summary Get an API token by ID.
+// @description Get an API token by ID.
+// @description The token (secret) is never returned.
+// @tags tokens
+// @produce json
+// @success 200 {object} api.APIToken
+// @router /tokens/{id} [get]
+// @param id path int true "Token ID"
+func (h TokenHandler) Get(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.APIToken{}
+	db := h.owned(ctx)
+	result := db.First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r := APIToken{}
+	r.With(m)
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// List godoc
+// @summary List the API tokens owned by the user.
+// @description List the API tokens owned by the user.
+// @tags tokens
+// @produce json
+// @success 200 {object} []api.APIToken
+// @router /tokens [get]
+func (h TokenHandler) List(ctx *gin.Context) {
+	var list []model.APIToken
+	db := h.owned(ctx)
+	result := db.Find(&list)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	resources := []APIToken{}
+	for i := range list {
+		r := APIToken{}
+		r.With(&list[i])
+		resources = append(resources, r)
+	}
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+// Create godoc
+// @summary Create an API token.
+// @description Create an API token owned by the user.
+// @description The scopes must be a subset of the scopes granted to the user.
+// @description The token (secret) is returned only in the response.
+// @description The expiration of tokens issued to users not managed by the hub
+// @description (keycloak|oidc) is capped by API_TOKEN_UNMANAGED_LIFESPAN (days).
+// @tags tokens
+// @accept json
+// @produce json
+// @success 201 {object} api.APIToken
+// @router /tokens [post]
+// @param token body api.APIToken true "Token data"
+func (h TokenHandler) Create(ctx *gin.Context) {
+	r := &APIToken{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	rtx := WithContext(ctx)
+	err = r.Validate(rtx.Scopes)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := r.Model()
+	m.User = h.CurrentUser(ctx)
+	m.CreateUser = m.User
+	err = r.issue(m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	result := h.DB(ctx).Create(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r.With(m)
+	h.Respond(ctx, http.StatusCreated, r)
+}
+
+// Delete godoc
+// @summary Revoke (delete) an API token.
+// @description Revoke (delete) an API token.
+// @tags tokens
+// @success 204
+// @router /tokens/{id} [delete]
+// @param id path int true "Token ID"
+func (h TokenHandler) Delete(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.APIToken{}
+	db := h.owned(ctx)
+	result := db.First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	result = h.DB(ctx).Delete(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	h.Status(ctx, http.StatusNoContent)
+}
+
+//
+// owned returns a DB scoped to (personal) tokens owned by the user.
+func (h TokenHandler) owned(ctx *gin.Context) (db *gorm.DB) {
+	db = h.DB(ctx).Where("ServiceAccountID IS NULL")
+	db = db.Where("User", h.CurrentUser(ctx))
+	return
+}
+
+//
+// APIToken REST resource.
+type APIToken struct {
+	Resource       `yaml:",inline"`
+	Name           string     `json:"name" binding:"required"`
+	User           string     `json:"user,omitempty" yaml:",omitempty"`
+	ServiceAccount *Ref       `json:"serviceAccount,omitempty" yaml:"serviceAccount,omitempty"`
+	Scopes         []string   `json:"scopes" binding:"required,min=1"`
+	Expiration     *time.Time `json:"expiration,omitempty" yaml:",omitempty"`
+	LastUsed       *time.Time `json:"lastUsed,omitempty" yaml:"lastUsed,omitempty"`
+	Prefix         string     `json:"prefix,omitempty" yaml:",omitempty"`
+	Token          string     `json:"token,omitempty" yaml:",omitempty"`
+}
+
+//
+// With updates the resource with the model.
+func (r *APIToken) With(m *model.APIToken) {
+	r.Resource.With(&m.Model)
+	r.Name = m.Name
+	r.User = m.User
+	r.ServiceAccount = r.refPtr(m.ServiceAccountID, m.ServiceAccount)
+	r.Scopes = []string{}
+	_ = json.Unmarshal(m.Scopes, &r.Scopes)
+	r.Expiration = m.Expiration
+	r.LastUsed = m.LastUsed
+	r.Prefix = m.Prefix
+}
+
+//
+// Model builds a model.
+func (r *APIToken) Model() (m *model.APIToken) {
+	m = &model.APIToken{
+		Name:       r.Name,
+		Expiration: r.Expiration,
+	}
+	m.Scopes, _ = json.Marshal(r.Scopes)
+	return
+}
+
+//
+// Validate the expiration and that the requested scopes
+// are covered by the granted scopes.
+func (r *APIToken) Validate(granted []auth.Scope) (err error) {
+	if r.Expiration != nil && r.Expiration.Before(time.Now()) {
+		err = &BadRequestError{Reason: "expiration must be in the future."}
+		return
+	}
+	err = validScopes(r.Scopes, granted)
+	return
+}
+
+//
+// issue generates the token.
+// The model is updated with the digest and the (default) expiration.
+// The expiration of personal tokens issued to users not managed
+// by the hub is capped.
+func (r *APIToken) issue(m *model.APIToken) (err error) {
+	token, digest, prefix, err := auth.NewAPIToken()
+	if err != nil {
+		return
+	}
+	m.Digest = digest
+	m.Prefix = prefix
+	now := time.Now()
+	lifespan := Settings.Auth.APIToken.Lifespan
+	if m.Expiration == nil && lifespan > 0 {
+		expiration := now.Add(time.Duration(lifespan) * 24 * time.Hour)
+		m.Expiration = &expiration
+	}
+	if m.ServiceAccountID == nil {
+		_, managed := auth.RBAC.Bound(m.User)
+		capped := auth.UnmanagedExpiration(now)
+		if !managed && capped != nil {
+			if m.Expiration == nil || m.Expiration.After(*capped) {
+				m.Expiration = capped
+			}
+		}
+	}
+	r.Token = token
+	return
+}
+
+//
+// validScopes validates the scopes are well-formed
+// and covered by the granted scopes.
+func validScopes(scopes []string, granted []auth.Scope) (err error) {
+	for _, s := range scopes {
+		part := strings.Split(s, ":")
+		if len(part) != 2 || part[0] == "" || part[1] == "" {
+			err = &BadRequestError{Reason: "scope: " + s + " must be: <resource>:<verb>."}
+			return
+		}
+		if !auth.ScopeCovered(granted, s) {
+			err = &Forbidden{Reason: "scope: " + s + " not granted."}
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/quota"
+	"net/http"
+)
+
+//
+// Routes
+const (
+	UsageRoot = "/usage"
+)
+
+//
+// UsageHandler handles storage usage routes.
+type UsageHandler struct {
+	BaseHandler
+}
+
+//
+// AddRoutes adds routes.
+func (h UsageHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("usage"))
+	routeGroup.GET(UsageRoot, h.Get)
+}
+
+// Get godoc
+// @summary Get storage usage.
+// @description Get storage usage (bytes) by owner kind.
+// @description The total includes buckets, files and snapshots but not the cache.
+// @tags usage
+// @produce json
+// @success 200 {object} api.Usage
+// @router /usage [get]
+func (h UsageHandler) Get(ctx *gin.Context) {
+	usage, err := quota.Measure(h.DB(ctx))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	r := Usage{}
+	r.With(&usage)
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+//
+// Usage REST resource.
+type Usage struct {
+	Application int64 `json:"application"`
+	Task        int64 `json:"task"`
+	TaskGroup   int64 `json:"taskGroup"`
+	Bucket      int64 `json:"bucket"`
+	File        int64 `json:"file"`
+	Snapshot    int64 `json:"snapshot"`
+	Cache       int64 `json:"cache"`
+	Total       int64 `json:"total"`
+	Quota       struct {
+		Bucket int64 `json:"bucket"`
+		Total  int64 `json:"total"`
+	} `json:"quota"`
+}
+
+//
+// With updates the resource with the usage.
+func (r *Usage) With(m *quota.Usage) {
+	r.Application = m.Application
+	r.Task = m.Task
+	r.TaskGroup = m.TaskGroup
+	r.Bucket = m.Bucket
+	r.File = m.File
+	r.Snapshot = m.Snapshot
+	r.Cache = m.Cache
+	r.Total = m.Total
+	r.Quota.Bucket = quota.Bucket()
+	r.Quota.Total = quota.Total()
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/tackle2-hub/auth"
+	"github.com/konveyor/tackle2-hub/model"
+	"gorm.io/gorm/clause"
+	"net/http"
+)
+
+//
+// Routes
+const (
+	UsersRoot = "/users"
+	UserRoot  = UsersRoot + "/:" + ID
+)
+
+//
+// UserHandler handles user (role binding) routes.
+type UserHandler struct {
+	BaseHandler
+}
+
+//
+// AddRoutes adds routes.
+func (h UserHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("users"), ReloadRBAC, Transaction)
+	routeGroup.GET(UsersRoot, h.List)
+	routeGroup.GET(UsersRoot+"/", h.List)
+	routeGroup.POST(UsersRoot, h.Create)
+	routeGroup.GET(UserRoot, h.Get)
+	routeGroup.PUT(UserRoot, h.Update)
+	routeGroup.DELETE(UserRoot, h.Delete)
+}
+
+// Get godoc
+// @summary Get a user by ID.
+// @description Get a user by ID.
+// @tags users
+// @produce json
+// @success 200 {object} api.User
+// @router /users/{id} [get]
+// @param id path int true "User ID"
+func (h UserHandler) Get(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.User{}
+	db := h.preLoad(h.DB(ctx), clause.Associations)
+	result := db.First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r := User{}
+	r.With(m)
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// List godoc
+// @summary List all users.
+// @description List all users.
+// @tags users
+// @produce json
+// @success 200 {object} []api.User
+// @router /users [get]
+func (h UserHandler) List(ctx *gin.Context) {
+	var list []model.User
+	db := h.preLoad(h.DB(ctx), clause.Associations)
+	result := db.Find(&list)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	resources := []User{}
+	for i := range list {
+		r := User{}
+		r.With(&list[i])
+		resources = append(resources, r)
+	}
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+// Create godoc
+// @summary Create a user.
+// @description Create a user and bind roles.
+// @description The password (optional) is used when the user is created by the provider.
+// @description The scopes granted by the roles must be a subset of the scopes granted to the user.
+// @tags users
+// @accept json
+// @produce json
+// @success 201 {object} api.User
+// @router /users [post]
+// @param user body api.User true "User data"
+func (h UserHandler) Create(ctx *gin.Context) {
+	r := &User{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := r.Model()
+	err = h.validRoles(ctx, m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m.CreateUser = h.CurrentUser(ctx)
+	result := h.DB(ctx).Omit("Roles.*").Create(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	auth.RBAC.Password(m.Name, r.Password)
+	r.With(m)
+	r.Password = ""
+	h.Respond(ctx, http.StatusCreated, r)
+}
+
+// Update godoc
+// @summary Update a user.
+// @description Update a user (role bindings).
+// @description The scopes granted by the roles must be a subset of the scopes granted to the user.
+// @tags users
+// @accept json
+// @success 204
+// @router /users/{id} [put]
+// @param id path int true "User ID"
+// @param user body api.User true "User data"
+func (h UserHandler) Update(ctx *gin.Context) {
+	id := h.pk(ctx)
+	r := &User{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := r.Model()
+	m.ID = id
+	err = h.validRoles(ctx, m)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m.UpdateUser = h.CurrentUser(ctx)
+	db := h.DB(ctx).Model(m)
+	db = db.Omit(clause.Associations)
+	result := db.Updates(h.fields(m))
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	db = h.DB(ctx).Model(m)
+	err = db.Association("Roles").Replace(m.Roles)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	h.Status(ctx, http.StatusNoContent)
+}
+
+// Delete godoc
+// @summary Delete a user.
+// @description Delete a user, the role bindings and the personal API tokens.
+// @tags users
+// @success 204
+// @router /users/{id} [delete]
+// @param id path int true "User ID"
+func (h UserHandler) Delete(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.User{}
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	result = h.DB(ctx).Delete(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	result = h.DB(ctx).Delete(&model.APIToken{}, "User", m.Name)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	h.Status(ctx, http.StatusNoContent)
+}
+
+//
+// validRoles ensures the roles exist and the scopes granted
+// by the roles are granted to the current user.
+func (h UserHandler) validRoles(ctx *gin.Context, m *model.User) (err error) {
+	if len(m.Roles) == 0 {
+		return
+	}
+	var ids []uint
+	for _, role := range m.Roles {
+		ids = append(ids, role.ID)
+	}
+	var roles []model.Role
+	err = h.DB(ctx).Find(&roles, ids).Error
+	if err != nil {
+		return
+	}
+	if len(roles) != len(ids) {
+		err = &BadRequestError{Reason: "role not found."}
+		return
+	}
+	rtx := WithContext(ctx)
+	for i := range roles {
+		r := Role{}
+		r.With(&roles[i])
+		err = validScopes(r.Scopes(), rtx.Scopes)
+		if err != nil {
+			return
+		}
+	}
+	m.Roles = roles
+	return
+}
+
+//
+// User REST resource.
+type User struct {
+	Resource `yaml:",inline"`
+	Name     string `json:"name" binding:"required"`
+	Password string `json:"password,omitempty" yaml:",omitempty"`
+	Roles    []Ref  `json:"roles"`
+}
+
+//
+// With updates the resource with the model.
+func (r *User) With(m *model.User) {
+	r.Resource.With(&m.Model)
+	r.Name = m.Name
+	r.Roles = []Ref{}
+	for _, role := range m.Roles {
+		r.Roles = append(r.Roles, Ref{ID: role.ID, Name: role.Name})
+	}
+}
+
+//
+// Model builds a model.
+func (r *User) Model() (m *model.User) {
+	m = &model.User{
+		Name: r.Name,
+	}
+	m.ID = r.ID
+	for _, role := range r.Roles {
+		m.Roles = append(m.Roles, model.Role{Model: model.Model{ID: role.ID}})
+	}
+	return
+}
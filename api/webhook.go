@@ -0,0 +1,365 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	qf "github.com/konveyor/tackle2-hub/api/filter"
+	"github.com/konveyor/tackle2-hub/event"
+	"github.com/konveyor/tackle2-hub/model"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"time"
+)
+
+//
+// Routes
+const (
+	WebhooksRoot          = "/webhooks"
+	WebhookRoot           = WebhooksRoot + "/:" + ID
+	WebhookDeliveriesRoot = WebhookRoot + "/deliveries"
+	WebhookDeliveryRoot   = WebhookDeliveriesRoot + "/:" + ID2
+	WebhookRetryRoot      = WebhookDeliveryRoot + "/retry"
+)
+
+//
+// WebhookHandler handles webhook routes.
+type WebhookHandler struct {
+	BaseHandler
+}
+
+//
+// AddRoutes adds routes.
+func (h WebhookHandler) AddRoutes(e *gin.Engine) {
+	routeGroup := e.Group("/")
+	routeGroup.Use(Required("webhooks"), Transaction)
+	routeGroup.GET(WebhooksRoot, h.List)
+	routeGroup.GET(WebhooksRoot+"/", h.List)
+	routeGroup.POST(WebhooksRoot, h.Create)
+	routeGroup.GET(WebhookRoot, h.Get)
+	routeGroup.PUT(WebhookRoot, h.Update)
+	routeGroup.DELETE(WebhookRoot, h.Delete)
+	routeGroup.GET(WebhookDeliveriesRoot, h.DeliveryList)
+	routeGroup.GET(WebhookDeliveryRoot, h.DeliveryGet)
+	routeGroup.POST(WebhookRetryRoot, h.DeliveryRetry)
+}
+
+// Get godoc
+// @summary Get a webhook by ID.
+// @description Get a webhook by ID.
+// @tags webhooks
+// @produce json
+// @success 200 {object} api.Webhook
+// @router /webhooks/{id} [get]
+// @param id path int true "Webhook ID"
+func (h WebhookHandler) Get(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.Webhook{}
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r := Webhook{}
+	r.With(m)
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// List godoc
+// @summary List all webhooks.
+// @description List all webhooks.
+// @tags webhooks
+// @produce json
+// @success 200 {object} []api.Webhook
+// @router /webhooks [get]
+func (h WebhookHandler) List(ctx *gin.Context) {
+	var list []model.Webhook
+	result := h.DB(ctx).Find(&list)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	resources := []Webhook{}
+	for i := range list {
+		r := Webhook{}
+		r.With(&list[i])
+		resources = append(resources, r)
+	}
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+// Create godoc
+// @summary Create a webhook.
+// @description Create a webhook.
+// @description Kinds: resource kinds (application) or event names (task.state). Empty = all.
+// @description The secret is used to sign (HMAC-SHA256) the payload: X-Hub-Signature-256.
+// @description The secret is stored encrypted and is not returned.
+// @tags webhooks
+// @accept json
+// @produce json
+// @success 201 {object} api.Webhook
+// @router /webhooks [post]
+// @param webhook body api.Webhook true "Webhook data"
+func (h WebhookHandler) Create(ctx *gin.Context) {
+	r := &Webhook{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := r.Model()
+	err = m.Encrypt()
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m.CreateUser = h.CurrentUser(ctx)
+	result := h.DB(ctx).Omit(clause.Associations).Create(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r.With(m)
+	h.Respond(ctx, http.StatusCreated, r)
+}
+
+// Update godoc
+// @summary Update a webhook.
+// @description Update a webhook.
+// @description The secret is not updated when omitted.
+// @tags webhooks
+// @accept json
+// @success 204
+// @router /webhooks/{id} [put]
+// @param id path int true "Webhook ID"
+// @param webhook body api.Webhook true "Webhook data"
+func (h WebhookHandler) Update(ctx *gin.Context) {
+	id := h.pk(ctx)
+	r := &Webhook{}
+	err := h.Bind(ctx, r)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := r.Model()
+	err = m.Encrypt()
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m.ID = id
+	m.UpdateUser = h.CurrentUser(ctx)
+	fields := h.fields(m)
+	if r.Secret == "" {
+		delete(fields, "Secret")
+	}
+	db := h.DB(ctx).Model(m)
+	db = db.Omit(clause.Associations)
+	result := db.Updates(fields)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	h.Status(ctx, http.StatusNoContent)
+}
+
+// Delete godoc
+// @summary Delete a webhook.
+// @description Delete a webhook and the delivery log.
+// @tags webhooks
+// @success 204
+// @router /webhooks/{id} [delete]
+// @param id path int true "Webhook ID"
+func (h WebhookHandler) Delete(ctx *gin.Context) {
+	id := h.pk(ctx)
+	m := &model.Webhook{}
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	result = h.DB(ctx).Delete(m)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	h.Status(ctx, http.StatusNoContent)
+}
+
+// DeliveryList godoc
+// @summary List the deliveries (log) of a webhook.
+// @description List the deliveries (log) of a webhook. Newest first.
+// @description filters:
+// @description - id
+// @description - event
+// @description - kind
+// @description - action
+// @description - state
+// @tags webhooks
+// @produce json
+// @success 200 {object} []api.WebhookDelivery
+// @router /webhooks/{id}/deliveries [get]
+// @param id path int true "Webhook ID"
+func (h WebhookHandler) DeliveryList(ctx *gin.Context) {
+	id := h.pk(ctx)
+	filter, err := qf.New(ctx,
+		[]qf.Assert{
+			{Field: "id", Kind: qf.LITERAL},
+			{Field: "event", Kind: qf.LITERAL},
+			{Field: "kind", Kind: qf.STRING},
+			{Field: "action", Kind: qf.STRING},
+			{Field: "state", Kind: qf.STRING},
+		})
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+	m := &model.Webhook{}
+	result := h.DB(ctx).First(m, id)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	var list []model.WebhookDelivery
+	db := h.DB(ctx).Where("WebhookID = ?", id)
+	db = filter.Where(db)
+	db = db.Order("ID DESC")
+	result = db.Find(&list)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	resources := []WebhookDelivery{}
+	for i := range list {
+		r := WebhookDelivery{}
+		r.With(&list[i])
+		resources = append(resources, r)
+	}
+	h.Respond(ctx, http.StatusOK, resources)
+}
+
+// DeliveryGet godoc
+// @summary Get a webhook delivery by ID.
+// @description Get a webhook delivery by ID.
+// @tags webhooks
+// @produce json
+// @success 200 {object} api.WebhookDelivery
+// @router /webhooks/{id}/deliveries/{id2} [get]
+// @param id path int true "Webhook ID"
+// @param id2 path int true "Delivery ID"
+func (h WebhookHandler) DeliveryGet(ctx *gin.Context) {
+	id := h.pk(ctx)
+	id2 := ctx.Param(ID2)
+	m := &model.WebhookDelivery{}
+	result := h.DB(ctx).First(m, "WebhookID = ? AND ID = ?", id, id2)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	r := WebhookDelivery{}
+	r.With(m)
+	h.Respond(ctx, http.StatusOK, r)
+}
+
+// DeliveryRetry godoc
+// @summary Retry a webhook delivery.
+// @description Retry (reschedule) a failed webhook delivery.
+// @tags webhooks
+// @success 202
+// @router /webhooks/{id}/deliveries/{id2}/retry [post]
+// @param id path int true "Webhook ID"
+// @param id2 path int true "Delivery ID"
+func (h WebhookHandler) DeliveryRetry(ctx *gin.Context) {
+	id := h.pk(ctx)
+	id2 := ctx.Param(ID2)
+	m := &model.WebhookDelivery{}
+	result := h.DB(ctx).First(m, "WebhookID = ? AND ID = ?", id, id2)
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	now := time.Now()
+	result = h.DB(ctx).Model(m).Updates(
+		map[string]interface{}{
+			"State":       event.Pending,
+			"Attempts":    0,
+			"NextAttempt": &now,
+		})
+	if result.Error != nil {
+		_ = ctx.Error(result.Error)
+		return
+	}
+	h.Status(ctx, http.StatusAccepted)
+}
+
+//
+// Webhook REST resource.
+type Webhook struct {
+	Resource `yaml:",inline"`
+	Name     string   `json:"name" binding:"required"`
+	URL      string   `json:"url" binding:"required"`
+	Secret   string   `json:"secret,omitempty" yaml:",omitempty"`
+	Kinds    []string `json:"kinds"`
+	Enabled  bool     `json:"enabled"`
+}
+
+//
+// With updates the resource with the model.
+// The secret is not included.
+func (r *Webhook) With(m *model.Webhook) {
+	r.Resource.With(&m.Model)
+	r.Name = m.Name
+	r.URL = m.URL
+	r.Secret = ""
+	r.Enabled = m.Enabled
+	r.Kinds = []string{}
+	_ = json.Unmarshal(m.Kinds, &r.Kinds)
+}
+
+//
+// Model builds a model.
+func (r *Webhook) Model() (m *model.Webhook) {
+	m = &model.Webhook{
+		Name:    r.Name,
+		URL:     r.URL,
+		Secret:  r.Secret,
+		Enabled: r.Enabled,
+	}
+	if r.Kinds == nil {
+		r.Kinds = []string{}
+	}
+	m.Kinds, _ = json.Marshal(r.Kinds)
+	m.ID = r.ID
+	return
+}
+
+//
+// WebhookDelivery REST resource.
+type WebhookDelivery struct {
+	ID          uint       `json:"id"`
+	CreateTime  time.Time  `json:"createTime"`
+	Event       uint64     `json:"event"`
+	Kind        string     `json:"kind"`
+	Action      string     `json:"action"`
+	State       string     `json:"state"`
+	Attempts    int        `json:"attempts"`
+	Status      int        `json:"status,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	NextAttempt *time.Time `json:"nextAttempt,omitempty"`
+	Delivered   *time.Time `json:"delivered,omitempty"`
+}
+
+//
+// With updates the resource with the model.
+func (r *WebhookDelivery) With(m *model.WebhookDelivery) {
+	r.ID = m.ID
+	r.CreateTime = m.CreateTime
+	r.Event = m.Event
+	r.Kind = m.Kind
+	r.Action = m.Action
+	r.State = m.State
+	r.Attempts = m.Attempts
+	r.Status = m.Status
+	r.Error = m.Error
+	r.NextAttempt = m.NextAttempt
+	r.Delivered = m.Delivered
+}
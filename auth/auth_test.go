@@ -141,6 +141,37 @@ func TestRequestHubPermit(t *testing.T) {
 	}
 }
 
+func TestRequestNoAuthPermit(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	Settings.Auth.Token.Key = "TestKey"
+	Hub = &NoAuth{}
+	Remote = &NoAuth{}
+	defer func() {
+		Hub = &NoAuth{}
+	}()
+	//
+	// Addon (task) token.
+	signed, err := Hub.NewToken("addon:test", AddonRole, jwt.MapClaims{"task": 4})
+	g.Expect(err).To(gomega.BeNil())
+	request := Request{
+		Token:  "Bearer " + signed,
+		Scope:  "things",
+		Method: "GET",
+	}
+	result, err := request.Permit()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(result.Authorized).To(gomega.BeTrue())
+	g.Expect(result.Task).To(gomega.Equal(uint(4)))
+	//
+	// No token.
+	request.Token = ""
+	result, err = request.Permit()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(result.Authorized).To(gomega.BeTrue())
+	g.Expect(result.User).To(gomega.Equal("admin.noauth"))
+	g.Expect(result.Task).To(gomega.BeZero())
+}
+
 func TestRequestRemotePermit(t *testing.T) {
 	g := gomega.NewGomegaWithT(t)
 	Settings.Auth.Token.Key = "TestKey"
@@ -28,13 +28,28 @@ type NoAuth struct {
 
 //
 // NewToken creates a new signed token.
+// Tokens are issued (builtin) so the claims (task) referenced
+// by addon tokens are known when auth is not required.
 func (r NoAuth) NewToken(user string, scopes []string, claims jwt.MapClaims) (signed string, err error) {
+	builtin := Builtin{}
+	signed, err = builtin.NewToken(user, scopes, claims)
 	return
 }
 
 //
-// Authenticate the token
-func (r *NoAuth) Authenticate(_ *Request) (jwToken *jwt.Token, err error) {
+// Authenticate the token.
+// Always permitted. The token (when provided) is parsed to
+// resolve the claims.
+func (r *NoAuth) Authenticate(request *Request) (jwToken *jwt.Token, err error) {
+	token := strings.Replace(request.Token, "Bearer", "", 1)
+	if len(strings.Fields(token)) == 0 {
+		return
+	}
+	builtin := Builtin{}
+	parsed, pErr := builtin.Authenticate(request)
+	if pErr == nil {
+		jwToken = parsed
+	}
 	return
 }
 
@@ -115,9 +115,18 @@ func (r *Keycloak) Authenticate(request *Request) (jwToken *jwt.Token, err error
 
 //
 // Scopes decodes a list of scopes from the token.
+// The scopes for users managed by the hub are granted by
+// the roles bound in the registry so that changes are
+// enforced before the realm has been reconciled and tokens
+// have been refreshed.
 func (r *Keycloak) Scopes(jwToken *jwt.Token) (scopes []Scope) {
 	claims := jwToken.Claims.(*jwt.MapClaims)
-	for _, s := range strings.Fields((*claims)["scope"].(string)) {
+	granted := strings.Fields((*claims)["scope"].(string))
+	bound, found := RBAC.Bound(r.User(jwToken))
+	if found {
+		granted = RBAC.Scopes(bound...)
+	}
+	for _, s := range granted {
 		scope := BaseScope{}
 		scope.With(s)
 		scopes = append(scopes, &scope)
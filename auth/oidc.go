@@ -0,0 +1,507 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/golang-jwt/jwt/v4"
+	liberr "github.com/jortel/go-utils/error"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+//
+// JWKS (re)fetch rate limit.
+const (
+	JWKSMinRefresh = time.Second * 10
+)
+
+//
+// NewOIDC builds a new (generic) OIDC auth provider.
+// The registry is used to map role names to scopes.
+func NewOIDC(registry *Registry) (p *OIDC) {
+	p = &OIDC{
+		Issuer:       Settings.Auth.OIDC.Issuer,
+		ClientID:     Settings.Auth.OIDC.ClientID,
+		ClientSecret: Settings.Auth.OIDC.ClientSecret,
+		Audience:     Settings.Auth.OIDC.Audience,
+		UserClaim:    Settings.Auth.OIDC.UserClaim,
+		RoleClaim:    Settings.Auth.OIDC.RoleClaim,
+		RoleMap:      ParseRoleMap(Settings.Auth.OIDC.RoleMap),
+		ScopeClaim:   Settings.Auth.OIDC.ScopeClaim,
+		KeyTTL:       time.Minute * time.Duration(Settings.Auth.OIDC.KeyTTL),
+		Registry:     registry,
+	}
+	return
+}
+
+//
+// OIDC auth provider.
+// Tokens are validated using keys fetched from the JWKS
+// endpoint advertised by the issuer (discovery). The keys are
+// cached and refreshed when the TTL has expired or a token is
+// signed by an unknown key (rotation). Scopes are granted by
+// mapping the values of the role claim to roles. Roles bound
+// to the user in the registry are also granted.
+type OIDC struct {
+	// Issuer URL.
+	Issuer string
+	// ClientID used for login and refresh.
+	ClientID string
+	// ClientSecret used for login and refresh.
+	ClientSecret string
+	// Audience (aud) required when specified.
+	Audience string
+	// UserClaim (name) contains the username.
+	UserClaim string
+	// RoleClaim (name) contains the roles or groups.
+	// Supports (.) dotted paths. Example: realm_access.roles.
+	RoleClaim string
+	// RoleMap maps claim values to roles.
+	// Unmapped values are matched to roles by name.
+	RoleMap map[string][]string
+	// ScopeClaim enables granting the scopes (resource:verb)
+	// found in the `scope` claim. Disabled by default, only
+	// roles (mapped through the registry) are granted.
+	ScopeClaim bool
+	// KeyTTL keys cache TTL.
+	KeyTTL time.Duration
+	// Client HTTP client.
+	Client *http.Client
+	// Registry of roles and user bindings.
+	Registry *Registry
+	// discovered provider configuration.
+	discovery *Discovery
+	// keys indexed by key ID.
+	keys map[string]interface{}
+	// fetched keys timestamp.
+	fetched time.Time
+	// attempted (keys fetch) timestamp.
+	attempted time.Time
+	mutex     sync.Mutex
+}
+
+//
+// Discovery OpenID provider configuration.
+type Discovery struct {
+	Issuer        string `json:"issuer"`
+	JWKSURI       string `json:"jwks_uri"`
+	TokenEndpoint string `json:"token_endpoint"`
+}
+
+//
+// With the roles used to map role names to scopes.
+func (r *OIDC) With(roles []Role) {
+	r.Registry = &Registry{}
+	r.Registry.With(roles, nil)
+}
+
+//
+// NewToken not supported.
+func (r *OIDC) NewToken(user string, scopes []string, claims jwt.MapClaims) (signed string, err error) {
+	return
+}
+
+//
+// Login and obtain a token (password grant).
+func (r *OIDC) Login(user, password string) (token Token, err error) {
+	token, err = r.grant(
+		url.Values{
+			"grant_type": {"password"},
+			"username":   {user},
+			"password":   {password},
+			"scope":      {"openid"},
+		})
+	return
+}
+
+//
+// Refresh token.
+func (r *OIDC) Refresh(refresh string) (token Token, err error) {
+	token, err = r.grant(
+		url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {refresh},
+		})
+	return
+}
+
+//
+// Authenticate the token.
+func (r *OIDC) Authenticate(request *Request) (jwToken *jwt.Token, err error) {
+	token := strings.TrimSpace(strings.TrimPrefix(request.Token, "Bearer"))
+	parser := jwt.Parser{
+		ValidMethods: []string{
+			"RS256", "RS384", "RS512",
+			"PS256", "PS384", "PS512",
+			"ES256", "ES384", "ES512",
+		},
+	}
+	jwToken, err = parser.ParseWithClaims(token, &jwt.MapClaims{}, r.keyFunc)
+	if err != nil || !jwToken.Valid {
+		vErr := &jwt.ValidationError{}
+		if errors.As(err, &vErr) && vErr.Errors&jwt.ValidationErrorExpired != 0 {
+			err = liberr.Wrap(&NotValid{Token: token, Reason: "expired."})
+			return
+		}
+		err = liberr.Wrap(&NotAuthenticated{Token: token})
+		return
+	}
+	claims := jwToken.Claims.(*jwt.MapClaims)
+	if !claims.VerifyIssuer(r.Issuer, true) {
+		err = liberr.Wrap(&NotAuthenticated{Token: token})
+		return
+	}
+	if r.Audience != "" && !claims.VerifyAudience(r.Audience, true) {
+		err = liberr.Wrap(&NotValid{Token: token, Reason: "audience not matched."})
+		return
+	}
+	if r.User(jwToken) == "" {
+		err = liberr.Wrap(&NotValid{Token: token, Reason: "user claim not found."})
+		return
+	}
+	return
+}
+
+//
+// Scopes granted by the roles found in the role claim and
+// the roles bound to the user in the registry.
+// Scopes (resource:verb) found in the `scope` claim
+// are also granted when enabled (ScopeClaim).
+func (r *OIDC) Scopes(jwToken *jwt.Token) (scopes []Scope) {
+	claims := jwToken.Claims.(*jwt.MapClaims)
+	granted := make(map[string]bool)
+	var names []string
+	add := func(s string) {
+		if granted[s] {
+			return
+		}
+		granted[s] = true
+		names = append(names, s)
+	}
+	roles := r.roles(claims)
+	bound, _ := r.Registry.Bound(r.User(jwToken))
+	roles = append(roles, bound...)
+	for _, s := range r.Registry.Scopes(roles...) {
+		add(s)
+	}
+	if s, cast := (*claims)["scope"].(string); cast && r.ScopeClaim {
+		for _, s := range strings.Fields(s) {
+			if strings.Contains(s, ":") {
+				add(s)
+			}
+		}
+	}
+	for _, s := range names {
+		scope := BaseScope{}
+		scope.With(s)
+		scopes = append(scopes, &scope)
+	}
+	return
+}
+
+//
+// User resolves the user claim.
+// Defaults to the subject (sub).
+func (r *OIDC) User(jwToken *jwt.Token) (user string) {
+	claims, _ := jwToken.Claims.(*jwt.MapClaims)
+	if r.UserClaim != "" {
+		user, _ = (*claims)[r.UserClaim].(string)
+	}
+	if user == "" {
+		user, _ = (*claims)["sub"].(string)
+	}
+	return
+}
+
+//
+// roles returns the roles mapped from the role claim.
+func (r *OIDC) roles(claims *jwt.MapClaims) (roles []string) {
+	var v interface{} = map[string]interface{}(*claims)
+	for _, part := range strings.Split(r.RoleClaim, ".") {
+		d, cast := v.(map[string]interface{})
+		if !cast {
+			return
+		}
+		v = d[part]
+	}
+	var values []string
+	switch list := v.(type) {
+	case string:
+		values = strings.Fields(list)
+	case []interface{}:
+		for _, item := range list {
+			if s, cast := item.(string); cast {
+				values = append(values, s)
+			}
+		}
+	}
+	for _, value := range values {
+		mapped, found := r.RoleMap[value]
+		if found {
+			roles = append(roles, mapped...)
+		} else {
+			roles = append(roles, value)
+		}
+	}
+	return
+}
+
+//
+// keyFunc returns the key used to verify the token.
+// Unknown keys trigger a (rate limited) refetch of the JWKS.
+func (r *OIDC) keyFunc(jwToken *jwt.Token) (key interface{}, err error) {
+	kid, _ := jwToken.Header["kid"].(string)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	expired := r.KeyTTL > 0 && time.Since(r.fetched) > r.KeyTTL
+	key, found := r.keys[kid]
+	if found && !expired {
+		return
+	}
+	if time.Since(r.attempted) < JWKSMinRefresh {
+		if !found {
+			err = liberr.New("key not found.", "kid", kid)
+		}
+		return
+	}
+	fErr := r.fetchKeys()
+	if fErr != nil {
+		if found {
+			Log.Error(fErr, "JWKS refresh failed; cached key used.")
+			return
+		}
+		err = fErr
+		return
+	}
+	key, found = r.keys[kid]
+	if !found {
+		err = liberr.New("key not found.", "kid", kid)
+		return
+	}
+	return
+}
+
+//
+// discover fetches the provider configuration.
+func (r *OIDC) discover() (d *Discovery, err error) {
+	if r.discovery != nil {
+		d = r.discovery
+		return
+	}
+	d = &Discovery{}
+	u := strings.TrimSuffix(r.Issuer, "/") + "/.well-known/openid-configuration"
+	err = r.get(u, d)
+	if err != nil {
+		return
+	}
+	if d.Issuer != r.Issuer {
+		err = liberr.New(
+			"issuer not matched.",
+			"expected",
+			r.Issuer,
+			"found",
+			d.Issuer)
+		return
+	}
+	r.discovery = d
+	return
+}
+
+//
+// fetchKeys fetches the JWKS.
+// The mutex must be held.
+func (r *OIDC) fetchKeys() (err error) {
+	r.attempted = time.Now()
+	d, err := r.discover()
+	if err != nil {
+		return
+	}
+	jwks := struct {
+		Keys []JWK `json:"keys"`
+	}{}
+	err = r.get(d.JWKSURI, &jwks)
+	if err != nil {
+		return
+	}
+	keys := make(map[string]interface{})
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, kErr := jwk.Key()
+		if kErr != nil {
+			Log.Error(kErr, "JWK ignored.", "kid", jwk.ID)
+			continue
+		}
+		keys[jwk.ID] = key
+	}
+	r.keys = keys
+	r.fetched = time.Now()
+	Log.Info("JWKS fetched.", "url", d.JWKSURI, "keys", len(keys))
+	return
+}
+
+//
+// grant requests a token from the token endpoint.
+func (r *OIDC) grant(form url.Values) (token Token, err error) {
+	r.mutex.Lock()
+	d, err := r.discover()
+	r.mutex.Unlock()
+	if err != nil {
+		return
+	}
+	form.Set("client_id", r.ClientID)
+	if r.ClientSecret != "" {
+		form.Set("client_secret", r.ClientSecret)
+	}
+	response, err := r.client().PostForm(d.TokenEndpoint, form)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode != http.StatusOK {
+		err = liberr.New(
+			"token request failed.",
+			"status",
+			response.StatusCode)
+		return
+	}
+	body := struct {
+		Access  string `json:"access_token"`
+		Refresh string `json:"refresh_token"`
+		Expiry  int    `json:"expires_in"`
+	}{}
+	err = json.NewDecoder(response.Body).Decode(&body)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	token.Access = body.Access
+	token.Refresh = body.Refresh
+	token.Expiry = body.Expiry
+	return
+}
+
+//
+// get fetches and decodes the json document.
+func (r *OIDC) get(u string, object interface{}) (err error) {
+	response, err := r.client().Get(u)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode != http.StatusOK {
+		err = liberr.New(
+			"request failed.",
+			"url",
+			u,
+			"status",
+			response.StatusCode)
+		return
+	}
+	err = json.NewDecoder(response.Body).Decode(object)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// client returns the HTTP client.
+func (r *OIDC) client() (client *http.Client) {
+	client = r.Client
+	if client == nil {
+		client = &http.Client{Timeout: time.Second * 30}
+	}
+	return
+}
+
+//
+// JWK json web key.
+type JWK struct {
+	ID    string `json:"kid"`
+	Type  string `json:"kty"`
+	Use   string `json:"use"`
+	N     string `json:"n"`
+	E     string `json:"e"`
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+}
+
+//
+// Key returns the public key.
+// Supported: RSA and EC (P-256, P-384, P-521).
+func (r *JWK) Key() (key interface{}, err error) {
+	decode := func(s string) (n *big.Int) {
+		b, dErr := base64.RawURLEncoding.DecodeString(s)
+		if dErr != nil {
+			err = liberr.Wrap(dErr)
+			return
+		}
+		n = new(big.Int).SetBytes(b)
+		return
+	}
+	switch r.Type {
+	case "RSA":
+		n := decode(r.N)
+		e := decode(r.E)
+		if err != nil {
+			return
+		}
+		key = &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		var curve elliptic.Curve
+		switch r.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			err = liberr.New("curve not supported.", "crv", r.Curve)
+			return
+		}
+		x := decode(r.X)
+		y := decode(r.Y)
+		if err != nil {
+			return
+		}
+		key = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	default:
+		err = liberr.New("key type not supported.", "kty", r.Type)
+	}
+	return
+}
+
+//
+// ParseRoleMap parses the role map.
+// Format: value=role[,value=role]
+// Example: admins=tackle-admin,devs=tackle-migrator
+func ParseRoleMap(s string) (m map[string][]string) {
+	m = make(map[string][]string)
+	for _, entry := range strings.Split(s, ",") {
+		part := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(part) != 2 || part[0] == "" || part[1] == "" {
+			continue
+		}
+		m[part[0]] = append(m[part[0]], part[1])
+	}
+	return
+}
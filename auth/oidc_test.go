@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/onsi/gomega"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+//
+// fakeIdP is a fake OIDC identity provider.
+type fakeIdP struct {
+	*httptest.Server
+	mutex   sync.Mutex
+	keys    map[string]interface{}
+	fetched int
+}
+
+func newFakeIdP() (idp *fakeIdP) {
+	idp = &fakeIdP{keys: make(map[string]interface{})}
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		"/.well-known/openid-configuration",
+		func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(
+				Discovery{
+					Issuer:        idp.URL,
+					JWKSURI:       idp.URL + "/jwks",
+					TokenEndpoint: idp.URL + "/token",
+				})
+		})
+	mux.HandleFunc(
+		"/jwks",
+		func(w http.ResponseWriter, r *http.Request) {
+			idp.mutex.Lock()
+			defer idp.mutex.Unlock()
+			idp.fetched++
+			var keys []JWK
+			for kid, key := range idp.keys {
+				keys = append(keys, idp.jwk(kid, key))
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+		})
+	mux.HandleFunc(
+		"/token",
+		func(w http.ResponseWriter, r *http.Request) {
+			_ = r.ParseForm()
+			switch r.Form.Get("grant_type") {
+			case "password":
+				if r.Form.Get("password") != "secret" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+			case "refresh_token":
+				if r.Form.Get("refresh_token") != "R1" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+			}
+			_ = json.NewEncoder(w).Encode(
+				map[string]interface{}{
+					"access_token":  "A1",
+					"refresh_token": "R1",
+					"expires_in":    300,
+				})
+		})
+	idp.Server = httptest.NewServer(mux)
+	return
+}
+
+func (idp *fakeIdP) jwk(kid string, key interface{}) (k JWK) {
+	encode := func(n *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(n.Bytes())
+	}
+	k.ID = kid
+	k.Use = "sig"
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		k.Type = "RSA"
+		k.N = encode(key.N)
+		k.E = encode(big.NewInt(int64(key.E)))
+	case *ecdsa.PrivateKey:
+		k.Type = "EC"
+		k.Curve = "P-256"
+		k.X = encode(key.X)
+		k.Y = encode(key.Y)
+	}
+	return
+}
+
+func (idp *fakeIdP) setKey(kid string, key interface{}) {
+	idp.mutex.Lock()
+	defer idp.mutex.Unlock()
+	idp.keys = map[string]interface{}{kid: key}
+}
+
+func (idp *fakeIdP) sign(kid string, key interface{}, claims jwt.MapClaims) (signed string) {
+	var method jwt.SigningMethod = jwt.SigningMethodRS256
+	if _, isEC := key.(*ecdsa.PrivateKey); isEC {
+		method = jwt.SigningMethodES256
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, _ = token.SignedString(key)
+	return
+}
+
+func (idp *fakeIdP) claims(user string, groups ...string) (claims jwt.MapClaims) {
+	claims = jwt.MapClaims{
+		"iss":                idp.URL,
+		"aud":                "hub",
+		"sub":                "1234",
+		"exp":                time.Now().Add(time.Minute).Unix(),
+		"preferred_username": user,
+		"groups":             groups,
+	}
+	return
+}
+
+func TestOIDC(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	idp := newFakeIdP()
+	defer idp.Close()
+	key1, _ := rsa.GenerateKey(rand.Reader, 2048)
+	idp.setKey("k1", key1)
+	p := &OIDC{
+		Issuer:    idp.URL,
+		ClientID:  "hub",
+		Audience:  "hub",
+		UserClaim: "preferred_username",
+		RoleClaim: "groups",
+		RoleMap:   ParseRoleMap("admins=tackle-admin, devs=tackle-migrator"),
+	}
+	p.With([]Role{
+		{
+			Name: "tackle-admin",
+			Resources: []Resource{
+				{Name: "applications", Verbs: []string{"get", "post"}},
+			},
+		},
+		{
+			Name: "tackle-migrator",
+			Resources: []Resource{
+				{Name: "applications", Verbs: []string{"get"}},
+			},
+		},
+	})
+	//
+	// Valid.
+	signed := idp.sign("k1", key1, idp.claims("jeff", "admins", "devs"))
+	jwToken, err := p.Authenticate(&Request{Token: "Bearer " + signed})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(p.User(jwToken)).To(gomega.Equal("jeff"))
+	g.Expect(p.Scopes(jwToken)).To(
+		gomega.Equal([]Scope{
+			&BaseScope{Resource: "applications", Method: "get"},
+			&BaseScope{Resource: "applications", Method: "post"},
+		}))
+	g.Expect(idp.fetched).To(gomega.Equal(1))
+	//
+	// Scope claim granted only when enabled.
+	claims := idp.claims("jeff", "devs")
+	claims["scope"] = "openid applications:delete"
+	jwToken, err = p.Authenticate(&Request{Token: idp.sign("k1", key1, claims)})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(p.Scopes(jwToken)).To(
+		gomega.Equal([]Scope{
+			&BaseScope{Resource: "applications", Method: "get"},
+		}))
+	p.ScopeClaim = true
+	g.Expect(p.Scopes(jwToken)).To(
+		gomega.Equal([]Scope{
+			&BaseScope{Resource: "applications", Method: "get"},
+			&BaseScope{Resource: "applications", Method: "delete"},
+		}))
+	p.ScopeClaim = false
+	//
+	// Cached.
+	_, err = p.Authenticate(&Request{Token: signed})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(idp.fetched).To(gomega.Equal(1))
+	//
+	// Expired.
+	claims = idp.claims("jeff")
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+	_, err = p.Authenticate(&Request{Token: idp.sign("k1", key1, claims)})
+	g.Expect(errors.Is(err, &NotValid{})).To(gomega.BeTrue())
+	//
+	// Wrong audience.
+	claims = idp.claims("jeff")
+	claims["aud"] = "other"
+	_, err = p.Authenticate(&Request{Token: idp.sign("k1", key1, claims)})
+	g.Expect(errors.Is(err, &NotValid{})).To(gomega.BeTrue())
+	//
+	// Wrong issuer.
+	claims = idp.claims("jeff")
+	claims["iss"] = "https://other"
+	_, err = p.Authenticate(&Request{Token: idp.sign("k1", key1, claims)})
+	g.Expect(errors.Is(err, &NotAuthenticated{})).To(gomega.BeTrue())
+	//
+	// HMAC (hub) token not authenticated.
+	builtin := Builtin{}
+	signed, _ = builtin.NewToken("addon", []string{"a:b"}, nil)
+	_, err = p.Authenticate(&Request{Token: signed})
+	g.Expect(errors.Is(err, &NotAuthenticated{})).To(gomega.BeTrue())
+	//
+	// Rotated (rate limited).
+	key2, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	idp.setKey("k2", key2)
+	signed = idp.sign("k2", key2, idp.claims("jeff", "devs"))
+	_, err = p.Authenticate(&Request{Token: signed})
+	g.Expect(errors.Is(err, &NotAuthenticated{})).To(gomega.BeTrue())
+	g.Expect(idp.fetched).To(gomega.Equal(1))
+	p.attempted = time.Time{}
+	jwToken, err = p.Authenticate(&Request{Token: signed})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(idp.fetched).To(gomega.Equal(2))
+	g.Expect(p.Scopes(jwToken)).To(
+		gomega.Equal([]Scope{
+			&BaseScope{Resource: "applications", Method: "get"},
+		}))
+	//
+	// Login & refresh.
+	token, err := p.Login("jeff", "secret")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(token).To(gomega.Equal(Token{Access: "A1", Refresh: "R1", Expiry: 300}))
+	_, err = p.Login("jeff", "wrong")
+	g.Expect(err).ToNot(gomega.BeNil())
+	token, err = p.Refresh("R1")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(token.Access).To(gomega.Equal("A1"))
+}
+
+func TestOIDCRoleClaim(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	p := &OIDC{RoleClaim: "realm_access.roles"}
+	claims := &jwt.MapClaims{
+		"realm_access": map[string]interface{}{
+			"roles": []interface{}{"tackle-admin", "x"},
+		},
+	}
+	g.Expect(p.roles(claims)).To(gomega.Equal([]string{"tackle-admin", "x"}))
+	p.RoleClaim = "roles"
+	claims = &jwt.MapClaims{"roles": "a b"}
+	g.Expect(p.roles(claims)).To(gomega.Equal([]string{"a", "b"}))
+}
@@ -15,11 +15,14 @@ var (
 	Hub Provider
 	// Remote provider.
 	Remote Provider
+	// Tokens (hub-managed API tokens) provider.
+	Tokens Provider
 )
 
 func init() {
 	Hub = &NoAuth{}
 	Remote = &NoAuth{}
+	Tokens = &TokenProvider{}
 }
 
 //
@@ -0,0 +1,279 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"sync"
+)
+
+//
+// RBAC registry of roles and user bindings managed in the DB.
+var RBAC = &Registry{}
+
+//
+// RealmReconciler reconciles roles and users with the provider.
+type RealmReconciler interface {
+	Reconcile() (err error)
+}
+
+//
+// Registry of roles and user (role) bindings.
+// Loaded from the DB and reloaded when roles and users
+// are changed at runtime.
+type Registry struct {
+	// Realm reconciler (optional).
+	// Called (async) after the registry is reloaded.
+	Realm RealmReconciler
+	// roles indexed by name.
+	roles map[string]Role
+	// users indexed by name.
+	users map[string]User
+	// passwords (initial) by user.
+	passwords map[string]string
+	mutex     sync.RWMutex
+	syncing   sync.Mutex
+}
+
+//
+// With the roles and users.
+func (r *Registry) With(roles []Role, users []User) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.roles = make(map[string]Role)
+	for _, role := range roles {
+		r.roles[role.Name] = role
+	}
+	r.users = make(map[string]User)
+	for _, user := range users {
+		r.users[user.Name] = user
+	}
+}
+
+//
+// Load the roles and users from the DB.
+func (r *Registry) Load(db *gorm.DB) (err error) {
+	var roleList []model.Role
+	err = db.Find(&roleList).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	var userList []model.User
+	err = db.Preload(clause.Associations).Find(&userList).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	var roles []Role
+	for i := range roleList {
+		m := &roleList[i]
+		role := Role{Name: m.Name}
+		_ = json.Unmarshal(m.Resources, &role.Resources)
+		roles = append(roles, role)
+	}
+	var users []User
+	for i := range userList {
+		m := &userList[i]
+		user := User{Name: m.Name}
+		for _, role := range m.Roles {
+			user.Roles = append(user.Roles, role.Name)
+		}
+		users = append(users, user)
+	}
+	r.With(roles, users)
+	return
+}
+
+//
+// Reload the registry and reconcile the realm (async).
+func (r *Registry) Reload(db *gorm.DB) (err error) {
+	err = r.Load(db)
+	if err != nil {
+		return
+	}
+	if r.Realm == nil {
+		return
+	}
+	go func() {
+		r.syncing.Lock()
+		defer r.syncing.Unlock()
+		err := r.Realm.Reconcile()
+		if err != nil {
+			Log.Error(err, "Realm reconcile failed.")
+		}
+	}()
+	return
+}
+
+//
+// Seed the DB with the (builtin) roles and users.
+// Builtin roles are created or updated to match the roles file
+// and builtin roles no longer defined are deleted. Users are
+// created when not found. The registry is loaded and the
+// (initial) user passwords are retained for the reconciler.
+func (r *Registry) Seed(db *gorm.DB, roles []Role, users []User) (err error) {
+	var names []string
+	for _, role := range roles {
+		names = append(names, role.Name)
+		resources, _ := json.Marshal(role.Resources)
+		m := &model.Role{}
+		err = db.First(m, "Name", role.Name).Error
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				err = liberr.Wrap(err)
+				return
+			}
+			m.Name = role.Name
+			m.Builtin = true
+			m.Resources = resources
+			err = db.Create(m).Error
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+			Log.Info("Role created.", "role", role.Name)
+			continue
+		}
+		m.Builtin = true
+		m.Resources = resources
+		err = db.Save(m).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+	}
+	db = db.Where("Builtin = ?", true)
+	if len(names) > 0 {
+		db = db.Where("Name NOT IN ?", names)
+	}
+	err = db.Delete(&model.Role{}).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	db = db.Session(&gorm.Session{NewDB: true})
+	for _, user := range users {
+		m := &model.User{}
+		err = db.First(m, "Name", user.Name).Error
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			err = liberr.Wrap(err)
+			return
+		}
+		m.Name = user.Name
+		if len(user.Roles) > 0 {
+			err = db.Find(&m.Roles, "Name IN ?", user.Roles).Error
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+		}
+		err = db.Create(m).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		Log.Info("User created.", "user", user.Name)
+	}
+	err = r.Load(db)
+	if err != nil {
+		return
+	}
+	for _, user := range users {
+		r.Password(user.Name, user.Password)
+	}
+	return
+}
+
+//
+// Password sets the (initial) password for the user.
+// Used by the reconciler when the user is created in
+// the provider.
+func (r *Registry) Password(user, password string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.passwords == nil {
+		r.passwords = make(map[string]string)
+	}
+	if password != "" {
+		r.passwords[user] = password
+	} else {
+		delete(r.passwords, user)
+	}
+}
+
+//
+// Roles returns the roles sorted by name.
+func (r *Registry) Roles() (roles []Role) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	for _, role := range r.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+	return
+}
+
+//
+// Users returns the users sorted by name.
+// The (initial) password is included.
+func (r *Registry) Users() (users []User) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	for _, user := range r.users {
+		user.Password = r.passwords[user.Name]
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Name < users[j].Name
+	})
+	return
+}
+
+//
+// Bound returns the roles bound to the user.
+// Found is false when the user is not managed by the hub.
+func (r *Registry) Bound(user string) (roles []string, found bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	u, found := r.users[user]
+	if found {
+		roles = u.Roles
+	}
+	return
+}
+
+//
+// Scopes granted by the roles.
+// Format: <resource>:<verb>.
+func (r *Registry) Scopes(roles ...string) (scopes []string) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	granted := make(map[string]bool)
+	for _, name := range roles {
+		role, found := r.roles[name]
+		if !found {
+			continue
+		}
+		for _, resource := range role.Resources {
+			for _, verb := range resource.Verbs {
+				s := resource.Name + ":" + verb
+				if granted[s] {
+					continue
+				}
+				granted[s] = true
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	return
+}
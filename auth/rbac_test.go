@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"path"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db, err := gorm.Open(
+		sqlite.Open(path.Join(t.TempDir(), "test.db")),
+		&gorm.Config{
+			NamingStrategy: &schema.NamingStrategy{
+				SingularTable: true,
+				NoLowerCase:   true,
+			},
+		})
+	g.Expect(err).To(gomega.BeNil())
+	err = db.AutoMigrate(&model.Role{}, &model.User{})
+	g.Expect(err).To(gomega.BeNil())
+	roles := []Role{
+		{
+			Name: "tackle-admin",
+			Resources: []Resource{
+				{Name: "applications", Verbs: []string{"get", "post"}},
+			},
+		},
+		{
+			Name: "tackle-migrator",
+			Resources: []Resource{
+				{Name: "applications", Verbs: []string{"get"}},
+			},
+		},
+	}
+	users := []User{
+		{Name: "admin", Password: "Passw0rd!", Roles: []string{"tackle-admin"}},
+	}
+	//
+	// Seed.
+	registry := &Registry{}
+	err = registry.Seed(db, roles, users)
+	g.Expect(err).To(gomega.BeNil())
+	bound, found := registry.Bound("admin")
+	g.Expect(found).To(gomega.BeTrue())
+	g.Expect(bound).To(gomega.Equal([]string{"tackle-admin"}))
+	g.Expect(registry.Scopes(bound...)).To(
+		gomega.Equal([]string{"applications:get", "applications:post"}))
+	g.Expect(registry.Users()[0].Password).To(gomega.Equal("Passw0rd!"))
+	_, found = registry.Bound("jeff")
+	g.Expect(found).To(gomega.BeFalse())
+	//
+	// Custom role and user binding.
+	role := &model.Role{
+		Name:      "auditor",
+		Resources: []byte(`[{"name":"auditlog","verbs":["get"]}]`),
+	}
+	err = db.Create(role).Error
+	g.Expect(err).To(gomega.BeNil())
+	err = db.Create(&model.User{Name: "jeff", Roles: []model.Role{*role}}).Error
+	g.Expect(err).To(gomega.BeNil())
+	err = registry.Reload(db)
+	g.Expect(err).To(gomega.BeNil())
+	bound, found = registry.Bound("jeff")
+	g.Expect(found).To(gomega.BeTrue())
+	g.Expect(registry.Scopes(bound...)).To(gomega.Equal([]string{"auditlog:get"}))
+	//
+	// Reseed: builtin roles updated and removed.
+	roles[0].Resources[0].Verbs = []string{"*"}
+	err = registry.Seed(db, roles[:1], nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(registry.Scopes("tackle-admin")).To(gomega.Equal([]string{"applications:*"}))
+	g.Expect(registry.Scopes("tackle-migrator")).To(gomega.BeEmpty())
+	g.Expect(registry.Scopes("auditor")).To(gomega.Equal([]string{"auditlog:get"}))
+	//
+	// OIDC grants the roles bound to the user.
+	p := &OIDC{UserClaim: "preferred_username", RoleClaim: "groups", Registry: registry}
+	jwToken := &jwt.Token{
+		Claims: &jwt.MapClaims{
+			"preferred_username": "jeff",
+			"groups":             []interface{}{"tackle-admin"},
+		},
+	}
+	g.Expect(p.Scopes(jwToken)).To(
+		gomega.Equal([]Scope{
+			&BaseScope{Resource: "applications", Method: "*"},
+			&BaseScope{Resource: "auditlog", Method: "get"},
+		}))
+}
@@ -23,12 +23,16 @@ func NewReconciler(host, realm, id, secret, admin, pass, adminRealm string) (r R
 		admin:      admin,
 		pass:       pass,
 		adminRealm: adminRealm,
+		registry:   RBAC,
+		roles:      make(map[string]bool),
+		users:      make(map[string]bool),
 	}
 	return
 }
 
 //
-// Keycloak realm reconciler
+// Keycloak realm reconciler.
+// Roles and users are reconciled with the registry.
 type Reconciler struct {
 	client     gocloak.GoCloak
 	realm      string
@@ -38,6 +42,11 @@ type Reconciler struct {
 	pass       string
 	adminRealm string
 	token      *gocloak.JWT
+	registry   *Registry
+	// roles (names) reconciled.
+	roles map[string]bool
+	// users (names) reconciled.
+	users map[string]bool
 }
 
 //
@@ -85,6 +94,11 @@ func (r *Reconciler) Reconcile() (err error) {
 		return
 	}
 
+	err = r.deleteRoles(realm)
+	if err != nil {
+		return
+	}
+
 	Log.Info("Realm synced.")
 
 	return
@@ -171,10 +185,7 @@ func (r *Reconciler) ensureClient() (err error) {
 //
 // ensureUsers ensures that the hub users exist and have the necessary roles.
 func (r *Reconciler) ensureUsers(realm *Realm) (err error) {
-	users, err := LoadUsers(Settings.Auth.UserPath)
-	if err != nil {
-		return
-	}
+	users := r.registry.Users()
 
 	var allRoles []gocloak.Role
 	for _, role := range realm.Roles {
@@ -198,17 +209,19 @@ func (r *Reconciler) ensureUsers(realm *Realm) (err error) {
 				return
 			}
 			u.ID = &userid
-			err = r.client.SetPassword(
-				context.Background(),
-				r.token.AccessToken,
-				userid,
-				r.realm,
-				user.Password,
-				Settings.Keycloak.RequirePasswordUpdate,
-			)
-			if err != nil {
-				err = liberr.Wrap(err)
-				return
+			if user.Password != "" {
+				err = r.client.SetPassword(
+					context.Background(),
+					r.token.AccessToken,
+					userid,
+					r.realm,
+					user.Password,
+					Settings.Keycloak.RequirePasswordUpdate,
+				)
+				if err != nil {
+					err = liberr.Wrap(err)
+					return
+				}
 			}
 			realm.Users[user.Name] = u
 		} else {
@@ -238,19 +251,92 @@ func (r *Reconciler) ensureUsers(realm *Realm) (err error) {
 			err = liberr.Wrap(err)
 			return
 		}
+		r.registry.Password(user.Name, "")
+	}
+
+	// remove the roles from users no longer managed by the hub.
+	wanted := make(map[string]bool)
+	for _, user := range users {
+		wanted[user.Name] = true
+	}
+	for name := range r.users {
+		if wanted[name] {
+			continue
+		}
+		u, found := realm.Users[name]
+		if found {
+			Log.Info("Removing roles from user.", "user", name)
+			err = r.client.DeleteRealmRoleFromUser(
+				context.Background(), r.token.AccessToken, r.realm, *u.ID, r.hubRoles(realm),
+			)
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+		}
+		delete(r.users, name)
+	}
+	for name := range wanted {
+		r.users[name] = true
 	}
 
 	return
 }
 
+//
+// deleteRoles deletes the realm roles no longer managed by the hub.
+func (r *Reconciler) deleteRoles(realm *Realm) (err error) {
+	wanted := make(map[string]bool)
+	for _, role := range r.registry.Roles() {
+		wanted[role.Name] = true
+	}
+	for name := range r.roles {
+		if wanted[name] {
+			continue
+		}
+		if _, found := realm.Roles[name]; found {
+			Log.Info("Deleting realm role.", "role", name, "realm", r.realm)
+			err = r.client.DeleteRealmRole(
+				context.Background(), r.token.AccessToken, r.realm, name,
+			)
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+			delete(realm.Roles, name)
+		}
+		delete(r.roles, name)
+	}
+	for name := range wanted {
+		r.roles[name] = true
+	}
+	return
+}
+
+//
+// hubRoles returns the realm roles managed by the hub.
+func (r *Reconciler) hubRoles(realm *Realm) (roles []gocloak.Role) {
+	for name := range r.roles {
+		if role, found := realm.Roles[name]; found {
+			roles = append(roles, role)
+		}
+	}
+	for _, role := range r.registry.Roles() {
+		if r.roles[role.Name] {
+			continue
+		}
+		if realmRole, found := realm.Roles[role.Name]; found {
+			roles = append(roles, realmRole)
+		}
+	}
+	return
+}
+
 //
 // ensureRoles ensures that hub roles and scopes are present in keycloak by
 // creating them if they are missing and assigning scope mappings.
 func (r *Reconciler) ensureRoles(realm *Realm) (err error) {
-	hubRoles, err := LoadRoles(Settings.Auth.RolePath)
-	if err != nil {
-		return
-	}
+	hubRoles := r.registry.Roles()
 
 	// create missing roles and scopes, and build mapping of scopes to roles
 	scopesToRoles := make(map[string][]gocloak.Role)
@@ -348,6 +434,44 @@ func (r *Reconciler) ensureRoles(realm *Realm) (err error) {
 		}
 	}
 
+	// remove hub roles from scopes no longer granted.
+	managed := r.hubRoles(realm)
+	for name, scope := range realm.Scopes {
+		if !strings.Contains(name, ":") {
+			continue
+		}
+		if _, found := scopesToRoles[*scope.ID]; found {
+			continue
+		}
+		var existingRoles []*gocloak.Role
+		existingRoles, err = r.client.GetClientScopesScopeMappingsRealmRoles(
+			context.Background(), r.token.AccessToken, r.realm, *scope.ID,
+		)
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		var deleteRoles []gocloak.Role
+		for _, existing := range existingRoles {
+			for _, role := range managed {
+				if *existing.Name == *role.Name {
+					deleteRoles = append(deleteRoles, *existing)
+				}
+			}
+		}
+		if len(deleteRoles) == 0 {
+			continue
+		}
+		Log.Info("Removing scope mappings.", "scope", name, "roles", deleteRoles)
+		err = r.client.DeleteClientScopesScopeMappingsRealmRoles(
+			context.Background(), r.token.AccessToken, r.realm, *scope.ID, deleteRoles,
+		)
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+	}
+
 	return
 }
 
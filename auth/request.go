@@ -22,7 +22,7 @@ func (r *Request) Permit() (result Result, err error) {
 		jwToken *jwt.Token
 		p       Provider
 	)
-	for _, p = range []Provider{Hub, Remote} {
+	for _, p = range []Provider{Tokens, Hub, Remote} {
 		var pErr error
 		jwToken, pErr = p.Authenticate(r)
 		if pErr == nil {
@@ -48,6 +48,8 @@ func (r *Request) Permit() (result Result, err error) {
 			if scope.Match(r.Scope, r.Method) {
 				result.Scopes = scopes
 				result.User = p.User(jwToken)
+				result.Task = r.task(p, jwToken)
+				result.Token = r.token(p, jwToken)
 				result.Authorized = true
 				break
 			}
@@ -56,10 +58,46 @@ func (r *Request) Permit() (result Result, err error) {
 	return
 }
 
+//
+// task returns the task referenced by an addon token.
+// Only hub (builtin) issued tokens reference tasks.
+func (r *Request) task(p Provider, jwToken *jwt.Token) (id uint) {
+	if p != Hub || jwToken == nil {
+		return
+	}
+	claims, cast := jwToken.Claims.(jwt.MapClaims)
+	if !cast {
+		return
+	}
+	n, cast := claims["task"].(float64)
+	if cast {
+		id = uint(n)
+	}
+	return
+}
+
+//
+// token returns the ID of the (hub-managed) API token.
+func (r *Request) token(p Provider, jwToken *jwt.Token) (id uint) {
+	if p != Tokens || jwToken == nil {
+		return
+	}
+	claims, cast := jwToken.Claims.(jwt.MapClaims)
+	if !cast {
+		return
+	}
+	id, _ = claims["token"].(uint)
+	return
+}
+
 // Result - auth result.
 type Result struct {
 	Authenticated bool
 	Authorized    bool
 	User          string
 	Scopes        []Scope
+	// Task referenced by an addon token.
+	Task uint
+	// Token (hub-managed API token) ID.
+	Token uint
 }
@@ -15,6 +15,7 @@ var Settings = &settings.Settings
 var AddonRole = []string{
 	"applications:get",
 	"applications:put",
+	"applications.all:*",
 	"applications.tags:*",
 	"applications.facts:*",
 	"applications.bucket:*",
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/golang-jwt/jwt/v4"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/model"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+//
+// TokenPrefix identifies hub-managed API tokens.
+const TokenPrefix = "hub_"
+
+//
+// LastUsedInterval is the minimum interval between
+// updates of the token last-used time.
+const LastUsedInterval = time.Minute
+
+//
+// NewAPIToken generates a new API token.
+// Returns the token, the digest to be stored and the
+// prefix used to identify the token in listings.
+func NewAPIToken() (token, digest, prefix string, err error) {
+	b := make([]byte, 32)
+	_, err = rand.Read(b)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	token = TokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	digest = TokenDigest(token)
+	prefix = token[:len(TokenPrefix)+6]
+	return
+}
+
+//
+// TokenDigest returns the (sha256) digest of the token.
+func TokenDigest(token string) (d string) {
+	h := sha256.Sum256([]byte(token))
+	d = hex.EncodeToString(h[:])
+	return
+}
+
+//
+// UnmanagedExpiration returns the (capped) expiration of a personal
+// token issued to a user not managed by the hub. The scopes granted to
+// these users by the (keycloak|oidc) provider are not known when the
+// token is used, so the lifespan is capped instead.
+// Returns nil when not capped.
+func UnmanagedExpiration(created time.Time) (expiration *time.Time) {
+	lifespan := Settings.Auth.APIToken.Unmanaged
+	if lifespan > 0 {
+		t := created.Add(time.Duration(lifespan) * 24 * time.Hour)
+		expiration = &t
+	}
+	return
+}
+
+//
+// ScopeCovered returns true when the scope is matched
+// by one of the granted scopes.
+func ScopeCovered(granted []Scope, s string) (b bool) {
+	in := BaseScope{}
+	in.With(s)
+	for _, scope := range granted {
+		if scope.Match(in.Resource, in.Method) {
+			b = true
+			break
+		}
+	}
+	return
+}
+
+//
+// TokenProvider authenticates hub-managed API tokens.
+// Tokens are opaque and looked up (by digest) in the DB.
+type TokenProvider struct {
+	Builtin
+}
+
+//
+// NewToken is not supported.
+func (r *TokenProvider) NewToken(user string, scopes []string, claims jwt.MapClaims) (signed string, err error) {
+	return
+}
+
+//
+// Authenticate the token.
+// Tokens without the prefix are not authenticated and are
+// passed to the next provider. The token scopes are limited
+// to the scopes currently granted to the service account or
+// to the roles bound to the (hub managed) user. Personal tokens
+// of unmanaged users expire at the (capped) unmanaged lifespan.
+func (r *TokenProvider) Authenticate(request *Request) (jwToken *jwt.Token, err error) {
+	token := strings.Replace(request.Token, "Bearer", "", 1)
+	fields := strings.Fields(token)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], TokenPrefix) || request.DB == nil {
+		err = liberr.Wrap(&NotAuthenticated{})
+		return
+	}
+	token = fields[0]
+	prefix := token
+	if len(prefix) > len(TokenPrefix)+6 {
+		prefix = prefix[:len(TokenPrefix)+6]
+	}
+	defer func() {
+		if err != nil {
+			Log.Info(err.Error())
+		}
+	}()
+	m := &model.APIToken{}
+	db := request.DB.Preload("ServiceAccount")
+	err = db.First(m, "Digest", TokenDigest(token)).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = liberr.Wrap(
+				&NotValid{
+					Reason: "Token not found.",
+					Token:  prefix,
+				})
+		} else {
+			err = liberr.Wrap(err)
+		}
+		return
+	}
+	now := time.Now()
+	if m.Expiration != nil && now.After(*m.Expiration) {
+		err = liberr.Wrap(
+			&NotValid{
+				Reason: "Token expired.",
+				Token:  prefix,
+			})
+		return
+	}
+	var scopes []string
+	_ = json.Unmarshal(m.Scopes, &scopes)
+	user := m.User
+	if m.ServiceAccount != nil {
+		var granted []string
+		_ = json.Unmarshal(m.ServiceAccount.Scopes, &granted)
+		scopes = r.intersect(scopes, granted)
+		user = m.ServiceAccount.Name
+	} else {
+		bound, found := RBAC.Bound(user)
+		if found {
+			scopes = r.intersect(scopes, RBAC.Scopes(bound...))
+		} else {
+			expiration := UnmanagedExpiration(m.CreateTime)
+			if expiration != nil && now.After(*expiration) {
+				err = liberr.Wrap(
+					&NotValid{
+						Reason: "Token expired (unmanaged user).",
+						Token:  prefix,
+					})
+				return
+			}
+		}
+	}
+	if m.LastUsed == nil || now.Sub(*m.LastUsed) > LastUsedInterval {
+		err = request.DB.Model(m).UpdateColumn("LastUsed", now).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+	}
+	jwToken = &jwt.Token{
+		Raw:   prefix,
+		Valid: true,
+		Claims: jwt.MapClaims{
+			"user":  user,
+			"scope": strings.Join(scopes, " "),
+			"token": m.ID,
+		},
+	}
+	return
+}
+
+//
+// intersect returns the scopes covered by the granted scopes.
+func (r *TokenProvider) intersect(scopes, granted []string) (covered []string) {
+	var grantedScopes []Scope
+	for _, s := range granted {
+		scope := &BaseScope{}
+		scope.With(s)
+		grantedScopes = append(grantedScopes, scope)
+	}
+	for _, s := range scopes {
+		if ScopeCovered(grantedScopes, s) {
+			covered = append(covered, s)
+		}
+	}
+	return
+}
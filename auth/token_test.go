@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"errors"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenProvider(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db, err := gorm.Open(
+		sqlite.Open(path.Join(t.TempDir(), "test.db")),
+		&gorm.Config{
+			NamingStrategy: &schema.NamingStrategy{
+				SingularTable: true,
+				NoLowerCase:   true,
+			},
+		})
+	g.Expect(err).To(gomega.BeNil())
+	err = db.AutoMigrate(&model.ServiceAccount{}, &model.APIToken{})
+	g.Expect(err).To(gomega.BeNil())
+	p := &TokenProvider{}
+	//
+	// Personal.
+	token, digest, prefix, err := NewAPIToken()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(strings.HasPrefix(token, TokenPrefix)).To(gomega.BeTrue())
+	g.Expect(strings.HasPrefix(token, prefix)).To(gomega.BeTrue())
+	m := &model.APIToken{
+		Name:   "ci",
+		User:   "jeff",
+		Scopes: []byte(`["applications:get","tasks:*"]`),
+		Digest: digest,
+		Prefix: prefix,
+	}
+	err = db.Create(m).Error
+	g.Expect(err).To(gomega.BeNil())
+	jwToken, err := p.Authenticate(&Request{Token: "Bearer " + token, DB: db})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(p.User(jwToken)).To(gomega.Equal("jeff"))
+	g.Expect(jwToken.Claims.(jwt.MapClaims)["token"]).To(gomega.Equal(m.ID))
+	g.Expect(p.Scopes(jwToken)).To(
+		gomega.Equal([]Scope{
+			&BaseScope{Resource: "applications", Method: "get"},
+			&BaseScope{Resource: "tasks", Method: "*"},
+		}))
+	err = db.First(m, m.ID).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(m.LastUsed).ToNot(gomega.BeNil())
+	//
+	// Limited to the roles currently bound (managed user).
+	saved := RBAC
+	defer func() {
+		RBAC = saved
+	}()
+	RBAC = &Registry{}
+	RBAC.With(
+		[]Role{
+			{
+				Name: "viewer",
+				Resources: []Resource{
+					{Name: "applications", Verbs: []string{"get"}},
+					{Name: "tasks", Verbs: []string{"get"}},
+				},
+			},
+		},
+		[]User{{Name: "jeff", Roles: []string{"viewer"}}})
+	jwToken, err = p.Authenticate(&Request{Token: token, DB: db})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(p.Scopes(jwToken)).To(
+		gomega.Equal([]Scope{
+			&BaseScope{Resource: "applications", Method: "get"},
+		}))
+	RBAC.With(nil, []User{{Name: "jeff"}})
+	jwToken, err = p.Authenticate(&Request{Token: token, DB: db})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(p.Scopes(jwToken)).To(gomega.BeEmpty())
+	RBAC = saved
+	//
+	// Unmanaged user (lifespan capped).
+	Settings.Auth.APIToken.Unmanaged = 7
+	defer func() {
+		Settings.Auth.APIToken.Unmanaged = 0
+	}()
+	_, err = p.Authenticate(&Request{Token: token, DB: db})
+	g.Expect(err).To(gomega.BeNil())
+	created := time.Now().Add(-8 * 24 * time.Hour)
+	err = db.Exec("UPDATE APIToken SET CreateTime = ? WHERE ID = ?", created, m.ID).Error
+	g.Expect(err).To(gomega.BeNil())
+	_, err = p.Authenticate(&Request{Token: token, DB: db})
+	g.Expect(errors.Is(err, &NotValid{})).To(gomega.BeTrue())
+	Settings.Auth.APIToken.Unmanaged = 0
+	//
+	// Not a hub token.
+	_, err = p.Authenticate(&Request{Token: "Bearer abc", DB: db})
+	g.Expect(errors.Is(err, &NotAuthenticated{})).To(gomega.BeTrue())
+	//
+	// Unknown (revoked).
+	_, err = p.Authenticate(&Request{Token: TokenPrefix + "unknown", DB: db})
+	g.Expect(errors.Is(err, &NotValid{})).To(gomega.BeTrue())
+	//
+	// Expired.
+	expiration := time.Now().Add(-time.Minute)
+	err = db.Model(m).Update("Expiration", expiration).Error
+	g.Expect(err).To(gomega.BeNil())
+	_, err = p.Authenticate(&Request{Token: token, DB: db})
+	g.Expect(errors.Is(err, &NotValid{})).To(gomega.BeTrue())
+	//
+	// Service account.
+	account := &model.ServiceAccount{
+		Name:   "pipeline",
+		Scopes: []byte(`["applications:*"]`),
+	}
+	err = db.Create(account).Error
+	g.Expect(err).To(gomega.BeNil())
+	token, digest, prefix, err = NewAPIToken()
+	g.Expect(err).To(gomega.BeNil())
+	m = &model.APIToken{
+		Name:             "deploy",
+		ServiceAccountID: &account.ID,
+		Scopes:           []byte(`["applications:get","tasks:get"]`),
+		Digest:           digest,
+		Prefix:           prefix,
+	}
+	err = db.Create(m).Error
+	g.Expect(err).To(gomega.BeNil())
+	jwToken, err = p.Authenticate(&Request{Token: token, DB: db})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(p.User(jwToken)).To(gomega.Equal("pipeline"))
+	g.Expect(p.Scopes(jwToken)).To(
+		gomega.Equal([]Scope{
+			&BaseScope{Resource: "applications", Method: "get"},
+		}))
+}
+
+func TestScopeCovered(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	granted := []Scope{
+		&BaseScope{Resource: "applications", Method: "*"},
+		&BaseScope{Resource: "tasks", Method: "get"},
+	}
+	g.Expect(ScopeCovered(granted, "applications:get")).To(gomega.BeTrue())
+	g.Expect(ScopeCovered(granted, "applications:*")).To(gomega.BeTrue())
+	g.Expect(ScopeCovered(granted, "tasks:get")).To(gomega.BeTrue())
+	g.Expect(ScopeCovered(granted, "tasks:*")).To(gomega.BeFalse())
+	g.Expect(ScopeCovered(granted, "*:get")).To(gomega.BeFalse())
+}
@@ -0,0 +1,135 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/konveyor/tackle2-hub/database"
+	"github.com/konveyor/tackle2-hub/migration"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/storage"
+	"github.com/konveyor/tackle2-hub/tar"
+	"github.com/onsi/gomega"
+	"io"
+	"os"
+	pathlib "path"
+	"strings"
+	"testing"
+)
+
+func TestBackup(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	savedDB := Settings.DB.Path
+	savedBucket := Settings.Bucket.Path
+	Settings.DB.Path = pathlib.Join(t.TempDir(), "hub.db")
+	Settings.Bucket.Path = t.TempDir()
+	defer func() {
+		Settings.DB.Path = savedDB
+		Settings.Bucket.Path = savedBucket
+	}()
+	latest := len(migration.All()) + migration.MinimumVersion
+	db, err := database.Open(true)
+	g.Expect(err).To(gomega.BeNil())
+	setVersion := func(version int) {
+		b, _ := json.Marshal(migration.Version{Version: version})
+		setting := &model.Setting{Key: migration.VersionKey, Value: b}
+		err := db.Where("Key", setting.Key).Delete(&model.Setting{}).Error
+		g.Expect(err).To(gomega.BeNil())
+		err = db.Create(setting).Error
+		g.Expect(err).To(gomega.BeNil())
+	}
+	setVersion(latest)
+	content := map[string]string{
+		"1/a.txt":       "a",
+		"1/sub/b.txt":   "b",
+		".file/digest1": "file",
+	}
+	for p, s := range content {
+		err = storage.Store.Put(
+			pathlib.Join(Settings.Bucket.Path, p),
+			strings.NewReader(s),
+			int64(len(s)))
+		g.Expect(err).To(gomega.BeNil())
+	}
+	//
+	// Backup.
+	archive := &bytes.Buffer{}
+	manifest, err := Backup(db, archive, tar.Zstd)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(manifest.Version).To(gomega.Equal(latest))
+	g.Expect(len(manifest.Entries)).To(gomega.Equal(4))
+	g.Expect(manifest.Entries[0].Path).To(gomega.Equal(DatabaseName))
+	b := archive.Bytes()
+	//
+	// Change the hub.
+	setVersion(latest - 1)
+	err = database.Close(db)
+	g.Expect(err).To(gomega.BeNil())
+	err = storage.Store.Delete(pathlib.Join(Settings.Bucket.Path, "1"))
+	g.Expect(err).To(gomega.BeNil())
+	err = storage.Store.Put(
+		pathlib.Join(Settings.Bucket.Path, "2/c.txt"),
+		strings.NewReader("c"),
+		1)
+	g.Expect(err).To(gomega.BeNil())
+	//
+	// Content not restored (database not replaced).
+	bucketPath := Settings.Bucket.Path
+	blocked := pathlib.Join(t.TempDir(), "blocked")
+	err = os.WriteFile(blocked, []byte("x"), 0644)
+	g.Expect(err).To(gomega.BeNil())
+	Settings.Bucket.Path = pathlib.Join(blocked, "bucket")
+	_, err = Restore(bytes.NewReader(b))
+	g.Expect(err).ToNot(gomega.BeNil())
+	Settings.Bucket.Path = bucketPath
+	db, err = database.Open(true)
+	g.Expect(err).To(gomega.BeNil())
+	v, err := version(db)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(v).To(gomega.Equal(latest - 1))
+	err = database.Close(db)
+	g.Expect(err).To(gomega.BeNil())
+	//
+	// Restore.
+	restored, err := Restore(bytes.NewReader(b))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(restored.Version).To(gomega.Equal(latest))
+	for p, s := range content {
+		f, err := os.ReadFile(pathlib.Join(Settings.Bucket.Path, p))
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(string(f)).To(gomega.Equal(s))
+	}
+	_, err = os.Stat(pathlib.Join(Settings.Bucket.Path, "2"))
+	g.Expect(os.IsNotExist(err)).To(gomega.BeTrue())
+	db, err = database.Open(true)
+	g.Expect(err).To(gomega.BeNil())
+	v, err = version(db)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(v).To(gomega.Equal(latest))
+	err = database.Close(db)
+	g.Expect(err).To(gomega.BeNil())
+	//
+	// Corrupted.
+	corrupted := &bytes.Buffer{}
+	writer := tar.NewWriter(corrupted)
+	err = tar.NewReader().Walk(
+		bytes.NewReader(b),
+		func(path string, size int64, r io.Reader) (err error) {
+			content, _ := io.ReadAll(r)
+			if path == "/bucket/1/a.txt" {
+				content = []byte("x")
+			}
+			err = writer.AddStream(
+				path,
+				size,
+				func(w io.Writer) (err error) {
+					_, err = w.Write(content)
+					return
+				})
+			return
+		})
+	g.Expect(err).To(gomega.BeNil())
+	writer.Close()
+	_, err = Restore(corrupted)
+	g.Expect(errors.Is(err, &Invalid{})).To(gomega.BeTrue())
+}
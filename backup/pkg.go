@@ -0,0 +1,467 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/jortel/go-utils/logr"
+	"github.com/konveyor/tackle2-hub/migration"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/settings"
+	"github.com/konveyor/tackle2-hub/storage"
+	"github.com/konveyor/tackle2-hub/tar"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"io"
+	"os"
+	pathlib "path"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	Settings = &settings.Settings
+	Log      = logr.WithName("backup")
+)
+
+//
+// Archive layout.
+const (
+	ManifestName = "/manifest.json"
+	DatabaseName = "/hub.db"
+	ContentRoot  = "/bucket"
+)
+
+//
+// Invalid reports an invalid archive.
+type Invalid struct {
+	Reason string
+}
+
+func (e *Invalid) Error() string {
+	return "Archive invalid: " + e.Reason
+}
+
+func (e *Invalid) Is(err error) (matched bool) {
+	_, matched = err.(*Invalid)
+	return
+}
+
+//
+// Entry is an archived file.
+type Entry struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Digest string `json:"digest"`
+}
+
+//
+// Manifest describes the archive.
+type Manifest struct {
+	// Version of the (migrated) database.
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Entries []Entry   `json:"entries"`
+}
+
+//
+// Backup writes an archive of the database and the stored
+// (bucket and file) content to the output.
+// The database is copied online using VACUUM INTO. Content
+// is streamed after the database has been copied; content
+// deleted in the interim is omitted.
+func Backup(db *gorm.DB, output io.Writer, compression string) (manifest Manifest, err error) {
+	manifest.Created = time.Now()
+	manifest.Version, err = version(db)
+	if err != nil {
+		return
+	}
+	dir, err := os.MkdirTemp(pathlib.Dir(Settings.DB.Path), ".backup-")
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbPath := pathlib.Join(dir, pathlib.Base(DatabaseName))
+	err = db.Exec("VACUUM INTO ?", dbPath).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	writer := tar.NewWriterWith(output, compression)
+	defer func() {
+		writer.Close()
+	}()
+	entry, err := add(writer, DatabaseName, dbPath)
+	if err != nil {
+		return
+	}
+	manifest.Entries = append(manifest.Entries, entry)
+	root := pathlib.Clean(Settings.Bucket.Path)
+	list, err := storage.Store.List(root)
+	if err != nil {
+		if !errors.Is(err, &storage.NotFound{}) {
+			return
+		}
+		err = nil
+	}
+	sort.Slice(
+		list,
+		func(i, j int) bool {
+			return list[i].Path < list[j].Path
+		})
+	for _, info := range list {
+		rPath := strings.TrimPrefix(info.Path, root)
+		entry, err = stream(writer, pathlib.Join(ContentRoot, rPath), info)
+		if err != nil {
+			if errors.Is(err, &storage.NotFound{}) {
+				Log.Info("Content deleted (omitted).", "path", info.Path)
+				err = nil
+				continue
+			}
+			return
+		}
+		manifest.Entries = append(manifest.Entries, entry)
+	}
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	err = writer.AddStream(
+		ManifestName,
+		int64(len(b)),
+		func(w io.Writer) (err error) {
+			_, err = w.Write(b)
+			return
+		})
+	if err != nil {
+		return
+	}
+	Log.Info(
+		"Backup created.",
+		"version",
+		manifest.Version,
+		"entries",
+		len(manifest.Entries))
+	return
+}
+
+//
+// Restore the database and stored content from the archive.
+// The archive is staged and validated (checksums and migration
+// version) before it is applied. The hub must not be running.
+func Restore(input io.Reader) (manifest Manifest, err error) {
+	dir, err := os.MkdirTemp(pathlib.Dir(Settings.DB.Path), ".restore-")
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	staged, err := stage(input, dir)
+	if err != nil {
+		return
+	}
+	manifest, err = validate(dir, staged)
+	if err != nil {
+		return
+	}
+	err = apply(dir, manifest)
+	if err != nil {
+		return
+	}
+	Log.Info(
+		"Backup restored.",
+		"version",
+		manifest.Version,
+		"created",
+		manifest.Created,
+		"entries",
+		len(manifest.Entries))
+	return
+}
+
+//
+// stage extracts the archive into the directory.
+// Returns the staged entries keyed by path.
+func stage(input io.Reader, dir string) (staged map[string]Entry, err error) {
+	staged = make(map[string]Entry)
+	reader := tar.NewReader()
+	err = reader.Walk(
+		input,
+		func(path string, size int64, r io.Reader) (err error) {
+			entry := Entry{Path: path}
+			stagedPath := pathlib.Join(dir, path)
+			err = os.MkdirAll(pathlib.Dir(stagedPath), 0755)
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+			f, err := os.Create(stagedPath)
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+			defer func() {
+				_ = f.Close()
+			}()
+			h := sha256.New()
+			entry.Size, err = io.Copy(io.MultiWriter(f, h), r)
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+			entry.Digest = hex.EncodeToString(h.Sum(nil))
+			staged[path] = entry
+			return
+		})
+	return
+}
+
+//
+// validate the staged archive.
+// Every entry listed in the manifest must be staged with a matching
+// size and digest. The version must be supported by the migrations.
+func validate(dir string, staged map[string]Entry) (manifest Manifest, err error) {
+	b, err := os.ReadFile(pathlib.Join(dir, ManifestName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = &Invalid{Reason: "manifest not found."}
+			return
+		}
+		err = liberr.Wrap(err)
+		return
+	}
+	err = json.Unmarshal(b, &manifest)
+	if err != nil {
+		err = &Invalid{Reason: "manifest: " + err.Error()}
+		return
+	}
+	delete(staged, ManifestName)
+	for _, entry := range manifest.Entries {
+		found, matched := staged[entry.Path]
+		if !matched {
+			err = &Invalid{Reason: entry.Path + " not found."}
+			return
+		}
+		if found.Size != entry.Size || found.Digest != entry.Digest {
+			err = &Invalid{Reason: entry.Path + " checksum not matched."}
+			return
+		}
+		delete(staged, entry.Path)
+	}
+	for path := range staged {
+		err = &Invalid{Reason: path + " not in manifest."}
+		return
+	}
+	latest := len(migration.All()) + migration.MinimumVersion
+	if manifest.Version < migration.MinimumVersion || manifest.Version > latest {
+		err = &Invalid{
+			Reason: fmt.Sprintf(
+				"version %d not supported (%d-%d).",
+				manifest.Version,
+				migration.MinimumVersion,
+				latest),
+		}
+		return
+	}
+	db, err := gorm.Open(
+		sqlite.Open(pathlib.Join(dir, DatabaseName)),
+		&gorm.Config{
+			NamingStrategy: &schema.NamingStrategy{
+				SingularTable: true,
+				NoLowerCase:   true,
+			},
+		})
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		sqlDB, nErr := db.DB()
+		if nErr == nil {
+			_ = sqlDB.Close()
+		}
+	}()
+	v, err := version(db)
+	if err != nil {
+		return
+	}
+	if v != manifest.Version {
+		err = &Invalid{
+			Reason: fmt.Sprintf(
+				"database version %d not matched.",
+				v),
+		}
+		return
+	}
+	return
+}
+
+//
+// apply the staged archive.
+// The stored content is replaced with the archived content
+// first. The database is replaced last (renamed) so that it
+// is not changed unless the content has been restored.
+func apply(dir string, manifest Manifest) (err error) {
+	root := pathlib.Clean(Settings.Bucket.Path)
+	err = purge(root)
+	if err != nil {
+		return
+	}
+	for _, entry := range manifest.Entries {
+		if !strings.HasPrefix(entry.Path, ContentRoot+"/") {
+			continue
+		}
+		err = put(
+			pathlib.Join(dir, entry.Path),
+			pathlib.Join(root, strings.TrimPrefix(entry.Path, ContentRoot)),
+			entry.Size)
+		if err != nil {
+			return
+		}
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		err = os.Remove(Settings.DB.Path + suffix)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				err = liberr.Wrap(err)
+				return
+			}
+			err = nil
+		}
+	}
+	err = os.Rename(pathlib.Join(dir, DatabaseName), Settings.DB.Path)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// purge deletes the stored content within the directory.
+// The directory (may be a mount point) is not deleted.
+func purge(root string) (err error) {
+	list, err := storage.Store.List(root)
+	if err != nil {
+		if errors.Is(err, &storage.NotFound{}) {
+			err = storage.Store.MkDir(root)
+		}
+		return
+	}
+	deleted := make(map[string]bool)
+	for _, info := range list {
+		rPath := strings.TrimPrefix(info.Path, root+"/")
+		part := pathlib.Join(root, strings.SplitN(rPath, "/", 2)[0])
+		if deleted[part] {
+			continue
+		}
+		err = storage.Store.Delete(part)
+		if err != nil {
+			return
+		}
+		deleted[part] = true
+	}
+	return
+}
+
+//
+// version returns the migration version of the database.
+func version(db *gorm.DB) (n int, err error) {
+	setting := &model.Setting{}
+	err = db.First(setting, "Key", migration.VersionKey).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	v := migration.Version{}
+	err = json.Unmarshal(setting.Value, &v)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	n = v.Version
+	return
+}
+
+//
+// add a (local) file to the archive.
+func add(writer *tar.Writer, destPath, path string) (entry Entry, err error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	entry, err = write(writer, destPath, st.Size(), f)
+	return
+}
+
+//
+// stream stored content to the archive.
+func stream(writer *tar.Writer, destPath string, info storage.Info) (entry Entry, err error) {
+	reader, err := storage.Store.Get(info.Path)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	entry, err = write(writer, destPath, info.Size, reader)
+	return
+}
+
+//
+// write content to the archive.
+// The size and digest are recorded in the entry.
+func write(writer *tar.Writer, destPath string, size int64, reader io.Reader) (entry Entry, err error) {
+	h := sha256.New()
+	err = writer.AddStream(
+		destPath,
+		size,
+		func(w io.Writer) (err error) {
+			_, err = io.CopyN(io.MultiWriter(w, h), reader, size)
+			return
+		})
+	if err != nil {
+		return
+	}
+	entry = Entry{
+		Path:   destPath,
+		Size:   size,
+		Digest: hex.EncodeToString(h.Sum(nil)),
+	}
+	return
+}
+
+//
+// put stores a staged file.
+func put(path, destPath string, size int64) (err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	err = storage.Store.Put(destPath, f, size)
+	return
+}
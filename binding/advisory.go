@@ -0,0 +1,35 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/api"
+)
+
+//
+// Advisory API.
+type Advisory struct {
+	client *Client
+}
+
+//
+// Get an Advisory by ID.
+func (h *Advisory) Get(id uint) (r *api.Advisory, err error) {
+	r = &api.Advisory{}
+	path := Path(api.AdvisoryRoot).Inject(Params{api.ID: id})
+	err = h.client.Get(path, r)
+	return
+}
+
+//
+// List Advisories.
+func (h *Advisory) List() (list []api.Advisory, err error) {
+	list = []api.Advisory{}
+	err = h.client.Get(api.AdvisoriesRoot, &list)
+	return
+}
+
+//
+// Refresh requests the feed be reloaded and dependencies re-matched.
+func (h *Advisory) Refresh() (err error) {
+	err = h.client.Post(api.AdvisoriesRefreshRoot, &struct{}{})
+	return
+}
@@ -72,6 +72,20 @@ func (h *Application) Bucket(id uint) (b *BucketContent) {
 	return
 }
 
+//
+// Snapshot returns the bucket snapshot API.
+func (h *Application) Snapshot(id uint) (s *Snapshot) {
+	s = &Snapshot{
+		client:  h.client,
+		root:    api.AppSnapshotsRoot,
+		entry:   api.AppSnapshotRoot,
+		restore: api.AppSnapshotRestore,
+		diff:    api.AppSnapshotDiff,
+		id:      id,
+	}
+	return
+}
+
 //
 // FindIdentity by kind.
 func (h *Application) FindIdentity(id uint, kind string) (r *api.Identity, found bool, err error) {
@@ -354,3 +368,22 @@ func (h *Analysis) Create(r *api.Analysis, encoding string, issues, deps io.Read
 		r)
 	return
 }
+
+//
+// ImportDeps creates an analysis with the dependencies described
+// by the SBOM. The encoding is the SBOM (CycloneDX|SPDX) MIME type.
+func (h *Analysis) ImportDeps(r *api.Analysis, encoding string, sbom io.Reader) (err error) {
+	path := Path(api.AppAnalysisDepsRoot).Inject(Params{api.ID: h.appId})
+	err = h.client.FileSend(
+		path,
+		http.MethodPost,
+		[]Field{
+			{
+				Name:     api.FileField,
+				Encoding: encoding,
+				Reader:   sbom,
+			},
+		},
+		r)
+	return
+}
@@ -0,0 +1,36 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/api"
+)
+
+//
+// AuditLog API.
+type AuditLog struct {
+	client *Client
+}
+
+//
+// Get an audit log entry by ID.
+func (h *AuditLog) Get(id uint) (r *api.AuditEntry, err error) {
+	r = &api.AuditEntry{}
+	path := Path(api.AuditEntryRoot).Inject(Params{api.ID: id})
+	err = h.client.Get(path, r)
+	return
+}
+
+//
+// List audit log entries.
+func (h *AuditLog) List() (list []api.AuditEntry, err error) {
+	list = []api.AuditEntry{}
+	err = h.client.Get(api.AuditLogRoot, &list)
+	return
+}
+
+//
+// Find audit log entries by filter.
+func (h *AuditLog) Find(filter Filter) (list []api.AuditEntry, err error) {
+	list = []api.AuditEntry{}
+	err = h.client.Get(api.AuditLogRoot, &list, filter.Param())
+	return
+}
@@ -0,0 +1,19 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/api"
+)
+
+//
+// Backup API.
+type Backup struct {
+	client *Client
+}
+
+//
+// Get a backup archive.
+// The archive (tarball) is written to the destination.
+func (h *Backup) Get(destination string) (err error) {
+	err = h.client.FileGet(api.BackupRoot, destination)
+	return
+}
@@ -1,9 +1,16 @@
 package binding
 
 import (
-	pathlib "path"
-
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	liberr "github.com/jortel/go-utils/error"
 	"github.com/konveyor/tackle2-hub/api"
+	"io"
+	"io/fs"
+	"os"
+	pathlib "path"
+	"path/filepath"
 )
 
 //
@@ -88,3 +95,145 @@ func (h *BucketContent) Delete(path string) (err error) {
 	err = h.client.Delete(pathlib.Join(h.root, path))
 	return
 }
+
+//
+// Manifest returns the manifest of the directory.
+// The path is relative to the bucket root. The (trailing) slash
+// ensures the bucket root content is addressed.
+func (h *BucketContent) Manifest(path string) (list []api.BucketEntry, err error) {
+	list = []api.BucketEntry{}
+	err = h.client.Get(
+		pathlib.Join(h.root, path)+"/",
+		&list,
+		Param{Key: api.Manifest, Value: "true"})
+	return
+}
+
+//
+// Pull reads changed files from the bucket.
+// Only files that differ from the bucket manifest are received
+// and files not found in the bucket are deleted.
+// The source (root) is relative to the bucket root.
+func (h *BucketContent) Pull(source, destination string) (err error) {
+	remote, err := h.Manifest(source)
+	if err != nil {
+		return
+	}
+	local, err := h.manifest(destination)
+	if err != nil {
+		return
+	}
+	for _, entry := range remote {
+		digest, found := local[entry.Path]
+		delete(local, entry.Path)
+		if found && digest == entry.Digest {
+			continue
+		}
+		output := pathlib.Join(destination, entry.Path)
+		err = os.MkdirAll(pathlib.Dir(output), 0777)
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		err = h.Get(pathlib.Join(source, entry.Path), output)
+		if err != nil {
+			return
+		}
+	}
+	for path := range local {
+		err = os.Remove(pathlib.Join(destination, path))
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+	}
+	return
+}
+
+//
+// Push writes changed files to the bucket.
+// Only files that differ from the bucket manifest are sent
+// and files not found in the source are deleted.
+// The destination (root) is relative to the bucket root.
+func (h *BucketContent) Push(source, destination string) (err error) {
+	local, err := h.manifest(source)
+	if err != nil {
+		return
+	}
+	remote, err := h.Manifest(destination)
+	if err != nil {
+		if !errors.Is(err, &NotFound{}) {
+			return
+		}
+		err = nil
+	}
+	var changed []string
+	for _, entry := range remote {
+		digest, found := local[entry.Path]
+		if !found {
+			err = h.Delete(pathlib.Join(destination, entry.Path))
+			if err != nil {
+				return
+			}
+			continue
+		}
+		delete(local, entry.Path)
+		if digest != entry.Digest {
+			changed = append(changed, entry.Path)
+		}
+	}
+	for path := range local {
+		changed = append(changed, path)
+	}
+	if len(changed) == 0 {
+		return
+	}
+	err = h.client.BucketMerge(
+		source,
+		changed,
+		pathlib.Join(h.root, destination)+"/")
+	return
+}
+
+//
+// manifest returns the digests of files in the local
+// directory keyed by (relative) path.
+func (h *BucketContent) manifest(root string) (digests map[string]string, err error) {
+	digests = make(map[string]string)
+	err = filepath.WalkDir(
+		root,
+		func(path string, d fs.DirEntry, nErr error) (err error) {
+			if nErr != nil {
+				if os.IsNotExist(nErr) {
+					return
+				}
+				err = liberr.Wrap(nErr)
+				return
+			}
+			if !d.Type().IsRegular() {
+				return
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+			defer func() {
+				_ = file.Close()
+			}()
+			hash := sha256.New()
+			_, err = io.Copy(hash, file)
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+			digests[filepath.ToSlash(rel)] = hex.EncodeToString(hash.Sum(nil))
+			return
+		})
+	return
+}
@@ -0,0 +1,36 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/api"
+)
+
+//
+// Bundle API.
+type Bundle struct {
+	client *Client
+}
+
+//
+// Get an application bundle.
+// The bundle (tarball) is written to the destination.
+func (h *Bundle) Get(id uint, destination string) (err error) {
+	path := Path(api.AppBundleRoot).Inject(Params{api.ID: id})
+	err = h.client.FileGet(path, destination)
+	return
+}
+
+//
+// Find a bundle of the (filtered) applications.
+// The bundle (tarball) is written to the destination.
+func (h *Bundle) Find(filter Filter, destination string) (err error) {
+	err = h.client.FileGet(api.BundlesRoot, destination, filter.Param())
+	return
+}
+
+//
+// Import a bundle.
+func (h *Bundle) Import(source string) (r *api.BundleReport, err error) {
+	r = &api.BundleReport{}
+	err = h.client.FilePost(api.BundlesRoot, source, r)
+	return
+}
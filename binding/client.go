@@ -93,6 +93,8 @@ type Client struct {
 	token string
 	// transport
 	transport http.RoundTripper
+	// compression (upload) accepted by the hub.
+	compression string
 	// Retry limit.
 	Retry int
 	// Error
@@ -160,7 +162,7 @@ func (r *Client) Get(path string, object interface{}, params ...Param) (err erro
 
 //
 // Post a resource.
-func (r *Client) Post(path string, object interface{}) (err error) {
+func (r *Client) Post(path string, object interface{}, params ...Param) (err error) {
 	request := func() (request *http.Request, err error) {
 		bfr, err := json.Marshal(object)
 		if err != nil {
@@ -175,6 +177,13 @@ func (r *Client) Post(path string, object interface{}) (err error) {
 			URL:    r.join(path),
 		}
 		request.Header.Set(api.Accept, binding.MIMEJSON)
+		if len(params) > 0 {
+			q := request.URL.Query()
+			for _, p := range params {
+				q.Add(p.Key, p.Value)
+			}
+			request.URL.RawQuery = q.Encode()
+		}
 		return
 	}
 	response, err := r.send(request)
@@ -196,7 +205,8 @@ func (r *Client) Post(path string, object interface{}) (err error) {
 			err = liberr.Wrap(err)
 			return
 		}
-	case http.StatusNoContent:
+	case http.StatusAccepted,
+		http.StatusNoContent:
 	default:
 		err = r.restError(response)
 	}
@@ -304,6 +314,7 @@ func (r *Client) BucketGet(source, destination string) (err error) {
 			URL:    r.join(source),
 		}
 		request.Header.Set(api.Accept, api.MIMEOCTETSTREAM)
+		request.Header.Set(api.Compression, tar.Supported)
 		return
 	}
 	response, err := r.send(request)
@@ -337,6 +348,58 @@ func (r *Client) BucketPut(source, destination string) (err error) {
 	if err != nil {
 		return
 	}
+	if isDir {
+		err = r.bucketPut(
+			source,
+			destination,
+			api.DirectoryExpand,
+			func(writer io.Writer, compression string) error {
+				return r.putDir(writer, source, compression)
+			})
+	} else {
+		err = r.bucketPut(
+			source,
+			destination,
+			"",
+			func(writer io.Writer, _ string) error {
+				return r.putFile(writer, source)
+			})
+	}
+	return
+}
+
+//
+// BucketMerge uploads files within a directory.
+// The files (paths) are relative to the source directory.
+// The files are written into the destination directory and
+// other content is retained.
+// The destination (path) is relative to the bucket root.
+func (r *Client) BucketMerge(source string, paths []string, destination string) (err error) {
+	err = r.bucketPut(
+		source,
+		destination,
+		api.DirectoryMerge,
+		func(writer io.Writer, compression string) (err error) {
+			tarWriter := tar.NewWriterWith(writer, compression)
+			defer tarWriter.Close()
+			for _, path := range paths {
+				err = tarWriter.AddFile(pathlib.Join(source, path), path)
+				if err != nil {
+					return
+				}
+			}
+			return
+		})
+	return
+}
+
+//
+// bucketPut uploads content written by the function.
+// The directory specifies how the content is handled.
+// Directories (tarballs) are written using the compression
+// accepted by the hub.
+func (r *Client) bucketPut(source, destination, directory string, fn func(io.Writer, string) error) (err error) {
+	compression := r.uploadCompression()
 	request := func() (request *http.Request, err error) {
 		pr, pw := io.Pipe()
 		request = &http.Request{
@@ -348,8 +411,9 @@ func (r *Client) BucketPut(source, destination string) (err error) {
 		mp := multipart.NewWriter(pw)
 		request.Header.Set(api.Accept, api.MIMEOCTETSTREAM)
 		request.Header.Add(api.ContentType, mp.FormDataContentType())
-		if isDir {
-			request.Header.Set(api.Directory, api.DirectoryExpand)
+		if directory != "" {
+			request.Header.Set(api.Directory, directory)
+			request.Header.Set(api.Compression, compression)
 		}
 		go func() {
 			var err error
@@ -366,11 +430,7 @@ func (r *Client) BucketPut(source, destination string) (err error) {
 				err = nErr
 				return
 			}
-			if isDir {
-				err = r.putDir(part, source)
-			} else {
-				err = r.putFile(part, source)
-			}
+			err = fn(part, compression)
 		}()
 		return
 	}
@@ -392,7 +452,7 @@ func (r *Client) BucketPut(source, destination string) (err error) {
 
 //
 // FileGet downloads a file.
-func (r *Client) FileGet(path, destination string) (err error) {
+func (r *Client) FileGet(path, destination string, params ...Param) (err error) {
 	request := func() (request *http.Request, err error) {
 		request = &http.Request{
 			Header: http.Header{},
@@ -400,6 +460,13 @@ func (r *Client) FileGet(path, destination string) (err error) {
 			URL:    r.join(path),
 		}
 		request.Header.Set(api.Accept, api.MIMEOCTETSTREAM)
+		if len(params) > 0 {
+			q := request.URL.Query()
+			for _, p := range params {
+				q.Add(p.Key, p.Value)
+			}
+			request.URL.RawQuery = q.Encode()
+		}
 		return
 	}
 	response, err := r.send(request)
@@ -533,6 +600,18 @@ func (r *Client) FileSend(path, method string, fields []Field, object interface{
 	return
 }
 
+//
+// uploadCompression returns the compression used to upload
+// directories. Hubs that do not advertise the accepted
+// compression support only gzip.
+func (r *Client) uploadCompression() (compression string) {
+	compression = r.compression
+	if compression == "" {
+		compression = tar.Gzip
+	}
+	return
+}
+
 //
 // getDir downloads and expands a directory.
 func (r *Client) getDir(body io.Reader, output string) (err error) {
@@ -543,8 +622,8 @@ func (r *Client) getDir(body io.Reader, output string) (err error) {
 
 //
 // putDir archive and uploads a directory.
-func (r *Client) putDir(writer io.Writer, input string) (err error) {
-	tarWriter := tar.NewWriter(writer)
+func (r *Client) putDir(writer io.Writer, input, compression string) (err error) {
+	tarWriter := tar.NewWriterWith(writer, compression)
 	defer tarWriter.Close()
 	err = tarWriter.AddDir(input)
 	return
@@ -654,6 +733,10 @@ func (r *Client) send(rb func() (*http.Request, error)) (response *http.Response
 					response.StatusCode,
 					request.Method,
 					request.URL.Path))
+			accepted := response.Header.Get(api.AcceptCompression)
+			if accepted != "" {
+				r.compression = tar.Negotiate(accepted)
+			}
 			break
 		}
 	}
@@ -685,6 +768,9 @@ func (r *Client) buildTransport() (err error) {
 func (r *Client) join(path string) (parsedURL *url.URL) {
 	parsedURL, _ = url.Parse(r.baseURL)
 	parsedURL.Path = pathlib.Join(parsedURL.Path, path)
+	if strings.HasSuffix(path, "/") && !strings.HasSuffix(parsedURL.Path, "/") {
+		parsedURL.Path += "/"
+	}
 	return
 }
 
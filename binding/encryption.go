@@ -0,0 +1,19 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/api"
+)
+
+//
+// Encryption API.
+type Encryption struct {
+	client *Client
+}
+
+//
+// Rotate (re-encrypt) identities using the current key.
+func (h *Encryption) Rotate() (r *api.KeyRotation, err error) {
+	r = &api.KeyRotation{}
+	err = h.client.Post(api.EncryptionRotateRoot, r)
+	return
+}
@@ -1,7 +1,12 @@
 package binding
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	liberr "github.com/jortel/go-utils/error"
 	"github.com/konveyor/tackle2-hub/api"
+	"io"
+	"os"
 	pathlib "path"
 )
 
@@ -42,6 +47,54 @@ func (h *File) Put(source string) (r *api.File, err error) {
 	return
 }
 
+//
+// Find files by content (SHA-256) digest.
+func (h *File) Find(digest string) (list []api.File, err error) {
+	list = []api.File{}
+	err = h.client.Get(
+		api.FilesRoot,
+		&list,
+		Param{
+			Key:   api.Digest,
+			Value: digest,
+		})
+	return
+}
+
+//
+// Ensure uploads a file unless a file with the same name
+// and content (digest) exists. Files (orphaned) to be reaped
+// are not reused.
+func (h *File) Ensure(source string) (r *api.File, err error) {
+	f, err := os.Open(source)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	hash := sha256.New()
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	list, err := h.Find(hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return
+	}
+	name := pathlib.Base(source)
+	for i := range list {
+		if list[i].Name == name && list[i].Expiration == nil {
+			r = &list[i]
+			return
+		}
+	}
+	r, err = h.Put(source)
+	return
+}
+
 //
 // Delete a file.
 func (h *File) Delete(id uint) (err error) {
@@ -56,3 +56,31 @@ func (h *Identity) Delete(id uint) (err error) {
 	err = h.client.Delete(Path(api.IdentityRoot).Inject(Params{api.ID: id}))
 	return
 }
+
+//
+// ForceDelete a Identity referenced by other resources.
+func (h *Identity) ForceDelete(id uint) (err error) {
+	p := Param{
+		Key:   api.Force,
+		Value: "1",
+	}
+	err = h.client.Delete(Path(api.IdentityRoot).Inject(Params{api.ID: id}), p)
+	return
+}
+
+//
+// Usage lists the resources referencing an Identity.
+func (h *Identity) Usage(id uint) (r *api.IdentityUsage, err error) {
+	r = &api.IdentityUsage{}
+	path := Path(api.IdentityUsageRoot).Inject(Params{api.ID: id})
+	err = h.client.Get(path, r)
+	return
+}
+
+//
+// Validate requests validation of the Identity credentials.
+func (h *Identity) Validate(id uint) (err error) {
+	path := Path(api.IdentityValidateRoot).Inject(Params{api.ID: id})
+	err = h.client.Post(path, nil)
+	return
+}
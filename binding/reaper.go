@@ -0,0 +1,44 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/api"
+	"strconv"
+)
+
+//
+// Reaper API.
+type Reaper struct {
+	client *Client
+}
+
+//
+// Run (trigger) the reapers.
+// Nothing is changed on a dry-run.
+func (h *Reaper) Run(dryRun bool) (r *api.ReaperRun, err error) {
+	r = &api.ReaperRun{}
+	err = h.client.Post(
+		api.ReaperRoot,
+		r,
+		Param{
+			Key:   api.DryRun,
+			Value: strconv.FormatBool(dryRun),
+		})
+	return
+}
+
+//
+// Get a (recorded) run by ID.
+func (h *Reaper) Get(id uint) (r *api.ReaperRun, err error) {
+	r = &api.ReaperRun{}
+	path := Path(api.ReaperRunRoot).Inject(Params{api.ID: id})
+	err = h.client.Get(path, r)
+	return
+}
+
+//
+// List (recorded) runs.
+func (h *Reaper) List() (list []api.ReaperRun, err error) {
+	list = []api.ReaperRun{}
+	err = h.client.Get(api.ReaperRunsRoot, &list)
+	return
+}
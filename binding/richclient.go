@@ -21,18 +21,26 @@ func init() {
 // The RichClient provides API integration.
 type RichClient struct {
 	// Resources APIs.
+	Advisory         Advisory
 	Application      Application
+	AuditLog         AuditLog
+	Backup           Backup
 	Bucket           Bucket
+	Bundle           Bundle
 	BusinessService  BusinessService
 	Dependency       Dependency
+	Encryption       Encryption
 	File             File
 	Identity         Identity
 	JobFunction      JobFunction
 	MigrationWave    MigrationWave
 	Proxy            Proxy
 	Questionnaire    Questionnaire
+	Reaper           Reaper
 	Review           Review
+	Role             Role
 	RuleSet          RuleSet
+	ServiceAccount   ServiceAccount
 	Setting          Setting
 	Stakeholder      Stakeholder
 	StakeholderGroup StakeholderGroup
@@ -41,7 +49,11 @@ type RichClient struct {
 	Target           Target
 	Task             Task
 	Ticket           Ticket
+	Token            Token
 	Tracker          Tracker
+	Usage            Usage
+	User             User
+	Webhook          Webhook
 
 	// A REST client.
 	Client *Client
@@ -56,18 +68,33 @@ func New(baseUrl string) (r *RichClient) {
 	//
 	// Build RichClient.
 	r = &RichClient{
+		Advisory: Advisory{
+			client: client,
+		},
 		Application: Application{
 			client: client,
 		},
+		AuditLog: AuditLog{
+			client: client,
+		},
+		Backup: Backup{
+			client: client,
+		},
 		Bucket: Bucket{
 			client: client,
 		},
+		Bundle: Bundle{
+			client: client,
+		},
 		BusinessService: BusinessService{
 			client: client,
 		},
 		Dependency: Dependency{
 			client: client,
 		},
+		Encryption: Encryption{
+			client: client,
+		},
 		File: File{
 			client: client,
 		},
@@ -86,12 +113,21 @@ func New(baseUrl string) (r *RichClient) {
 		Questionnaire: Questionnaire{
 			client: client,
 		},
+		Reaper: Reaper{
+			client: client,
+		},
 		Review: Review{
 			client: client,
 		},
+		Role: Role{
+			client: client,
+		},
 		RuleSet: RuleSet{
 			client: client,
 		},
+		ServiceAccount: ServiceAccount{
+			client: client,
+		},
 		Setting: Setting{
 			client: client,
 		},
@@ -116,9 +152,21 @@ func New(baseUrl string) (r *RichClient) {
 		Ticket: Ticket{
 			client: client,
 		},
+		Token: Token{
+			client: client,
+		},
 		Tracker: Tracker{
 			client: client,
 		},
+		Usage: Usage{
+			client: client,
+		},
+		User: User{
+			client: client,
+		},
+		Webhook: Webhook{
+			client: client,
+		},
 		Client: client,
 	}
 
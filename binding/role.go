@@ -0,0 +1,50 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/api"
+)
+
+//
+// Role API.
+type Role struct {
+	client *Client
+}
+
+//
+// Create a Role.
+func (h *Role) Create(r *api.Role) (err error) {
+	err = h.client.Post(api.RolesRoot, &r)
+	return
+}
+
+//
+// Get a Role by ID.
+func (h *Role) Get(id uint) (r *api.Role, err error) {
+	r = &api.Role{}
+	path := Path(api.RoleRoot).Inject(Params{api.ID: id})
+	err = h.client.Get(path, r)
+	return
+}
+
+//
+// List Roles.
+func (h *Role) List() (list []api.Role, err error) {
+	list = []api.Role{}
+	err = h.client.Get(api.RolesRoot, &list)
+	return
+}
+
+//
+// Update a Role.
+func (h *Role) Update(r *api.Role) (err error) {
+	path := Path(api.RoleRoot).Inject(Params{api.ID: r.ID})
+	err = h.client.Put(path, r)
+	return
+}
+
+//
+// Delete a Role.
+func (h *Role) Delete(id uint) (err error) {
+	err = h.client.Delete(Path(api.RoleRoot).Inject(Params{api.ID: id}))
+	return
+}
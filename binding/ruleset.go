@@ -56,3 +56,21 @@ func (h *RuleSet) Delete(id uint) (err error) {
 	err = h.client.Delete(Path(api.RuleSetRoot).Inject(Params{api.ID: id}))
 	return
 }
+
+//
+// Revisions lists the revisions of a RuleSet.
+func (h *RuleSet) Revisions(id uint) (list []api.RuleSetRevision, err error) {
+	list = []api.RuleSetRevision{}
+	path := Path(api.RuleSetRevisionsRoot).Inject(Params{api.ID: id})
+	err = h.client.Get(path, &list)
+	return
+}
+
+//
+// Revision gets a RuleSet revision.
+func (h *RuleSet) Revision(id, revision uint) (r *api.RuleSetRevision, err error) {
+	r = &api.RuleSetRevision{}
+	path := Path(api.RuleSetRevisionRoot).Inject(Params{api.ID: id, api.ID2: revision})
+	err = h.client.Get(path, r)
+	return
+}
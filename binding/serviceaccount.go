@@ -0,0 +1,76 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/api"
+)
+
+//
+// ServiceAccount API.
+type ServiceAccount struct {
+	client *Client
+}
+
+//
+// Create a ServiceAccount.
+func (h *ServiceAccount) Create(r *api.ServiceAccount) (err error) {
+	err = h.client.Post(api.ServiceAccountsRoot, &r)
+	return
+}
+
+//
+// Get a ServiceAccount by ID.
+func (h *ServiceAccount) Get(id uint) (r *api.ServiceAccount, err error) {
+	r = &api.ServiceAccount{}
+	path := Path(api.ServiceAccountRoot).Inject(Params{api.ID: id})
+	err = h.client.Get(path, r)
+	return
+}
+
+//
+// List ServiceAccounts.
+func (h *ServiceAccount) List() (list []api.ServiceAccount, err error) {
+	list = []api.ServiceAccount{}
+	err = h.client.Get(api.ServiceAccountsRoot, &list)
+	return
+}
+
+//
+// Update a ServiceAccount.
+func (h *ServiceAccount) Update(r *api.ServiceAccount) (err error) {
+	path := Path(api.ServiceAccountRoot).Inject(Params{api.ID: r.ID})
+	err = h.client.Put(path, r)
+	return
+}
+
+//
+// Delete a ServiceAccount.
+func (h *ServiceAccount) Delete(id uint) (err error) {
+	err = h.client.Delete(Path(api.ServiceAccountRoot).Inject(Params{api.ID: id}))
+	return
+}
+
+//
+// Tokens lists the tokens issued to a ServiceAccount.
+func (h *ServiceAccount) Tokens(id uint) (list []api.APIToken, err error) {
+	list = []api.APIToken{}
+	path := Path(api.ServiceAccountTokensRoot).Inject(Params{api.ID: id})
+	err = h.client.Get(path, &list)
+	return
+}
+
+//
+// TokenCreate issues a token to a ServiceAccount.
+// The token (secret) is set on the returned resource.
+func (h *ServiceAccount) TokenCreate(id uint, r *api.APIToken) (err error) {
+	path := Path(api.ServiceAccountTokensRoot).Inject(Params{api.ID: id})
+	err = h.client.Post(path, &r)
+	return
+}
+
+//
+// TokenDelete revokes a token issued to a ServiceAccount.
+func (h *ServiceAccount) TokenDelete(id, id2 uint) (err error) {
+	path := Path(api.ServiceAccountTokenRoot).Inject(Params{api.ID: id, api.ID2: id2})
+	err = h.client.Delete(path)
+	return
+}
@@ -0,0 +1,87 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/api"
+	"strconv"
+)
+
+//
+// Snapshot API.
+// Bucket snapshots owned by an application or task.
+type Snapshot struct {
+	client *Client
+	// route templates.
+	root    string
+	entry   string
+	restore string
+	diff    string
+	// owner ID.
+	id uint
+}
+
+//
+// Create a snapshot of the bucket content.
+func (h *Snapshot) Create() (r *api.BucketSnapshot, err error) {
+	r = &api.BucketSnapshot{}
+	path := Path(h.root).Inject(Params{api.ID: h.id})
+	err = h.client.Post(path, r)
+	return
+}
+
+//
+// Get a snapshot by ID.
+func (h *Snapshot) Get(id uint) (r *api.BucketSnapshot, err error) {
+	r = &api.BucketSnapshot{}
+	path := Path(h.entry).Inject(Params{api.ID: h.id, api.ID2: id})
+	err = h.client.Get(path, r)
+	return
+}
+
+//
+// List snapshots.
+func (h *Snapshot) List() (list []api.BucketSnapshot, err error) {
+	list = []api.BucketSnapshot{}
+	path := Path(h.root).Inject(Params{api.ID: h.id})
+	err = h.client.Get(path, &list)
+	return
+}
+
+//
+// Delete a snapshot.
+func (h *Snapshot) Delete(id uint) (err error) {
+	path := Path(h.entry).Inject(Params{api.ID: h.id, api.ID2: id})
+	err = h.client.Delete(path)
+	return
+}
+
+//
+// Restore the bucket content from a snapshot.
+func (h *Snapshot) Restore(id uint) (err error) {
+	path := Path(h.restore).Inject(Params{api.ID: h.id, api.ID2: id})
+	err = h.client.Put(path, nil)
+	return
+}
+
+//
+// Diff compares a snapshot with the current bucket content.
+func (h *Snapshot) Diff(id uint) (r *api.BucketDiff, err error) {
+	r = &api.BucketDiff{}
+	path := Path(h.diff).Inject(Params{api.ID: h.id, api.ID2: id})
+	err = h.client.Get(path, r)
+	return
+}
+
+//
+// DiffWith compares a snapshot with another snapshot.
+func (h *Snapshot) DiffWith(id, other uint) (r *api.BucketDiff, err error) {
+	r = &api.BucketDiff{}
+	path := Path(h.diff).Inject(Params{api.ID: h.id, api.ID2: id})
+	err = h.client.Get(
+		path,
+		r,
+		Param{
+			Key:   api.Snapshot,
+			Value: strconv.Itoa(int(other)),
+		})
+	return
+}
@@ -63,3 +63,17 @@ func (h *Task) Bucket(id uint) (b *BucketContent) {
 	}
 	return
 }
+
+//
+// Snapshot returns the bucket snapshot API.
+func (h *Task) Snapshot(id uint) (s *Snapshot) {
+	s = &Snapshot{
+		client:  h.client,
+		root:    api.TaskSnapshotsRoot,
+		entry:   api.TaskSnapshotRoot,
+		restore: api.TaskSnapshotRestore,
+		diff:    api.TaskSnapshotDiff,
+		id:      id,
+	}
+	return
+}
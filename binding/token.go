@@ -0,0 +1,43 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/api"
+)
+
+//
+// Token (personal) API token API.
+type Token struct {
+	client *Client
+}
+
+//
+// Create a Token.
+// The token (secret) is set on the returned resource.
+func (h *Token) Create(r *api.APIToken) (err error) {
+	err = h.client.Post(api.TokensRoot, &r)
+	return
+}
+
+//
+// Get a Token by ID.
+func (h *Token) Get(id uint) (r *api.APIToken, err error) {
+	r = &api.APIToken{}
+	path := Path(api.TokenRoot).Inject(Params{api.ID: id})
+	err = h.client.Get(path, r)
+	return
+}
+
+//
+// List Tokens.
+func (h *Token) List() (list []api.APIToken, err error) {
+	list = []api.APIToken{}
+	err = h.client.Get(api.TokensRoot, &list)
+	return
+}
+
+//
+// Delete (revoke) a Token.
+func (h *Token) Delete(id uint) (err error) {
+	err = h.client.Delete(Path(api.TokenRoot).Inject(Params{api.ID: id}))
+	return
+}
@@ -0,0 +1,19 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/api"
+)
+
+//
+// Usage API.
+type Usage struct {
+	client *Client
+}
+
+//
+// Get the storage usage.
+func (h *Usage) Get() (r *api.Usage, err error) {
+	r = &api.Usage{}
+	err = h.client.Get(api.UsageRoot, r)
+	return
+}
@@ -0,0 +1,50 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/api"
+)
+
+//
+// User API.
+type User struct {
+	client *Client
+}
+
+//
+// Create a User.
+func (h *User) Create(r *api.User) (err error) {
+	err = h.client.Post(api.UsersRoot, &r)
+	return
+}
+
+//
+// Get a User by ID.
+func (h *User) Get(id uint) (r *api.User, err error) {
+	r = &api.User{}
+	path := Path(api.UserRoot).Inject(Params{api.ID: id})
+	err = h.client.Get(path, r)
+	return
+}
+
+//
+// List Users.
+func (h *User) List() (list []api.User, err error) {
+	list = []api.User{}
+	err = h.client.Get(api.UsersRoot, &list)
+	return
+}
+
+//
+// Update a User.
+func (h *User) Update(r *api.User) (err error) {
+	path := Path(api.UserRoot).Inject(Params{api.ID: r.ID})
+	err = h.client.Put(path, r)
+	return
+}
+
+//
+// Delete a User.
+func (h *User) Delete(id uint) (err error) {
+	err = h.client.Delete(Path(api.UserRoot).Inject(Params{api.ID: id}))
+	return
+}
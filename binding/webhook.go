@@ -0,0 +1,67 @@
+package binding
+
+import (
+	"github.com/konveyor/tackle2-hub/api"
+)
+
+//
+// Webhook API.
+type Webhook struct {
+	client *Client
+}
+
+//
+// Create a Webhook.
+func (h *Webhook) Create(r *api.Webhook) (err error) {
+	err = h.client.Post(api.WebhooksRoot, &r)
+	return
+}
+
+//
+// Get a Webhook by ID.
+func (h *Webhook) Get(id uint) (r *api.Webhook, err error) {
+	r = &api.Webhook{}
+	path := Path(api.WebhookRoot).Inject(Params{api.ID: id})
+	err = h.client.Get(path, r)
+	return
+}
+
+//
+// List Webhooks.
+func (h *Webhook) List() (list []api.Webhook, err error) {
+	list = []api.Webhook{}
+	err = h.client.Get(api.WebhooksRoot, &list)
+	return
+}
+
+//
+// Update a Webhook.
+func (h *Webhook) Update(r *api.Webhook) (err error) {
+	path := Path(api.WebhookRoot).Inject(Params{api.ID: r.ID})
+	err = h.client.Put(path, r)
+	return
+}
+
+//
+// Delete a Webhook.
+func (h *Webhook) Delete(id uint) (err error) {
+	err = h.client.Delete(Path(api.WebhookRoot).Inject(Params{api.ID: id}))
+	return
+}
+
+//
+// Deliveries lists the deliveries (log) of a Webhook.
+func (h *Webhook) Deliveries(id uint, filter ...Param) (list []api.WebhookDelivery, err error) {
+	list = []api.WebhookDelivery{}
+	path := Path(api.WebhookDeliveriesRoot).Inject(Params{api.ID: id})
+	err = h.client.Get(path, &list, filter...)
+	return
+}
+
+//
+// Retry a Webhook delivery.
+func (h *Webhook) Retry(id, id2 uint) (err error) {
+	path := Path(api.WebhookRetryRoot).Inject(Params{api.ID: id, api.ID2: id2})
+	err = h.client.Post(path, nil)
+	return
+}
@@ -0,0 +1,242 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/konveyor/tackle2-hub/database"
+	"github.com/konveyor/tackle2-hub/migration"
+	v12 "github.com/konveyor/tackle2-hub/migration/v12/model"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/quota"
+	"github.com/konveyor/tackle2-hub/storage"
+	"github.com/konveyor/tackle2-hub/tar"
+	"github.com/onsi/gomega"
+	"gorm.io/gorm"
+	"io"
+	"os"
+	pathlib "path"
+	"strings"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	savedDB := Settings.DB.Path
+	savedBucket := Settings.Bucket.Path
+	Settings.Bucket.Path = t.TempDir()
+	defer func() {
+		Settings.DB.Path = savedDB
+		Settings.Bucket.Path = savedBucket
+	}()
+	open := func() (db *gorm.DB) {
+		Settings.DB.Path = pathlib.Join(t.TempDir(), "hub.db")
+		db, err := database.Open(true)
+		g.Expect(err).To(gomega.BeNil())
+		err = db.AutoMigrate(v12.All()...)
+		g.Expect(err).To(gomega.BeNil())
+		b, _ := json.Marshal(migration.Version{Version: migration.MinimumVersion})
+		setting := &model.Setting{Key: migration.VersionKey, Value: b}
+		err = db.Where("Key", setting.Key).Delete(&model.Setting{}).Error
+		g.Expect(err).To(gomega.BeNil())
+		err = db.Create(setting).Error
+		g.Expect(err).To(gomega.BeNil())
+		return
+	}
+	uuid := "q-uuid"
+	//
+	// Exporting hub.
+	db := open()
+	questionnaire := &model.Questionnaire{UUID: &uuid, Name: "Q1"}
+	g.Expect(db.Create(questionnaire).Error).To(gomega.BeNil())
+	category := &model.TagCategory{Name: "Language", Color: "red"}
+	g.Expect(db.Create(category).Error).To(gomega.BeNil())
+	tag := &model.Tag{Name: "Java", CategoryID: category.ID}
+	g.Expect(db.Create(tag).Error).To(gomega.BeNil())
+	owner := &model.Stakeholder{Name: "Ann", Email: "ann@x.com"}
+	g.Expect(db.Create(owner).Error).To(gomega.BeNil())
+	identity := &model.Identity{Name: "git", Kind: "source", Password: "secret"}
+	g.Expect(db.Create(identity).Error).To(gomega.BeNil())
+	missing := &model.Identity{Name: "maven", Kind: "maven"}
+	g.Expect(db.Create(missing).Error).To(gomega.BeNil())
+	application := &model.Application{
+		Name:       "A",
+		OwnerID:    &owner.ID,
+		Identities: []model.Identity{*identity, *missing},
+	}
+	g.Expect(db.Omit("Tags").Create(application).Error).To(gomega.BeNil())
+	err := db.Create(
+		&model.ApplicationTag{
+			ApplicationID: application.ID,
+			TagID:         tag.ID,
+			Source:        "language-discovery",
+		}).Error
+	g.Expect(err).To(gomega.BeNil())
+	err = db.Create(
+		&model.Fact{
+			ApplicationID: application.ID,
+			Key:           "k",
+			Value:         []byte(`{"v":1}`),
+		}).Error
+	g.Expect(err).To(gomega.BeNil())
+	err = db.Create(
+		&model.Assessment{
+			ApplicationID:   &application.ID,
+			QuestionnaireID: questionnaire.ID,
+			Sections:        []byte(`[]`),
+			Stakeholders:    []model.Stakeholder{*owner},
+		}).Error
+	g.Expect(err).To(gomega.BeNil())
+	err = db.Create(
+		&model.Review{
+			ApplicationID:  &application.ID,
+			EffortEstimate: "small",
+			ProposedAction: "rehost",
+		}).Error
+	g.Expect(err).To(gomega.BeNil())
+	err = db.Create(
+		&model.Analysis{
+			ApplicationID: application.ID,
+			Effort:        10,
+			Issues: []model.Issue{
+				{
+					RuleSet:  "rs",
+					Rule:     "r1",
+					Category: "mandatory",
+					Effort:   10,
+					Incidents: []model.Incident{
+						{File: "a.java", Line: 1},
+					},
+				},
+			},
+		}).Error
+	g.Expect(err).To(gomega.BeNil())
+	bucket := &model.Bucket{}
+	g.Expect(db.First(bucket, *application.BucketID).Error).To(gomega.BeNil())
+	err = storage.Store.Put(
+		pathlib.Join(bucket.Path, "sub/a.txt"),
+		strings.NewReader("a"),
+		1)
+	g.Expect(err).To(gomega.BeNil())
+	archive := &bytes.Buffer{}
+	manifest, err := Export(db, archive, tar.Gzip, application.ID)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(manifest.Applications).To(gomega.Equal([]string{"A"}))
+	g.Expect(len(manifest.Entries)).To(gomega.Equal(2))
+	b := archive.Bytes()
+	g.Expect(database.Close(db)).To(gomega.BeNil())
+	//
+	// Importing hub.
+	db = open()
+	defer func() {
+		_ = database.Close(db)
+	}()
+	questionnaire = &model.Questionnaire{UUID: &uuid, Name: "Renamed"}
+	g.Expect(db.Create(questionnaire).Error).To(gomega.BeNil())
+	g.Expect(db.Create(&model.Application{Name: "Z"}).Error).To(gomega.BeNil())
+	identity = &model.Identity{Name: "git", Kind: "source"}
+	g.Expect(db.Create(identity).Error).To(gomega.BeNil())
+	report, err := Import(db, bytes.NewReader(b), "tester")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(report.Applications)).To(gomega.Equal(1))
+	imported := report.Applications[0]
+	g.Expect(imported.Source).To(gomega.Equal(application.ID))
+	created := make(map[string]string)
+	for _, r := range report.Created {
+		created[r.Kind] = r.Name
+	}
+	g.Expect(created).To(
+		gomega.Equal(
+			map[string]string{
+				KindTagCategory: "Language",
+				KindTag:         "Language=Java",
+				KindStakeholder: "Ann",
+			}))
+	g.Expect(len(report.Conflicts)).To(gomega.Equal(1))
+	g.Expect(report.Conflicts[0].Kind).To(gomega.Equal(KindIdentity))
+	g.Expect(report.Conflicts[0].Name).To(gomega.Equal("maven"))
+	m := &model.Application{}
+	db2 := db.Preload("Owner").Preload("Identities").Preload("Facts")
+	db2 = db2.Preload("Review").Preload("Assessments.Stakeholders")
+	db2 = db2.Preload("Analyses.Issues.Incidents").Preload("Bucket")
+	g.Expect(db2.First(m, imported.ID).Error).To(gomega.BeNil())
+	g.Expect(m.Owner.Email).To(gomega.Equal("ann@x.com"))
+	g.Expect(len(m.Identities)).To(gomega.Equal(1))
+	g.Expect(m.Identities[0].ID).To(gomega.Equal(identity.ID))
+	g.Expect(len(m.Facts)).To(gomega.Equal(1))
+	g.Expect(m.Review.ProposedAction).To(gomega.Equal("rehost"))
+	g.Expect(len(m.Assessments)).To(gomega.Equal(1))
+	g.Expect(m.Assessments[0].QuestionnaireID).To(gomega.Equal(questionnaire.ID))
+	g.Expect(m.Assessments[0].Stakeholders[0].ID).To(gomega.Equal(*m.OwnerID))
+	g.Expect(len(m.Analyses)).To(gomega.Equal(1))
+	g.Expect(len(m.Analyses[0].Issues[0].Incidents)).To(gomega.Equal(1))
+	var tags []model.ApplicationTag
+	g.Expect(db.Find(&tags, "ApplicationID", m.ID).Error).To(gomega.BeNil())
+	g.Expect(len(tags)).To(gomega.Equal(1))
+	g.Expect(tags[0].Source).To(gomega.Equal("language-discovery"))
+	content, err := os.ReadFile(pathlib.Join(m.Bucket.Path, "sub/a.txt"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(string(content)).To(gomega.Equal("a"))
+	//
+	// Import again (conflict).
+	report, err = Import(db, bytes.NewReader(b), "tester")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(report.Applications)).To(gomega.Equal(0))
+	g.Expect(len(report.Created)).To(gomega.Equal(0))
+	g.Expect(report.Conflicts[0].Kind).To(gomega.Equal(KindApplication))
+	//
+	// Corrupted.
+	corrupted := &bytes.Buffer{}
+	writer := tar.NewWriter(corrupted)
+	err = tar.NewReader().Walk(
+		bytes.NewReader(b),
+		func(path string, size int64, r io.Reader) (err error) {
+			content, _ := io.ReadAll(r)
+			if strings.HasSuffix(path, "/a.txt") {
+				content = []byte("x")
+			}
+			err = writer.AddStream(
+				path,
+				int64(len(content)),
+				func(w io.Writer) (err error) {
+					_, err = w.Write(content)
+					return
+				})
+			return
+		})
+	g.Expect(err).To(gomega.BeNil())
+	writer.Close()
+	_, err = Import(db, corrupted, "tester")
+	g.Expect(errors.Is(err, &Invalid{})).To(gomega.BeTrue())
+	//
+	// Quota exceeded (reported).
+	savedQuota := Settings.Quota
+	Settings.Quota.Total = 1
+	defer func() {
+		Settings.Quota = savedQuota
+		quota.Reset()
+	}()
+	err = storage.Store.Put(
+		pathlib.Join(Settings.Bucket.Path, "filler"),
+		bytes.NewReader(make([]byte, quota.MiB)),
+		quota.MiB)
+	g.Expect(err).To(gomega.BeNil())
+	quota.Reset()
+	db3 := open()
+	defer func() {
+		_ = database.Close(db3)
+	}()
+	stored, err := os.ReadDir(Settings.Bucket.Path)
+	g.Expect(err).To(gomega.BeNil())
+	report, err = Import(db3, bytes.NewReader(b), "tester")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(report.Applications)).To(gomega.Equal(0))
+	g.Expect(report.Conflicts[0].Kind).To(gomega.Equal(KindApplication))
+	g.Expect(report.Conflicts[0].Reason).To(gomega.ContainSubstring("Quota"))
+	var n int64
+	g.Expect(db3.Model(&model.Application{}).Count(&n).Error).To(gomega.BeNil())
+	g.Expect(n).To(gomega.Equal(int64(0)))
+	after, err := os.ReadDir(Settings.Bucket.Path)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(after)).To(gomega.Equal(len(stored)))
+}
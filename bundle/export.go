@@ -0,0 +1,360 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/migration"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/storage"
+	"github.com/konveyor/tackle2-hub/tar"
+	"gorm.io/gorm"
+	"io"
+	pathlib "path"
+	"sort"
+	"strings"
+	"time"
+)
+
+//
+// Export writes a bundle of the applications to the output.
+// Each application is written as a (portable) document followed
+// by the bucket content. The manifest is written last.
+func Export(db *gorm.DB, output io.Writer, compression string, ids ...uint) (manifest Manifest, err error) {
+	manifest.Format = Format
+	manifest.Created = time.Now()
+	manifest.Applications = []string{}
+	manifest.Version, err = version(db)
+	if err != nil {
+		return
+	}
+	writer := tar.NewWriterWith(output, compression)
+	defer func() {
+		writer.Close()
+	}()
+	for _, id := range ids {
+		var entries []Entry
+		var name string
+		name, entries, err = export(db, writer, id)
+		if err != nil {
+			return
+		}
+		manifest.Applications = append(manifest.Applications, name)
+		manifest.Entries = append(manifest.Entries, entries...)
+	}
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	_, err = write(writer, ManifestName, int64(len(b)), bytes.NewReader(b))
+	if err != nil {
+		return
+	}
+	Log.Info(
+		"Bundle exported.",
+		"applications",
+		len(manifest.Applications),
+		"entries",
+		len(manifest.Entries))
+	return
+}
+
+//
+// export an application.
+func export(db *gorm.DB, writer *tar.Writer, id uint) (name string, entries []Entry, err error) {
+	m := &model.Application{}
+	db = db.Preload("Bucket")
+	db = db.Preload("BusinessService")
+	db = db.Preload("Owner")
+	db = db.Preload("Contributors")
+	db = db.Preload("Identities")
+	db = db.Preload("Facts")
+	db = db.Preload("Review")
+	db = db.Preload("Assessments.Questionnaire")
+	db = db.Preload("Assessments.Stakeholders")
+	db = db.Preload("Assessments.StakeholderGroups")
+	db = db.Preload("Analyses.Issues.Incidents")
+	db = db.Preload("Analyses.Dependencies")
+	err = db.First(m, id).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	name = m.Name
+	d := &Application{}
+	d.With(m)
+	var tags []model.ApplicationTag
+	err = db.Session(&gorm.Session{NewDB: true}).
+		Preload("Tag.Category").
+		Find(&tags, "ApplicationID", id).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	d.withTags(tags)
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	root := pathlib.Join(ApplicationsRoot, fmt.Sprint(id))
+	entry, err := write(
+		writer,
+		pathlib.Join(root, DocumentName),
+		int64(len(b)),
+		bytes.NewReader(b))
+	if err != nil {
+		return
+	}
+	entries = append(entries, entry)
+	if m.Bucket == nil {
+		return
+	}
+	bucketPath := pathlib.Clean(m.Bucket.Path)
+	list, err := storage.Store.List(bucketPath)
+	if err != nil {
+		if errors.Is(err, &storage.NotFound{}) {
+			err = nil
+		}
+		return
+	}
+	sort.Slice(
+		list,
+		func(i, j int) bool {
+			return list[i].Path < list[j].Path
+		})
+	for _, info := range list {
+		rPath := strings.TrimPrefix(info.Path, bucketPath)
+		entry, err = stream(writer, pathlib.Join(root, ContentRoot, rPath), info)
+		if err != nil {
+			if errors.Is(err, &storage.NotFound{}) {
+				Log.Info("Content deleted (omitted).", "path", info.Path)
+				err = nil
+				continue
+			}
+			return
+		}
+		entries = append(entries, entry)
+	}
+	return
+}
+
+//
+// With updates the document with the model.
+func (r *Application) With(m *model.Application) {
+	r.ID = m.ID
+	r.Name = m.Name
+	r.Description = m.Description
+	r.Comments = m.Comments
+	r.Binary = m.Binary
+	r.Repository = raw(m.Repository)
+	if m.BusinessService != nil {
+		r.BusinessService = &Ref{Name: m.BusinessService.Name}
+	}
+	if m.Owner != nil {
+		r.Owner = &StakeholderRef{
+			Name:  m.Owner.Name,
+			Email: m.Owner.Email,
+		}
+	}
+	for _, s := range m.Contributors {
+		r.Contributors = append(
+			r.Contributors,
+			StakeholderRef{
+				Name:  s.Name,
+				Email: s.Email,
+			})
+	}
+	for _, f := range m.Facts {
+		r.Facts = append(
+			r.Facts,
+			Fact{
+				Key:    f.Key,
+				Source: f.Source,
+				Value:  raw(f.Value),
+			})
+	}
+	for _, identity := range m.Identities {
+		r.Identities = append(
+			r.Identities,
+			IdentityRef{
+				Kind: identity.Kind,
+				Name: identity.Name,
+			})
+	}
+	for i := range m.Assessments {
+		assessment := Assessment{}
+		assessment.With(&m.Assessments[i])
+		r.Assessments = append(r.Assessments, assessment)
+	}
+	if m.Review != nil {
+		r.Review = &Review{
+			BusinessCriticality: m.Review.BusinessCriticality,
+			EffortEstimate:      m.Review.EffortEstimate,
+			ProposedAction:      m.Review.ProposedAction,
+			WorkPriority:        m.Review.WorkPriority,
+			Comments:            m.Review.Comments,
+		}
+	}
+	for i := range m.Analyses {
+		analysis := Analysis{}
+		analysis.With(&m.Analyses[i])
+		r.Analyses = append(r.Analyses, analysis)
+	}
+}
+
+//
+// withTags updates the document with the application tags.
+func (r *Application) withTags(tags []model.ApplicationTag) {
+	for _, m := range tags {
+		tag := Tag{
+			Ref: ref(m.Tag.UUID, m.Tag.Name),
+			Category: TagCategory{
+				Ref:   ref(m.Tag.Category.UUID, m.Tag.Category.Name),
+				Rank:  m.Tag.Category.Rank,
+				Color: m.Tag.Category.Color,
+			},
+			Source: m.Source,
+		}
+		r.Tags = append(r.Tags, tag)
+	}
+}
+
+//
+// With updates the document with the model.
+func (r *Assessment) With(m *model.Assessment) {
+	r.Questionnaire = ref(m.Questionnaire.UUID, m.Questionnaire.Name)
+	r.Sections = raw(m.Sections)
+	r.Thresholds = raw(m.Thresholds)
+	r.RiskMessages = raw(m.RiskMessages)
+	for _, s := range m.Stakeholders {
+		r.Stakeholders = append(
+			r.Stakeholders,
+			StakeholderRef{
+				Name:  s.Name,
+				Email: s.Email,
+			})
+	}
+	for _, g := range m.StakeholderGroups {
+		r.StakeholderGroups = append(
+			r.StakeholderGroups,
+			Ref{Name: g.Name})
+	}
+}
+
+//
+// With updates the document with the model.
+func (r *Analysis) With(m *model.Analysis) {
+	r.Effort = m.Effort
+	r.Archived = m.Archived
+	r.Summary = raw(m.Summary)
+	r.RuleSets = raw(m.RuleSets)
+	for _, issue := range m.Issues {
+		d := Issue{
+			RuleSet:     issue.RuleSet,
+			Rule:        issue.Rule,
+			Name:        issue.Name,
+			Description: issue.Description,
+			Category:    issue.Category,
+			Effort:      issue.Effort,
+			Links:       raw(issue.Links),
+			Facts:       raw(issue.Facts),
+			Labels:      raw(issue.Labels),
+		}
+		for _, incident := range issue.Incidents {
+			d.Incidents = append(
+				d.Incidents,
+				Incident{
+					File:     incident.File,
+					Line:     incident.Line,
+					Message:  incident.Message,
+					CodeSnip: incident.CodeSnip,
+					Facts:    raw(incident.Facts),
+				})
+		}
+		r.Issues = append(r.Issues, d)
+	}
+	for _, dep := range m.Dependencies {
+		r.Dependencies = append(
+			r.Dependencies,
+			Dependency{
+				Provider: dep.Provider,
+				Name:     dep.Name,
+				Version:  dep.Version,
+				SHA:      dep.SHA,
+				Indirect: dep.Indirect,
+				Labels:   raw(dep.Labels),
+			})
+	}
+}
+
+//
+// ref returns a reference.
+func ref(uuid *string, name string) (r Ref) {
+	r.Name = name
+	if uuid != nil {
+		r.UUID = *uuid
+	}
+	return
+}
+
+//
+// version returns the migration version of the database.
+func version(db *gorm.DB) (n int, err error) {
+	setting := &model.Setting{}
+	err = db.First(setting, "Key", migration.VersionKey).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	v := migration.Version{}
+	err = json.Unmarshal(setting.Value, &v)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	n = v.Version
+	return
+}
+
+//
+// stream stored content to the archive.
+func stream(writer *tar.Writer, destPath string, info storage.Info) (entry Entry, err error) {
+	reader, err := storage.Store.Get(info.Path)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	entry, err = write(writer, destPath, info.Size, reader)
+	return
+}
+
+//
+// write content to the archive.
+// The size and digest are recorded in the entry.
+func write(writer *tar.Writer, destPath string, size int64, reader io.Reader) (entry Entry, err error) {
+	h := sha256.New()
+	err = writer.AddStream(
+		destPath,
+		size,
+		func(w io.Writer) (err error) {
+			_, err = io.CopyN(io.MultiWriter(w, h), reader, size)
+			return
+		})
+	if err != nil {
+		return
+	}
+	entry = Entry{
+		Path:   destPath,
+		Size:   size,
+		Digest: hex.EncodeToString(h.Sum(nil)),
+	}
+	return
+}
@@ -0,0 +1,799 @@
+package bundle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/quota"
+	"github.com/konveyor/tackle2-hub/storage"
+	"github.com/konveyor/tackle2-hub/tar"
+	"gorm.io/gorm"
+	"io"
+	"io/fs"
+	"os"
+	pathlib "path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//
+// Import the applications in the bundle.
+// The bundle is staged and validated (checksums) before the
+// applications are imported. Each application (and bucket content)
+// is imported in a transaction and assigned a new ID. Applications
+// that cannot be imported are reported as conflicts. Related
+// resources are resolved by UUID or name:
+//   - tags, tag categories, stakeholders, stakeholder groups and business
+//     services are created when not found.
+//   - identities and questionnaires are never created; references not
+//     resolved are reported as conflicts and omitted.
+//
+// Applications that already exist (by name) are reported as conflicts
+// and skipped.
+func Import(db *gorm.DB, input io.Reader, user string) (report Report, err error) {
+	dir, err := os.MkdirTemp(pathlib.Dir(Settings.DB.Path), ".bundle-")
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	staged, err := stage(input, dir)
+	if err != nil {
+		return
+	}
+	manifest, err := validate(dir, staged)
+	if err != nil {
+		return
+	}
+	documents, err := load(dir, manifest)
+	if err != nil {
+		return
+	}
+	for i := range documents {
+		d := &documents[i]
+		imp := importer{
+			user:        user,
+			application: d.Name,
+		}
+		err = imp.Import(db, dir, d)
+		if err != nil {
+			Log.Error(err, "Application not imported.", "name", d.Name)
+			report.conflict(
+				d.Name,
+				KindApplication,
+				d.Name,
+				"import failed: "+err.Error())
+			err = nil
+			continue
+		}
+		report.Applications = append(report.Applications, imp.report.Applications...)
+		report.Created = append(report.Created, imp.report.Created...)
+		report.Conflicts = append(report.Conflicts, imp.report.Conflicts...)
+	}
+	Log.Info(
+		"Bundle imported.",
+		"applications",
+		len(report.Applications),
+		"created",
+		len(report.Created),
+		"conflicts",
+		len(report.Conflicts))
+	return
+}
+
+//
+// importer imports an application.
+type importer struct {
+	db          *gorm.DB
+	user        string
+	application string
+	report      Report
+}
+
+//
+// Import the application.
+// The bucket content is stored within the transaction and
+// deleted when the import fails.
+func (r *importer) Import(db *gorm.DB, dir string, d *Application) (err error) {
+	found, err := r.first(db, &model.Application{}, "Name", d.Name)
+	if err != nil || found {
+		if found {
+			r.report.conflict(
+				r.application,
+				KindApplication,
+				d.Name,
+				"already exists (skipped).")
+		}
+		return
+	}
+	root := pathlib.Join(dir, d.path, ContentRoot)
+	size, err := dirSize(root)
+	if err != nil {
+		return
+	}
+	m := &model.Application{}
+	var reservation *quota.Reservation
+	err = db.Transaction(
+		func(tx *gorm.DB) (err error) {
+			r.db = tx
+			err = r.create(m, d)
+			if err != nil {
+				return
+			}
+			reservation, err = r.content(root, m, size)
+			return
+		})
+	if reservation != nil {
+		if err == nil {
+			reservation.Commit()
+		} else {
+			reservation.Release()
+		}
+	}
+	if err != nil {
+		return
+	}
+	r.report.Applications = append(
+		r.report.Applications,
+		Imported{
+			Name:   m.Name,
+			Source: d.ID,
+			ID:     m.ID,
+		})
+	return
+}
+
+//
+// create the application and related resources.
+func (r *importer) create(m *model.Application, d *Application) (err error) {
+	m.Name = d.Name
+	m.Description = d.Description
+	m.Comments = d.Comments
+	m.Binary = d.Binary
+	m.Repository = model.JSON(d.Repository)
+	m.CreateUser = r.user
+	if d.BusinessService != nil {
+		var id uint
+		id, err = r.businessService(d.BusinessService)
+		if err != nil {
+			return
+		}
+		m.BusinessServiceID = &id
+	}
+	if d.Owner != nil {
+		var id uint
+		id, err = r.stakeholder(d.Owner)
+		if err != nil {
+			return
+		}
+		m.OwnerID = &id
+	}
+	for i := range d.Contributors {
+		var id uint
+		id, err = r.stakeholder(&d.Contributors[i])
+		if err != nil {
+			return
+		}
+		m.Contributors = append(m.Contributors, model.Stakeholder{Model: model.Model{ID: id}})
+	}
+	for i := range d.Identities {
+		var id uint
+		id, err = r.identity(&d.Identities[i])
+		if err != nil {
+			return
+		}
+		if id > 0 {
+			m.Identities = append(m.Identities, model.Identity{Model: model.Model{ID: id}})
+		}
+	}
+	err = r.db.Omit("Tags").Create(m).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	err = r.tags(m, d)
+	if err != nil {
+		return
+	}
+	for _, f := range d.Facts {
+		fact := &model.Fact{
+			ApplicationID: m.ID,
+			Key:           f.Key,
+			Source:        f.Source,
+			Value:         model.JSON(f.Value),
+		}
+		err = r.db.Create(fact).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+	}
+	for i := range d.Assessments {
+		err = r.assessment(m, &d.Assessments[i])
+		if err != nil {
+			return
+		}
+	}
+	if d.Review != nil {
+		review := &model.Review{
+			BusinessCriticality: d.Review.BusinessCriticality,
+			EffortEstimate:      d.Review.EffortEstimate,
+			ProposedAction:      d.Review.ProposedAction,
+			WorkPriority:        d.Review.WorkPriority,
+			Comments:            d.Review.Comments,
+			ApplicationID:       &m.ID,
+		}
+		review.CreateUser = r.user
+		err = r.db.Create(review).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+	}
+	for i := range d.Analyses {
+		analysis := d.Analyses[i].Model()
+		analysis.ApplicationID = m.ID
+		analysis.CreateUser = r.user
+		err = r.db.Create(analysis).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+	}
+	return
+}
+
+//
+// tags creates the application tags.
+// Duplicates (resolved to the same tag and source) are ignored.
+func (r *importer) tags(m *model.Application, d *Application) (err error) {
+	type Key struct {
+		id     uint
+		source string
+	}
+	seen := make(map[Key]bool)
+	for i := range d.Tags {
+		var id uint
+		id, err = r.tag(&d.Tags[i])
+		if err != nil {
+			return
+		}
+		key := Key{id: id, source: d.Tags[i].Source}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		tag := &model.ApplicationTag{
+			ApplicationID: m.ID,
+			TagID:         id,
+			Source:        key.source,
+		}
+		err = r.db.Create(tag).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+	}
+	return
+}
+
+//
+// assessment creates an assessment.
+// Omitted when the questionnaire is not found.
+func (r *importer) assessment(m *model.Application, d *Assessment) (err error) {
+	q := &model.Questionnaire{}
+	found := false
+	if d.Questionnaire.UUID != "" {
+		found, err = r.first(r.db, q, "UUID", d.Questionnaire.UUID)
+		if err != nil {
+			return
+		}
+	}
+	if !found {
+		found, err = r.first(r.db, q, "Name", d.Questionnaire.Name)
+		if err != nil {
+			return
+		}
+	}
+	if !found {
+		r.report.conflict(
+			r.application,
+			KindQuestionnaire,
+			d.Questionnaire.Name,
+			"not found (assessment omitted).")
+		return
+	}
+	assessment := &model.Assessment{
+		ApplicationID:   &m.ID,
+		QuestionnaireID: q.ID,
+		Sections:        model.JSON(d.Sections),
+		Thresholds:      model.JSON(d.Thresholds),
+		RiskMessages:    model.JSON(d.RiskMessages),
+	}
+	assessment.CreateUser = r.user
+	for i := range d.Stakeholders {
+		var id uint
+		id, err = r.stakeholder(&d.Stakeholders[i])
+		if err != nil {
+			return
+		}
+		assessment.Stakeholders = append(
+			assessment.Stakeholders,
+			model.Stakeholder{Model: model.Model{ID: id}})
+	}
+	for i := range d.StakeholderGroups {
+		var id uint
+		id, err = r.stakeholderGroup(&d.StakeholderGroups[i])
+		if err != nil {
+			return
+		}
+		assessment.StakeholderGroups = append(
+			assessment.StakeholderGroups,
+			model.StakeholderGroup{Model: model.Model{ID: id}})
+	}
+	err = r.db.Create(assessment).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// tagCategory resolves a tag category by UUID or name.
+// Created when not found.
+func (r *importer) tagCategory(d *TagCategory) (id uint, err error) {
+	m := &model.TagCategory{}
+	found := false
+	if d.UUID != "" {
+		found, err = r.first(r.db, m, "UUID", d.UUID)
+		if err != nil {
+			return
+		}
+	}
+	if !found {
+		found, err = r.first(r.db, m, "Name", d.Name)
+		if err != nil {
+			return
+		}
+	}
+	if !found {
+		m.Name = d.Name
+		m.Rank = d.Rank
+		m.Color = d.Color
+		m.CreateUser = r.user
+		err = r.db.Create(m).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		r.report.created(KindTagCategory, m.Name, m.ID)
+	}
+	id = m.ID
+	return
+}
+
+//
+// tag resolves a tag by UUID or (category) name.
+// Created when not found.
+func (r *importer) tag(d *Tag) (id uint, err error) {
+	m := &model.Tag{}
+	if d.UUID != "" {
+		var found bool
+		found, err = r.first(r.db, m, "UUID", d.UUID)
+		if err != nil || found {
+			id = m.ID
+			return
+		}
+	}
+	category, err := r.tagCategory(&d.Category)
+	if err != nil {
+		return
+	}
+	found, err := r.first(r.db, m, "CategoryID = ? AND Name = ?", category, d.Name)
+	if err != nil {
+		return
+	}
+	if !found {
+		m.Name = d.Name
+		m.CategoryID = category
+		m.CreateUser = r.user
+		err = r.db.Create(m).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		r.report.created(KindTag, d.Category.Name+"="+m.Name, m.ID)
+	}
+	id = m.ID
+	return
+}
+
+//
+// stakeholder resolves a stakeholder by email or name.
+// Created when not found.
+func (r *importer) stakeholder(d *StakeholderRef) (id uint, err error) {
+	m := &model.Stakeholder{}
+	found, err := r.first(r.db, m, "Email", d.Email)
+	if err != nil {
+		return
+	}
+	if !found {
+		found, err = r.first(r.db, m, "Name", d.Name)
+		if err != nil {
+			return
+		}
+	}
+	if !found {
+		m.Name = d.Name
+		m.Email = d.Email
+		m.CreateUser = r.user
+		err = r.db.Create(m).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		r.report.created(KindStakeholder, m.Name, m.ID)
+	}
+	id = m.ID
+	return
+}
+
+//
+// stakeholderGroup resolves a stakeholder group by name.
+// Created when not found.
+func (r *importer) stakeholderGroup(d *Ref) (id uint, err error) {
+	m := &model.StakeholderGroup{}
+	found, err := r.first(r.db, m, "Name", d.Name)
+	if err != nil {
+		return
+	}
+	if !found {
+		m.Name = d.Name
+		m.CreateUser = r.user
+		err = r.db.Create(m).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		r.report.created(KindStakeholderGroup, m.Name, m.ID)
+	}
+	id = m.ID
+	return
+}
+
+//
+// businessService resolves a business service by name.
+// Created when not found.
+func (r *importer) businessService(d *Ref) (id uint, err error) {
+	m := &model.BusinessService{}
+	found, err := r.first(r.db, m, "Name", d.Name)
+	if err != nil {
+		return
+	}
+	if !found {
+		m.Name = d.Name
+		m.CreateUser = r.user
+		err = r.db.Create(m).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		r.report.created(KindBusinessService, m.Name, m.ID)
+	}
+	id = m.ID
+	return
+}
+
+//
+// identity resolves an identity by name and kind.
+// Returns 0 (and reports a conflict) when not found or the
+// kind is not matched.
+func (r *importer) identity(d *IdentityRef) (id uint, err error) {
+	m := &model.Identity{}
+	found, err := r.first(r.db, m, "Name", d.Name)
+	if err != nil {
+		return
+	}
+	switch {
+	case !found:
+		r.report.conflict(
+			r.application,
+			KindIdentity,
+			d.Name,
+			"not found (omitted).")
+	case m.Kind != d.Kind:
+		r.report.conflict(
+			r.application,
+			KindIdentity,
+			d.Name,
+			"kind ("+m.Kind+") not matched (omitted).")
+	default:
+		id = m.ID
+	}
+	return
+}
+
+//
+// content stores the (staged) bucket content.
+// The quotas are checked and the content is deleted on error.
+// Returns the quota reservation to be committed (or released)
+// with the transaction.
+func (r *importer) content(root string, m *model.Application, size int64) (reservation *quota.Reservation, err error) {
+	if m.BucketID == nil {
+		return
+	}
+	bucket := &model.Bucket{}
+	err = r.db.First(bucket, *m.BucketID).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = storage.Store.Delete(bucket.Path)
+		}
+	}()
+	reservation, err = quota.Check(bucket.Path, size)
+	if err != nil {
+		return
+	}
+	err = filepath.WalkDir(
+		root,
+		func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == root {
+					err = nil
+				}
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			return put(path, pathlib.Join(bucket.Path, strings.TrimPrefix(path, root)))
+		})
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// dirSize returns the size (bytes) of the staged content.
+func dirSize(root string) (n int64, err error) {
+	err = filepath.WalkDir(
+		root,
+		func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == root {
+					err = nil
+				}
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			n += info.Size()
+			return nil
+		})
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// put stores a staged file.
+func put(path, destPath string) (err error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	err = storage.Store.Put(destPath, f, st.Size())
+	return
+}
+
+//
+// first finds the first matching model.
+func (r *importer) first(db *gorm.DB, m any, query any, args ...any) (found bool, err error) {
+	err = db.First(m, append([]any{query}, args...)...).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		} else {
+			err = liberr.Wrap(err)
+		}
+		return
+	}
+	found = true
+	return
+}
+
+//
+// Model builds a model.
+func (r *Analysis) Model() (m *model.Analysis) {
+	m = &model.Analysis{
+		Effort:   r.Effort,
+		Archived: r.Archived,
+		Summary:  model.JSON(r.Summary),
+		RuleSets: model.JSON(r.RuleSets),
+	}
+	for _, d := range r.Issues {
+		issue := model.Issue{
+			RuleSet:     d.RuleSet,
+			Rule:        d.Rule,
+			Name:        d.Name,
+			Description: d.Description,
+			Category:    d.Category,
+			Effort:      d.Effort,
+			Links:       model.JSON(d.Links),
+			Facts:       model.JSON(d.Facts),
+			Labels:      model.JSON(d.Labels),
+		}
+		for _, incident := range d.Incidents {
+			issue.Incidents = append(
+				issue.Incidents,
+				model.Incident{
+					File:     incident.File,
+					Line:     incident.Line,
+					Message:  incident.Message,
+					CodeSnip: incident.CodeSnip,
+					Facts:    model.JSON(incident.Facts),
+				})
+		}
+		m.Issues = append(m.Issues, issue)
+	}
+	for _, d := range r.Dependencies {
+		m.Dependencies = append(
+			m.Dependencies,
+			model.TechDependency{
+				Provider: d.Provider,
+				Name:     d.Name,
+				Version:  d.Version,
+				SHA:      d.SHA,
+				Indirect: d.Indirect,
+				Labels:   model.JSON(d.Labels),
+			})
+	}
+	return
+}
+
+//
+// stage extracts the bundle into the directory.
+// Returns the staged entries keyed by path.
+func stage(input io.Reader, dir string) (staged map[string]Entry, err error) {
+	staged = make(map[string]Entry)
+	reader := tar.NewReader()
+	err = reader.Walk(
+		input,
+		func(path string, size int64, r io.Reader) (err error) {
+			path = pathlib.Join("/", path)
+			entry := Entry{Path: path}
+			stagedPath := pathlib.Join(dir, path)
+			err = os.MkdirAll(pathlib.Dir(stagedPath), 0755)
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+			f, err := os.Create(stagedPath)
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+			defer func() {
+				_ = f.Close()
+			}()
+			h := sha256.New()
+			entry.Size, err = io.Copy(io.MultiWriter(f, h), r)
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+			entry.Digest = hex.EncodeToString(h.Sum(nil))
+			staged[path] = entry
+			return
+		})
+	if err != nil {
+		err = &Invalid{Reason: err.Error()}
+	}
+	return
+}
+
+//
+// validate the staged bundle.
+// Every entry listed in the manifest must be staged with a
+// matching size and digest. The format must be supported.
+func validate(dir string, staged map[string]Entry) (manifest Manifest, err error) {
+	b, err := os.ReadFile(pathlib.Join(dir, ManifestName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = &Invalid{Reason: "manifest not found."}
+			return
+		}
+		err = liberr.Wrap(err)
+		return
+	}
+	err = json.Unmarshal(b, &manifest)
+	if err != nil {
+		err = &Invalid{Reason: "manifest: " + err.Error()}
+		return
+	}
+	if manifest.Format != Format {
+		err = &Invalid{Reason: "format not supported."}
+		return
+	}
+	delete(staged, ManifestName)
+	for _, entry := range manifest.Entries {
+		found, matched := staged[entry.Path]
+		if !matched {
+			err = &Invalid{Reason: entry.Path + " not found."}
+			return
+		}
+		if found.Size != entry.Size || found.Digest != entry.Digest {
+			err = &Invalid{Reason: entry.Path + " checksum not matched."}
+			return
+		}
+		delete(staged, entry.Path)
+	}
+	for path := range staged {
+		err = &Invalid{Reason: path + " not in manifest."}
+		return
+	}
+	return
+}
+
+//
+// load the (staged) application documents.
+func load(dir string, manifest Manifest) (documents []Application, err error) {
+	for _, entry := range manifest.Entries {
+		if pathlib.Base(entry.Path) != DocumentName ||
+			pathlib.Dir(pathlib.Dir(entry.Path)) != ApplicationsRoot {
+			continue
+		}
+		var b []byte
+		b, err = os.ReadFile(pathlib.Join(dir, entry.Path))
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		d := Application{}
+		err = json.Unmarshal(b, &d)
+		if err != nil {
+			err = &Invalid{Reason: entry.Path + ": " + err.Error()}
+			return
+		}
+		if d.Name == "" {
+			err = &Invalid{Reason: entry.Path + ": name required."}
+			return
+		}
+		d.path = pathlib.Dir(entry.Path)
+		documents = append(documents, d)
+	}
+	sort.Slice(
+		documents,
+		func(i, j int) bool {
+			return documents[i].Name < documents[j].Name
+		})
+	return
+}
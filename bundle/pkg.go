@@ -0,0 +1,283 @@
+package bundle
+
+import (
+	"encoding/json"
+	"github.com/jortel/go-utils/logr"
+	"github.com/konveyor/tackle2-hub/settings"
+	"time"
+)
+
+var (
+	Settings = &settings.Settings
+	Log      = logr.WithName("bundle")
+)
+
+//
+// Format (version) of the bundle.
+const Format = 1
+
+//
+// Archive layout.
+const (
+	ManifestName     = "/manifest.json"
+	ApplicationsRoot = "/applications"
+	DocumentName     = "application.json"
+	ContentRoot      = "bucket"
+)
+
+//
+// Kinds (resolved).
+const (
+	KindApplication      = "application"
+	KindBusinessService  = "businessservice"
+	KindIdentity         = "identity"
+	KindQuestionnaire    = "questionnaire"
+	KindStakeholder      = "stakeholder"
+	KindStakeholderGroup = "stakeholdergroup"
+	KindTag              = "tag"
+	KindTagCategory      = "tagcategory"
+)
+
+//
+// Invalid reports an invalid bundle.
+type Invalid struct {
+	Reason string
+}
+
+func (e *Invalid) Error() string {
+	return "Bundle invalid: " + e.Reason
+}
+
+func (e *Invalid) Is(err error) (matched bool) {
+	_, matched = err.(*Invalid)
+	return
+}
+
+//
+// Entry is an archived file.
+type Entry struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Digest string `json:"digest"`
+}
+
+//
+// Manifest describes the bundle.
+type Manifest struct {
+	// Format of the bundle.
+	Format int `json:"format"`
+	// Version of the (migrated) database exported.
+	Version      int       `json:"version"`
+	Created      time.Time `json:"created"`
+	Applications []string  `json:"applications"`
+	Entries      []Entry   `json:"entries"`
+}
+
+//
+// Ref references a resource by name or UUID.
+type Ref struct {
+	UUID string `json:"uuid,omitempty"`
+	Name string `json:"name"`
+}
+
+//
+// StakeholderRef references a stakeholder by email or name.
+type StakeholderRef struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+//
+// IdentityRef references an identity by name and kind.
+// Credentials are never exported.
+type IdentityRef struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+//
+// TagCategory (portable).
+type TagCategory struct {
+	Ref
+	Rank  uint   `json:"rank,omitempty"`
+	Color string `json:"color,omitempty"`
+}
+
+//
+// Tag (portable) applied to the application.
+type Tag struct {
+	Ref
+	Category TagCategory `json:"category"`
+	Source   string      `json:"source,omitempty"`
+}
+
+//
+// Fact (portable).
+type Fact struct {
+	Key    string          `json:"key"`
+	Source string          `json:"source,omitempty"`
+	Value  json.RawMessage `json:"value"`
+}
+
+//
+// Assessment (portable).
+type Assessment struct {
+	Questionnaire     Ref              `json:"questionnaire"`
+	Sections          json.RawMessage  `json:"sections,omitempty"`
+	Thresholds        json.RawMessage  `json:"thresholds,omitempty"`
+	RiskMessages      json.RawMessage  `json:"riskMessages,omitempty"`
+	Stakeholders      []StakeholderRef `json:"stakeholders,omitempty"`
+	StakeholderGroups []Ref            `json:"stakeholderGroups,omitempty"`
+}
+
+//
+// Review (portable).
+type Review struct {
+	BusinessCriticality uint   `json:"businessCriticality"`
+	EffortEstimate      string `json:"effortEstimate"`
+	ProposedAction      string `json:"proposedAction"`
+	WorkPriority        uint   `json:"workPriority"`
+	Comments            string `json:"comments,omitempty"`
+}
+
+//
+// Incident (portable).
+type Incident struct {
+	File     string          `json:"file"`
+	Line     int             `json:"line"`
+	Message  string          `json:"message"`
+	CodeSnip string          `json:"codeSnip,omitempty"`
+	Facts    json.RawMessage `json:"facts,omitempty"`
+}
+
+//
+// Issue (portable).
+type Issue struct {
+	RuleSet     string          `json:"ruleset"`
+	Rule        string          `json:"rule"`
+	Name        string          `json:"name,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Category    string          `json:"category"`
+	Effort      int             `json:"effort"`
+	Links       json.RawMessage `json:"links,omitempty"`
+	Facts       json.RawMessage `json:"facts,omitempty"`
+	Labels      json.RawMessage `json:"labels,omitempty"`
+	Incidents   []Incident      `json:"incidents,omitempty"`
+}
+
+//
+// Dependency (portable) tech dependency.
+type Dependency struct {
+	Provider string          `json:"provider,omitempty"`
+	Name     string          `json:"name"`
+	Version  string          `json:"version,omitempty"`
+	SHA      string          `json:"sha,omitempty"`
+	Indirect bool            `json:"indirect,omitempty"`
+	Labels   json.RawMessage `json:"labels,omitempty"`
+}
+
+//
+// Analysis (portable).
+type Analysis struct {
+	Effort       int             `json:"effort"`
+	Archived     bool            `json:"archived,omitempty"`
+	Summary      json.RawMessage `json:"summary,omitempty"`
+	RuleSets     json.RawMessage `json:"ruleSets,omitempty"`
+	Issues       []Issue         `json:"issues,omitempty"`
+	Dependencies []Dependency    `json:"dependencies,omitempty"`
+}
+
+//
+// Application (portable) document.
+// Related resources are referenced by name (or UUID) and
+// resolved when imported. The ID is the ID in the exporting hub.
+type Application struct {
+	ID              uint             `json:"id"`
+	Name            string           `json:"name"`
+	Description     string           `json:"description,omitempty"`
+	Comments        string           `json:"comments,omitempty"`
+	Binary          string           `json:"binary,omitempty"`
+	Repository      json.RawMessage  `json:"repository,omitempty"`
+	BusinessService *Ref             `json:"businessService,omitempty"`
+	Owner           *StakeholderRef  `json:"owner,omitempty"`
+	Contributors    []StakeholderRef `json:"contributors,omitempty"`
+	Tags            []Tag            `json:"tags,omitempty"`
+	Facts           []Fact           `json:"facts,omitempty"`
+	Identities      []IdentityRef    `json:"identities,omitempty"`
+	Assessments     []Assessment     `json:"assessments,omitempty"`
+	Review          *Review          `json:"review,omitempty"`
+	Analyses        []Analysis       `json:"analyses,omitempty"`
+	// path (staged).
+	path string
+}
+
+//
+// Imported application.
+type Imported struct {
+	Name string
+	// Source ID (exporting hub).
+	Source uint
+	// ID assigned.
+	ID uint
+}
+
+//
+// Resolved resource created by the import.
+type Resolved struct {
+	Kind string
+	Name string
+	ID   uint
+}
+
+//
+// Conflict reported by the import.
+type Conflict struct {
+	Application string
+	Kind        string
+	Name        string
+	Reason      string
+}
+
+//
+// Report of an import.
+type Report struct {
+	Applications []Imported
+	Created      []Resolved
+	Conflicts    []Conflict
+}
+
+//
+// conflict reports a conflict.
+func (r *Report) conflict(application, kind, name, reason string) {
+	r.Conflicts = append(
+		r.Conflicts,
+		Conflict{
+			Application: application,
+			Kind:        kind,
+			Name:        name,
+			Reason:      reason,
+		})
+}
+
+//
+// created reports a created resource.
+func (r *Report) created(kind, name string, id uint) {
+	r.Created = append(
+		r.Created,
+		Resolved{
+			Kind: kind,
+			Name: name,
+			ID:   id,
+		})
+}
+
+//
+// raw returns the (model) json as a raw message.
+// Empty (null) documents are omitted.
+func raw(b []byte) (m json.RawMessage) {
+	if len(b) == 0 || string(b) == "null" {
+		return
+	}
+	m = json.RawMessage(b)
+	return
+}
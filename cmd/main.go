@@ -5,25 +5,34 @@ import (
 	"github.com/gin-gonic/gin"
 	liberr "github.com/jortel/go-utils/error"
 	"github.com/jortel/go-utils/logr"
+	"github.com/konveyor/tackle2-hub/advisory"
 	"github.com/konveyor/tackle2-hub/api"
 	"github.com/konveyor/tackle2-hub/auth"
+	"github.com/konveyor/tackle2-hub/backup"
 	"github.com/konveyor/tackle2-hub/controller"
+	"github.com/konveyor/tackle2-hub/credential"
 	"github.com/konveyor/tackle2-hub/database"
+	"github.com/konveyor/tackle2-hub/event"
 	"github.com/konveyor/tackle2-hub/importer"
 	"github.com/konveyor/tackle2-hub/k8s"
 	crd "github.com/konveyor/tackle2-hub/k8s/api"
 	"github.com/konveyor/tackle2-hub/metrics"
 	"github.com/konveyor/tackle2-hub/migration"
 	"github.com/konveyor/tackle2-hub/reaper"
+	"github.com/konveyor/tackle2-hub/secret"
 	"github.com/konveyor/tackle2-hub/seed"
 	"github.com/konveyor/tackle2-hub/settings"
+	"github.com/konveyor/tackle2-hub/storage"
+	"github.com/konveyor/tackle2-hub/tar"
 	"github.com/konveyor/tackle2-hub/task"
 	"github.com/konveyor/tackle2-hub/tracker"
 	"gorm.io/gorm"
 	"k8s.io/client-go/kubernetes/scheme"
 	"net/http"
+	"os"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"strings"
 	"syscall"
 )
 
@@ -50,6 +59,10 @@ func Setup() (db *gorm.DB, err error) {
 	if err != nil {
 		return
 	}
+	err = secret.LoadSalt(db)
+	if err != nil {
+		return
+	}
 	return
 }
 
@@ -86,6 +99,58 @@ func addonManager(db *gorm.DB) (mgr manager.Manager, err error) {
 	return
 }
 
+//
+// command runs a (CLI) command:
+//   backup <path>  - write a backup archive.
+//   restore <path> - restore a backup archive.
+// The hub must not be running when restored.
+func command(args []string) (err error) {
+	if len(args) != 2 {
+		err = liberr.New("usage: hub backup|restore <path>")
+		return
+	}
+	path := args[1]
+	switch args[0] {
+	case "backup":
+		var db *gorm.DB
+		db, err = database.Open(true)
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = database.Close(db)
+		}()
+		var f *os.File
+		f, err = os.Create(path)
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		compression := tar.Gzip
+		if strings.HasSuffix(path, tar.Extension(tar.Zstd)) {
+			compression = tar.Zstd
+		}
+		_, err = backup.Backup(db, f, compression)
+	case "restore":
+		var f *os.File
+		f, err = os.Open(path)
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		_, err = backup.Restore(f)
+	default:
+		err = liberr.New("command: " + args[0] + " not supported.")
+	}
+	return
+}
+
 //
 // main.
 func main() {
@@ -98,6 +163,21 @@ func main() {
 	}()
 	syscall.Umask(0)
 	//
+	// Storage.
+	storage.Store, err = storage.New()
+	if err != nil {
+		panic(err)
+	}
+	//
+	// Command (backup|restore).
+	if len(os.Args) > 1 {
+		err = command(os.Args[1:])
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+	//
 	// Model
 	db, err := Setup()
 	if err != nil {
@@ -133,27 +213,76 @@ func main() {
 		return
 	}
 	//
+	// Identity secrets.
+	secret.Store, err = secret.New(client)
+	if err != nil {
+		return
+	}
+	if Settings.Encryption.Rotate {
+		err = db.Transaction(func(tx *gorm.DB) (err error) {
+			_, err = secret.Rotate(tx)
+			return
+		})
+		if err != nil {
+			return
+		}
+	}
+	//
 	// Auth
 	if settings.Settings.Auth.Required {
-		r := auth.NewReconciler(
-			settings.Settings.Auth.Keycloak.Host,
-			settings.Settings.Auth.Keycloak.Realm,
-			settings.Settings.Auth.Keycloak.ClientID,
-			settings.Settings.Auth.Keycloak.ClientSecret,
-			settings.Settings.Auth.Keycloak.Admin.User,
-			settings.Settings.Auth.Keycloak.Admin.Pass,
-			settings.Settings.Auth.Keycloak.Admin.Realm,
-		)
-		err = r.Reconcile()
+		auth.Hub = &auth.Builtin{}
+		var roles []auth.Role
+		roles, err = auth.LoadRoles(settings.Settings.Auth.RolePath)
 		if err != nil {
 			return
 		}
-		auth.Hub = &auth.Builtin{}
-		auth.Remote = auth.NewKeycloak(
-			settings.Settings.Auth.Keycloak.Host,
-			settings.Settings.Auth.Keycloak.Realm,
-		)
+		switch settings.Settings.Auth.Provider {
+		case settings.AuthOIDC:
+			err = auth.RBAC.Seed(db, roles, nil)
+			if err != nil {
+				return
+			}
+			auth.Remote = auth.NewOIDC(auth.RBAC)
+		default:
+			var users []auth.User
+			users, err = auth.LoadUsers(settings.Settings.Auth.UserPath)
+			if err != nil {
+				return
+			}
+			err = auth.RBAC.Seed(db, roles, users)
+			if err != nil {
+				return
+			}
+			r := auth.NewReconciler(
+				settings.Settings.Auth.Keycloak.Host,
+				settings.Settings.Auth.Keycloak.Realm,
+				settings.Settings.Auth.Keycloak.ClientID,
+				settings.Settings.Auth.Keycloak.ClientSecret,
+				settings.Settings.Auth.Keycloak.Admin.User,
+				settings.Settings.Auth.Keycloak.Admin.Pass,
+				settings.Settings.Auth.Keycloak.Admin.Realm,
+			)
+			err = r.Reconcile()
+			if err != nil {
+				return
+			}
+			auth.RBAC.Realm = &r
+			auth.Remote = auth.NewKeycloak(
+				settings.Settings.Auth.Keycloak.Host,
+				settings.Settings.Auth.Keycloak.Realm,
+			)
+		}
+	}
+	//
+	// Events
+	err = event.Register(db)
+	if err != nil {
+		return
+	}
+	webhookManager := event.Manager{
+		DB: db,
 	}
+	webhookManager.Run(context.Background())
 	//
 	// Task
 	taskManager := task.Manager{
@@ -181,6 +310,18 @@ func main() {
 	}
 	trackerManager.Run(context.Background())
 	//
+	// Credential validation.
+	credentialManager := credential.Manager{
+		DB: db,
+	}
+	credentialManager.Run(context.Background())
+	//
+	// Vulnerability advisories.
+	advisoryManager := advisory.Manager{
+		DB: db,
+	}
+	advisoryManager.Run(context.Background())
+	//
 	// Metrics
 	if Settings.Metrics.Enabled {
 		log.Info("Serving Prometheus metrics", "port", Settings.Metrics.Port)
@@ -194,15 +335,18 @@ func main() {
 		metricsManager.Run(context.Background())
 	}
 	// Web
+	api.Limiter = api.NewRateLimiter()
 	router := gin.Default()
 	router.Use(api.Render())
-	router.Use(api.ErrorHandler())
 	router.Use(
 		func(ctx *gin.Context) {
 			rtx := api.WithContext(ctx)
 			rtx.DB = db
 			rtx.Client = client
 		})
+	router.Use(api.AuditLog(db))
+	router.Use(api.Events())
+	router.Use(api.ErrorHandler())
 	for _, h := range api.All() {
 		h.AddRoutes(router)
 	}
@@ -0,0 +1,203 @@
+package credential
+
+import (
+	"context"
+	"encoding/json"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/jortel/go-utils/logr"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/secret"
+	"gorm.io/gorm"
+	"time"
+)
+
+var Log = logr.WithName("credential")
+
+//
+// Unit polling interval.
+const Unit = time.Second * 10
+
+//
+// validation requested.
+var requested = make(chan struct{}, 1)
+
+//
+// Request notifies the manager that validation
+// has been requested (pending).
+func Request() {
+	select {
+	case requested <- struct{}{}:
+	default:
+	}
+}
+
+//
+// Manager validates the credentials of identities with
+// validation pending. Identities are validated one at a time
+// against the repository of the applications referencing
+// the identity.
+type Manager struct {
+	// DB
+	DB *gorm.DB
+}
+
+//
+// Run the manager.
+func (m *Manager) Run(ctx context.Context) {
+	go func() {
+		Log.Info("Started.")
+		defer Log.Info("Died.")
+		err := m.recover()
+		if err != nil {
+			Log.Error(err, "")
+		}
+		for {
+			err = m.validate(ctx)
+			if err != nil {
+				Log.Error(err, "")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-requested:
+			case <-time.After(Unit):
+			}
+		}
+	}()
+}
+
+//
+// recover re-queues validation interrupted by a restart.
+func (m *Manager) recover() (err error) {
+	db := m.DB.Model(&model.Identity{})
+	db = db.Where("VerifyState", Running)
+	err = db.UpdateColumn("VerifyState", Pending).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// validate identities with validation pending.
+func (m *Manager) validate(ctx context.Context) (err error) {
+	var list []model.Identity
+	db := m.DB.Where("VerifyState", Pending)
+	db = db.Order("ID")
+	err = db.Find(&list).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for i := range list {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		identity := &list[i]
+		err = m.validateOne(identity)
+		if err != nil {
+			Log.Error(err, "", "id", identity.ID)
+			err = nil
+		}
+	}
+	return
+}
+
+//
+// validateOne validates the identity and records the result.
+// The result is not recorded when the identity was updated
+// during validation.
+func (m *Manager) validateOne(identity *model.Identity) (err error) {
+	updated, err := m.update(identity.ID, Pending, Result{State: Running})
+	if err != nil || !updated {
+		return
+	}
+	result := m.validateWith(identity)
+	_, err = m.update(identity.ID, Running, result)
+	if err != nil {
+		return
+	}
+	Log.Info(
+		"Identity validated.",
+		"id",
+		identity.ID,
+		"state",
+		result.State)
+	return
+}
+
+//
+// validateWith validates the identity against the repository
+// of the applications referencing the identity.
+func (m *Manager) validateWith(identity *model.Identity) (r Result) {
+	repository, err := FindRepository(m.DB, identity.ID)
+	if err != nil {
+		r = Result{State: Failed, Message: err.Error()}
+		return
+	}
+	if repository.URL == "" && identity.Kind != Maven {
+		r = Result{
+			State:   Unsupported,
+			Message: "Not referenced by an application with a repository.",
+		}
+		return
+	}
+	err = secret.Decrypt(identity)
+	if err != nil {
+		r = Result{State: Failed, Message: "Decrypt failed."}
+		return
+	}
+	r = Validate(identity, repository)
+	return
+}
+
+//
+// update the identity verification when in the expected state.
+func (m *Manager) update(id uint, state string, result Result) (updated bool, err error) {
+	fields := map[string]interface{}{
+		"VerifyState":   result.State,
+		"VerifyMessage": result.Message,
+	}
+	if result.State != Running {
+		fields["LastVerified"] = time.Now()
+	}
+	db := m.DB.Model(&model.Identity{})
+	db = db.Where("ID", id)
+	db = db.Where("VerifyState", state)
+	db = db.UpdateColumns(fields)
+	err = db.Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	updated = db.RowsAffected > 0
+	return
+}
+
+//
+// FindRepository returns the repository of the first application
+// (with a repository) referencing the identity.
+func FindRepository(db *gorm.DB, id uint) (r Repository, err error) {
+	var list []model.Application
+	db = db.Select("ID", "Repository")
+	db = db.Where("ID IN (SELECT ApplicationID FROM ApplicationIdentity WHERE IdentityID = ?)", id)
+	db = db.Order("ID")
+	err = db.Find(&list).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for _, m := range list {
+		if len(m.Repository) == 0 {
+			continue
+		}
+		_ = json.Unmarshal(m.Repository, &r)
+		if r.URL != "" {
+			break
+		}
+	}
+	return
+}
@@ -0,0 +1,230 @@
+package credential
+
+import (
+	"encoding/xml"
+	"github.com/konveyor/tackle2-hub/model"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//
+// Identity and repository kinds.
+const (
+	Git        = "git"
+	Svn        = "svn"
+	Subversion = "subversion"
+	Maven      = "maven"
+	Source     = "source"
+)
+
+//
+// Verification states.
+const (
+	Pending     = "Pending"
+	Running     = "Running"
+	Verified    = "Verified"
+	Failed      = "Failed"
+	Unsupported = "Unsupported"
+)
+
+//
+// Timeout for requests to the repository.
+var Timeout = 30 * time.Second
+
+//
+// Result of validation.
+type Result struct {
+	State   string
+	Message string
+}
+
+//
+// Repository to validate against.
+type Repository struct {
+	Kind string
+	URL  string
+}
+
+//
+// Validator validates credentials against a repository.
+type Validator interface {
+	// Validate the (decrypted) identity.
+	Validate(m *model.Identity, repository Repository) (r Result)
+}
+
+//
+// New returns a validator for the identity (kind).
+// Source identities are validated based on the repository kind.
+// Returns nil when the kind is not supported.
+func New(m *model.Identity, repository Repository) (v Validator) {
+	kind := m.Kind
+	if kind == Source {
+		kind = repository.Kind
+	}
+	switch kind {
+	case Git, "":
+		v = &GitValidator{}
+	case Svn, Subversion:
+		v = &SvnValidator{}
+	case Maven:
+		v = &MavenValidator{}
+	}
+	return
+}
+
+//
+// Validate the (decrypted) identity against the repository.
+func Validate(m *model.Identity, repository Repository) (r Result) {
+	v := New(m, repository)
+	if v == nil {
+		r = Result{
+			State:   Unsupported,
+			Message: "Identity kind: " + m.Kind + " not supported.",
+		}
+		return
+	}
+	r = v.Validate(m, repository)
+	return
+}
+
+//
+// GitValidator validates git credentials.
+// Only (http|https) repositories are supported. The credentials are
+// validated by requesting the (smart HTTP) reference advertisement.
+type GitValidator struct {
+}
+
+//
+// Validate the credentials.
+func (v *GitValidator) Validate(m *model.Identity, repository Repository) (r Result) {
+	u, r, ok := httpURL(repository.URL)
+	if !ok {
+		return
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/info/refs"
+	u.RawQuery = "service=git-upload-pack"
+	r = get(m, u.String())
+	return
+}
+
+//
+// SvnValidator validates subversion credentials.
+// Only (http|https) repositories are supported.
+type SvnValidator struct {
+}
+
+//
+// Validate the credentials.
+func (v *SvnValidator) Validate(m *model.Identity, repository Repository) (r Result) {
+	u, r, ok := httpURL(repository.URL)
+	if !ok {
+		return
+	}
+	r = get(m, u.String())
+	return
+}
+
+//
+// MavenValidator validates maven credentials.
+// The settings must be a well-formed settings.xml. When a repository
+// is specified, the identity user and password are validated
+// against the repository.
+type MavenValidator struct {
+}
+
+//
+// Validate the credentials.
+func (v *MavenValidator) Validate(m *model.Identity, repository Repository) (r Result) {
+	if m.Settings != "" {
+		settings := struct {
+			XMLName xml.Name `xml:"settings"`
+		}{}
+		err := xml.Unmarshal([]byte(m.Settings), &settings)
+		if err != nil {
+			r = Result{
+				State:   Failed,
+				Message: "Settings not valid: " + err.Error(),
+			}
+			return
+		}
+	}
+	if repository.URL == "" {
+		r = Result{
+			State:   Verified,
+			Message: "Settings valid.",
+		}
+		return
+	}
+	u, r, ok := httpURL(repository.URL)
+	if !ok {
+		return
+	}
+	r = get(m, u.String())
+	return
+}
+
+//
+// httpURL parses the repository URL.
+// Returns ok=false and the result when not (http|https).
+func httpURL(repository string) (u *url.URL, r Result, ok bool) {
+	u, err := url.Parse(repository)
+	if err != nil || repository == "" {
+		r = Result{
+			State:   Failed,
+			Message: "Repository URL not valid.",
+		}
+		return
+	}
+	switch u.Scheme {
+	case "http", "https":
+		ok = true
+	default:
+		r = Result{
+			State:   Unsupported,
+			Message: "Repository URL scheme: " + u.Scheme + " not supported.",
+		}
+	}
+	return
+}
+
+//
+// get the URL using the identity (basic) credentials.
+func get(m *model.Identity, u string) (r Result) {
+	request, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		r = Result{State: Failed, Message: err.Error()}
+		return
+	}
+	if m.User != "" || m.Password != "" {
+		request.SetBasicAuth(m.User, m.Password)
+	}
+	client := &http.Client{Timeout: Timeout}
+	response, err := client.Do(request)
+	if err != nil {
+		r = Result{State: Failed, Message: err.Error()}
+		return
+	}
+	_ = response.Body.Close()
+	switch {
+	case response.StatusCode == http.StatusUnauthorized,
+		response.StatusCode == http.StatusForbidden:
+		r = Result{
+			State:   Failed,
+			Message: "Credentials rejected: " + strconv.Itoa(response.StatusCode),
+		}
+	case response.StatusCode >= http.StatusBadRequest:
+		r = Result{
+			State:   Failed,
+			Message: "Repository request failed: " + strconv.Itoa(response.StatusCode),
+		}
+	default:
+		r = Result{
+			State:   Verified,
+			Message: "Credentials accepted.",
+		}
+	}
+	return
+}
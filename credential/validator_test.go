@@ -0,0 +1,138 @@
+package credential
+
+import (
+	"context"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/secret"
+	"github.com/konveyor/tackle2-hub/settings"
+	"github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, password, _ := r.BasicAuth()
+			if user != "jeff" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			switch r.URL.Path {
+			case "/repo.git/info/refs":
+				g.Expect(r.URL.RawQuery).To(gomega.Equal("service=git-upload-pack"))
+			case "/svn/repo":
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	defer server.Close()
+	identity := &model.Identity{Kind: Source, User: "jeff", Password: "secret"}
+	//
+	// Git.
+	r := Validate(identity, Repository{Kind: Git, URL: server.URL + "/repo.git"})
+	g.Expect(r.State).To(gomega.Equal(Verified))
+	//
+	// Subversion.
+	r = Validate(identity, Repository{Kind: Subversion, URL: server.URL + "/svn/repo"})
+	g.Expect(r.State).To(gomega.Equal(Verified))
+	//
+	// Rejected.
+	r = Validate(
+		&model.Identity{Kind: Source, User: "jeff", Password: "wrong"},
+		Repository{Kind: Git, URL: server.URL + "/repo.git"})
+	g.Expect(r.State).To(gomega.Equal(Failed))
+	//
+	// SSH.
+	r = Validate(identity, Repository{Kind: Git, URL: "ssh://git@example.com/repo.git"})
+	g.Expect(r.State).To(gomega.Equal(Unsupported))
+	//
+	// Maven.
+	maven := &model.Identity{Kind: Maven, Settings: "<settings><servers/></settings>"}
+	r = Validate(maven, Repository{})
+	g.Expect(r.State).To(gomega.Equal(Verified))
+	maven.Settings = "<settings>"
+	r = Validate(maven, Repository{})
+	g.Expect(r.State).To(gomega.Equal(Failed))
+	//
+	// Not supported.
+	r = Validate(&model.Identity{Kind: "proxy"}, Repository{URL: server.URL})
+	g.Expect(r.State).To(gomega.Equal(Unsupported))
+}
+
+func TestManager(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, password, _ := r.BasicAuth()
+			if user != "jeff" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+	defer server.Close()
+	db, err := gorm.Open(
+		sqlite.Open(path.Join(t.TempDir(), "test.db")),
+		&gorm.Config{
+			NamingStrategy: &schema.NamingStrategy{
+				SingularTable: true,
+				NoLowerCase:   true,
+			},
+		})
+	g.Expect(err).To(gomega.BeNil())
+	err = db.AutoMigrate(&model.Identity{}, &model.Application{})
+	g.Expect(err).To(gomega.BeNil())
+	saved := settings.Settings.Hub.Bucket.Path
+	settings.Settings.Hub.Bucket.Path = t.TempDir()
+	defer func() {
+		settings.Settings.Hub.Bucket.Path = saved
+	}()
+	//
+	// Referenced by an application.
+	referenced := &model.Identity{Kind: Source, Name: "a", User: "jeff", Password: "secret"}
+	err = secret.Encrypt(referenced, &model.Identity{})
+	g.Expect(err).To(gomega.BeNil())
+	err = db.Create(referenced).Error
+	g.Expect(err).To(gomega.BeNil())
+	err = db.Create(
+		&model.Application{
+			Name:       "a",
+			Repository: []byte(`{"kind":"svn","url":"` + server.URL + `"}`),
+			Identities: []model.Identity{*referenced},
+		}).Error
+	g.Expect(err).To(gomega.BeNil())
+	//
+	// Not referenced.
+	orphan := &model.Identity{Kind: Source, Name: "b", User: "jeff", Password: "secret"}
+	err = db.Create(orphan).Error
+	g.Expect(err).To(gomega.BeNil())
+	//
+	// Validated when pending.
+	err = db.Model(&model.Identity{}).Where("ID IN ?", []uint{referenced.ID, orphan.ID}).
+		UpdateColumn("VerifyState", Pending).Error
+	g.Expect(err).To(gomega.BeNil())
+	m := Manager{DB: db}
+	err = m.validate(context.Background())
+	g.Expect(err).To(gomega.BeNil())
+	err = db.First(referenced, referenced.ID).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(referenced.VerifyState).To(gomega.Equal(Verified))
+	g.Expect(referenced.LastVerified).ToNot(gomega.BeNil())
+	err = db.First(orphan, orphan.ID).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(orphan.VerifyState).To(gomega.Equal(Unsupported))
+	//
+	// Interrupted validation re-queued.
+	err = db.Model(referenced).UpdateColumn("VerifyState", Running).Error
+	g.Expect(err).To(gomega.BeNil())
+	err = m.recover()
+	g.Expect(err).To(gomega.BeNil())
+	err = db.First(referenced, referenced.ID).Error
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(referenced.VerifyState).To(gomega.Equal(Pending))
+}
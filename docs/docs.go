@@ -115,6 +115,72 @@ const docTemplate = `{
                 }
             }
         },
+        "/advisories": {
+            "get": {
+                "description": "List advisories.\nfilters:\n- key\n- severity",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "advisories"
+                ],
+                "summary": "List advisories.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Advisory"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/advisories/refresh": {
+            "post": {
+                "description": "Request the advisory feed be reloaded and all\ndependencies re-matched. Performed asynchronously.",
+                "tags": [
+                    "advisories"
+                ],
+                "summary": "Refresh advisories.",
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    }
+                }
+            }
+        },
+        "/advisories/{id}": {
+            "get": {
+                "description": "Get an advisory by ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "advisories"
+                ],
+                "summary": "Get an advisory by ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Advisory ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Advisory"
+                        }
+                    }
+                }
+            }
+        },
         "/analyses": {
             "get": {
                 "description": "List analyses for an application.\nResources do not include relations.",
@@ -140,7 +206,7 @@ const docTemplate = `{
         },
         "/analyses/dependencies": {
             "get": {
-                "description": "Each report collates dependencies by name and SHA.\nfilters:\n- provider\n- name\n- version\n- sha\n- indirect\n- labels\n- application.id\n- application.name\n- businessService.id\n- businessService.name\n- tag.id\nsort:\n- provider\n- name\n- labels",
+                "description": "Each report collates dependencies by name and SHA.\nfilters:\n- provider\n- name\n- version\n- sha\n- indirect\n- labels\n- application.id\n- application.name\n- businessService.id\n- businessService.name\n- tag.id\nsort:\n- provider\n- name\n- labels\n- advisories",
                 "produces": [
                     "application/json"
                 ],
@@ -245,9 +311,32 @@ const docTemplate = `{
                 }
             }
         },
+        "/analyses/report/advisories": {
+            "get": {
+                "description": "Each report collates the applications and dependencies\n(of the latest analyses) matched to an advisory.\nfilters:\n- key\n- severity\n- applications\n- dependencies\n- application.id\n- application.name\n- businessService.id\n- businessService.name\n- tag.id\nsort:\n- key\n- severity\n- applications\n- dependencies",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "advisories"
+                ],
+                "summary": "List advisory reports.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.AdvisoryReport"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/analyses/report/applications": {
             "get": {
-                "description": "List application reports.\nfilters:\n- id\n- name\n- description\n- businessService\n- provider\n- name\n- version\n- sha\n- indirect\n- dep.provider\n- dep.name\n- dep.version\n- dep.sha\n- dep.indirect\n- dep.labels\n- application.id\n- application.name\n- businessService.id\n- businessService.name\nsort:\n- name\n- description\n- businessService\n- provider\n- name\n- version\n- sha\n- indirect",
+                "description": "List application reports.\nfilters:\n- id\n- name\n- description\n- businessService\n- provider\n- name\n- version\n- sha\n- indirect\n- dep.provider\n- dep.name\n- dep.version\n- dep.sha\n- dep.indirect\n- dep.labels\n- application.id\n- application.name\n- businessService.id\n- businessService.name\nsort:\n- name\n- description\n- businessService\n- provider\n- name\n- version\n- sha\n- indirect\n- advisories",
                 "produces": [
                     "application/json"
                 ],
@@ -332,6 +421,23 @@ const docTemplate = `{
                 }
             }
         },
+        "/analyses/report/portfolio": {
+            "get": {
+                "description": "Get the (static) report containing the latest analysis\nof each of the selected applications.\nfilters:\n- application.id\n- application.name\n- businessService.id\n- businessService.name\n- tag.id",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "analyses"
+                ],
+                "summary": "Get the portfolio (static) report.",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
         "/analyses/report/rules": {
             "get": {
                 "description": "Each report collates issues by ruleset/rule.\nfilters:\n- ruleset\n- rule\n- category\n- effort\n- labels\n- applications\n- application.id\n- application.name\n- businessService.id\n- businessService.name\n- tag.id\nsort:\n- ruleset\n- rule\n- category\n- effort\n- applications",
@@ -407,7 +513,7 @@ const docTemplate = `{
         },
         "/application/{id}/analyses": {
             "post": {
-                "description": "Create an analysis.\nForm fields:\n- file: file that contains the api.Analysis resource.\n- issues: file that multiple api.Issue resources.\n- dependencies: file that multiple api.TechDependency resources.",
+                "description": "Create an analysis.\nForm fields:\n- file: file that contains the api.Analysis resource.\n- issues: file that multiple api.Issue resources.\n- dependencies: file that multiple api.TechDependency resources.\nMay be an SBOM when the encoding is CycloneDX or SPDX (JSON).\nThe analysis references the bucket snapshot it ran against. When not\nspecified, the application bucket is snapshot when created by a task and\nautomatic snapshots (BUCKET_SNAPSHOT) are enabled.\nWhen created by a task, the ruleSet revisions pinned by the task are recorded.",
                 "produces": [
                     "application/json"
                 ],
@@ -434,12 +540,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/application/{id}/analysis/dependencies": {
+        "/application/{id}/analysis/advisories": {
             "get": {
-                "description": "List application dependencies.\nfilters:\n- name\n- version\n- sha\n- indirect\n- labels",
+                "description": "List advisories matched to the dependencies of\nthe latest analysis. One report per advisory and dependency.\nfilters:\n- key\n- severity\n- dep.provider\n- dep.name\n- dep.version\n- dep.indirect",
                 "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "advisories"
+                ],
+                "summary": "List application advisories.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.AppAdvisoryReport"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/application/{id}/analysis/dependencies": {
+            "get": {
+                "description": "List application dependencies.\nfilters:\n- name\n- version\n- sha\n- indirect\n- labels\nAn SBOM is returned when CycloneDX or SPDX (JSON) is accepted.",
+                "produces": [
+                    "application/json",
+                    "application/vnd.cyclonedx+json",
+                    "application/spdx+json"
+                ],
                 "tags": [
                     "dependencies"
                 ],
@@ -464,6 +604,36 @@ const docTemplate = `{
                         }
                     }
                 }
+            },
+            "post": {
+                "description": "Create an analysis with dependencies imported from an SBOM.\nIntended for applications analyzed elsewhere. Prior analyses are archived.\nForm fields:\n- file: CycloneDX or SPDX (JSON) document. The encoding is\ndetermined by the part Content-Type.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dependencies"
+                ],
+                "summary": "Import application dependencies.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.Analysis"
+                        }
+                    }
+                }
             }
         },
         "/application/{id}/analysis/issues": {
@@ -500,7 +670,7 @@ const docTemplate = `{
         },
         "/applications": {
             "get": {
-                "description": "List all applications.",
+                "description": "List all applications.\nWhen ownership is enforced, only owned applications are listed.",
                 "produces": [
                     "application/json"
                 ],
@@ -787,7 +957,7 @@ const docTemplate = `{
         },
         "/applications/{id}/bucket/{wildcard}": {
             "get": {
-                "description": "Get bucket content by ID and path.\nReturns index.html for directories when Accept=text/html else a tarball.\n?filter=glob supports directory content filtering.",
+                "description": "Get bucket content by ID and path.\nReturns index.html for directories when Accept=text/html else a tarball.\nReturns the manifest for directories when ?manifest=true.\nThe tarball compression (gzip|zstd) is negotiated using the X-Compression header.\nFiles support (single) byte ranges.\n?filter=glob supports directory content filtering.",
                 "produces": [
                     "application/octet-stream"
                 ],
@@ -815,6 +985,12 @@ const docTemplate = `{
                         "description": "Filter",
                         "name": "filter",
                         "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Manifest",
+                        "name": "manifest",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -824,7 +1000,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Upload bucket content by ID and path (handles both [post] and [put] requests).",
+                "description": "Upload bucket content by ID and path (handles both [post] and [put] requests).\nX-Directory=expand replaces the directory; X-Directory=merge writes into the directory.\nThe bucket is snapshot before it is first written by a task.",
                 "produces": [
                     "application/json"
                 ],
@@ -886,6 +1062,32 @@ const docTemplate = `{
                 }
             }
         },
+        "/applications/{id}/bundle": {
+            "get": {
+                "description": "Export an application bundle (tarball). The bundle is a portable archive\nof the application with: tags, facts, identity (references), assessments,\nreview, analyses and bucket content. Related resources are referenced by name\n(or UUID). Identity credentials are never exported.\nThe compression (gzip|zstd) is negotiated using the X-Compression header.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "bundles"
+                ],
+                "summary": "Export an application bundle.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
         "/applications/{id}/facts": {
             "post": {
                 "description": "Create a fact.",
@@ -1104,48 +1306,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/applications/{id}/stakeholders": {
-            "patch": {
-                "description": "Update the owner and contributors of an Application.",
-                "tags": [
-                    "applications"
-                ],
-                "summary": "Update the owner and contributors of an Application.",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Application ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Application stakeholders",
-                        "name": "application",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.Stakeholders"
-                        }
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
-                    }
-                }
-            }
-        },
-        "/applications/{id}/tags": {
+        "/applications/{id}/snapshots": {
             "get": {
-                "description": "List tag references.",
+                "description": "List bucket snapshots.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "applications"
                 ],
-                "summary": "List tag references.",
+                "summary": "List bucket snapshots.",
                 "parameters": [
                     {
                         "type": "integer",
@@ -1161,34 +1331,22 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.Ref"
+                                "$ref": "#/definitions/api.BucketSnapshot"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Ensure tag is associated with the application.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Create a (manual) snapshot of the bucket content.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "applications"
                 ],
-                "summary": "Add tag association.",
+                "summary": "Create a bucket snapshot.",
                 "parameters": [
-                    {
-                        "description": "Tag data",
-                        "name": "tag",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.Ref"
-                        }
-                    },
                     {
                         "type": "integer",
                         "description": "Application ID",
@@ -1201,20 +1359,22 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/api.Ref"
+                            "$ref": "#/definitions/api.BucketSnapshot"
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Replace tag associations.",
-                "consumes": [
+            }
+        },
+        "/applications/{id}/snapshots/{sid}": {
+            "get": {
+                "description": "Get a bucket snapshot.",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "applications"
                 ],
-                "summary": "Replace tag associations.",
+                "summary": "Get a bucket snapshot.",
                 "parameters": [
                     {
                         "type": "integer",
@@ -1224,38 +1384,281 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Source",
-                        "name": "source",
-                        "in": "query"
-                    },
-                    {
-                        "description": "Tag references",
-                        "name": "tags",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/api.TagRef"
-                            }
-                        }
+                        "type": "integer",
+                        "description": "Snapshot ID",
+                        "name": "sid",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.BucketSnapshot"
+                        }
                     }
                 }
-            }
-        },
-        "/applications/{id}/tags/{sid}": {
+            },
             "delete": {
-                "description": "Ensure tag is not associated with the application.",
+                "description": "Delete a bucket snapshot.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "applications"
                 ],
-                "summary": "Delete tag association.",
+                "summary": "Delete a bucket snapshot.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Snapshot ID",
+                        "name": "sid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/applications/{id}/snapshots/{sid}/diff": {
+            "get": {
+                "description": "Compare the snapshot with the current bucket content.\n?snapshot=id compares with another snapshot.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Diff a bucket snapshot.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Snapshot ID",
+                        "name": "sid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Snapshot ID",
+                        "name": "snapshot",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.BucketDiff"
+                        }
+                    }
+                }
+            }
+        },
+        "/applications/{id}/snapshots/{sid}/restore": {
+            "put": {
+                "description": "Restore the bucket content from the snapshot.\nThe current content is snapshot before it is replaced.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Restore a bucket snapshot.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Snapshot ID",
+                        "name": "sid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/applications/{id}/stakeholders": {
+            "patch": {
+                "description": "Update the owner and contributors of an Application.",
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Update the owner and contributors of an Application.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Application stakeholders",
+                        "name": "application",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.Stakeholders"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/applications/{id}/tags": {
+            "get": {
+                "description": "List tag references.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "List tag references.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Ref"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Ensure tag is associated with the application.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Add tag association.",
+                "parameters": [
+                    {
+                        "description": "Tag data",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.Ref"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.Ref"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Replace tag associations.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Replace tag associations.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Source",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Tag references",
+                        "name": "tags",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.TagRef"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/applications/{id}/tags/{sid}": {
+            "delete": {
+                "description": "Ensure tag is not associated with the application.",
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Delete tag association.",
                 "parameters": [
                     {
                         "type": "integer",
@@ -1591,29 +1994,81 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/login": {
-            "post": {
-                "description": "Login and obtain a bearer token.",
+        "/auditlog": {
+            "get": {
+                "description": "List audit log entries (newest first).\nfilters:\n- id\n- user\n- method\n- route\n- path\n- kind\n- resourceId\n- status\n- outcome",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "auditlog"
                 ],
-                "summary": "Login and obtain a bearer token.",
+                "summary": "List audit log entries.",
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.Login"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.AuditEntry"
+                            }
                         }
                     }
                 }
             }
         },
-        "/auth/refresh": {
-            "post": {
-                "description": "Refresh bearer token.",
+        "/auditlog/{id}": {
+            "get": {
+                "description": "Get an audit log entry by ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auditlog"
+                ],
+                "summary": "Get an audit log entry by ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.AuditEntry"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Login and obtain a bearer token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Login and obtain a bearer token.",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.Login"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Refresh bearer token.",
                 "produces": [
                     "application/json"
                 ],
@@ -1631,6 +2086,23 @@ const docTemplate = `{
                 }
             }
         },
+        "/backup": {
+            "get": {
+                "description": "Get a backup archive (tarball) of the database and the\nstored (bucket and file) content. The archive includes a\nmanifest with the migration version and checksums.\nThe compression (gzip|zstd) is negotiated using the X-Compression header.\nThe archive is restored using: hub restore \u003cpath\u003e.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "backup"
+                ],
+                "summary": "Get a backup archive.",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
         "/batch/tags": {
             "post": {
                 "description": "Batch-create Tags.",
@@ -1709,7 +2181,7 @@ const docTemplate = `{
         },
         "/buckets": {
             "get": {
-                "description": "List all buckets.",
+                "description": "List all buckets.\nWhen ownership is enforced, buckets associated with\napplications that are not owned are not listed.",
                 "produces": [
                     "application/json"
                 ],
@@ -1789,7 +2261,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a bucket.",
+                "description": "Delete a bucket and the bucket snapshots.",
                 "tags": [
                     "buckets"
                 ],
@@ -1812,7 +2284,7 @@ const docTemplate = `{
         },
         "/buckets/{id}/{wildcard}": {
             "get": {
-                "description": "Get bucket content by ID and path.\nWhen path is FILE, returns file content.\nWhen path is DIRECTORY and Accept=text/html returns index.html.\n?filter=glob supports directory content filtering.\nElse returns a tarball.",
+                "description": "Get bucket content by ID and path.\nWhen path is FILE, returns file content.\nWhen path is DIRECTORY and ?manifest=true returns the manifest.\nWhen path is DIRECTORY and Accept=text/html returns index.html.\n?filter=glob supports directory content filtering.\nElse returns a tarball.\nThe tarball compression (gzip|zstd) is negotiated using the X-Compression header.\nThe compression accepted for uploads is advertised using the X-Accept-Compression header.\nFiles support (single) byte ranges.",
                 "produces": [
                     "application/octet-stream"
                 ],
@@ -1840,6 +2312,12 @@ const docTemplate = `{
                         "description": "Filter",
                         "name": "filter",
                         "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Manifest",
+                        "name": "manifest",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1849,7 +2327,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Upload bucket content by ID and path (handles both [post] and [put] requests).",
+                "description": "Upload bucket content by ID and path (handles both [post] and [put] requests).\nX-Directory=expand replaces the directory; X-Directory=merge writes into the directory.\nThe compression accepted for uploads is advertised using the X-Accept-Compression header.",
                 "produces": [
                     "application/json"
                 ],
@@ -1911,6 +2389,44 @@ const docTemplate = `{
                 }
             }
         },
+        "/bundles": {
+            "get": {
+                "description": "Export a bundle (tarball) of the (filtered) applications.\nSee: GET /applications/{id}/bundle.\nfilters:\n- id\n- name\n- businessService.id\n- businessService.name\n- tag.id",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "bundles"
+                ],
+                "summary": "Export a bundle of (filtered) applications.",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "post": {
+                "description": "Import the applications in a bundle (tarball). The bundle is validated\n(checksums) before the applications are imported. Each application is\nassigned a new ID. Related resources are resolved by UUID or name:\n- tags, tag categories, stakeholders (email), stakeholder groups and business\nservices are created when not found.\n- identities (name and kind) and questionnaires are not created. Not resolved\nreferences are reported as conflicts and omitted.\nApplications that already exist (name) are reported as conflicts and skipped.\nEach application (and bucket content) is imported in a transaction. Applications\nthat cannot be imported (e.g. quota exceeded) are reported as conflicts.\nForm fields:\n- file: the bundle.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bundles"
+                ],
+                "summary": "Import a bundle.",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.BundleReport"
+                        }
+                    }
+                }
+            }
+        },
         "/businessservices": {
             "get": {
                 "description": "List all business services.",
@@ -2048,7 +2564,7 @@ const docTemplate = `{
         },
         "/cache": {
             "delete": {
-                "description": "Delete a directory within the cache.",
+                "description": "Delete a directory within the cache.\nA cache volume (or directory within) leased to a task cannot be deleted.",
                 "produces": [
                     "application/json"
                 ],
@@ -2059,13 +2575,16 @@ const docTemplate = `{
                 "responses": {
                     "204": {
                         "description": "No Content"
+                    },
+                    "409": {
+                        "description": "Conflict"
                     }
                 }
             }
         },
         "/caches/{wildcard}": {
             "get": {
-                "description": "Get the cache.",
+                "description": "Get the cache.\nThe cache includes the (named) cache volumes with statistics and\nthe leases granted to tasks. When the DIR is a cache volume, only\nthe volume is included.",
                 "produces": [
                     "application/json"
                 ],
@@ -2200,9 +2719,73 @@ const docTemplate = `{
                 }
             }
         },
+        "/encryption/rotate": {
+            "post": {
+                "description": "Re-encrypt the sensitive fields of all identities and the secrets\nof all webhooks using the current key (passphrase). Fields encrypted\nusing previous keys (ENCRYPTION_PASSPHRASE_PREVIOUS) or not versioned\nare rotated.\nPerformed in a single transaction. When any identity (or webhook) fails,\nthe transaction is rolled back and 422 is returned with the report.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "encryption"
+                ],
+                "summary": "Rotate the encryption key.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.KeyRotation"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.KeyRotation"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "Stream (server-sent) events reporting changes to hub resources.\nEvents are named: \u003ckind\u003e.\u003caction\u003e. Actions: created|updated|deleted|state.\nEvents after the Last-Event-ID header (or since param) are replayed when retained.\nEvent IDs are seeded using the hub start time and increase across restarts.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Stream hub events.",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Resource kinds (default: all).",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Replay events after the event ID.",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Event"
+                        }
+                    }
+                }
+            }
+        },
         "/files": {
             "get": {
-                "description": "List all files.",
+                "description": "List all files.\nThe digest param (SHA-256) may be used to find files by content.",
                 "produces": [
                     "application/json"
                 ],
@@ -2210,6 +2793,14 @@ const docTemplate = `{
                     "file"
                 ],
                 "summary": "List all files.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Content digest (SHA-256)",
+                        "name": "digest",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -2223,7 +2814,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create a file.",
+                "description": "Create a file.\nThe content is stored by digest (SHA-256) and shared\nby files with the same content.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2282,7 +2873,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a file.",
+                "description": "Delete a file.\nThe content is deleted when not shared by other files.",
                 "tags": [
                     "file"
                 ],
@@ -2420,7 +3011,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete an identity.",
+                "description": "Delete an identity.\nReturns 409 when the identity is referenced by applications,\nproxies, trackers or rulesets unless forced.",
                 "tags": [
                     "identities"
                 ],
@@ -2432,6 +3023,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Delete when referenced",
+                        "name": "force",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -2441,6 +3038,58 @@ const docTemplate = `{
                 }
             }
         },
+        "/identities/{id}/usage": {
+            "get": {
+                "description": "List the applications, proxies, trackers and rulesets\nreferencing an identity.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "identities"
+                ],
+                "summary": "List the resources referencing an identity.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Identity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.IdentityUsage"
+                        }
+                    }
+                }
+            }
+        },
+        "/identities/{id}/validate": {
+            "post": {
+                "description": "Request validation of the credentials against the repository\nof the first application referencing the identity.\nPerformed asynchronously (queued). The result is reported in\nthe identity verification.",
+                "tags": [
+                    "identities"
+                ],
+                "summary": "Validate identity credentials.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Identity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    }
+                }
+            }
+        },
         "/imports": {
             "get": {
                 "description": "List imports.",
@@ -3205,6 +3854,86 @@ const docTemplate = `{
                 }
             }
         },
+        "/reaper": {
+            "post": {
+                "description": "Run (trigger) the reapers and report the actions taken.\nA dry-run (dryRun=true) reports the tasks, groups, buckets, files and\nsnapshots that would be released or deleted (and why) but nothing is changed.\nReasons: ttl|orphan|submitted|pressure|retention.\nThe run (not dry-run) is recorded in the history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reaper"
+                ],
+                "summary": "Run the reapers.",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Dry run",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ReaperRun"
+                        }
+                    }
+                }
+            }
+        },
+        "/reaper/runs": {
+            "get": {
+                "description": "List the (recorded) reaper runs (history) with counts and bytes reclaimed.\nScheduled runs are recorded only when something was done.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reaper"
+                ],
+                "summary": "List the (recorded) reaper runs.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.ReaperRun"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reaper/runs/{id}": {
+            "get": {
+                "description": "Get a (recorded) reaper run by ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reaper"
+                ],
+                "summary": "Get a (recorded) reaper run by ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ReaperRun"
+                        }
+                    }
+                }
+            }
+        },
         "/reviews": {
             "get": {
                 "description": "List all reviews.",
@@ -3371,30 +4100,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/rulesets": {
+        "/roles": {
             "get": {
-                "description": "List all bindings.\nfilters:\n- name\n- labels",
+                "description": "List all roles.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rulesets"
+                    "roles"
                 ],
-                "summary": "List all bindings.",
+                "summary": "List all roles.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.RuleSet"
+                                "$ref": "#/definitions/api.Role"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a ruleset.",
+                "description": "Create a (custom) role.\nThe granted scopes must be a subset of the scopes granted to the user.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3402,17 +4131,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rulesets"
+                    "roles"
                 ],
-                "summary": "Create a ruleset.",
+                "summary": "Create a role.",
                 "parameters": [
                     {
-                        "description": "RuleSet data",
-                        "name": "ruleBundle",
+                        "description": "Role data",
+                        "name": "role",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.RuleSet"
+                            "$ref": "#/definitions/api.Role"
                         }
                     }
                 ],
@@ -3420,26 +4149,26 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/api.RuleSet"
+                            "$ref": "#/definitions/api.Role"
                         }
                     }
                 }
             }
         },
-        "/rulesets/{id}": {
+        "/roles/{id}": {
             "get": {
-                "description": "Get a RuleSet by ID.",
+                "description": "Get a role by ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rulesets"
+                    "roles"
                 ],
-                "summary": "Get a RuleSet by ID.",
+                "summary": "Get a role by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "RuleSet ID",
+                        "description": "Role ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -3449,35 +4178,35 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.RuleSet"
+                            "$ref": "#/definitions/api.Role"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update a ruleset.",
+                "description": "Update a (custom) role.\nBuiltin roles cannot be updated.\nThe granted scopes must be a subset of the scopes granted to the user.",
                 "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "rulesets"
+                    "roles"
                 ],
-                "summary": "Update a ruleset.",
+                "summary": "Update a role.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "RuleSet ID",
+                        "description": "Role ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "RuleSet data",
-                        "name": "ruleBundle",
+                        "description": "Role data",
+                        "name": "role",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.RuleSet"
+                            "$ref": "#/definitions/api.Role"
                         }
                     }
                 ],
@@ -3488,15 +4217,15 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a ruleset.",
+                "description": "Delete a (custom) role and the user bindings.\nBuiltin roles cannot be deleted.",
                 "tags": [
-                    "rulesets"
+                    "roles"
                 ],
-                "summary": "Delete a ruleset.",
+                "summary": "Delete a role.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "RuleSet ID",
+                        "description": "Role ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -3509,50 +4238,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/schema": {
-            "get": {
-                "description": "Get the API schema.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "schema"
-                ],
-                "summary": "Get the API schema.",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/api.Schema"
-                        }
-                    }
-                }
-            }
-        },
-        "/settings": {
+        "/rulesets": {
             "get": {
-                "description": "List all settings.",
+                "description": "List all bindings.\nfilters:\n- name\n- labels",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "settings"
+                    "rulesets"
                 ],
-                "summary": "List all settings.",
+                "summary": "List all bindings.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.Setting"
+                                "$ref": "#/definitions/api.RuleSet"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a setting.",
+                "description": "Create a ruleset.\nThe rule files are parsed and validated. Labels, descriptions\nand ruleIDs are extracted from the files.\nInvalid rules are reported (400) as RuleSetValidation.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3560,17 +4269,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "settings"
+                    "rulesets"
                 ],
-                "summary": "Create a setting.",
+                "summary": "Create a ruleset.",
                 "parameters": [
                     {
-                        "description": "Setting data",
-                        "name": "setting",
+                        "description": "RuleSet data",
+                        "name": "ruleBundle",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.Setting"
+                            "$ref": "#/definitions/api.RuleSet"
                         }
                     }
                 ],
@@ -3578,111 +4287,130 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/api.Setting"
+                            "$ref": "#/definitions/api.RuleSet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.RuleSetValidation"
                         }
                     }
                 }
             }
         },
-        "/settings/{key}": {
-            "get": {
-                "description": "Get a setting by its key.",
+        "/rulesets/validate": {
+            "post": {
+                "description": "Validate (dry-run) a ruleset. The rule files are parsed\nand validated against the analyzer rule schema.\nDuplicate ruleIDs within the ruleset and defined by other\nrulesets are reported as warnings.\nWhen the ID is specified, the ruleset is excluded from\nthe duplicate ruleID search.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "settings"
+                    "rulesets"
                 ],
-                "summary": "Get a setting by its key.",
+                "summary": "Validate a ruleset.",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Key",
-                        "name": "key",
-                        "in": "path",
-                        "required": true
+                        "description": "RuleSet data",
+                        "name": "ruleBundle",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.RuleSet"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.Setting"
+                            "$ref": "#/definitions/api.RuleSetValidation"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update a setting.",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/rulesets/{id}": {
+            "get": {
+                "description": "Get a RuleSet by ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "settings"
+                    "rulesets"
                 ],
-                "summary": "Update a setting.",
+                "summary": "Get a RuleSet by ID.",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Key",
-                        "name": "key",
+                        "type": "integer",
+                        "description": "RuleSet ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.RuleSet"
+                        }
                     }
                 }
             },
-            "post": {
-                "description": "Create a setting.",
+            "put": {
+                "description": "Update a ruleset.\nThe rule files are parsed and validated.\nInvalid rules are reported (400) as RuleSetValidation.",
                 "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "settings"
+                    "rulesets"
                 ],
-                "summary": "Create a setting.",
+                "summary": "Update a ruleset.",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Key",
-                        "name": "key",
+                        "type": "integer",
+                        "description": "RuleSet ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Setting value",
-                        "name": "setting",
+                        "description": "RuleSet data",
+                        "name": "ruleBundle",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.Setting"
+                            "$ref": "#/definitions/api.RuleSet"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created"
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.RuleSetValidation"
+                        }
                     }
                 }
             },
             "delete": {
-                "description": "Delete a setting.",
+                "description": "Delete a ruleset.",
                 "tags": [
-                    "settings"
+                    "rulesets"
                 ],
-                "summary": "Delete a setting.",
+                "summary": "Delete a ruleset.",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Key",
-                        "name": "key",
+                        "type": "integer",
+                        "description": "RuleSet ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -3694,30 +4422,124 @@ const docTemplate = `{
                 }
             }
         },
-        "/stakeholdergroups": {
+        "/rulesets/{id}/revisions": {
             "get": {
-                "description": "List all stakeholder groups.",
+                "description": "List the (immutable) revisions of a ruleset.\nEach revision includes the diff from the previous revision.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stakeholdergroups"
+                    "rulesets"
+                ],
+                "summary": "List ruleset revisions.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "RuleSet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List all stakeholder groups.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.StakeholderGroup"
+                                "$ref": "#/definitions/api.RuleSetRevision"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rulesets/{id}/revisions/{revision}": {
+            "get": {
+                "description": "Get a ruleset revision.\nThe diff is from the previous revision unless\nspecified by the ` + "`" + `from` + "`" + ` parameter.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rulesets"
+                ],
+                "summary": "Get a ruleset revision.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "RuleSet ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Diff from revision",
+                        "name": "from",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.RuleSetRevision"
+                        }
+                    }
+                }
+            }
+        },
+        "/schema": {
+            "get": {
+                "description": "Get the API schema.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schema"
+                ],
+                "summary": "Get the API schema.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Schema"
+                        }
+                    }
+                }
+            }
+        },
+        "/serviceaccounts": {
+            "get": {
+                "description": "List all service accounts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "serviceaccounts"
+                ],
+                "summary": "List all service accounts.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.ServiceAccount"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a stakeholder group.",
+                "description": "Create a service account.\nThe scopes must be a subset of the scopes granted to the user.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3725,17 +4547,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stakeholdergroups"
+                    "serviceaccounts"
                 ],
-                "summary": "Create a stakeholder group.",
+                "summary": "Create a service account.",
                 "parameters": [
                     {
-                        "description": "Stakeholder Group data",
-                        "name": "stakeholder_group",
+                        "description": "Service account data",
+                        "name": "account",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.StakeholderGroup"
+                            "$ref": "#/definitions/api.ServiceAccount"
                         }
                     }
                 ],
@@ -3743,26 +4565,26 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/api.StakeholderGroup"
+                            "$ref": "#/definitions/api.ServiceAccount"
                         }
                     }
                 }
             }
         },
-        "/stakeholdergroups/{id}": {
+        "/serviceaccounts/{id}": {
             "get": {
-                "description": "Get a stakeholder group by ID.",
+                "description": "Get a service account by ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stakeholdergroups"
+                    "serviceaccounts"
                 ],
-                "summary": "Get a stakeholder group by ID.",
+                "summary": "Get a service account by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Stakeholder Group ID",
+                        "description": "Service account ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -3772,35 +4594,35 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.StakeholderGroup"
+                            "$ref": "#/definitions/api.ServiceAccount"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update a stakeholder group.",
+                "description": "Update a service account.\nThe scopes must be a subset of the scopes granted to the user.\nToken scopes are limited to the account scopes when authenticated.",
                 "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "stakeholdergroups"
+                    "serviceaccounts"
                 ],
-                "summary": "Update a stakeholder group.",
+                "summary": "Update a service account.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Stakeholder Group ID",
+                        "description": "Service account ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Stakeholder Group data",
-                        "name": "stakeholder_group",
+                        "description": "Service account data",
+                        "name": "account",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.StakeholderGroup"
+                            "$ref": "#/definitions/api.ServiceAccount"
                         }
                     }
                 ],
@@ -3811,15 +4633,15 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a stakeholder group.",
+                "description": "Delete a service account and revoke its tokens.",
                 "tags": [
-                    "stakeholdergroups"
+                    "serviceaccounts"
                 ],
-                "summary": "Delete a stakeholder group.",
+                "summary": "Delete a service account.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Stakeholder Group ID",
+                        "description": "Service account ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -3832,30 +4654,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/stakeholders": {
+        "/serviceaccounts/{id}/tokens": {
             "get": {
-                "description": "List all stakeholders.",
+                "description": "List the API tokens issued to a service account.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stakeholders"
+                    "serviceaccounts"
+                ],
+                "summary": "List the API tokens issued to a service account.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List all stakeholders.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.Stakeholder"
+                                "$ref": "#/definitions/api.APIToken"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a stakeholder.",
+                "description": "Issue an API token to a service account.\nThe scopes must be a subset of the account scopes.\nThe token (secret) is returned only in the response.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3863,17 +4694,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stakeholders"
+                    "serviceaccounts"
                 ],
-                "summary": "Create a stakeholder.",
+                "summary": "Issue an API token to a service account.",
                 "parameters": [
                     {
-                        "description": "Stakeholder data",
-                        "name": "stakeholder",
+                        "type": "integer",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Token data",
+                        "name": "token",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.Stakeholder"
+                            "$ref": "#/definitions/api.APIToken"
                         }
                     }
                 ],
@@ -3881,27 +4719,112 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/api.Stakeholder"
+                            "$ref": "#/definitions/api.APIToken"
                         }
                     }
                 }
             }
         },
-        "/stakeholders/{id}": {
+        "/serviceaccounts/{id}/tokens/{id2}": {
+            "delete": {
+                "description": "Revoke (delete) an API token issued to a service account.",
+                "tags": [
+                    "serviceaccounts"
+                ],
+                "summary": "Revoke (delete) an API token issued to a service account.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Token ID",
+                        "name": "id2",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/settings": {
             "get": {
-                "description": "Get a stakeholder by ID.",
+                "description": "List all settings.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stakeholders"
+                    "settings"
                 ],
-                "summary": "Get a stakeholder by ID.",
+                "summary": "List all settings.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Setting"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a setting.\nThe task.lifecycle (task lifecycle policies) setting is validated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Create a setting.",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Stakeholder ID",
-                        "name": "id",
+                        "description": "Setting data",
+                        "name": "setting",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.Setting"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.Setting"
+                        }
+                    }
+                }
+            }
+        },
+        "/settings/{key}": {
+            "get": {
+                "description": "Get a setting by its key.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Get a setting by its key.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key",
+                        "name": "key",
                         "in": "path",
                         "required": true
                     }
@@ -3910,55 +4833,82 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.Stakeholder"
+                            "$ref": "#/definitions/api.Setting"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update a stakeholder.",
+                "description": "Update a setting.\nThe task.lifecycle (task lifecycle policies) setting is validated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Update a setting.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a setting.\nThe task.lifecycle (task lifecycle policies) setting is validated.",
                 "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "stakeholders"
+                    "settings"
                 ],
-                "summary": "Update a stakeholder.",
+                "summary": "Create a setting.",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Stakeholder ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Key",
+                        "name": "key",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Stakeholder data",
-                        "name": "stakeholder",
+                        "description": "Setting value",
+                        "name": "setting",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.Stakeholder"
+                            "$ref": "#/definitions/api.Setting"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "201": {
+                        "description": "Created"
                     }
                 }
             },
             "delete": {
-                "description": "Delete a stakeholder.",
+                "description": "Delete a setting.",
                 "tags": [
-                    "stakeholders"
+                    "settings"
                 ],
-                "summary": "Delete a stakeholder.",
+                "summary": "Delete a setting.",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Stakeholder ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Key",
+                        "name": "key",
                         "in": "path",
                         "required": true
                     }
@@ -3970,38 +4920,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/tagcategories": {
+        "/stakeholdergroups": {
             "get": {
-                "description": "List all tag categories.",
+                "description": "List all stakeholder groups.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tagcategories"
-                ],
-                "summary": "List all tag categories.",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Optional category name filter",
-                        "name": "name",
-                        "in": "query"
-                    }
+                    "stakeholdergroups"
                 ],
+                "summary": "List all stakeholder groups.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.TagCategory"
+                                "$ref": "#/definitions/api.StakeholderGroup"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a tag category.",
+                "description": "Create a stakeholder group.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4009,17 +4951,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tagcategories"
+                    "stakeholdergroups"
                 ],
-                "summary": "Create a tag category.",
+                "summary": "Create a stakeholder group.",
                 "parameters": [
                     {
-                        "description": "Tag Category data",
-                        "name": "tag_type",
+                        "description": "Stakeholder Group data",
+                        "name": "stakeholder_group",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.TagCategory"
+                            "$ref": "#/definitions/api.StakeholderGroup"
                         }
                     }
                 ],
@@ -4027,26 +4969,26 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/api.TagCategory"
+                            "$ref": "#/definitions/api.StakeholderGroup"
                         }
                     }
                 }
             }
         },
-        "/tagcategories/{id}": {
+        "/stakeholdergroups/{id}": {
             "get": {
-                "description": "Get a tag category by ID.",
+                "description": "Get a stakeholder group by ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tagcategories"
+                    "stakeholdergroups"
                 ],
-                "summary": "Get a tag category by ID.",
+                "summary": "Get a stakeholder group by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tag Category ID",
+                        "description": "Stakeholder Group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4056,35 +4998,35 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.TagCategory"
+                            "$ref": "#/definitions/api.StakeholderGroup"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update a tag category.",
+                "description": "Update a stakeholder group.",
                 "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "tagcategories"
+                    "stakeholdergroups"
                 ],
-                "summary": "Update a tag category.",
+                "summary": "Update a stakeholder group.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tag Category ID",
+                        "description": "Stakeholder Group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Tag Category data",
-                        "name": "tag_type",
+                        "description": "Stakeholder Group data",
+                        "name": "stakeholder_group",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.TagCategory"
+                            "$ref": "#/definitions/api.StakeholderGroup"
                         }
                     }
                 ],
@@ -4095,15 +5037,15 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a tag category.",
+                "description": "Delete a stakeholder group.",
                 "tags": [
-                    "tagcategories"
+                    "stakeholdergroups"
                 ],
-                "summary": "Delete a tag category.",
+                "summary": "Delete a stakeholder group.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tag Category ID",
+                        "description": "Stakeholder Group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4116,68 +5058,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/tagcategories/{id}/tags": {
+        "/stakeholders": {
             "get": {
-                "description": "List the tags in the tag category.",
+                "description": "List all stakeholders.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tagcategories"
+                    "stakeholders"
                 ],
-                "summary": "List the tags in the tag category.",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Tag Category ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Optional tag name filter",
-                        "name": "name",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/api.Tag"
-                            }
-                        }
-                    }
-                }
-            }
-        },
-        "/tags": {
-            "get": {
-                "description": "List all tags.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "tags"
-                ],
-                "summary": "List all tags.",
+                "summary": "List all stakeholders.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.Tag"
+                                "$ref": "#/definitions/api.Stakeholder"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a tag.",
+                "description": "Create a stakeholder.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4185,17 +5089,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tags"
+                    "stakeholders"
                 ],
-                "summary": "Create a tag.",
+                "summary": "Create a stakeholder.",
                 "parameters": [
                     {
-                        "description": "Tag data",
-                        "name": "tag",
+                        "description": "Stakeholder data",
+                        "name": "stakeholder",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.Tag"
+                            "$ref": "#/definitions/api.Stakeholder"
                         }
                     }
                 ],
@@ -4203,26 +5107,26 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/api.Tag"
+                            "$ref": "#/definitions/api.Stakeholder"
                         }
                     }
                 }
             }
         },
-        "/tags/{id}": {
+        "/stakeholders/{id}": {
             "get": {
-                "description": "Get a tag by ID.",
+                "description": "Get a stakeholder by ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tags"
+                    "stakeholders"
                 ],
-                "summary": "Get a tag by ID.",
+                "summary": "Get a stakeholder by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tag ID",
+                        "description": "Stakeholder ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4232,35 +5136,35 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.Tag"
+                            "$ref": "#/definitions/api.Stakeholder"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update a tag.",
+                "description": "Update a stakeholder.",
                 "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "tags"
+                    "stakeholders"
                 ],
-                "summary": "Update a tag.",
+                "summary": "Update a stakeholder.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tag ID",
+                        "description": "Stakeholder ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Tag data",
-                        "name": "tag",
+                        "description": "Stakeholder data",
+                        "name": "stakeholder",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.Tag"
+                            "$ref": "#/definitions/api.Stakeholder"
                         }
                     }
                 ],
@@ -4271,15 +5175,15 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a tag.",
+                "description": "Delete a stakeholder.",
                 "tags": [
-                    "tags"
+                    "stakeholders"
                 ],
-                "summary": "Delete a tag.",
+                "summary": "Delete a stakeholder.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tag ID",
+                        "description": "Stakeholder ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4292,30 +5196,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/targets": {
+        "/tagcategories": {
             "get": {
-                "description": "List all targets.",
+                "description": "List all tag categories.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "targets"
+                    "tagcategories"
+                ],
+                "summary": "List all tag categories.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Optional category name filter",
+                        "name": "name",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List all targets.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.Target"
+                                "$ref": "#/definitions/api.TagCategory"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a target.",
+                "description": "Create a tag category.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4323,17 +5235,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "targets"
+                    "tagcategories"
                 ],
-                "summary": "Create a target.",
+                "summary": "Create a tag category.",
                 "parameters": [
                     {
-                        "description": "Target data",
-                        "name": "target",
+                        "description": "Tag Category data",
+                        "name": "tag_type",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.Target"
+                            "$ref": "#/definitions/api.TagCategory"
                         }
                     }
                 ],
@@ -4341,26 +5253,26 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/api.Target"
+                            "$ref": "#/definitions/api.TagCategory"
                         }
                     }
                 }
             }
         },
-        "/targets/{id}": {
+        "/tagcategories/{id}": {
             "get": {
-                "description": "Get a Target by ID.",
+                "description": "Get a tag category by ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "targets"
+                    "tagcategories"
                 ],
-                "summary": "Get a Target by ID.",
+                "summary": "Get a tag category by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Target ID",
+                        "description": "Tag Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4370,35 +5282,35 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.Target"
+                            "$ref": "#/definitions/api.TagCategory"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update a target.",
+                "description": "Update a tag category.",
                 "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "targets"
+                    "tagcategories"
                 ],
-                "summary": "Update a target.",
+                "summary": "Update a tag category.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Target ID",
+                        "description": "Tag Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Target data",
-                        "name": "target",
+                        "description": "Tag Category data",
+                        "name": "tag_type",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.Target"
+                            "$ref": "#/definitions/api.TagCategory"
                         }
                     }
                 ],
@@ -4409,15 +5321,15 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a target.",
+                "description": "Delete a tag category.",
                 "tags": [
-                    "targets"
+                    "tagcategories"
                 ],
-                "summary": "Delete a target.",
+                "summary": "Delete a tag category.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Target ID",
+                        "description": "Tag Category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4430,30 +5342,68 @@ const docTemplate = `{
                 }
             }
         },
-        "/taskgroups": {
+        "/tagcategories/{id}/tags": {
             "get": {
-                "description": "List all task groups.",
+                "description": "List the tags in the tag category.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "taskgroups"
+                    "tagcategories"
+                ],
+                "summary": "List the tags in the tag category.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional tag name filter",
+                        "name": "name",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List all task groups.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.TaskGroup"
+                                "$ref": "#/definitions/api.Tag"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "List all tags.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "List all tags.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Tag"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a task group.",
+                "description": "Create a tag.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4461,17 +5411,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "taskgroups"
+                    "tags"
                 ],
-                "summary": "Create a task group.",
+                "summary": "Create a tag.",
                 "parameters": [
                     {
-                        "description": "TaskGroup data",
-                        "name": "taskgroup",
+                        "description": "Tag data",
+                        "name": "tag",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.TaskGroup"
+                            "$ref": "#/definitions/api.Tag"
                         }
                     }
                 ],
@@ -4479,26 +5429,26 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/api.TaskGroup"
+                            "$ref": "#/definitions/api.Tag"
                         }
                     }
                 }
             }
         },
-        "/taskgroups/{id}": {
+        "/tags/{id}": {
             "get": {
-                "description": "Get a task group by ID.",
+                "description": "Get a tag by ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "taskgroups"
+                    "tags"
                 ],
-                "summary": "Get a task group by ID.",
+                "summary": "Get a tag by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "TaskGroup ID",
+                        "description": "Tag ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4508,35 +5458,35 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.TaskGroup"
+                            "$ref": "#/definitions/api.Tag"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update a task group.",
+                "description": "Update a tag.",
                 "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "taskgroups"
+                    "tags"
                 ],
-                "summary": "Update a task group.",
+                "summary": "Update a tag.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Task ID",
+                        "description": "Tag ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Task data",
-                        "name": "task",
+                        "description": "Tag data",
+                        "name": "tag",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.TaskGroup"
+                            "$ref": "#/definitions/api.Tag"
                         }
                     }
                 ],
@@ -4547,15 +5497,15 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a task group.",
+                "description": "Delete a tag.",
                 "tags": [
-                    "taskgroups"
+                    "tags"
                 ],
-                "summary": "Delete a task group.",
+                "summary": "Delete a tag.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "TaskGroup ID",
+                        "description": "Tag ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4568,131 +5518,113 @@ const docTemplate = `{
                 }
             }
         },
-        "/taskgroups/{id}/bucket/{wildcard}": {
+        "/targets": {
             "get": {
-                "description": "Get bucket content by ID and path.\nReturns index.html for directories when Accept=text/html else a tarball.\n?filter=glob supports directory content filtering.",
+                "description": "List all targets.",
                 "produces": [
-                    "application/octet-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "taskgroups"
-                ],
-                "summary": "Get bucket content by ID and path.",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "TaskGroup ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Content path",
-                        "name": "wildcard",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter",
-                        "name": "filter",
-                        "in": "query"
-                    }
+                    "targets"
                 ],
+                "summary": "List all targets.",
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Target"
+                            }
+                        }
                     }
                 }
             },
             "post": {
-                "description": "Upload bucket content by ID and path (handles both [post] and [put] requests).",
+                "description": "Create a target.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "taskgroups"
+                    "targets"
                 ],
-                "summary": "Upload bucket content by ID and path.",
+                "summary": "Create a target.",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "TaskGroup ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Content path",
-                        "name": "wildcard",
-                        "in": "path",
-                        "required": true
+                        "description": "Target data",
+                        "name": "target",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.Target"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.Target"
+                        }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete bucket content by ID and path.",
+            }
+        },
+        "/targets/{id}": {
+            "get": {
+                "description": "Get a Target by ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "taskgroups"
+                    "targets"
                 ],
-                "summary": "Delete bucket content by ID and path.",
+                "summary": "Get a Target by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Task ID",
+                        "description": "Target ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Content path",
-                        "name": "wildcard",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Target"
+                        }
                     }
                 }
-            }
-        },
-        "/taskgroups/{id}/submit": {
+            },
             "put": {
-                "description": "Submit a task group.",
+                "description": "Update a target.",
                 "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "taskgroups"
+                    "targets"
                 ],
-                "summary": "Submit a task group.",
+                "summary": "Update a target.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "TaskGroup ID",
+                        "description": "Target ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "TaskGroup data (optional)",
-                        "name": "taskgroup",
+                        "description": "Target data",
+                        "name": "target",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.TaskGroup"
+                            "$ref": "#/definitions/api.Target"
                         }
                     }
                 ],
@@ -4701,32 +5633,53 @@ const docTemplate = `{
                         "description": "No Content"
                     }
                 }
+            },
+            "delete": {
+                "description": "Delete a target.",
+                "tags": [
+                    "targets"
+                ],
+                "summary": "Delete a target.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
             }
         },
-        "/tasks": {
+        "/taskgroups": {
             "get": {
-                "description": "List all tasks.",
+                "description": "List all task groups.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "taskgroups"
                 ],
-                "summary": "List all tasks.",
+                "summary": "List all task groups.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.Task"
+                                "$ref": "#/definitions/api.TaskGroup"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a task.",
+                "description": "Create a task group.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4734,17 +5687,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "taskgroups"
                 ],
-                "summary": "Create a task.",
+                "summary": "Create a task group.",
                 "parameters": [
                     {
-                        "description": "Task data",
-                        "name": "task",
+                        "description": "TaskGroup data",
+                        "name": "taskgroup",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.Task"
+                            "$ref": "#/definitions/api.TaskGroup"
                         }
                     }
                 ],
@@ -4752,26 +5705,26 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/api.Task"
+                            "$ref": "#/definitions/api.TaskGroup"
                         }
                     }
                 }
             }
         },
-        "/tasks/{id}": {
+        "/taskgroups/{id}": {
             "get": {
-                "description": "Get a task by ID.",
+                "description": "Get a task group by ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "taskgroups"
                 ],
-                "summary": "Get a task by ID.",
+                "summary": "Get a task group by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Task ID",
+                        "description": "TaskGroup ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4781,20 +5734,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.Task"
+                            "$ref": "#/definitions/api.TaskGroup"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update a task.",
+                "description": "Update a task group.",
                 "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "taskgroups"
                 ],
-                "summary": "Update a task.",
+                "summary": "Update a task group.",
                 "parameters": [
                     {
                         "type": "integer",
@@ -4809,7 +5762,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.Task"
+                            "$ref": "#/definitions/api.TaskGroup"
                         }
                     }
                 ],
@@ -4820,15 +5773,15 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a task.",
+                "description": "Delete a task group.",
                 "tags": [
-                    "tasks"
+                    "taskgroups"
                 ],
-                "summary": "Delete a task.",
+                "summary": "Delete a task group.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Task ID",
+                        "description": "TaskGroup ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4841,20 +5794,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{id}/bucket/{wildcard}": {
+        "/taskgroups/{id}/bucket/{wildcard}": {
             "get": {
-                "description": "Get bucket content by ID and path.\nReturns index.html for directories when Accept=text/html else a tarball.\n?filter=glob supports directory content filtering.",
+                "description": "Get bucket content by ID and path.\nReturns index.html for directories when Accept=text/html else a tarball.\nReturns the manifest for directories when ?manifest=true.\nThe tarball compression (gzip|zstd) is negotiated using the X-Compression header.\nFiles support (single) byte ranges.\n?filter=glob supports directory content filtering.",
                 "produces": [
                     "application/octet-stream"
                 ],
                 "tags": [
-                    "tasks"
+                    "taskgroups"
                 ],
                 "summary": "Get bucket content by ID and path.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Task ID",
+                        "description": "TaskGroup ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4871,6 +5824,12 @@ const docTemplate = `{
                         "description": "Filter",
                         "name": "filter",
                         "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Manifest",
+                        "name": "manifest",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -4880,18 +5839,18 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Upload bucket content by ID and path (handles both [post] and [put] requests).",
+                "description": "Upload bucket content by ID and path (handles both [post] and [put] requests).\nX-Directory=expand replaces the directory; X-Directory=merge writes into the directory.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "taskgroups"
                 ],
                 "summary": "Upload bucket content by ID and path.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Task ID",
+                        "description": "TaskGroup ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -4916,7 +5875,7 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "taskgroups"
                 ],
                 "summary": "Delete bucket content by ID and path.",
                 "parameters": [
@@ -4942,20 +5901,31 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{id}/cancel": {
+        "/taskgroups/{id}/submit": {
             "put": {
-                "description": "Cancel a task.",
+                "description": "Submit a task group.",
+                "consumes": [
+                    "application/json"
+                ],
                 "tags": [
-                    "tasks"
+                    "taskgroups"
                 ],
-                "summary": "Cancel a task.",
+                "summary": "Submit a task group.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Task ID",
+                        "description": "TaskGroup ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "TaskGroup data (optional)",
+                        "name": "taskgroup",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.TaskGroup"
+                        }
                     }
                 ],
                 "responses": {
@@ -4965,9 +5935,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{id}/report": {
-            "put": {
-                "description": "Update a task report.",
+        "/tasks": {
+            "get": {
+                "description": "List all tasks.\nWhen ownership is enforced, tasks associated with\napplications that are not owned are not listed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "List all tasks.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Task"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a task.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4977,159 +5968,1082 @@ const docTemplate = `{
                 "tags": [
                     "tasks"
                 ],
-                "summary": "Update a task report.",
+                "summary": "Create a task.",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Task ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Task data",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.Task"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.Task"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}": {
+            "get": {
+                "description": "Get a task by ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get a task by ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Task"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a task.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Update a task.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Task data",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.Task"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a task.",
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Delete a task.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/bucket/{wildcard}": {
+            "get": {
+                "description": "Get bucket content by ID and path.\nReturns index.html for directories when Accept=text/html else a tarball.\nReturns the manifest for directories when ?manifest=true.\nThe tarball compression (gzip|zstd) is negotiated using the X-Compression header.\nFiles support (single) byte ranges.\n?filter=glob supports directory content filtering.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get bucket content by ID and path.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content path",
+                        "name": "wildcard",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Manifest",
+                        "name": "manifest",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "post": {
+                "description": "Upload bucket content by ID and path (handles both [post] and [put] requests).\nX-Directory=expand replaces the directory; X-Directory=merge writes into the directory.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Upload bucket content by ID and path.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content path",
+                        "name": "wildcard",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete bucket content by ID and path.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Delete bucket content by ID and path.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content path",
+                        "name": "wildcard",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/cancel": {
+            "put": {
+                "description": "Cancel a task.",
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Cancel a task.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/report": {
+            "put": {
+                "description": "Update a task report.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Update a task report.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "TaskReport data",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.TaskReport"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.TaskReport"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Update a task report.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Create a task report.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "TaskReport data",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.TaskReport"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.TaskReport"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a task report.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Delete a task report.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/snapshots": {
+            "get": {
+                "description": "List bucket snapshots.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "List bucket snapshots.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.BucketSnapshot"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a (manual) snapshot of the bucket content.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Create a bucket snapshot.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.BucketSnapshot"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/snapshots/{sid}": {
+            "get": {
+                "description": "Get a bucket snapshot.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get a bucket snapshot.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Snapshot ID",
+                        "name": "sid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.BucketSnapshot"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a bucket snapshot.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Delete a bucket snapshot.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Snapshot ID",
+                        "name": "sid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/snapshots/{sid}/diff": {
+            "get": {
+                "description": "Compare the snapshot with the current bucket content.\n?snapshot=id compares with another snapshot.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Diff a bucket snapshot.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Snapshot ID",
+                        "name": "sid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Snapshot ID",
+                        "name": "snapshot",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.BucketDiff"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/snapshots/{sid}/restore": {
+            "put": {
+                "description": "Restore the bucket content from the snapshot.\nThe current content is snapshot before it is replaced.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Restore a bucket snapshot.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Snapshot ID",
+                        "name": "sid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/tasks/{id}/submit": {
+            "put": {
+                "description": "Submit a task.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Submit a task.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "description": "TaskReport data",
-                        "name": "task",
+                        "description": "Task data (optional)",
+                        "name": "task",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.Task"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/tickets": {
+            "get": {
+                "description": "List all tickets.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "List all tickets.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Ticket"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a ticket.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Create a ticket.",
+                "parameters": [
+                    {
+                        "description": "Ticket data",
+                        "name": "ticket",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.Ticket"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.Ticket"
+                        }
+                    }
+                }
+            }
+        },
+        "/tickets/{id}": {
+            "get": {
+                "description": "Get a ticket by ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Get a ticket by ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Ticket ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Ticket"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a ticket.",
+                "tags": [
+                    "tickets"
+                ],
+                "summary": "Delete a ticket.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Ticket id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/tokens": {
+            "get": {
+                "description": "List the API tokens owned by the user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "List the API tokens owned by the user.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.APIToken"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create an API token owned by the user.\nThe scopes must be a subset of the scopes granted to the user.\nThe token (secret) is returned only in the response.\nThe expiration of tokens issued to users not managed by the hub\n(keycloak|oidc) is capped by API_TOKEN_UNMANAGED_LIFESPAN (days).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Create an API token.",
+                "parameters": [
+                    {
+                        "description": "Token data",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.APIToken"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIToken"
+                        }
+                    }
+                }
+            }
+        },
+        "/tokens/{id}": {
+            "get": {
+                "description": "Get an API token by ID.\nThe token (secret) is never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Get an API token by ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIToken"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Revoke (delete) an API token.",
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Revoke (delete) an API token.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/trackers": {
+            "get": {
+                "description": "List all trackers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trackers"
+                ],
+                "summary": "List all trackers.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Tracker"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a tracker.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trackers"
+                ],
+                "summary": "Create a tracker.",
+                "parameters": [
+                    {
+                        "description": "Tracker data",
+                        "name": "tracker",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.TaskReport"
+                            "$ref": "#/definitions/api.Tracker"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.Tracker"
                         }
                     }
+                }
+            }
+        },
+        "/trackers/{id}": {
+            "get": {
+                "description": "Get a tracker by ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trackers"
+                ],
+                "summary": "Get a tracker by ID.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tracker ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.TaskReport"
+                            "$ref": "#/definitions/api.Tracker"
                         }
                     }
                 }
             },
-            "post": {
-                "description": "Update a task report.",
+            "put": {
+                "description": "Update a tracker.",
                 "consumes": [
                     "application/json"
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "tasks"
+                    "trackers"
                 ],
-                "summary": "Create a task report.",
+                "summary": "Update a tracker.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Task ID",
+                        "description": "Tracker id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "TaskReport data",
-                        "name": "task",
+                        "description": "Tracker data",
+                        "name": "application",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.TaskReport"
+                            "$ref": "#/definitions/api.Tracker"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/api.TaskReport"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             },
             "delete": {
-                "description": "Delete a task report.",
-                "consumes": [
+                "description": "Delete a tracker.",
+                "tags": [
+                    "trackers"
+                ],
+                "summary": "Delete a tracker.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tracker id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/trackers/{id}/projects": {
+            "get": {
+                "description": "List a tracker's projects.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "trackers"
+                ],
+                "summary": "List a tracker's projects.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tracker ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Project"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trackers/{id}/projects/{id2}": {
+            "get": {
+                "description": "Get a tracker project by ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "trackers"
                 ],
-                "summary": "Delete a task report.",
+                "summary": "Get a tracker project by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Task ID",
+                        "description": "Tracker ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id2",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Project"
+                        }
                     }
                 }
             }
         },
-        "/tasks/{id}/submit": {
-            "put": {
-                "description": "Submit a task.",
-                "consumes": [
+        "/trackers/{id}/projects/{id2}/issuetypes": {
+            "get": {
+                "description": "List a tracker project's issue types.",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "trackers"
                 ],
-                "summary": "Submit a task.",
+                "summary": "List a tracker project's issue types.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Task ID",
+                        "description": "Tracker ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Task data (optional)",
-                        "name": "task",
-                        "in": "body",
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id2",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.Task"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.IssueType"
+                            }
                         }
                     }
+                }
+            }
+        },
+        "/usage": {
+            "get": {
+                "description": "Get storage usage (bytes) by owner kind.\nThe total includes buckets, files and snapshots but not the cache.",
+                "produces": [
+                    "application/json"
                 ],
+                "tags": [
+                    "usage"
+                ],
+                "summary": "Get storage usage.",
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.Usage"
+                        }
                     }
                 }
             }
         },
-        "/tickets": {
+        "/users": {
             "get": {
-                "description": "List all tickets.",
+                "description": "List all users.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tickets"
+                    "users"
                 ],
-                "summary": "List all tickets.",
+                "summary": "List all users.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.Ticket"
+                                "$ref": "#/definitions/api.User"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a ticket.",
+                "description": "Create a user and bind roles.\nThe password (optional) is used when the user is created by the provider.\nThe scopes granted by the roles must be a subset of the scopes granted to the user.",
                 "consumes": [
                     "application/json"
                 ],
@@ -5137,17 +7051,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tickets"
+                    "users"
                 ],
-                "summary": "Create a ticket.",
+                "summary": "Create a user.",
                 "parameters": [
                     {
-                        "description": "Ticket data",
-                        "name": "ticket",
+                        "description": "User data",
+                        "name": "user",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.Ticket"
+                            "$ref": "#/definitions/api.User"
                         }
                     }
                 ],
@@ -5155,26 +7069,26 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/api.Ticket"
+                            "$ref": "#/definitions/api.User"
                         }
                     }
                 }
             }
         },
-        "/tickets/{id}": {
+        "/users/{id}": {
             "get": {
-                "description": "Get a ticket by ID.",
+                "description": "Get a user by ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tickets"
+                    "users"
                 ],
-                "summary": "Get a ticket by ID.",
+                "summary": "Get a user by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Ticket ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -5184,21 +7098,54 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.Ticket"
+                            "$ref": "#/definitions/api.User"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a user (role bindings).\nThe scopes granted by the roles must be a subset of the scopes granted to the user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update a user.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.User"
                         }
                     }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
                 }
             },
             "delete": {
-                "description": "Delete a ticket.",
+                "description": "Delete a user, the role bindings and the personal API tokens.",
                 "tags": [
-                    "tickets"
+                    "users"
                 ],
-                "summary": "Delete a ticket.",
+                "summary": "Delete a user.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Ticket id",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -5211,30 +7158,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/trackers": {
+        "/webhooks": {
             "get": {
-                "description": "List all trackers.",
+                "description": "List all webhooks.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "trackers"
+                    "webhooks"
                 ],
-                "summary": "List all trackers.",
+                "summary": "List all webhooks.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.Tracker"
+                                "$ref": "#/definitions/api.Webhook"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a tracker.",
+                "description": "Create a webhook.\nKinds: resource kinds (application) or event names (task.state). Empty = all.\nThe secret is used to sign (HMAC-SHA256) the payload: X-Hub-Signature-256.\nThe secret is stored encrypted and is not returned.",
                 "consumes": [
                     "application/json"
                 ],
@@ -5242,17 +7189,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "trackers"
+                    "webhooks"
                 ],
-                "summary": "Create a tracker.",
+                "summary": "Create a webhook.",
                 "parameters": [
                     {
-                        "description": "Tracker data",
-                        "name": "tracker",
+                        "description": "Webhook data",
+                        "name": "webhook",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.Tracker"
+                            "$ref": "#/definitions/api.Webhook"
                         }
                     }
                 ],
@@ -5260,26 +7207,26 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/api.Tracker"
+                            "$ref": "#/definitions/api.Webhook"
                         }
                     }
                 }
             }
         },
-        "/trackers/{id}": {
+        "/webhooks/{id}": {
             "get": {
-                "description": "Get a tracker by ID.",
+                "description": "Get a webhook by ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "trackers"
+                    "webhooks"
                 ],
-                "summary": "Get a tracker by ID.",
+                "summary": "Get a webhook by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tracker ID",
+                        "description": "Webhook ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -5289,35 +7236,35 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.Tracker"
+                            "$ref": "#/definitions/api.Webhook"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update a tracker.",
+                "description": "Update a webhook.\nThe secret is not updated when omitted.",
                 "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "trackers"
+                    "webhooks"
                 ],
-                "summary": "Update a tracker.",
+                "summary": "Update a webhook.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tracker id",
+                        "description": "Webhook ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Tracker data",
-                        "name": "application",
+                        "description": "Webhook data",
+                        "name": "webhook",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.Tracker"
+                            "$ref": "#/definitions/api.Webhook"
                         }
                     }
                 ],
@@ -5328,15 +7275,15 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a tracker.",
+                "description": "Delete a webhook and the delivery log.",
                 "tags": [
-                    "trackers"
+                    "webhooks"
                 ],
-                "summary": "Delete a tracker.",
+                "summary": "Delete a webhook.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tracker id",
+                        "description": "Webhook ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -5349,20 +7296,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/trackers/{id}/projects": {
+        "/webhooks/{id}/deliveries": {
             "get": {
-                "description": "List a tracker's projects.",
+                "description": "List the deliveries (log) of a webhook. Newest first.\nfilters:\n- id\n- event\n- kind\n- action\n- state",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "trackers"
+                    "webhooks"
                 ],
-                "summary": "List a tracker's projects.",
+                "summary": "List the deliveries (log) of a webhook.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tracker ID",
+                        "description": "Webhook ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -5374,34 +7321,34 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.Project"
+                                "$ref": "#/definitions/api.WebhookDelivery"
                             }
                         }
                     }
                 }
             }
         },
-        "/trackers/{id}/projects/{id2}": {
+        "/webhooks/{id}/deliveries/{id2}": {
             "get": {
-                "description": "Get a tracker project by ID.",
+                "description": "Get a webhook delivery by ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "trackers"
+                    "webhooks"
                 ],
-                "summary": "Get a tracker project by ID.",
+                "summary": "Get a webhook delivery by ID.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tracker ID",
+                        "description": "Webhook ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Project ID",
+                        "type": "integer",
+                        "description": "Delivery ID",
                         "name": "id2",
                         "in": "path",
                         "required": true
@@ -5411,53 +7358,93 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.Project"
+                            "$ref": "#/definitions/api.WebhookDelivery"
                         }
                     }
                 }
             }
         },
-        "/trackers/{id}/projects/{id2}/issuetypes": {
-            "get": {
-                "description": "List a tracker project's issue types.",
-                "produces": [
-                    "application/json"
-                ],
+        "/webhooks/{id}/deliveries/{id2}/retry": {
+            "post": {
+                "description": "Retry (reschedule) a failed webhook delivery.",
                 "tags": [
-                    "trackers"
+                    "webhooks"
                 ],
-                "summary": "List a tracker project's issue types.",
+                "summary": "Retry a webhook delivery.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tracker ID",
+                        "description": "Webhook ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Project ID",
+                        "type": "integer",
+                        "description": "Delivery ID",
                         "name": "id2",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/api.IssueType"
-                            }
-                        }
+                    "202": {
+                        "description": "Accepted"
                     }
                 }
             }
         }
     },
     "definitions": {
+        "api.APIToken": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "createTime": {
+                    "type": "string"
+                },
+                "createUser": {
+                    "type": "string"
+                },
+                "expiration": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastUsed": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "serviceAccount": {
+                    "$ref": "#/definitions/api.Ref"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "updateUser": {
+                    "type": "string"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
         "api.Addon": {
             "type": "object",
             "properties": {
@@ -5469,6 +7456,108 @@ const docTemplate = `{
                 }
             }
         },
+        "api.Advisory": {
+            "type": "object",
+            "properties": {
+                "affected": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.AffectedPackage"
+                    }
+                },
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "createTime": {
+                    "type": "string"
+                },
+                "createUser": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "modified": {
+                    "type": "string"
+                },
+                "published": {
+                    "type": "string"
+                },
+                "references": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "updateUser": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.AdvisoryReport": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "applications": {
+                    "type": "integer"
+                },
+                "dependencies": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "summary": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.AffectedPackage": {
+            "type": "object",
+            "properties": {
+                "ecosystem": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ranges": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "api.Analysis": {
             "type": "object",
             "properties": {
@@ -5493,16 +7582,68 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
-                "issues": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/api.Issue"
-                    }
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Issue"
+                    }
+                },
+                "ruleSets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.RevisionRef"
+                    }
+                },
+                "snapshot": {
+                    "$ref": "#/definitions/api.Ref"
+                },
+                "summary": {
+                    "type": "object"
+                },
+                "updateUser": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.AppAdvisoryReport": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "dependency": {
+                    "type": "object",
+                    "properties": {
+                        "id": {
+                            "type": "integer"
+                        },
+                        "indirect": {
+                            "type": "boolean"
+                        },
+                        "name": {
+                            "type": "string"
+                        },
+                        "provider": {
+                            "type": "string"
+                        },
+                        "version": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
                 },
                 "summary": {
-                    "type": "object"
-                },
-                "updateUser": {
                     "type": "string"
                 }
             }
@@ -5736,6 +7877,70 @@ const docTemplate = `{
                 }
             }
         },
+        "api.AuditChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "masked": {
+                    "type": "boolean"
+                },
+                "new": {
+                    "type": "string"
+                },
+                "old": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.AuditChange"
+                    }
+                },
+                "createTime": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "resourceId": {
+                    "type": "integer"
+                },
+                "route": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
         "api.Bucket": {
             "type": "object",
             "properties": {
@@ -5759,6 +7964,126 @@ const docTemplate = `{
                 }
             }
         },
+        "api.BucketDiff": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "changed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.BucketSnapshot": {
+            "type": "object",
+            "properties": {
+                "createTime": {
+                    "type": "string"
+                },
+                "createUser": {
+                    "type": "string"
+                },
+                "expiration": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "task": {
+                    "$ref": "#/definitions/api.Ref"
+                },
+                "updateUser": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.BundleConflict": {
+            "type": "object",
+            "properties": {
+                "application": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.BundleImported": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.BundleReport": {
+            "type": "object",
+            "properties": {
+                "applications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.BundleImported"
+                    }
+                },
+                "conflicts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.BundleConflict"
+                    }
+                },
+                "created": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.BundleResolved"
+                    }
+                }
+            }
+        },
+        "api.BundleResolved": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "api.BusinessService": {
             "type": "object",
             "required": [
@@ -5802,6 +8127,55 @@ const docTemplate = `{
                 },
                 "used": {
                     "type": "string"
+                },
+                "volumes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CacheVolume"
+                    }
+                }
+            }
+        },
+        "api.CacheLease": {
+            "type": "object",
+            "properties": {
+                "readOnly": {
+                    "type": "boolean"
+                },
+                "task": {
+                    "$ref": "#/definitions/api.Ref"
+                }
+            }
+        },
+        "api.CacheVolume": {
+            "type": "object",
+            "properties": {
+                "evicted": {
+                    "type": "integer"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastUsed": {
+                    "type": "string"
+                },
+                "leases": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CacheLease"
+                    }
+                },
+                "misses": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
                 }
             }
         },
@@ -5832,6 +8206,9 @@ const docTemplate = `{
                 "dependency": {
                     "type": "object",
                     "properties": {
+                        "advisories": {
+                            "type": "integer"
+                        },
                         "id": {
                             "type": "integer"
                         },
@@ -5892,6 +8269,29 @@ const docTemplate = `{
                 }
             }
         },
+        "api.Event": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "resource": {
+                    "type": "integer"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
         "api.Fact": {
             "type": "object",
             "properties": {
@@ -5921,6 +8321,9 @@ const docTemplate = `{
                 "createUser": {
                     "type": "string"
                 },
+                "digest": {
+                    "type": "string"
+                },
                 "expiration": {
                     "type": "string"
                 },
@@ -5983,16 +8386,70 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string"
+                "password": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "settings": {
+                    "type": "string"
+                },
+                "updateUser": {
+                    "type": "string"
+                },
+                "user": {
+                    "type": "string"
+                },
+                "verification": {
+                    "description": "Verification (read-only).",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.IdentityVerification"
+                        }
+                    ]
+                }
+            }
+        },
+        "api.IdentityUsage": {
+            "type": "object",
+            "properties": {
+                "applications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Ref"
+                    }
+                },
+                "proxies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Ref"
+                    }
+                },
+                "ruleSets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Ref"
+                    }
                 },
-                "settings": {
+                "trackers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Ref"
+                    }
+                }
+            }
+        },
+        "api.IdentityVerification": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string"
                 },
-                "updateUser": {
+                "state": {
                     "type": "string"
                 },
-                "user": {
+                "time": {
                     "type": "string"
                 }
             }
@@ -6257,6 +8714,29 @@ const docTemplate = `{
                 }
             }
         },
+        "api.KeyRotation": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.RotationFailed"
+                    }
+                },
+                "keyId": {
+                    "type": "string"
+                },
+                "rotated": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
         "api.Label": {
             "type": "object",
             "properties": {
@@ -6443,6 +8923,67 @@ const docTemplate = `{
                 }
             }
         },
+        "api.ReaperAction": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.ReaperRun": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ReaperAction"
+                    }
+                },
+                "counts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "createTime": {
+                    "type": "string"
+                },
+                "createUser": {
+                    "type": "string"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "duration": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "manual": {
+                    "type": "boolean"
+                },
+                "reclaimed": {
+                    "type": "integer"
+                },
+                "updateUser": {
+                    "type": "string"
+                }
+            }
+        },
         "api.Ref": {
             "type": "object",
             "required": [
@@ -6515,6 +9056,127 @@ const docTemplate = `{
                 }
             }
         },
+        "api.RevisionDiff": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "modified": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.RevisionRef": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.Role": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "builtin": {
+                    "type": "boolean"
+                },
+                "createTime": {
+                    "type": "string"
+                },
+                "createUser": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "resources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.RoleResource"
+                    }
+                },
+                "updateUser": {
+                    "type": "string"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Ref"
+                    }
+                }
+            }
+        },
+        "api.RoleResource": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "verbs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.RotationFailed": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "identity": {
+                    "$ref": "#/definitions/api.Ref"
+                },
+                "webhook": {
+                    "$ref": "#/definitions/api.Ref"
+                }
+            }
+        },
         "api.Rule": {
             "type": "object",
             "properties": {
@@ -6542,11 +9204,37 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "ruleIDs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "updateUser": {
                     "type": "string"
                 }
             }
         },
+        "api.RuleIssue": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "file": {
+                    "$ref": "#/definitions/api.Ref"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "ruleID": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
+                }
+            }
+        },
         "api.RuleReport": {
             "type": "object",
             "properties": {
@@ -6559,35 +9247,82 @@ const docTemplate = `{
                 "description": {
                     "type": "string"
                 },
-                "effort": {
+                "effort": {
+                    "type": "integer"
+                },
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Link"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "ruleset": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.RuleSet": {
+            "type": "object",
+            "properties": {
+                "createTime": {
+                    "type": "string"
+                },
+                "createUser": {
+                    "type": "string"
+                },
+                "dependsOn": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Ref"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "labels": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "identity": {
+                    "$ref": "#/definitions/api.Ref"
                 },
-                "links": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/api.Link"
-                    }
+                "kind": {
+                    "type": "string"
                 },
                 "name": {
                     "type": "string"
                 },
-                "rule": {
-                    "type": "string"
+                "repository": {
+                    "$ref": "#/definitions/api.Repository"
                 },
-                "ruleset": {
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Rule"
+                    }
+                },
+                "updateUser": {
                     "type": "string"
                 }
             }
         },
-        "api.RuleSet": {
+        "api.RuleSetRevision": {
             "type": "object",
             "properties": {
+                "commit": {
+                    "type": "string"
+                },
                 "createTime": {
                     "type": "string"
                 },
@@ -6603,21 +9338,30 @@ const docTemplate = `{
                 "description": {
                     "type": "string"
                 },
+                "diff": {
+                    "$ref": "#/definitions/api.RevisionDiff"
+                },
+                "digest": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "identity": {
                     "$ref": "#/definitions/api.Ref"
                 },
-                "kind": {
-                    "type": "string"
-                },
                 "name": {
                     "type": "string"
                 },
                 "repository": {
                     "$ref": "#/definitions/api.Repository"
                 },
+                "revision": {
+                    "type": "integer"
+                },
+                "ruleSet": {
+                    "$ref": "#/definitions/api.Ref"
+                },
                 "rules": {
                     "type": "array",
                     "items": {
@@ -6629,6 +9373,20 @@ const docTemplate = `{
                 }
             }
         },
+        "api.RuleSetValidation": {
+            "type": "object",
+            "properties": {
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.RuleIssue"
+                    }
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
         "api.Schema": {
             "type": "object",
             "properties": {
@@ -6643,6 +9401,44 @@ const docTemplate = `{
                 }
             }
         },
+        "api.ServiceAccount": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "createTime": {
+                    "type": "string"
+                },
+                "createUser": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tokens": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Ref"
+                    }
+                },
+                "updateUser": {
+                    "type": "string"
+                }
+            }
+        },
         "api.Setting": {
             "type": "object",
             "properties": {
@@ -6767,6 +9563,9 @@ const docTemplate = `{
         "api.TTL": {
             "type": "object",
             "properties": {
+                "canceled": {
+                    "type": "integer"
+                },
                 "created": {
                     "type": "integer"
                 },
@@ -6779,6 +9578,9 @@ const docTemplate = `{
                 "postponed": {
                     "type": "integer"
                 },
+                "ready": {
+                    "type": "integer"
+                },
                 "running": {
                     "type": "integer"
                 },
@@ -6938,6 +9740,12 @@ const docTemplate = `{
                 "bucket": {
                     "$ref": "#/definitions/api.Ref"
                 },
+                "caches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.TaskCache"
+                    }
+                },
                 "canceled": {
                     "type": "boolean"
                 },
@@ -6983,6 +9791,12 @@ const docTemplate = `{
                 "retries": {
                     "type": "integer"
                 },
+                "ruleSets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.RevisionRef"
+                    }
+                },
                 "started": {
                     "type": "string"
                 },
@@ -7003,6 +9817,17 @@ const docTemplate = `{
                 }
             }
         },
+        "api.TaskCache": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "readOnly": {
+                    "type": "boolean"
+                }
+            }
+        },
         "api.TaskError": {
             "type": "object",
             "properties": {
@@ -7041,6 +9866,12 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "ruleSets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.RevisionRef"
+                    }
+                },
                 "state": {
                     "type": "string"
                 },
@@ -7105,6 +9936,12 @@ const docTemplate = `{
                 "name"
             ],
             "properties": {
+                "advisories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Ref"
+                    }
+                },
                 "createTime": {
                     "type": "string"
                 },
@@ -7247,6 +10084,78 @@ const docTemplate = `{
                 }
             }
         },
+        "api.Usage": {
+            "type": "object",
+            "properties": {
+                "application": {
+                    "type": "integer"
+                },
+                "bucket": {
+                    "type": "integer"
+                },
+                "cache": {
+                    "type": "integer"
+                },
+                "file": {
+                    "type": "integer"
+                },
+                "quota": {
+                    "type": "object",
+                    "properties": {
+                        "bucket": {
+                            "type": "integer"
+                        },
+                        "total": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "snapshot": {
+                    "type": "integer"
+                },
+                "task": {
+                    "type": "integer"
+                },
+                "taskGroup": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.User": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "createTime": {
+                    "type": "string"
+                },
+                "createUser": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Ref"
+                    }
+                },
+                "updateUser": {
+                    "type": "string"
+                }
+            }
+        },
         "api.Vertex": {
             "type": "object",
             "properties": {
@@ -7273,6 +10182,83 @@ const docTemplate = `{
                 }
             }
         },
+        "api.Webhook": {
+            "type": "object",
+            "required": [
+                "name",
+                "url"
+            ],
+            "properties": {
+                "createTime": {
+                    "type": "string"
+                },
+                "createUser": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kinds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "updateUser": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "createTime": {
+                    "type": "string"
+                },
+                "delivered": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "nextAttempt": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
         "assessment.Answer": {
             "type": "object",
             "required": [
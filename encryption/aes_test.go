@@ -1,6 +1,7 @@
 package encryption
 
 import (
+	"errors"
 	"github.com/onsi/gomega"
 	"testing"
 )
@@ -21,3 +22,44 @@ func TestAES(t *testing.T) {
 	g.Expect(err).To(gomega.BeNil())
 	g.Expect(plain).To(gomega.Equal(decrypted))
 }
+
+func TestKeyring(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	plain := "ABCDEFGHIJKLMNOPQUSTUVQXYZ"
+	legacy, err := New("k0").Encrypt(plain)
+	g.Expect(err).To(gomega.BeNil())
+	salt := []byte("salt")
+	//
+	// Key ID derived using the salt.
+	g.Expect(KeyID(salt, "k1")).ToNot(gomega.Equal(KeyID([]byte("other"), "k1")))
+	//
+	// Versioned.
+	k1 := NewKeyring(salt, "k1", "k0")
+	g.Expect(k1.Current()).To(gomega.Equal(KeyID(salt, "k1")))
+	encrypted, err := k1.Encrypt(plain)
+	g.Expect(err).To(gomega.BeNil())
+	id, versioned := k1.KeyID(encrypted)
+	g.Expect(versioned).To(gomega.BeTrue())
+	g.Expect(id).To(gomega.Equal(KeyID(salt, "k1")))
+	decrypted, err := k1.Decrypt(encrypted)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(decrypted).To(gomega.Equal(plain))
+	//
+	// Legacy (oldest key).
+	_, versioned = k1.KeyID(legacy)
+	g.Expect(versioned).To(gomega.BeFalse())
+	decrypted, err = k1.Decrypt(legacy)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(decrypted).To(gomega.Equal(plain))
+	//
+	// Rotated: previous key still active.
+	k2 := NewKeyring(salt, "k2", "k1", "k0")
+	decrypted, err = k2.Decrypt(encrypted)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(decrypted).To(gomega.Equal(plain))
+	//
+	// Key no longer active.
+	k3 := NewKeyring(salt, "k3")
+	_, err = k3.Decrypt(encrypted)
+	g.Expect(errors.Is(err, &KeyNotFound{})).To(gomega.BeTrue())
+}
@@ -0,0 +1,137 @@
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+//
+// Versioned ciphertext delimiter.
+// Versioned ciphertext format: $<key-id>$<ciphertext>.
+const Delimiter = "$"
+
+//
+// KeyID returns the ID of the key for the passphrase.
+// The ID is derived (HMAC) using the (installation) salt so
+// that it cannot be used to verify a guessed passphrase.
+func KeyID(salt []byte, passphrase string) (id string) {
+	h := hmac.New(sha256.New, salt)
+	_, _ = h.Write([]byte(passphrase))
+	id = hex.EncodeToString(h.Sum(nil)[:4])
+	return
+}
+
+//
+// Key versioned AES key.
+type Key struct {
+	AES
+	// ID key ID.
+	ID string
+}
+
+//
+// Keyring of (active) keys.
+// Encryption uses the current key and the ciphertext is prefixed
+// with the key ID. Decryption uses the key identified by the prefix.
+// Unversioned (legacy) ciphertext is decrypted using the oldest key.
+type Keyring struct {
+	current *Key
+	legacy  *Key
+	keys    map[string]*Key
+}
+
+//
+// NewKeyring returns a keyring.
+// The current passphrase is used to encrypt. The previous
+// passphrases (newest first) are used only to decrypt.
+// The salt is used to derive the key IDs.
+func NewKeyring(salt []byte, current string, previous ...string) (r *Keyring) {
+	r = &Keyring{
+		keys: make(map[string]*Key),
+	}
+	for _, passphrase := range append([]string{current}, previous...) {
+		key := &Key{ID: KeyID(salt, passphrase)}
+		key.With(passphrase)
+		if r.current == nil {
+			r.current = key
+		}
+		r.legacy = key
+		r.keys[key.ID] = key
+	}
+	return
+}
+
+//
+// Current returns the ID of the current key.
+func (r *Keyring) Current() (id string) {
+	id = r.current.ID
+	return
+}
+
+//
+// KeyID returns the ID of the key used to encrypt the ciphertext.
+// Returns versioned=false for unversioned (legacy) ciphertext.
+func (r *Keyring) KeyID(encrypted string) (id string, versioned bool) {
+	if !strings.HasPrefix(encrypted, Delimiter) {
+		return
+	}
+	part := strings.SplitN(encrypted[1:], Delimiter, 2)
+	if len(part) == 2 {
+		id = part[0]
+		versioned = true
+	}
+	return
+}
+
+//
+// Encrypt plain string using the current key.
+// Returns versioned ciphertext.
+func (r *Keyring) Encrypt(plain string) (encrypted string, err error) {
+	if plain == "" {
+		return
+	}
+	encrypted, err = r.current.Encrypt(plain)
+	if err != nil {
+		return
+	}
+	encrypted = Delimiter + r.current.ID + Delimiter + encrypted
+	return
+}
+
+//
+// Decrypt (versioned or legacy) ciphertext.
+func (r *Keyring) Decrypt(encrypted string) (plain string, err error) {
+	if encrypted == "" {
+		return
+	}
+	key := r.legacy
+	id, versioned := r.KeyID(encrypted)
+	if versioned {
+		found := false
+		key, found = r.keys[id]
+		if !found {
+			err = &KeyNotFound{ID: id}
+			return
+		}
+		encrypted = encrypted[len(id)+2:]
+	}
+	plain, err = key.Decrypt(encrypted)
+	return
+}
+
+//
+// KeyNotFound reports key not found in the keyring.
+type KeyNotFound struct {
+	ID string
+}
+
+func (e *KeyNotFound) Error() string {
+	return "Encryption key: " + e.ID + " not found."
+}
+
+func (e *KeyNotFound) Is(err error) (matched bool) {
+	_, matched = err.(*KeyNotFound)
+	return
+}
@@ -0,0 +1,341 @@
+package event
+
+import (
+	"context"
+	liberr "github.com/jortel/go-utils/error"
+	"gorm.io/gorm"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+//
+// Kinds (resources) for which events are emitted.
+// Indexed by model (schema) name.
+var Kinds = map[string]string{
+	"Analysis":         "analysis",
+	"Application":      "application",
+	"Archetype":        "archetype",
+	"Assessment":       "assessment",
+	"BusinessService":  "businessservice",
+	"Identity":         "identity",
+	"JobFunction":      "jobfunction",
+	"MigrationWave":    "migrationwave",
+	"Proxy":            "proxy",
+	"Questionnaire":    "questionnaire",
+	"Review":           "review",
+	"RuleSet":          "ruleset",
+	"Setting":          "setting",
+	"Stakeholder":      "stakeholder",
+	"StakeholderGroup": "stakeholdergroup",
+	"Tag":              "tag",
+	"TagCategory":      "tagcategory",
+	"Target":           "target",
+	"Task":             "task",
+	"TaskGroup":        "taskgroup",
+	"Ticket":           "ticket",
+	"Tracker":          "tracker",
+}
+
+//
+// Instance keys.
+const (
+	// stateKey the task state transition.
+	stateKey = "event:state"
+	// idsKey the IDs of the models affected.
+	idsKey = "event:ids"
+)
+
+//
+// Register the callbacks used to emit events.
+func Register(db *gorm.DB) (err error) {
+	callback := db.Callback()
+	err = callback.Create().After("gorm:create").Register(
+		"event:create",
+		func(tx *gorm.DB) {
+			emit(tx, Created)
+		})
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	err = callback.Update().Before("gorm:update").Register(
+		"event:ids",
+		func(tx *gorm.DB) {
+			affected(tx)
+		})
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	err = callback.Update().Before("gorm:update").After("event:ids").Register(
+		"event:state",
+		transition)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	err = callback.Update().After("gorm:update").Register(
+		"event:update",
+		func(tx *gorm.DB) {
+			emit(tx, Updated)
+		})
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	err = callback.Delete().Before("gorm:delete").Register(
+		"event:ids",
+		func(tx *gorm.DB) {
+			affected(tx)
+		})
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	err = callback.Delete().After("gorm:delete").Register(
+		"event:delete",
+		func(tx *gorm.DB) {
+			emit(tx, Deleted)
+		})
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// collectorKey is the (statement) context key for the collector.
+type collectorKey struct{}
+
+//
+// Collector collects events emitted within a transaction.
+// The events are published after the transaction is committed.
+type Collector struct {
+	events []Event
+	mutex  sync.Mutex
+}
+
+//
+// WithCollector returns a context with a new collector.
+func WithCollector(ctx context.Context) (cx context.Context, collector *Collector) {
+	collector = &Collector{}
+	cx = context.WithValue(ctx, collectorKey{}, collector)
+	return
+}
+
+//
+// Add events.
+func (r *Collector) Add(events ...Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, events...)
+}
+
+//
+// Publish the collected events.
+func (r *Collector) Publish() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(r.events) > 0 {
+		Bus.Publish(r.events...)
+		r.events = nil
+	}
+}
+
+//
+// Discard the collected events.
+func (r *Collector) Discard() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = nil
+}
+
+//
+// transition records the task state before the update.
+func transition(tx *gorm.DB) {
+	stmt := tx.Statement
+	if tx.Error != nil || stmt.Schema == nil || stmt.Schema.Name != "Task" {
+		return
+	}
+	next, found := field(stmt, "State")
+	if !found {
+		return
+	}
+	list := affected(tx)
+	if len(list) != 1 {
+		return
+	}
+	var current string
+	db := tx.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+	err := db.Table(stmt.Table).Select("State").Where("ID = ?", list[0]).Scan(&current).Error
+	if err != nil {
+		return
+	}
+	if current != next {
+		tx.InstanceSet(stateKey, next)
+	}
+}
+
+//
+// emit events for the statement.
+// Collected when a collector is found in the statement context.
+// Otherwise, published.
+// Task updates not changing the state are only emitted when
+// collected (API requests).
+func emit(tx *gorm.DB, action string) {
+	stmt := tx.Statement
+	if tx.Error != nil || tx.RowsAffected == 0 || stmt.Schema == nil {
+		return
+	}
+	kind, found := Kinds[stmt.Schema.Name]
+	if !found {
+		return
+	}
+	collector, collected := stmt.Context.Value(collectorKey{}).(*Collector)
+	state := ""
+	if action == Updated && kind == "task" {
+		v, found := tx.InstanceGet(stateKey)
+		if found {
+			state, _ = v.(string)
+			action = State
+		} else if !collected {
+			return
+		}
+	}
+	var list []uint
+	if action == Created {
+		list = ids(stmt)
+	} else {
+		list = affected(tx)
+	}
+	var events []Event
+	for _, id := range list {
+		events = append(
+			events,
+			Event{
+				Kind:     kind,
+				Action:   action,
+				Resource: id,
+				State:    state,
+			})
+	}
+	if len(events) == 0 {
+		return
+	}
+	if collected {
+		collector.Add(events...)
+	} else {
+		Bus.Publish(events...)
+	}
+}
+
+//
+// affected returns the IDs of the models affected by the
+// update (or delete). Resolved using the statement model(s) or,
+// when not found, queried using the statement conditions. Must be
+// resolved before the statement is executed; the IDs are stored on
+// the instance for the callbacks executed after.
+func affected(tx *gorm.DB) (list []uint) {
+	stmt := tx.Statement
+	if tx.Error != nil || stmt.Schema == nil {
+		return
+	}
+	if _, found := Kinds[stmt.Schema.Name]; !found {
+		return
+	}
+	v, found := tx.InstanceGet(idsKey)
+	if found {
+		list, _ = v.([]uint)
+		return
+	}
+	list = ids(stmt)
+	if len(list) == 0 {
+		list = selected(tx)
+	}
+	tx.InstanceSet(idsKey, list)
+	return
+}
+
+//
+// selected returns the (primary key) IDs of the models
+// selected by the statement conditions (where clause).
+func selected(tx *gorm.DB) (list []uint) {
+	stmt := tx.Statement
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return
+	}
+	where, found := stmt.Clauses["WHERE"]
+	if !found {
+		return
+	}
+	db := tx.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+	db = db.Model(reflect.New(stmt.Schema.ModelType).Interface())
+	db = db.Clauses(where.Expression)
+	err := db.Pluck(pk.DBName, &list).Error
+	if err != nil {
+		Log.Error(err, "Affected IDs not selected.", "table", stmt.Table)
+		list = nil
+	}
+	return
+}
+
+//
+// ids returns the (primary key) IDs of the statement model(s).
+func ids(stmt *gorm.Statement) (list []uint) {
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return
+	}
+	add := func(v reflect.Value) {
+		v = reflect.Indirect(v)
+		if v.Kind() != reflect.Struct || v.Type() != stmt.Schema.ModelType {
+			return
+		}
+		value, zero := pk.ValueOf(stmt.Context, v)
+		if zero {
+			return
+		}
+		if id, cast := value.(uint); cast {
+			list = append(list, id)
+		}
+	}
+	v := stmt.ReflectValue
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			add(v.Index(i))
+		}
+	default:
+		add(v)
+	}
+	return
+}
+
+//
+// field returns the (string) value of the field being updated.
+func field(stmt *gorm.Statement, name string) (value string, found bool) {
+	switch dest := stmt.Dest.(type) {
+	case map[string]interface{}:
+		for k, v := range dest {
+			if strings.EqualFold(k, name) {
+				value, found = v.(string)
+				return
+			}
+		}
+		return
+	}
+	f := stmt.Schema.LookUpField(name)
+	if f == nil {
+		return
+	}
+	v := reflect.Indirect(reflect.ValueOf(stmt.Dest))
+	if v.Kind() != reflect.Struct || v.Type() != stmt.Schema.ModelType {
+		return
+	}
+	fv, _ := f.ValueOf(stmt.Context, v)
+	value, found = fv.(string)
+	return
+}
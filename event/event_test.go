@@ -0,0 +1,221 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	v12 "github.com/konveyor/tackle2-hub/migration/v12/model"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/settings"
+	"github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBus(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	bus := &EventBus{}
+	all, _ := bus.Subscribe(0)
+	tags, _ := bus.Subscribe(0, "Tag,application")
+	bus.Publish(
+		Event{Kind: "tag", Action: Created, Resource: 1},
+		Event{Kind: "task", Action: State, Resource: 2, State: "Running"})
+	g.Expect(len(all.Events)).To(gomega.Equal(2))
+	g.Expect(len(tags.Events)).To(gomega.Equal(1))
+	e := <-tags.Events
+	g.Expect(e.ID > Epoch(time.Now().Add(-time.Minute))).To(gomega.BeTrue())
+	g.Expect(e.Name()).To(gomega.Equal("tag.created"))
+	// replay.
+	_, replay := bus.Subscribe(e.ID)
+	g.Expect(len(replay)).To(gomega.Equal(1))
+	g.Expect(replay[0].State).To(gomega.Equal("Running"))
+	// slow subscriber dropped.
+	for i := 0; i < Backlog; i++ {
+		bus.Publish(Event{Kind: "task", Action: Updated})
+	}
+	_, open := <-all.Events
+	g.Expect(open).To(gomega.BeTrue())
+	for range all.Events {
+	}
+	g.Expect(bus.subscribers[all]).To(gomega.BeFalse())
+	bus.Unsubscribe(all)
+}
+
+func TestCallbacks(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db := testDB(t)
+	Bus = &EventBus{}
+	sub, _ := Bus.Subscribe(0)
+	next := func() (e Event) {
+		select {
+		case e = <-sub.Events:
+		default:
+		}
+		return
+	}
+	// create, update, delete.
+	m := &model.JobFunction{Name: "a"}
+	g.Expect(db.Create(m).Error).To(gomega.BeNil())
+	e := next()
+	g.Expect(e.Name()).To(gomega.Equal("jobfunction.created"))
+	g.Expect(e.Resource).To(gomega.Equal(m.ID))
+	m.Name = "b"
+	g.Expect(db.Save(m).Error).To(gomega.BeNil())
+	g.Expect(next().Name()).To(gomega.Equal("jobfunction.updated"))
+	g.Expect(db.Delete(m).Error).To(gomega.BeNil())
+	g.Expect(next().Name()).To(gomega.Equal("jobfunction.deleted"))
+	// updated and deleted by condition (where).
+	m = &model.JobFunction{Name: "d"}
+	g.Expect(db.Create(m).Error).To(gomega.BeNil())
+	g.Expect(next().Name()).To(gomega.Equal("jobfunction.created"))
+	err := db.Model(&model.JobFunction{}).Where("Name", "d").Update("Name", "e").Error
+	g.Expect(err).To(gomega.BeNil())
+	e = next()
+	g.Expect(e.Name()).To(gomega.Equal("jobfunction.updated"))
+	g.Expect(e.Resource).To(gomega.Equal(m.ID))
+	g.Expect(db.Delete(&model.JobFunction{}, m.ID).Error).To(gomega.BeNil())
+	e = next()
+	g.Expect(e.Name()).To(gomega.Equal("jobfunction.deleted"))
+	g.Expect(e.Resource).To(gomega.Equal(m.ID))
+	m = &model.JobFunction{Name: "f"}
+	g.Expect(db.Create(m).Error).To(gomega.BeNil())
+	g.Expect(next().Name()).To(gomega.Equal("jobfunction.created"))
+	g.Expect(db.Delete(&model.JobFunction{}, "Name", "f").Error).To(gomega.BeNil())
+	e = next()
+	g.Expect(e.Name()).To(gomega.Equal("jobfunction.deleted"))
+	g.Expect(e.Resource).To(gomega.Equal(m.ID))
+	g.Expect(db.Delete(&model.JobFunction{}, "Name", "none").Error).To(gomega.BeNil())
+	g.Expect(next().ID).To(gomega.BeZero())
+	// task state transition.
+	task := &model.Task{Name: "t", State: "Created"}
+	g.Expect(db.Create(task).Error).To(gomega.BeNil())
+	g.Expect(next().Name()).To(gomega.Equal("task.created"))
+	task.State = "Running"
+	g.Expect(db.Save(task).Error).To(gomega.BeNil())
+	e = next()
+	g.Expect(e.Name()).To(gomega.Equal("task.state"))
+	g.Expect(e.State).To(gomega.Equal("Running"))
+	g.Expect(db.Model(task).Update("State", "Succeeded").Error).To(gomega.BeNil())
+	g.Expect(next().State).To(gomega.Equal("Succeeded"))
+	// task updated (not collected) without a transition.
+	task.Priority = 10
+	g.Expect(db.Save(task).Error).To(gomega.BeNil())
+	g.Expect(next().ID).To(gomega.BeZero())
+	// collected.
+	cx, collector := WithCollector(context.Background())
+	m = &model.JobFunction{Name: "c"}
+	g.Expect(db.WithContext(cx).Create(m).Error).To(gomega.BeNil())
+	g.Expect(next().ID).To(gomega.BeZero())
+	collector.Publish()
+	g.Expect(next().Name()).To(gomega.Equal("jobfunction.created"))
+	cx, collector = WithCollector(context.Background())
+	g.Expect(db.WithContext(cx).Delete(m).Error).To(gomega.BeNil())
+	collector.Discard()
+	collector.Publish()
+	g.Expect(next().ID).To(gomega.BeZero())
+}
+
+func TestWebhook(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db := testDB(t)
+	Bus = &EventBus{}
+	Backoff = 0
+	var mutex sync.Mutex
+	var received []*http.Request
+	var payload []byte
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				payload, _ = io.ReadAll(r.Body)
+				received = append(received, r)
+				if len(received) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+	defer server.Close()
+	kinds, _ := json.Marshal([]string{"task.state"})
+	webhook := &model.Webhook{
+		Name:    "test",
+		URL:     server.URL,
+		Secret:  "secret",
+		Kinds:   kinds,
+		Enabled: true,
+	}
+	g.Expect(webhook.Encrypt()).To(gomega.BeNil())
+	g.Expect(webhook.Secret).ToNot(gomega.Equal("secret"))
+	g.Expect(db.Create(webhook).Error).To(gomega.BeNil())
+	manager := Manager{DB: db}
+	manager.received(&Event{ID: 1, Kind: "task", Action: Created})
+	manager.received(&Event{ID: 2, Kind: "task", Action: State, State: "Running"})
+	var list []model.WebhookDelivery
+	g.Expect(db.Find(&list).Error).To(gomega.BeNil())
+	g.Expect(len(list)).To(gomega.Equal(1))
+	// failed (503) then delivered.
+	manager.deliverPending()
+	manager.deliverPending()
+	delivery := &model.WebhookDelivery{}
+	g.Expect(db.First(delivery, list[0].ID).Error).To(gomega.BeNil())
+	g.Expect(delivery.State).To(gomega.Equal(Delivered))
+	g.Expect(delivery.Attempts).To(gomega.Equal(2))
+	g.Expect(delivery.Status).To(gomega.Equal(http.StatusOK))
+	mutex.Lock()
+	defer mutex.Unlock()
+	g.Expect(len(received)).To(gomega.Equal(2))
+	r := received[1]
+	g.Expect(r.Header.Get(HeaderEvent)).To(gomega.Equal("task.state"))
+	g.Expect(r.Header.Get(HeaderSignature)).To(gomega.Equal(Sign("secret", payload)))
+	e := Event{}
+	g.Expect(json.Unmarshal(payload, &e)).To(gomega.BeNil())
+	g.Expect(e.State).To(gomega.Equal("Running"))
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db := testDB(t)
+	Backoff = time.Hour
+	webhook := &model.Webhook{Name: "test", URL: "http://127.0.0.1:1", Enabled: true}
+	g.Expect(db.Create(webhook).Error).To(gomega.BeNil())
+	manager := Manager{DB: db}
+	manager.received(&Event{ID: 1, Kind: "tag", Action: Deleted})
+	delivery := &model.WebhookDelivery{}
+	g.Expect(db.Preload("Webhook").First(delivery).Error).To(gomega.BeNil())
+	for i := 0; i < Retries; i++ {
+		g.Expect(delivery.State).To(gomega.Equal(Pending))
+		manager.deliver(delivery)
+	}
+	g.Expect(delivery.State).To(gomega.Equal(Failed))
+	g.Expect(delivery.NextAttempt).To(gomega.BeNil())
+	g.Expect(delivery.Error).ToNot(gomega.BeEmpty())
+}
+
+func testDB(t *testing.T) (db *gorm.DB) {
+	g := gomega.NewGomegaWithT(t)
+	db, err := gorm.Open(
+		sqlite.Open(path.Join(t.TempDir(), "test.db")),
+		&gorm.Config{
+			NamingStrategy: &schema.NamingStrategy{
+				SingularTable: true,
+				NoLowerCase:   true,
+			},
+		})
+	g.Expect(err).To(gomega.BeNil())
+	err = db.AutoMigrate(v12.All()...)
+	g.Expect(err).To(gomega.BeNil())
+	saved := settings.Settings.Hub.Bucket.Path
+	settings.Settings.Hub.Bucket.Path = t.TempDir()
+	t.Cleanup(func() {
+		settings.Settings.Hub.Bucket.Path = saved
+	})
+	err = Register(db)
+	g.Expect(err).To(gomega.BeNil())
+	return
+}
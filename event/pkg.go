@@ -0,0 +1,177 @@
+package event
+
+import (
+	"github.com/jortel/go-utils/logr"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	Log = logr.WithName("event")
+)
+
+//
+// Bus is the hub event bus.
+var Bus = &EventBus{}
+
+//
+// Actions.
+const (
+	Created = "created"
+	Updated = "updated"
+	Deleted = "deleted"
+	// State (task) transition.
+	State = "state"
+)
+
+//
+// Limits.
+const (
+	// History (events) retained for replay.
+	History = 1000
+	// Backlog (events) buffered per subscriber.
+	Backlog = 100
+)
+
+//
+// Event reports a change to a hub resource.
+type Event struct {
+	ID       uint64    `json:"id"`
+	Time     time.Time `json:"time"`
+	Kind     string    `json:"kind"`
+	Action   string    `json:"action"`
+	Resource uint      `json:"resource"`
+	// State (task) after the transition.
+	State string `json:"state,omitempty"`
+}
+
+//
+// Name returns the event name.
+// Format: <kind>.<action>.
+func (e Event) Name() (s string) {
+	s = e.Kind + "." + e.Action
+	return
+}
+
+//
+// Subscriber to the event bus.
+// The channel is closed when the subscriber is dropped
+// because the backlog has been exceeded.
+type Subscriber struct {
+	Events chan Event
+	kinds  map[string]bool
+}
+
+//
+// Match returns true when the subscriber is interested in the event.
+func (s *Subscriber) Match(e *Event) (matched bool) {
+	matched = len(s.kinds) == 0 || s.kinds[e.Kind]
+	return
+}
+
+//
+// EpochShift bits reserved for the events published each
+// second (on average) since the bus was started.
+const EpochShift = 20
+
+//
+// Epoch returns the first event ID for a bus started at the
+// specified time. Event IDs are seeded using the (unix) start time
+// shifted by EpochShift so IDs issued after a restart are greater
+// than IDs issued before (Last-Event-ID). The IDs fit (53 bits) in
+// a JSON number.
+func Epoch(started time.Time) (id uint64) {
+	id = uint64(started.Unix()) << EpochShift
+	return
+}
+
+//
+// EventBus delivers events to subscribers.
+// Recent events are retained (history) to support replay
+// for subscribers (re)connecting.
+type EventBus struct {
+	next        uint64
+	history     []Event
+	subscribers map[*Subscriber]bool
+	mutex       sync.Mutex
+}
+
+//
+// Publish events.
+// Subscribers that cannot keep up are dropped.
+func (b *EventBus) Publish(events ...Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.next == 0 {
+		b.next = Epoch(time.Now())
+	}
+	for _, e := range events {
+		b.next++
+		e.ID = b.next
+		if e.Time.IsZero() {
+			e.Time = time.Now()
+		}
+		b.history = append(b.history, e)
+		if len(b.history) > History {
+			b.history = b.history[len(b.history)-History:]
+		}
+		for s := range b.subscribers {
+			if !s.Match(&e) {
+				continue
+			}
+			select {
+			case s.Events <- e:
+			default:
+				Log.Info("Subscriber dropped.", "event", e.ID)
+				delete(b.subscribers, s)
+				close(s.Events)
+			}
+		}
+	}
+}
+
+//
+// Subscribe to events of the specified kinds.
+// Empty kinds = all. Events (retained) after the specified
+// event ID are returned for replay.
+func (b *EventBus) Subscribe(since uint64, kinds ...string) (s *Subscriber, replay []Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	s = &Subscriber{
+		Events: make(chan Event, Backlog),
+		kinds:  make(map[string]bool),
+	}
+	for _, kind := range kinds {
+		for _, k := range strings.Split(kind, ",") {
+			k = strings.TrimSpace(k)
+			if k != "" {
+				s.kinds[strings.ToLower(k)] = true
+			}
+		}
+	}
+	if since > 0 {
+		for i := range b.history {
+			e := &b.history[i]
+			if e.ID > since && s.Match(e) {
+				replay = append(replay, *e)
+			}
+		}
+	}
+	if b.subscribers == nil {
+		b.subscribers = make(map[*Subscriber]bool)
+	}
+	b.subscribers[s] = true
+	return
+}
+
+//
+// Unsubscribe the subscriber.
+func (b *EventBus) Unsubscribe(s *Subscriber) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.subscribers[s] {
+		delete(b.subscribers, s)
+		close(s.Events)
+	}
+}
@@ -0,0 +1,263 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//
+// Delivery states.
+const (
+	Pending   = "Pending"
+	Delivered = "Delivered"
+	Failed    = "Failed"
+)
+
+//
+// Webhook headers.
+const (
+	HeaderEvent     = "X-Hub-Event"
+	HeaderDelivery  = "X-Hub-Delivery"
+	HeaderSignature = "X-Hub-Signature-256"
+)
+
+//
+// Webhook delivery settings.
+var (
+	// Retries (attempts) before the delivery has failed.
+	Retries = 5
+	// Backoff (initial) between attempts. Doubled for each attempt.
+	Backoff = time.Second * 10
+	// Timeout for requests to the webhook.
+	Timeout = time.Second * 10
+	// Retention of the delivery log.
+	Retention = time.Hour * 24 * 7
+)
+
+//
+// Manager delivers events to webhooks.
+type Manager struct {
+	// DB
+	DB *gorm.DB
+	// last event received.
+	last uint64
+	// pruned timestamp.
+	pruned time.Time
+}
+
+//
+// Run the manager.
+func (m *Manager) Run(ctx context.Context) {
+	go func() {
+		Log.Info("Webhook manager started.")
+		defer Log.Info("Webhook manager died.")
+		sub, _ := Bus.Subscribe(0)
+		defer func() {
+			Bus.Unsubscribe(sub)
+		}()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, open := <-sub.Events:
+				if !open {
+					var replay []Event
+					sub, replay = Bus.Subscribe(m.last)
+					for i := range replay {
+						m.received(&replay[i])
+					}
+					continue
+				}
+				m.received(&e)
+			case <-ticker.C:
+				m.deliverPending()
+				m.prune()
+			}
+		}
+	}()
+}
+
+//
+// received creates deliveries for the webhooks
+// interested in the event.
+func (m *Manager) received(e *Event) {
+	m.last = e.ID
+	var list []model.Webhook
+	err := m.DB.Find(&list, "Enabled", true).Error
+	if err != nil {
+		Log.Error(err, "Failed to query webhooks.")
+		return
+	}
+	payload, _ := json.Marshal(e)
+	now := time.Now()
+	for i := range list {
+		webhook := &list[i]
+		if !m.match(webhook, e) {
+			continue
+		}
+		delivery := &model.WebhookDelivery{
+			WebhookID:   webhook.ID,
+			Event:       e.ID,
+			Kind:        e.Kind,
+			Action:      e.Action,
+			Payload:     payload,
+			State:       Pending,
+			NextAttempt: &now,
+		}
+		err = m.DB.Create(delivery).Error
+		if err != nil {
+			Log.Error(err, "Failed to create delivery.", "webhook", webhook.ID)
+		}
+	}
+}
+
+//
+// match returns true when the webhook is interested in the event.
+func (m *Manager) match(webhook *model.Webhook, e *Event) (matched bool) {
+	var kinds []string
+	_ = json.Unmarshal(webhook.Kinds, &kinds)
+	if len(kinds) == 0 {
+		matched = true
+		return
+	}
+	for _, kind := range kinds {
+		if kind == e.Kind || kind == e.Name() {
+			matched = true
+			break
+		}
+	}
+	return
+}
+
+//
+// deliverPending delivers pending deliveries due to be attempted.
+func (m *Manager) deliverPending() {
+	var list []model.WebhookDelivery
+	db := m.DB.Preload("Webhook")
+	db = db.Where("State = ? AND NextAttempt <= ?", Pending, time.Now())
+	err := db.Order("ID").Limit(100).Find(&list).Error
+	if err != nil {
+		Log.Error(err, "Failed to query deliveries.")
+		return
+	}
+	for i := range list {
+		delivery := &list[i]
+		m.deliver(delivery)
+		err = m.DB.Omit(clause.Associations).Save(delivery).Error
+		if err != nil {
+			Log.Error(err, "Failed to update delivery.", "id", delivery.ID)
+		}
+	}
+}
+
+//
+// deliver (attempt) the delivery.
+func (m *Manager) deliver(delivery *model.WebhookDelivery) {
+	delivery.Attempts++
+	err := m.post(delivery)
+	now := time.Now()
+	if err == nil {
+		delivery.State = Delivered
+		delivery.Delivered = &now
+		delivery.NextAttempt = nil
+		delivery.Error = ""
+		return
+	}
+	delivery.Error = err.Error()
+	if delivery.Attempts >= Retries {
+		delivery.State = Failed
+		delivery.NextAttempt = nil
+		Log.Info(
+			"Delivery failed.",
+			"id",
+			delivery.ID,
+			"webhook",
+			delivery.WebhookID,
+			"error",
+			delivery.Error)
+		return
+	}
+	next := now.Add(Backoff * time.Duration(1<<(delivery.Attempts-1)))
+	delivery.NextAttempt = &next
+}
+
+//
+// post the payload to the webhook.
+func (m *Manager) post(delivery *model.WebhookDelivery) (err error) {
+	webhook := delivery.Webhook
+	if webhook == nil {
+		err = liberr.New("Webhook not found.")
+		return
+	}
+	request, err := http.NewRequest(
+		http.MethodPost,
+		webhook.URL,
+		bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEvent, delivery.Kind+"."+delivery.Action)
+	request.Header.Set(HeaderDelivery, strconv.Itoa(int(delivery.ID)))
+	if webhook.Secret != "" {
+		decrypted := *webhook
+		err = decrypted.Decrypt()
+		if err != nil {
+			return
+		}
+		request.Header.Set(HeaderSignature, Sign(decrypted.Secret, delivery.Payload))
+	}
+	client := &http.Client{Timeout: Timeout}
+	response, err := client.Do(request)
+	if err != nil {
+		return
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+	delivery.Status = response.StatusCode
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		err = liberr.New("Webhook responded: " + response.Status)
+	}
+	return
+}
+
+//
+// prune the delivery log.
+func (m *Manager) prune() {
+	now := time.Now()
+	if now.Sub(m.pruned) < time.Hour {
+		return
+	}
+	m.pruned = now
+	err := m.DB.Delete(
+		&model.WebhookDelivery{},
+		"CreateTime < ?",
+		now.Add(-Retention)).Error
+	if err != nil {
+		Log.Error(err, "Failed to prune deliveries.")
+	}
+}
+
+//
+// Sign returns the (HMAC) signature of the payload.
+// Format: sha256=<hex>.
+func Sign(secret string, payload []byte) (signature string) {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(payload)
+	signature = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return
+}
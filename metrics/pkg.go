@@ -22,4 +22,28 @@ var (
 		Name: "konveyor_issues_exported_total",
 		Help: "The total number of issues exported to external trackers",
 	})
+	RequestsThrottled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "konveyor_requests_throttled_total",
+		Help: "The total number of API requests rejected by rate limiting",
+	}, []string{"group"})
+	StorageUsage = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "konveyor_storage_usage_bytes",
+		Help: "The current storage usage (bytes) by owner kind",
+	}, []string{"kind"})
+	CacheSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "konveyor_cache_size_bytes",
+		Help: "The current size (bytes) of the cache volumes",
+	}, []string{"cache"})
+	CacheHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "konveyor_cache_hits_total",
+		Help: "The total number of cache volume leases granted with content",
+	}, []string{"cache"})
+	CacheMisses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "konveyor_cache_misses_total",
+		Help: "The total number of cache volume leases granted without content",
+	}, []string{"cache"})
+	CacheEvictions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "konveyor_cache_evictions_total",
+		Help: "The total number of cache volumes evicted",
+	}, []string{"cache"})
 )
@@ -0,0 +1,42 @@
+package v12
+
+import (
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/jortel/go-utils/logr"
+	"github.com/konveyor/tackle2-hub/migration/v12/model"
+	"gorm.io/gorm"
+)
+
+var log = logr.WithName("migration|v12")
+
+type Migration struct{}
+
+func (r Migration) Apply(db *gorm.DB) (err error) {
+	err = r.fileIndex(db)
+	if err != nil {
+		return
+	}
+	err = db.AutoMigrate(r.Models()...)
+	return
+}
+
+//
+// fileIndex drops the (unique) File.Path index.
+// Content may be shared by files.
+func (r Migration) fileIndex(db *gorm.DB) (err error) {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&model.File{}) {
+		return
+	}
+	if migrator.HasIndex(&model.File{}, "idx_File_Path") {
+		err = migrator.DropIndex(&model.File{}, "idx_File_Path")
+		if err != nil {
+			err = liberr.Wrap(err)
+		}
+	}
+	return
+}
+
+func (r Migration) Models() []interface{} {
+	return model.All()
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+//
+// Advisory vulnerability advisory.
+// Loaded from an offline (OSV) feed.
+type Advisory struct {
+	Model
+	Key        string `gorm:"uniqueIndex;not null"`
+	Summary    string
+	Severity   string
+	Aliases    JSON `gorm:"type:json"`
+	Affected   JSON `gorm:"type:json"`
+	References JSON `gorm:"type:json"`
+	Published  time.Time
+	Modified   time.Time
+}
+
+//
+// DependencyAdvisory advisory matched to a tech dependency.
+type DependencyAdvisory struct {
+	TechDependencyID uint           `gorm:"primaryKey"`
+	AdvisoryID       uint           `gorm:"primaryKey;index"`
+	TechDependency   TechDependency `gorm:"constraint:OnDelete:CASCADE"`
+	Advisory         Advisory       `gorm:"constraint:OnDelete:CASCADE"`
+}
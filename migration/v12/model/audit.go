@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+//
+// AuditEntry (append-only) audit trail entry.
+// Records a mutating (or otherwise sensitive) API request.
+type AuditEntry struct {
+	ID         uint      `gorm:"primaryKey"`
+	CreateTime time.Time `gorm:"index;autoCreateTime"`
+	User       string    `gorm:"index"`
+	Method     string
+	Route      string
+	Path       string
+	Kind       string `gorm:"index"`
+	ResourceID *uint
+	Status     int
+	Outcome    string
+	Changes    JSON `gorm:"type:json"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+//
+// CacheVolume a named (shared) addon cache.
+// The content is stored in a directory (named) within
+// the cache volume (PVC).
+type CacheVolume struct {
+	Model
+	Name string `gorm:"index;unique;not null"`
+	// Size (bytes) measured.
+	Size    int64
+	Hits    int64
+	Misses  int64
+	Evicted int64
+	// LastUsed when a lease was last granted or released.
+	LastUsed time.Time
+	Leases   []CacheLease `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+//
+// CacheLease cache volume lease granted to a task.
+// A read-write lease is exclusive.
+type CacheLease struct {
+	Model
+	ReadOnly      bool
+	CacheVolumeID uint `gorm:"index;not null"`
+	CacheVolume   *CacheVolume
+	TaskID        uint  `gorm:"index;not null"`
+	Task          *Task `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+//
+// CacheMount (named) cache volume requested by a task.
+type CacheMount struct {
+	Name     string `json:"name"`
+	ReadOnly bool   `json:"readOnly,omitempty"`
+}
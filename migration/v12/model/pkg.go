@@ -0,0 +1,71 @@
+package model
+
+import "github.com/konveyor/tackle2-hub/settings"
+
+var (
+	Settings = &settings.Settings
+)
+
+//
+// JSON field (data) type.
+type JSON = []byte
+
+//
+// All builds all models.
+// Models are enumerated such that each are listed after
+// all the other models on which they may depend.
+func All() []interface{} {
+	return []interface{}{
+		Application{},
+		Advisory{},
+		TechDependency{},
+		DependencyAdvisory{},
+		Incident{},
+		Analysis{},
+		Issue{},
+		Bucket{},
+		BucketSnapshot{},
+		BusinessService{},
+		Dependency{},
+		File{},
+		FileContent{},
+		Fact{},
+		Identity{},
+		Import{},
+		ImportSummary{},
+		ImportTag{},
+		JobFunction{},
+		MigrationWave{},
+		Proxy{},
+		Review{},
+		Setting{},
+		RuleSet{},
+		Rule{},
+		RuleSetRevision{},
+		RevisionRule{},
+		Stakeholder{},
+		StakeholderGroup{},
+		Tag{},
+		TagCategory{},
+		Target{},
+		Task{},
+		TaskGroup{},
+		TaskReport{},
+		Ticket{},
+		Tracker{},
+		ApplicationTag{},
+		Questionnaire{},
+		Assessment{},
+		Archetype{},
+		ServiceAccount{},
+		APIToken{},
+		AuditEntry{},
+		Role{},
+		User{},
+		Webhook{},
+		WebhookDelivery{},
+		ReaperRun{},
+		CacheVolume{},
+		CacheLease{},
+	}
+}
@@ -0,0 +1,15 @@
+package model
+
+//
+// ReaperRun reaper run (history).
+// The CreateTime is when the run started.
+type ReaperRun struct {
+	Model
+	Manual bool
+	// Duration (milliseconds).
+	Duration int64
+	// Counts keyed by kind.action.
+	Counts JSON `gorm:"type:json"`
+	// Reclaimed (bytes).
+	Reclaimed int64
+}
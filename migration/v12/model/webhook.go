@@ -0,0 +1,74 @@
+package model
+
+import (
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/encryption"
+	"time"
+)
+
+//
+// Webhook (outbound) delivers hub events.
+// Payloads are signed (HMAC) using the secret.
+// The secret is stored encrypted.
+type Webhook struct {
+	Model
+	Name       string `gorm:"uniqueIndex;not null"`
+	URL        string `gorm:"not null"`
+	Secret     string
+	Kinds      JSON `gorm:"type:json"`
+	Enabled    bool
+	Deliveries []WebhookDelivery `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// Encrypt the secret.
+func (r *Webhook) Encrypt() (err error) {
+	if r.Secret == "" {
+		return
+	}
+	keyring := encryption.NewKeyring(
+		Settings.Encryption.Salt,
+		Settings.Encryption.Passphrase,
+		Settings.Encryption.Previous...)
+	r.Secret, err = keyring.Encrypt(r.Secret)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+// Decrypt the secret.
+func (r *Webhook) Decrypt() (err error) {
+	if r.Secret == "" {
+		return
+	}
+	keyring := encryption.NewKeyring(
+		Settings.Encryption.Salt,
+		Settings.Encryption.Passphrase,
+		Settings.Encryption.Previous...)
+	r.Secret, err = keyring.Decrypt(r.Secret)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// WebhookDelivery (log) of an event delivered to a webhook.
+type WebhookDelivery struct {
+	ID          uint      `gorm:"primaryKey"`
+	CreateTime  time.Time `gorm:"index;autoCreateTime"`
+	WebhookID   uint      `gorm:"index"`
+	Webhook     *Webhook
+	Event       uint64
+	Kind        string
+	Action      string
+	Payload     JSON   `gorm:"type:json"`
+	State       string `gorm:"index"`
+	Attempts    int
+	Status      int
+	Error       string
+	NextAttempt *time.Time
+	Delivered   *time.Time
+}
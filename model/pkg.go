@@ -1,7 +1,7 @@
 package model
 
 import (
-	"github.com/konveyor/tackle2-hub/migration/v11/model"
+	"github.com/konveyor/tackle2-hub/migration/v12/model"
 	"gorm.io/datatypes"
 )
 
@@ -12,19 +12,24 @@ type JSON = datatypes.JSON
 //
 // Models
 type Model = model.Model
+type APIToken = model.APIToken
+type Advisory = model.Advisory
 type Application = model.Application
 type Archetype = model.Archetype
 type Assessment = model.Assessment
+type AuditEntry = model.AuditEntry
 type TechDependency = model.TechDependency
 type Incident = model.Incident
 type Analysis = model.Analysis
 type ArchivedIssue = model.ArchivedIssue
 type Issue = model.Issue
 type Bucket = model.Bucket
+type BucketSnapshot = model.BucketSnapshot
 type BucketOwner = model.BucketOwner
 type BusinessService = model.BusinessService
 type Dependency = model.Dependency
 type File = model.File
+type FileContent = model.FileContent
 type Fact = model.Fact
 type Identity = model.Identity
 type Import = model.Import
@@ -35,9 +40,13 @@ type MigrationWave = model.MigrationWave
 type Proxy = model.Proxy
 type Questionnaire = model.Questionnaire
 type Review = model.Review
+type ServiceAccount = model.ServiceAccount
 type Setting = model.Setting
+type Role = model.Role
 type RuleSet = model.RuleSet
 type Rule = model.Rule
+type RuleSetRevision = model.RuleSetRevision
+type RevisionRule = model.RevisionRule
 type Stakeholder = model.Stakeholder
 type StakeholderGroup = model.StakeholderGroup
 type Tag = model.Tag
@@ -48,13 +57,21 @@ type TaskGroup = model.TaskGroup
 type TaskReport = model.TaskReport
 type Ticket = model.Ticket
 type Tracker = model.Tracker
+type User = model.User
+type Webhook = model.Webhook
+type WebhookDelivery = model.WebhookDelivery
+type ReaperRun = model.ReaperRun
+type CacheVolume = model.CacheVolume
+type CacheLease = model.CacheLease
 
 //
 type TTL = model.TTL
+type CacheMount = model.CacheMount
 
 //
 // Join tables
 type ApplicationTag = model.ApplicationTag
+type DependencyAdvisory = model.DependencyAdvisory
 
 //
 // Errors
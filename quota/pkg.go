@@ -0,0 +1,482 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jortel/go-utils/logr"
+	"github.com/konveyor/tackle2-hub/metrics"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/settings"
+	"github.com/konveyor/tackle2-hub/storage"
+	"gorm.io/gorm"
+	"io/fs"
+	"os"
+	pathlib "path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	Settings = &settings.Settings
+	Log      = logr.WithName("quota")
+)
+
+//
+// MiB megabyte.
+const MiB = int64(1024 * 1024)
+
+//
+// Owner kinds.
+const (
+	KindApplication = "application"
+	KindTask        = "task"
+	KindTaskGroup   = "taskgroup"
+	KindBucket      = "bucket"
+	KindFile        = "file"
+	KindSnapshot    = "snapshot"
+	KindCache       = "cache"
+	KindTotal       = "total"
+)
+
+//
+// tracker tracks the total (buckets and files) usage.
+// The total is (re)measured on demand after being reset.
+// Reserved is the content (bytes) being stored, in total
+// and by bucket, which has been checked but not yet written.
+var tracker struct {
+	sync.Mutex
+	measured bool
+	total    int64
+	reserved struct {
+		total   int64
+		buckets map[string]int64
+	}
+}
+
+//
+// Exceeded reports a quota has been exceeded.
+type Exceeded struct {
+	// Kind of quota (bucket|total).
+	Kind string
+	// Limit (bytes).
+	Limit int64
+	// Usage (bytes).
+	Usage int64
+	// Size (bytes) of the content to be stored.
+	Size int64
+}
+
+func (e *Exceeded) Error() string {
+	return fmt.Sprintf(
+		"Quota (%s) exceeded: limit=%d usage=%d size=%d.",
+		e.Kind,
+		e.Limit,
+		e.Usage,
+		e.Size)
+}
+
+func (e *Exceeded) Is(err error) (matched bool) {
+	_, matched = err.(*Exceeded)
+	return
+}
+
+//
+// Usage (bytes) by owner kind.
+type Usage struct {
+	Application int64
+	Task        int64
+	TaskGroup   int64
+	// Bucket not owned.
+	Bucket   int64
+	File     int64
+	Snapshot int64
+	Cache    int64
+	// Total buckets, files and snapshots.
+	// The cache is not included.
+	Total int64
+}
+
+//
+// Bucket returns the bucket quota (bytes).
+// Zero (0) = unlimited.
+func Bucket() (n int64) {
+	n = int64(Settings.Quota.Bucket) * MiB
+	return
+}
+
+//
+// Total returns the total quota (bytes).
+// Zero (0) = unlimited.
+func Total() (n int64) {
+	n = int64(Settings.Quota.Total) * MiB
+	return
+}
+
+//
+// Pressure returns the usage (bytes) at which orphaned
+// content is evicted. Zero (0) = never.
+func Pressure() (n int64) {
+	n = Total() * int64(Settings.Quota.Pressure) / 100
+	return
+}
+
+//
+// Reservation of quota for content being stored.
+// The reserved delta is counted as used by other checks
+// until committed or released.
+type Reservation struct {
+	// bucket path; empty when not stored in a bucket.
+	bucket string
+	// delta (bytes) change in stored content.
+	delta int64
+	// reserved (bytes) counted as used.
+	reserved int64
+	// used (bytes) by the bucket when first checked.
+	used int64
+	// measured indicates the bucket usage has been measured.
+	measured bool
+	// done indicates committed or released.
+	done bool
+}
+
+//
+// Delta returns the reserved change (bytes) in stored content.
+func (r *Reservation) Delta() (n int64) {
+	tracker.Lock()
+	defer tracker.Unlock()
+	n = r.delta
+	return
+}
+
+//
+// Grow the reservation by the change (bytes) in stored content.
+// The quotas are checked when the reservation is increased.
+func (r *Reservation) Grow(delta int64) (err error) {
+	tracker.Lock()
+	defer tracker.Unlock()
+	if r.done {
+		return
+	}
+	err = r.reserve(delta)
+	return
+}
+
+//
+// Commit the reservation after the content has been stored.
+// The tracked total is updated with the change (bytes) in
+// stored content.
+func (r *Reservation) Commit() {
+	tracker.Lock()
+	defer tracker.Unlock()
+	if r.done {
+		return
+	}
+	r.release()
+	if tracker.measured {
+		tracker.total += r.delta
+		metrics.StorageUsage.WithLabelValues(KindTotal).Set(float64(tracker.total))
+	}
+}
+
+//
+// Release the reservation.
+// Has no effect after the reservation is committed.
+func (r *Reservation) Release() {
+	tracker.Lock()
+	defer tracker.Unlock()
+	r.release()
+}
+
+//
+// reserve the change (bytes) in stored content.
+// The caller must hold the tracker lock.
+func (r *Reservation) reserve(delta int64) (err error) {
+	next := r.delta + delta
+	grow := -r.reserved
+	if next > 0 {
+		grow += next
+	}
+	if grow > 0 {
+		err = r.check(grow)
+		if err != nil {
+			return
+		}
+	}
+	r.delta = next
+	if grow == 0 {
+		return
+	}
+	if tracker.reserved.buckets == nil {
+		tracker.reserved.buckets = make(map[string]int64)
+	}
+	tracker.reserved.total += grow
+	if r.bucket != "" {
+		tracker.reserved.buckets[r.bucket] += grow
+		if tracker.reserved.buckets[r.bucket] == 0 {
+			delete(tracker.reserved.buckets, r.bucket)
+		}
+	}
+	r.reserved += grow
+	return
+}
+
+//
+// check the quotas allow the reserved content to grow.
+// The caller must hold the tracker lock.
+func (r *Reservation) check(grow int64) (err error) {
+	limit := Bucket()
+	if limit > 0 && r.bucket != "" {
+		if !r.measured {
+			r.used, err = Size(r.bucket)
+			if err != nil {
+				return
+			}
+			r.measured = true
+		}
+		used := r.used + tracker.reserved.buckets[r.bucket]
+		if used+grow > limit {
+			err = &Exceeded{
+				Kind:  KindBucket,
+				Limit: limit,
+				Usage: used,
+				Size:  grow,
+			}
+			return
+		}
+	}
+	limit = Total()
+	if limit > 0 {
+		var used int64
+		used, err = total()
+		if err != nil {
+			return
+		}
+		used += tracker.reserved.total
+		if used+grow > limit {
+			err = &Exceeded{
+				Kind:  KindTotal,
+				Limit: limit,
+				Usage: used,
+				Size:  grow,
+			}
+			return
+		}
+	}
+	return
+}
+
+//
+// release the reserved content.
+// The caller must hold the tracker lock.
+func (r *Reservation) release() {
+	if r.done {
+		return
+	}
+	r.done = true
+	if r.reserved == 0 {
+		return
+	}
+	tracker.reserved.total -= r.reserved
+	if r.bucket != "" {
+		tracker.reserved.buckets[r.bucket] -= r.reserved
+		if tracker.reserved.buckets[r.bucket] == 0 {
+			delete(tracker.reserved.buckets, r.bucket)
+		}
+	}
+	r.reserved = 0
+}
+
+//
+// Check the quotas allow the content to be stored.
+// The bucket is the path of the bucket receiving the
+// content; empty when not stored in a bucket.
+// The delta is the change (bytes) in stored content.
+// The delta is reserved until the returned reservation
+// is committed after the content is stored, or released.
+func Check(bucket string, delta int64) (r *Reservation, err error) {
+	if bucket != "" {
+		bucket = pathlib.Clean(bucket)
+	}
+	r = &Reservation{bucket: bucket}
+	tracker.Lock()
+	defer tracker.Unlock()
+	err = r.reserve(delta)
+	if err != nil {
+		r.done = true
+	}
+	return
+}
+
+//
+// Reset the tracked total.
+// The total is measured on next use.
+func Reset() {
+	tracker.Lock()
+	defer tracker.Unlock()
+	tracker.measured = false
+}
+
+//
+// Size returns the size (bytes) of the stored content
+// within the directory.
+func Size(path string) (n int64, err error) {
+	list, err := storage.Store.List(path)
+	if err != nil {
+		if errors.Is(err, &storage.NotFound{}) {
+			err = nil
+		}
+		return
+	}
+	for _, info := range list {
+		n += info.Size
+	}
+	return
+}
+
+//
+// Measure the usage.
+// Updates the tracked total and the usage metrics.
+func Measure(db *gorm.DB) (usage Usage, err error) {
+	kinds, err := owners(db)
+	if err != nil {
+		return
+	}
+	root := pathlib.Clean(Settings.Bucket.Path)
+	list, err := storage.Store.List(root)
+	if err != nil {
+		if !errors.Is(err, &storage.NotFound{}) {
+			return
+		}
+		err = nil
+	}
+	for _, info := range list {
+		rPath := strings.TrimPrefix(info.Path, root+"/")
+		part := strings.SplitN(rPath, "/", 2)[0]
+		switch part {
+		case ".file":
+			usage.File += info.Size
+			continue
+		case ".snapshot":
+			usage.Snapshot += info.Size
+			continue
+		}
+		switch kinds[pathlib.Join(root, part)] {
+		case KindApplication:
+			usage.Application += info.Size
+		case KindTask:
+			usage.Task += info.Size
+		case KindTaskGroup:
+			usage.TaskGroup += info.Size
+		default:
+			usage.Bucket += info.Size
+		}
+	}
+	usage.Total =
+		usage.Application +
+			usage.Task +
+			usage.TaskGroup +
+			usage.Bucket +
+			usage.File +
+			usage.Snapshot
+	usage.Cache, err = cache()
+	if err != nil {
+		return
+	}
+	tracker.Lock()
+	tracker.measured = true
+	tracker.total = usage.Total
+	tracker.Unlock()
+	for kind, n := range map[string]int64{
+		KindApplication: usage.Application,
+		KindTask:        usage.Task,
+		KindTaskGroup:   usage.TaskGroup,
+		KindBucket:      usage.Bucket,
+		KindFile:        usage.File,
+		KindSnapshot:    usage.Snapshot,
+		KindCache:       usage.Cache,
+		KindTotal:       usage.Total,
+	} {
+		metrics.StorageUsage.WithLabelValues(kind).Set(float64(n))
+	}
+	return
+}
+
+//
+// total returns the tracked total (bytes).
+// Measured as needed.
+// The caller must hold the tracker lock.
+func total() (n int64, err error) {
+	if !tracker.measured {
+		n, err = Size(Settings.Bucket.Path)
+		if err != nil {
+			return
+		}
+		tracker.total = n
+		tracker.measured = true
+	}
+	n = tracker.total
+	return
+}
+
+//
+// owners returns the owner kind of buckets keyed by path.
+func owners(db *gorm.DB) (kinds map[string]string, err error) {
+	kinds = make(map[string]string)
+	type Ref struct {
+		Path string
+	}
+	for kind, m := range map[string]interface{}{
+		KindApplication: &model.Application{},
+		KindTask:        &model.Task{},
+		KindTaskGroup:   &model.TaskGroup{},
+	} {
+		var list []Ref
+		q := db.Model(m)
+		q = q.Select("b.Path")
+		q = q.Joins("JOIN Bucket b ON b.ID = BucketID")
+		err = q.Scan(&list).Error
+		if err != nil {
+			return
+		}
+		for _, ref := range list {
+			kinds[pathlib.Clean(ref.Path)] = kind
+		}
+	}
+	return
+}
+
+//
+// cache returns the size (bytes) of the cache.
+func cache() (n int64, err error) {
+	n, err = DirSize(Settings.Cache.Path)
+	return
+}
+
+//
+// DirSize returns the size (bytes) of the regular files
+// within the (local) directory.
+func DirSize(dir string) (n int64, err error) {
+	err = filepath.WalkDir(
+		dir,
+		func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.Type().IsRegular() {
+				info, err := d.Info()
+				if err != nil {
+					if os.IsNotExist(err) {
+						return nil
+					}
+					return err
+				}
+				n += info.Size()
+			}
+			return nil
+		})
+	return
+}
@@ -0,0 +1,48 @@
+package reaper
+
+import (
+	"github.com/konveyor/tackle2-hub/model"
+	"gorm.io/gorm"
+	"time"
+)
+
+//
+// AuditReaper audit log reaper.
+type AuditReaper struct {
+	// DB
+	DB *gorm.DB
+}
+
+//
+// Run Executes the reaper.
+// Audit log entries are deleted when older than the retention.
+// A retention of 0 keeps entries indefinitely.
+// Deleted entries are counted but not individually reported.
+func (r *AuditReaper) Run(report *Report) {
+	Log.V(1).Info("Reaping audit log.")
+	retention := Settings.Audit.Retention
+	if retention < 1 {
+		return
+	}
+	mark := time.Now().Add(-time.Duration(retention) * 24 * time.Hour)
+	if report.DryRun {
+		var n int64
+		db := r.DB.Model(&model.AuditEntry{})
+		err := db.Where("CreateTime < ?", mark).Count(&n).Error
+		if err != nil {
+			Log.Error(err, "")
+			return
+		}
+		report.Count(KindAudit, ActionDeleted, int(n))
+		return
+	}
+	result := r.DB.Delete(&model.AuditEntry{}, "CreateTime < ?", mark)
+	if result.Error != nil {
+		Log.Error(result.Error, "")
+		return
+	}
+	if result.RowsAffected > 0 {
+		report.Count(KindAudit, ActionDeleted, int(result.RowsAffected))
+		Log.Info("Audit log pruned.", "count", result.RowsAffected)
+	}
+}
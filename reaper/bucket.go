@@ -3,9 +3,9 @@ package reaper
 import (
 	liberr "github.com/jortel/go-utils/error"
 	"github.com/konveyor/tackle2-hub/model"
-	"github.com/konveyor/tackle2-hub/nas"
+	"github.com/konveyor/tackle2-hub/quota"
+	"github.com/konveyor/tackle2-hub/storage"
 	"gorm.io/gorm"
-	"os"
 	"time"
 )
 
@@ -19,7 +19,9 @@ type BucketReaper struct {
 //
 // Run Executes the reaper.
 // A bucket is deleted when it is no longer referenced and the TTL has expired.
-func (r *BucketReaper) Run() {
+// A snapshot is deleted when it is no longer referenced and the snapshot TTL
+// has expired.
+func (r *BucketReaper) Run(report *Report) {
 	Log.V(1).Info("Reaping buckets.")
 	list := []model.Bucket{}
 	err := r.DB.Find(&list).Error
@@ -34,7 +36,7 @@ func (r *BucketReaper) Run() {
 			continue
 		}
 		if busy {
-			if bucket.Expiration != nil {
+			if bucket.Expiration != nil && !report.DryRun {
 				bucket.Expiration = nil
 				err = r.DB.Save(&bucket).Error
 				Log.Error(err, "")
@@ -42,6 +44,9 @@ func (r *BucketReaper) Run() {
 			continue
 		}
 		if bucket.Expiration == nil {
+			if report.DryRun {
+				continue
+			}
 			Log.Info("Bucket (orphan) found.", "id", bucket.ID, "path", bucket.Path)
 			mark := time.Now().Add(time.Minute * time.Duration(Settings.Bucket.TTL))
 			bucket.Expiration = &mark
@@ -51,15 +56,123 @@ func (r *BucketReaper) Run() {
 		}
 		mark := time.Now()
 		if mark.After(*bucket.Expiration) {
-			err = r.delete(&bucket)
+			var n int64
+			n, err = quota.Size(bucket.Path)
+			if err != nil {
+				Log.Error(err, "")
+				continue
+			}
+			if !report.DryRun {
+				err = r.delete(&bucket)
+				if err != nil {
+					Log.Error(err, "")
+					continue
+				}
+			}
+			report.Add(
+				Action{
+					Kind:   KindBucket,
+					ID:     bucket.ID,
+					Action: ActionDeleted,
+					Reason: ReasonOrphan,
+					Size:   n,
+				})
+		}
+	}
+	r.snapshots(report)
+}
+
+//
+// snapshots reaps snapshots.
+func (r *BucketReaper) snapshots(report *Report) {
+	list := []model.BucketSnapshot{}
+	err := r.DB.Find(&list).Error
+	if err != nil {
+		Log.Error(err, "")
+		return
+	}
+	for _, snapshot := range list {
+		var n int64
+		ref := RefCounter{DB: r.DB}
+		n, err = ref.Count(&model.Analysis{}, "snapshot", snapshot.ID)
+		if err != nil {
+			Log.Error(err, "")
+			continue
+		}
+		if n > 0 {
+			if snapshot.Expiration != nil && !report.DryRun {
+				snapshot.Expiration = nil
+				err = r.DB.Save(&snapshot).Error
+				Log.Error(err, "")
+			}
+			continue
+		}
+		if snapshot.Expiration == nil {
+			if report.DryRun {
+				continue
+			}
+			mark := time.Now().Add(time.Minute * time.Duration(Settings.Bucket.SnapshotTTL))
+			snapshot.Expiration = &mark
+			err = r.DB.Save(&snapshot).Error
+			Log.Error(err, "")
+			continue
+		}
+		mark := time.Now()
+		if mark.After(*snapshot.Expiration) {
+			if report.Reported(KindBucket, snapshot.BucketID) {
+				continue
+			}
+			n, err = quota.Size(snapshot.Path)
 			if err != nil {
 				Log.Error(err, "")
 				continue
 			}
+			if !report.DryRun {
+				err = r.deleteSnapshot(&snapshot)
+				if err != nil {
+					Log.Error(err, "")
+					continue
+				}
+			}
+			report.Add(
+				Action{
+					Kind:   KindSnapshot,
+					ID:     snapshot.ID,
+					Action: ActionDeleted,
+					Reason: ReasonTTL,
+					Size:   n,
+				})
 		}
 	}
 }
 
+//
+// deleteSnapshot deletes the snapshot.
+func (r *BucketReaper) deleteSnapshot(snapshot *model.BucketSnapshot) (err error) {
+	err = storage.Store.Delete(snapshot.Path)
+	if err != nil {
+		err = liberr.Wrap(
+			err,
+			"id",
+			snapshot.ID,
+			"path",
+			snapshot.Path)
+		return
+	}
+	err = r.DB.Delete(snapshot).Error
+	if err != nil {
+		err = liberr.Wrap(
+			err,
+			"id",
+			snapshot.ID,
+			"path",
+			snapshot.Path)
+		return
+	}
+	Log.Info("Snapshot deleted.", "id", snapshot.ID, "path", snapshot.Path)
+	return
+}
+
 //
 // busy determines if anything references the bucket.
 func (r *BucketReaper) busy(bucket *model.Bucket) (busy bool, err error) {
@@ -84,21 +197,30 @@ func (r *BucketReaper) busy(bucket *model.Bucket) (busy bool, err error) {
 
 //
 // Delete bucket.
+// The bucket snapshots are deleted.
 func (r *BucketReaper) delete(bucket *model.Bucket) (err error) {
-	err = nas.RmDir(bucket.Path)
+	list := []model.BucketSnapshot{}
+	err = r.DB.Find(&list, "BucketID", bucket.ID).Error
 	if err != nil {
-		if !os.IsNotExist(err) {
-			err = liberr.Wrap(
-				err,
-				"id",
-				bucket.ID,
-				"path",
-				bucket.Path)
+		err = liberr.Wrap(err, "id", bucket.ID)
+		return
+	}
+	for i := range list {
+		err = r.deleteSnapshot(&list[i])
+		if err != nil {
 			return
-		} else {
-			err = nil
 		}
 	}
+	err = storage.Store.Delete(bucket.Path)
+	if err != nil {
+		err = liberr.Wrap(
+			err,
+			"id",
+			bucket.ID,
+			"path",
+			bucket.Path)
+		return
+	}
 	err = r.DB.Delete(bucket).Error
 	if err != nil {
 		err = liberr.Wrap(
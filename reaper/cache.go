@@ -0,0 +1,189 @@
+package reaper
+
+import (
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/metrics"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/nas"
+	"github.com/konveyor/tackle2-hub/quota"
+	"github.com/konveyor/tackle2-hub/task"
+	"gorm.io/gorm"
+)
+
+//
+// MiB bytes.
+const MiB = int64(1024 * 1024)
+
+//
+// CacheReaper cache volume reaper.
+type CacheReaper struct {
+	// DB
+	DB *gorm.DB
+}
+
+//
+// Run Executes the reaper.
+// The size of each cache volume is measured (metrics). When the
+// total exceeds the capacity, volumes not leased (or requested) are
+// evicted least recently used first until the total is within
+// the capacity.
+func (r *CacheReaper) Run(report *Report) {
+	Log.V(1).Info("Reaping cache.")
+	var volumes []model.CacheVolume
+	err := r.DB.Order("LastUsed").Find(&volumes).Error
+	if err != nil {
+		Log.Error(err, "")
+		return
+	}
+	used := int64(0)
+	for i := range volumes {
+		volume := &volumes[i]
+		err = r.measure(volume, report.DryRun)
+		if err != nil {
+			Log.Error(err, "")
+			continue
+		}
+		used += volume.Size
+	}
+	capacity := int64(Settings.Cache.Capacity) * MiB
+	if capacity == 0 || used <= capacity {
+		return
+	}
+	Log.Info(
+		"Cache capacity exceeded.",
+		"usage",
+		used,
+		"capacity",
+		capacity)
+	requested, err := r.requested()
+	if err != nil {
+		Log.Error(err, "")
+		return
+	}
+	for i := range volumes {
+		if used <= capacity {
+			break
+		}
+		volume := &volumes[i]
+		if volume.Size == 0 || requested[volume.Name] {
+			continue
+		}
+		var n int64
+		n, err = r.evict(volume, report.DryRun)
+		if err != nil {
+			Log.Error(err, "")
+			continue
+		}
+		if n == 0 {
+			continue
+		}
+		report.Add(
+			Action{
+				Kind:   KindCache,
+				ID:     volume.ID,
+				Action: ActionEvicted,
+				Reason: ReasonPressure,
+				Size:   n,
+			})
+		used -= n
+	}
+}
+
+//
+// measure the cache volume.
+// The size is updated unless dry-run.
+func (r *CacheReaper) measure(volume *model.CacheVolume, dryRun bool) (err error) {
+	n, err := quota.DirSize(task.CachePath(volume.Name))
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	metrics.CacheSize.WithLabelValues(volume.Name).Set(float64(n))
+	if n == volume.Size {
+		return
+	}
+	volume.Size = n
+	if dryRun {
+		return
+	}
+	err = r.DB.Model(volume).Update("Size", n).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// requested returns the (names of) cache volumes leased or
+// requested by tasks that are ready, pending or running.
+func (r *CacheReaper) requested() (names map[string]bool, err error) {
+	names = make(map[string]bool)
+	var leased []string
+	db := r.DB.Model(&model.CacheVolume{})
+	db = db.Where("ID IN (?)", r.DB.Model(&model.CacheLease{}).Select("CacheVolumeID"))
+	err = db.Pluck("Name", &leased).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for _, name := range leased {
+		names[name] = true
+	}
+	var tasks []model.Task
+	db = r.DB.Select("ID", "Caches")
+	db = db.Where("State IN ?", []string{task.Ready, task.Pending, task.Running})
+	err = db.Find(&tasks).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	for i := range tasks {
+		for _, mount := range task.Mounts(&tasks[i]) {
+			names[mount.Name] = true
+		}
+	}
+	return
+}
+
+//
+// evict (delete) the cache volume content.
+// Returns the bytes evicted. Nothing is deleted on a dry-run.
+func (r *CacheReaper) evict(volume *model.CacheVolume, dryRun bool) (n int64, err error) {
+	n = volume.Size
+	if dryRun {
+		return
+	}
+	err = r.DB.Transaction(
+		func(tx *gorm.DB) (err error) {
+			var count int64
+			err = tx.Model(&model.CacheLease{}).Where("CacheVolumeID", volume.ID).Count(&count).Error
+			if err != nil || count > 0 {
+				n = 0
+				return
+			}
+			err = nas.RmDir(task.CachePath(volume.Name))
+			if err != nil {
+				return
+			}
+			volume.Size = 0
+			volume.Evicted++
+			err = tx.Model(volume).Updates(
+				map[string]interface{}{
+					"Size":    volume.Size,
+					"Evicted": volume.Evicted,
+				}).Error
+			return
+		})
+	if err != nil {
+		n = 0
+		err = liberr.Wrap(err)
+		return
+	}
+	if n > 0 {
+		metrics.CacheSize.WithLabelValues(volume.Name).Set(0)
+		metrics.CacheEvictions.WithLabelValues(volume.Name).Inc()
+		Log.Info("Cache evicted.", "name", volume.Name, "size", n)
+	}
+	return
+}
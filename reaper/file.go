@@ -1,10 +1,12 @@
 package reaper
 
 import (
+	"errors"
 	liberr "github.com/jortel/go-utils/error"
 	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/quota"
+	"github.com/konveyor/tackle2-hub/storage"
 	"gorm.io/gorm"
-	"os"
 	"time"
 )
 
@@ -18,7 +20,7 @@ type FileReaper struct {
 //
 // Run Executes the reaper.
 // A file is deleted when it is no longer referenced and the TTL has expired.
-func (r *FileReaper) Run() {
+func (r *FileReaper) Run(report *Report) {
 	Log.V(1).Info("Reaping files.")
 	list := []model.File{}
 	err := r.DB.Find(&list).Error
@@ -33,7 +35,7 @@ func (r *FileReaper) Run() {
 			continue
 		}
 		if busy {
-			if file.Expiration != nil {
+			if file.Expiration != nil && !report.DryRun {
 				file.Expiration = nil
 				err = r.DB.Save(&file).Error
 				Log.Error(err, "")
@@ -41,6 +43,9 @@ func (r *FileReaper) Run() {
 			continue
 		}
 		if file.Expiration == nil {
+			if report.DryRun {
+				continue
+			}
 			Log.Info("File (orphan) found.", "id", file.ID, "path", file.Path)
 			mark := time.Now().Add(time.Minute * time.Duration(Settings.File.TTL))
 			file.Expiration = &mark
@@ -50,11 +55,27 @@ func (r *FileReaper) Run() {
 		}
 		mark := time.Now()
 		if mark.After(*file.Expiration) {
-			err = r.delete(&file)
+			var n int64
+			n, err = r.size(&file)
 			if err != nil {
 				Log.Error(err, "")
 				continue
 			}
+			if !report.DryRun {
+				err = r.delete(&file)
+				if err != nil {
+					Log.Error(err, "")
+					continue
+				}
+			}
+			report.Add(
+				Action{
+					Kind:   KindFile,
+					ID:     file.ID,
+					Action: ActionDeleted,
+					Reason: ReasonOrphan,
+					Size:   n,
+				})
 		}
 	}
 }
@@ -68,6 +89,7 @@ func (r *FileReaper) busy(file *model.File) (busy bool, err error) {
 	for _, m := range []interface{}{
 		&model.RuleSet{},
 		&model.Rule{},
+		&model.RevisionRule{},
 		&model.Target{},
 	} {
 		n, err = ref.Count(m, "file", file.ID)
@@ -83,21 +105,23 @@ func (r *FileReaper) busy(file *model.File) (busy bool, err error) {
 
 //
 // Delete file.
+// The content is deleted when no longer referenced by other files.
 func (r *FileReaper) delete(file *model.File) (err error) {
-	err = os.Remove(file.Path)
+	err = r.Delete(file)
 	if err != nil {
-		if !os.IsNotExist(err) {
-			err = liberr.Wrap(
-				err,
-				"id",
-				file.ID,
-				"path",
-				file.Path)
-			return
-		} else {
-			err = nil
-		}
+		return
 	}
+	Log.Info("File (orphan) deleted.", "id", file.ID, "path", file.Path)
+	return
+}
+
+//
+// Delete the file.
+// The content is deleted when no longer referenced by other
+// files. Serialized with storing the (shared) content.
+func (r *FileReaper) Delete(file *model.File) (err error) {
+	unlock := storage.Lock(file.Digest)
+	defer unlock()
 	err = r.DB.Delete(file).Error
 	if err != nil {
 		err = liberr.Wrap(
@@ -108,6 +132,59 @@ func (r *FileReaper) delete(file *model.File) (err error) {
 			file.Path)
 		return
 	}
-	Log.Info("File (orphan) deleted.", "id", file.ID, "path", file.Path)
+	nRef, err := r.references(file)
+	if err != nil || nRef > 0 {
+		return
+	}
+	err = storage.Store.Delete(file.Path)
+	if err != nil {
+		err = liberr.Wrap(
+			err,
+			"id",
+			file.ID,
+			"path",
+			file.Path)
+		return
+	}
+	if file.ContentID != nil {
+		err = r.DB.Delete(&model.FileContent{}, *file.ContentID).Error
+		if err != nil {
+			err = liberr.Wrap(err, "id", file.ID)
+			return
+		}
+	}
+	quota.Reset()
+	return
+}
+
+//
+// size returns the size (bytes) reclaimed when the file is deleted.
+// Zero (0) when the content is shared by other files.
+func (r *FileReaper) size(file *model.File) (n int64, err error) {
+	nRef, err := r.references(file)
+	if err != nil || nRef > 1 {
+		return
+	}
+	st, err := storage.Store.Stat(file.Path)
+	if err != nil {
+		if errors.Is(err, &storage.NotFound{}) {
+			err = nil
+		}
+		return
+	}
+	n = st.Size
+	return
+}
+
+//
+// references returns the number of files referencing
+// the (shared) content. Zero (0) when the content is not
+// stored by digest.
+func (r *FileReaper) references(file *model.File) (nRef int64, err error) {
+	if file.ContentID == nil {
+		return
+	}
+	ref := RefCounter{DB: r.DB}
+	nRef, err = ref.Count(&model.File{}, "content", *file.ContentID)
 	return
 }
@@ -2,11 +2,15 @@ package reaper
 
 import (
 	"context"
+	"encoding/json"
+	liberr "github.com/jortel/go-utils/error"
 	"github.com/jortel/go-utils/logr"
+	"github.com/konveyor/tackle2-hub/model"
 	"github.com/konveyor/tackle2-hub/settings"
 	"github.com/konveyor/tackle2-hub/task"
 	"gorm.io/gorm"
 	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
 	"time"
 )
 
@@ -30,24 +34,13 @@ type Manager struct {
 	Client k8s.Client
 }
 
+//
+// reaping serializes reaper runs.
+var reaping sync.Mutex
+
 //
 // Run the manager.
 func (m *Manager) Run(ctx context.Context) {
-	registered := []Reaper{
-		&TaskReaper{
-			Client: m.Client,
-			DB:     m.DB,
-		},
-		&GroupReaper{
-			DB: m.DB,
-		},
-		&BucketReaper{
-			DB: m.DB,
-		},
-		&FileReaper{
-			DB: m.DB,
-		},
-	}
 	go func() {
 		Log.Info("Started.")
 		defer Log.Info("Died.")
@@ -56,8 +49,9 @@ func (m *Manager) Run(ctx context.Context) {
 			case <-ctx.Done():
 				return
 			default:
-				for _, r := range registered {
-					r.Run()
+				err := Execute(m.DB, m.Client, &Report{})
+				if err != nil {
+					Log.Error(err, "")
 				}
 				m.pause()
 			}
@@ -65,6 +59,85 @@ func (m *Manager) Run(ctx context.Context) {
 	}()
 }
 
+//
+// Execute (run) the reapers.
+// The run is recorded (history) unless a dry-run or nothing
+// was done by a scheduled run. The history older than the
+// retention is deleted.
+func Execute(db *gorm.DB, client k8s.Client, report *Report) (err error) {
+	reaping.Lock()
+	defer reaping.Unlock()
+	report.Started = time.Now()
+	registered := []Reaper{
+		&TaskReaper{
+			Client: client,
+			DB:     db,
+		},
+		&GroupReaper{
+			DB: db,
+		},
+		&BucketReaper{
+			DB: db,
+		},
+		&FileReaper{
+			DB: db,
+		},
+		&StorageReaper{
+			DB: db,
+		},
+		&CacheReaper{
+			DB: db,
+		},
+		&AuditReaper{
+			DB: db,
+		},
+	}
+	for _, r := range registered {
+		r.Run(report)
+	}
+	report.Duration = time.Since(report.Started)
+	if report.DryRun || (!report.Manual && len(report.Counts) == 0) {
+		return
+	}
+	counts, err := json.Marshal(report.Counts)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	m := &model.ReaperRun{
+		Manual:    report.Manual,
+		Duration:  report.Duration.Milliseconds(),
+		Counts:    counts,
+		Reclaimed: report.Reclaimed,
+	}
+	m.CreateTime = report.Started
+	err = db.Create(m).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	report.ID = m.ID
+	if Settings.Reaper.History > 0 {
+		mark := time.Now().Add(-time.Duration(Settings.Reaper.History) * 24 * time.Hour)
+		err = db.Delete(&model.ReaperRun{}, "CreateTime < ?", mark).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+	}
+	if report.Manual {
+		Log.Info(
+			"Reaper run (manual).",
+			"id",
+			m.ID,
+			"counts",
+			report.Counts,
+			"reclaimed",
+			report.Reclaimed)
+	}
+	return
+}
+
 //
 // Pause.
 func (m *Manager) pause() {
@@ -75,5 +148,7 @@ func (m *Manager) pause() {
 //
 // Reaper interface.
 type Reaper interface {
-	Run()
+	// Run the reaper.
+	// Actions are added to the report.
+	Run(report *Report)
 }
@@ -0,0 +1,106 @@
+package reaper
+
+import (
+	"time"
+)
+
+//
+// Kinds.
+const (
+	KindTask      = "task"
+	KindTaskGroup = "taskgroup"
+	KindBucket    = "bucket"
+	KindFile      = "file"
+	KindSnapshot  = "snapshot"
+	KindAudit     = "auditlog"
+	KindCache     = "cache"
+)
+
+//
+// Actions.
+const (
+	ActionDeleted  = "deleted"
+	ActionReleased = "released"
+	ActionEvicted  = "evicted"
+)
+
+//
+// Reasons.
+const (
+	// ReasonTTL the time-to-live has expired.
+	ReasonTTL = "ttl"
+	// ReasonOrphan no longer referenced (or empty).
+	ReasonOrphan = "orphan"
+	// ReasonSubmitted the group has been submitted.
+	ReasonSubmitted = "submitted"
+	// ReasonPressure storage pressure.
+	ReasonPressure = "pressure"
+	// ReasonRetention older than the retention.
+	ReasonRetention = "retention"
+)
+
+//
+// Action taken (or would be taken) by a reaper.
+type Action struct {
+	Kind   string
+	ID     uint
+	Action string
+	Reason string
+	// Size (bytes) reclaimed.
+	Size int64
+}
+
+//
+// Report of a reaper run.
+type Report struct {
+	// DryRun reports the actions that would be taken
+	// but nothing is changed.
+	DryRun bool
+	// Manual (triggered) run.
+	Manual bool
+	// ID of the recorded run (history).
+	ID uint
+	// Started timestamp.
+	Started time.Time
+	// Duration of the run.
+	Duration time.Duration
+	// Actions (excluding counted).
+	Actions []Action
+	// Counts keyed by kind.action.
+	Counts map[string]int
+	// Reclaimed (bytes).
+	Reclaimed int64
+}
+
+//
+// Add an action.
+func (r *Report) Add(action Action) {
+	r.Actions = append(r.Actions, action)
+	r.Count(action.Kind, action.Action, 1)
+	r.Reclaimed += action.Size
+}
+
+//
+// Count actions (not individually reported).
+func (r *Report) Count(kind, action string, n int) {
+	if n < 1 {
+		return
+	}
+	if r.Counts == nil {
+		r.Counts = make(map[string]int)
+	}
+	r.Counts[kind+"."+action] += n
+}
+
+//
+// Reported determines if an action has been reported
+// for the specified resource.
+func (r *Report) Reported(kind string, id uint) (found bool) {
+	for _, action := range r.Actions {
+		if action.Kind == kind && action.ID == id {
+			found = true
+			break
+		}
+	}
+	return
+}
@@ -0,0 +1,193 @@
+package reaper
+
+import (
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/quota"
+	"gorm.io/gorm"
+	"sort"
+	"time"
+)
+
+//
+// StorageReaper storage reaper.
+type StorageReaper struct {
+	// DB
+	DB *gorm.DB
+}
+
+//
+// Run Executes the reaper.
+// The storage usage is measured (metrics). When the usage exceeds
+// the pressure threshold, orphaned buckets and files marked for
+// expiration are evicted (soonest expiration first) until the
+// usage is below the threshold.
+// A dry-run assumes content reported (deleted) by other reapers
+// has been reclaimed.
+func (r *StorageReaper) Run(report *Report) {
+	Log.V(1).Info("Reaping storage.")
+	usage, err := quota.Measure(r.DB)
+	if err != nil {
+		Log.Error(err, "")
+		return
+	}
+	used := usage.Total
+	if report.DryRun {
+		used -= report.Reclaimed
+	}
+	pressure := quota.Pressure()
+	if pressure == 0 || used < pressure {
+		return
+	}
+	Log.Info(
+		"Storage pressure detected.",
+		"usage",
+		used,
+		"threshold",
+		pressure)
+	candidates, err := r.candidates()
+	if err != nil {
+		Log.Error(err, "")
+		return
+	}
+	for _, c := range candidates {
+		if used < pressure {
+			break
+		}
+		action, err := c.evict(report.DryRun)
+		if err != nil {
+			Log.Error(err, "")
+			continue
+		}
+		if action == nil || report.Reported(action.Kind, action.ID) {
+			continue
+		}
+		report.Add(*action)
+		used -= action.Size
+	}
+	if !report.DryRun {
+		quota.Reset()
+	}
+}
+
+//
+// candidates returns orphaned buckets and files marked for
+// expiration ordered by expiration.
+func (r *StorageReaper) candidates() (list []Evicted, err error) {
+	buckets := []model.Bucket{}
+	err = r.DB.Where("Expiration IS NOT NULL").Find(&buckets).Error
+	if err != nil {
+		return
+	}
+	for i := range buckets {
+		list = append(
+			list,
+			&evictedBucket{
+				reaper: &BucketReaper{DB: r.DB},
+				bucket: &buckets[i],
+			})
+	}
+	files := []model.File{}
+	err = r.DB.Where("Expiration IS NOT NULL").Find(&files).Error
+	if err != nil {
+		return
+	}
+	for i := range files {
+		list = append(
+			list,
+			&evictedFile{
+				reaper: &FileReaper{DB: r.DB},
+				file:   &files[i],
+			})
+	}
+	sort.Slice(
+		list,
+		func(i, j int) bool {
+			return list[i].expiration().Before(list[j].expiration())
+		})
+	return
+}
+
+//
+// Evicted content.
+type Evicted interface {
+	// evict (delete) the content.
+	// Returns the action; nil when not evicted.
+	// Nothing is deleted on a dry-run.
+	evict(dryRun bool) (action *Action, err error)
+	// expiration returns when the content expires.
+	expiration() time.Time
+}
+
+//
+// evictedBucket orphaned bucket.
+type evictedBucket struct {
+	reaper *BucketReaper
+	bucket *model.Bucket
+}
+
+func (e *evictedBucket) evict(dryRun bool) (action *Action, err error) {
+	busy, err := e.reaper.busy(e.bucket)
+	if err != nil || busy {
+		return
+	}
+	n, err := quota.Size(e.bucket.Path)
+	if err != nil {
+		return
+	}
+	if !dryRun {
+		err = e.reaper.delete(e.bucket)
+		if err != nil {
+			return
+		}
+		Log.Info("Bucket (orphan) evicted.", "id", e.bucket.ID, "size", n)
+	}
+	action = &Action{
+		Kind:   KindBucket,
+		ID:     e.bucket.ID,
+		Action: ActionEvicted,
+		Reason: ReasonPressure,
+		Size:   n,
+	}
+	return
+}
+
+func (e *evictedBucket) expiration() time.Time {
+	return *e.bucket.Expiration
+}
+
+//
+// evictedFile orphaned file.
+type evictedFile struct {
+	reaper *FileReaper
+	file   *model.File
+}
+
+func (e *evictedFile) evict(dryRun bool) (action *Action, err error) {
+	busy, err := e.reaper.busy(e.file)
+	if err != nil || busy {
+		return
+	}
+	n, err := e.reaper.size(e.file)
+	if err != nil {
+		return
+	}
+	if !dryRun {
+		err = e.reaper.delete(e.file)
+		if err != nil {
+			return
+		}
+		Log.Info("File (orphan) evicted.", "id", e.file.ID, "size", n)
+	}
+	action = &Action{
+		Kind:   KindFile,
+		ID:     e.file.ID,
+		Action: ActionEvicted,
+		Reason: ReasonPressure,
+		Size:   n,
+	}
+	return
+}
+
+func (e *evictedFile) expiration() time.Time {
+	return *e.file.Expiration
+}
@@ -1,8 +1,6 @@
 package reaper
 
 import (
-	"encoding/json"
-	"github.com/konveyor/tackle2-hub/api"
 	"github.com/konveyor/tackle2-hub/model"
 	"github.com/konveyor/tackle2-hub/task"
 	"gorm.io/gorm"
@@ -22,109 +20,54 @@ type TaskReaper struct {
 
 //
 // Run Executes the reaper.
-// Rules by state:
-//   Created
-//   - Deleted after TTL.Created > created timestamp or
-//     settings.Task.Reaper.Created.
-//   Pending
-//   - Deleted after TTL.Pending > created timestamp or
-//     settings.Task.Reaper.Created.
-//   Postponed
-//   - Deleted after TTL.Postponed > created timestamp or
-//     settings.Task.Reaper.Created.
-//   Running
-//   - Deleted after TTL.Running > started timestamp.
-//   Succeeded
-//   - Deleted after TTL > terminated timestamp or
-//     settings.Task.Reaper.Succeeded.
-//   - Bucket is released after the defined period.
-//   - Pod is deleted after the defined period.
-//   Failed
-//   - Deleted after TTL > terminated timestamp or
-//     settings.Task.Reaper.Failed.
-//   - Bucket is released after the defined period.
-//   - Pod is deleted after the defined period.
-func (r *TaskReaper) Run() {
+// The (resolved) lifecycle policy rule for the task state
+// determines when the task is deleted or when its resources
+// (pod, bucket, report) are released. Kept tasks are not deleted.
+// See: task.Lifecycle.
+func (r *TaskReaper) Run(report *Report) {
 	Log.V(1).Info("Reaping tasks.")
+	lifecycle, err := task.LoadLifecycle(r.DB)
+	if err != nil {
+		Log.Error(err, "")
+		return
+	}
 	list := []model.Task{}
-	result := r.DB.Find(
+	err = r.DB.Find(
 		&list,
 		"state IN ?",
 		[]string{
 			task.Created,
+			task.Postponed,
+			task.Ready,
+			task.Pending,
+			task.Running,
 			task.Succeeded,
 			task.Failed,
-		})
-	Log.Error(result.Error, "")
-	if result.Error != nil {
+			task.Canceled,
+		}).Error
+	if err != nil {
+		Log.Error(err, "")
 		return
 	}
+	kept := lifecycle.Kept(list)
 	for i := range list {
 		m := &list[i]
-		ttl := r.TTL(m)
-		switch m.State {
-		case task.Created:
-			mark := m.CreateTime
-			if ttl.Created > 0 {
-				d := time.Duration(ttl.Created) * Unit
-				if time.Since(mark) > d {
-					r.delete(m)
-				}
-			} else {
-				d := time.Duration(Settings.Hub.Task.Reaper.Created) * Unit
-				if time.Since(mark) > d {
-					r.release(m)
-				}
-			}
-		case task.Pending:
-			mark := m.CreateTime
-			if ttl.Pending > 0 {
-				d := time.Duration(ttl.Pending) * Unit
-				if time.Since(mark) > d {
-					r.delete(m)
-				}
-			}
-		case task.Postponed:
-			mark := m.CreateTime
-			if ttl.Postponed > 0 {
-				d := time.Duration(ttl.Postponed) * Unit
-				if time.Since(mark) > d {
-					r.delete(m)
-				}
-			}
-		case task.Running:
-			mark := *m.Started
-			if ttl.Running > 0 {
-				d := time.Duration(ttl.Running) * Unit
-				if time.Since(mark) > d {
-					r.delete(m)
-				}
-			}
-		case task.Succeeded:
-			mark := *m.Terminated
-			if ttl.Succeeded > 0 {
-				d := time.Duration(ttl.Succeeded) * Unit
-				if time.Since(mark) > d {
-					r.delete(m)
-				}
-			} else {
-				d := time.Duration(Settings.Hub.Task.Reaper.Succeeded) * Unit
-				if time.Since(mark) > d {
-					r.release(m)
-				}
+		mark := lifecycle.Mark(m)
+		if mark == nil {
+			continue
+		}
+		rule, _ := lifecycle.Rule(m)
+		if rule.Delete > 0 && !kept[m.ID] {
+			d := time.Duration(rule.Delete) * Unit
+			if time.Since(*mark) > d {
+				r.delete(m, report)
+				continue
 			}
-		case task.Failed:
-			mark := *m.Terminated
-			if ttl.Succeeded > 0 {
-				d := time.Duration(ttl.Failed) * Unit
-				if time.Since(mark) > d {
-					r.delete(m)
-				}
-			} else {
-				d := time.Duration(Settings.Hub.Task.Reaper.Failed) * Unit
-				if time.Since(mark) > d {
-					r.release(m)
-				}
+		}
+		if rule.Release > 0 {
+			d := time.Duration(rule.Release) * Unit
+			if time.Since(*mark) > d {
+				r.release(m, &rule, report)
 			}
 		}
 	}
@@ -132,9 +75,35 @@ func (r *TaskReaper) Run() {
 
 //
 // release resources.
-func (r *TaskReaper) release(m *model.Task) {
+func (r *TaskReaper) release(m *model.Task, rule *task.StateRule, report *Report) {
+	pod := rule.Releases(task.ReleasePod) && m.Pod != ""
+	bucket := rule.Releases(task.ReleaseBucket) && m.HasBucket()
+	taskReport := false
+	if rule.Releases(task.ReleaseReport) {
+		var n int64
+		db := r.DB.Model(&model.TaskReport{})
+		err := db.Where("TaskID", m.ID).Count(&n).Error
+		if err != nil {
+			Log.Error(err, "")
+			return
+		}
+		taskReport = n > 0
+	}
+	if !pod && !bucket && !taskReport {
+		return
+	}
+	report.Add(
+		Action{
+			Kind:   KindTask,
+			ID:     m.ID,
+			Action: ActionReleased,
+			Reason: ReasonTTL,
+		})
+	if report.DryRun {
+		return
+	}
 	nChanged := 0
-	if m.Pod != "" {
+	if pod {
 		rt := Task{Task: m}
 		err := rt.Delete(r.Client)
 		if err == nil {
@@ -144,11 +113,19 @@ func (r *TaskReaper) release(m *model.Task) {
 			Log.Error(err, "")
 		}
 	}
-	if m.HasBucket() {
+	if bucket {
 		Log.Info("Task bucket released.", "id", m.ID)
 		m.SetBucket(nil)
 		nChanged++
 	}
+	if taskReport {
+		err := r.DB.Delete(&model.TaskReport{}, "TaskID", m.ID).Error
+		if err == nil {
+			Log.Info("Task report released.", "id", m.ID)
+		} else {
+			Log.Error(err, "")
+		}
+	}
 	if nChanged > 0 {
 		err := r.DB.Save(m).Error
 		if err != nil {
@@ -160,8 +137,18 @@ func (r *TaskReaper) release(m *model.Task) {
 
 //
 // delete task.
-func (r *TaskReaper) delete(m *model.Task) {
-	rt := Task{m}
+func (r *TaskReaper) delete(m *model.Task, report *Report) {
+	report.Add(
+		Action{
+			Kind:   KindTask,
+			ID:     m.ID,
+			Action: ActionDeleted,
+			Reason: ReasonTTL,
+		})
+	if report.DryRun {
+		return
+	}
+	rt := Task{Task: m}
 	err := rt.Delete(r.Client)
 	if err != nil {
 		Log.Error(err, "")
@@ -174,16 +161,6 @@ func (r *TaskReaper) delete(m *model.Task) {
 	}
 }
 
-//
-// TTL returns the task TTL.
-func (r *TaskReaper) TTL(m *model.Task) (ttl api.TTL) {
-	if m.TTL != nil {
-		_ = json.Unmarshal(m.TTL, &ttl)
-	}
-
-	return
-}
-
 //
 //
 
@@ -202,7 +179,7 @@ type GroupReaper struct {
 //   Ready (submitted)
 //   - Deleted when all of its task have been deleted.
 //   - Bucket is released immediately.
-func (r *GroupReaper) Run() {
+func (r *GroupReaper) Run(report *Report) {
 	Log.V(1).Info("Reaping groups.")
 	list := []model.TaskGroup{}
 	db := r.DB.Preload(clause.Associations)
@@ -218,15 +195,15 @@ func (r *GroupReaper) Run() {
 			d := time.Duration(
 				Settings.Hub.Task.Reaper.Created) * Unit
 			if time.Since(mark) > d {
-				r.delete(m)
+				r.delete(m, report, ReasonTTL)
 			}
 		case task.Ready:
 			if len(m.Tasks) == 0 {
-				r.delete(m)
+				r.delete(m, report, ReasonOrphan)
 				continue
 			}
 			if m.HasBucket() {
-				r.release(m)
+				r.release(m, report)
 			}
 		}
 	}
@@ -234,7 +211,17 @@ func (r *GroupReaper) Run() {
 
 //
 // release resources.
-func (r *GroupReaper) release(m *model.TaskGroup) {
+func (r *GroupReaper) release(m *model.TaskGroup, report *Report) {
+	report.Add(
+		Action{
+			Kind:   KindTaskGroup,
+			ID:     m.ID,
+			Action: ActionReleased,
+			Reason: ReasonSubmitted,
+		})
+	if report.DryRun {
+		return
+	}
 	m.SetBucket(nil)
 	err := r.DB.Save(m).Error
 	if err == nil {
@@ -245,8 +232,18 @@ func (r *GroupReaper) release(m *model.TaskGroup) {
 }
 
 //
-// delete task.
-func (r *GroupReaper) delete(m *model.TaskGroup) {
+// delete group.
+func (r *GroupReaper) delete(m *model.TaskGroup, report *Report, reason string) {
+	report.Add(
+		Action{
+			Kind:   KindTaskGroup,
+			ID:     m.ID,
+			Action: ActionDeleted,
+			Reason: reason,
+		})
+	if report.DryRun {
+		return
+	}
 	err := r.DB.Delete(m).Error
 	if err == nil {
 		Log.Info("Group deleted.", "id", m.ID)
@@ -0,0 +1,416 @@
+package rule
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//
+// Issue severity.
+const (
+	Error   = "error"
+	Warning = "warning"
+)
+
+//
+// Rule fields defined by the analyzer rule schema.
+var fields = map[string]bool{
+	"ruleID":          true,
+	"description":     true,
+	"category":        true,
+	"effort":          true,
+	"labels":          true,
+	"message":         true,
+	"tag":             true,
+	"links":           true,
+	"when":            true,
+	"customVariables": true,
+}
+
+//
+// Ruleset (metadata) fields.
+var ruleSetFields = map[string]bool{
+	"name":        true,
+	"description": true,
+	"labels":      true,
+}
+
+//
+// Categories.
+var categories = map[string]bool{
+	"mandatory": true,
+	"optional":  true,
+	"potential": true,
+}
+
+//
+// Condition modifiers.
+var modifiers = map[string]bool{
+	"as":     true,
+	"from":   true,
+	"ignore": true,
+	"not":    true,
+}
+
+var (
+	// <provider>.<capability>
+	conditionPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+\.[a-zA-Z0-9_.-]+$`)
+	// key[=value]
+	labelPattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9_./-]*[a-zA-Z0-9])?(=\S*)?$`)
+)
+
+//
+// Issue reported by validation.
+type Issue struct {
+	File     string
+	RuleID   string
+	Field    string
+	Severity string
+	Message  string
+}
+
+//
+// String representation.
+func (i *Issue) String() (s string) {
+	s = i.File
+	if i.RuleID != "" {
+		s += "[" + i.RuleID + "]"
+	}
+	if i.Field != "" {
+		s += "." + i.Field
+	}
+	s += ": " + i.Message
+	return
+}
+
+//
+// File is a parsed rules (or ruleset) file.
+type File struct {
+	// Name of the file.
+	Name string
+	// RuleSet indicates the file contains ruleset metadata
+	// rather than rules.
+	RuleSet bool
+	// Description extracted from the ruleset metadata or
+	// when the file contains a single rule.
+	Description string
+	// Labels declared by the rules (or ruleset).
+	Labels []string
+	// RuleIDs declared by the rules.
+	RuleIDs []string
+	// Issues found.
+	Issues []Issue
+}
+
+//
+// Valid returns true when no errors were found.
+func (f *File) Valid() (b bool) {
+	b = true
+	for _, issue := range f.Issues {
+		if issue.Severity == Error {
+			b = false
+			break
+		}
+	}
+	return
+}
+
+//
+// Parse and validate the content of a rules file.
+// Content is expected to be either a list of rules or a
+// (ruleset.yaml) mapping of ruleset metadata.
+func Parse(name string, content []byte) (f *File) {
+	f = &File{Name: name}
+	var document interface{}
+	err := yaml.Unmarshal(content, &document)
+	if err != nil {
+		f.error("", "", err.Error())
+		return
+	}
+	switch d := document.(type) {
+	case []interface{}:
+		f.parseRules(d)
+	case map[interface{}]interface{}:
+		f.RuleSet = true
+		f.parseRuleSet(d)
+	case nil:
+		f.error("", "", "empty document.")
+	default:
+		f.error("", "", "list of rules expected.")
+	}
+	return
+}
+
+//
+// Lint the files as members of the same ruleset.
+// Duplicate rule IDs found in other files are reported.
+func Lint(files []*File) {
+	owner := make(map[string]string)
+	for _, f := range files {
+		for _, id := range f.RuleIDs {
+			other, found := owner[id]
+			if found {
+				f.warning(
+					id,
+					"ruleID",
+					fmt.Sprintf("duplicate ruleID; also defined in: %s.", other))
+				continue
+			}
+			owner[id] = f.Name
+		}
+	}
+}
+
+//
+// parseRuleSet parses ruleset metadata.
+func (f *File) parseRuleSet(d map[interface{}]interface{}) {
+	for _, k := range keys(d) {
+		if !ruleSetFields[k] {
+			f.warning("", k, "unknown field.")
+		}
+	}
+	name, isString := d["name"].(string)
+	if !isString || strings.TrimSpace(name) == "" {
+		f.error("", "name", "must be a non-empty string.")
+	}
+	if v, found := d["description"]; found {
+		s, isString := v.(string)
+		if !isString {
+			f.error("", "description", "must be a string.")
+		}
+		f.Description = s
+	}
+	f.Labels = f.labels("", d["labels"])
+}
+
+//
+// parseRules parses and validates a list of rules.
+func (f *File) parseRules(list []interface{}) {
+	seen := make(map[string]bool)
+	labels := make(map[string]bool)
+	for i, item := range list {
+		d, isMap := item.(map[interface{}]interface{})
+		if !isMap {
+			f.error(fmt.Sprintf("#%d", i), "", "rule must be a mapping.")
+			continue
+		}
+		id, isString := d["ruleID"].(string)
+		if !isString || strings.TrimSpace(id) == "" {
+			f.error(fmt.Sprintf("#%d", i), "ruleID", "must be a non-empty string.")
+			id = fmt.Sprintf("#%d", i)
+		} else {
+			if strings.ContainsAny(id, " \t\r\n;") {
+				f.error(id, "ruleID", "must not contain whitespace or ';'.")
+			}
+			if seen[id] {
+				f.error(id, "ruleID", "duplicate ruleID.")
+			} else {
+				seen[id] = true
+				f.RuleIDs = append(f.RuleIDs, id)
+			}
+		}
+		f.validate(id, d)
+		for _, label := range f.labels(id, d["labels"]) {
+			labels[label] = true
+		}
+		if len(list) == 1 {
+			f.Description, _ = d["description"].(string)
+		}
+	}
+	for label := range labels {
+		f.Labels = append(f.Labels, label)
+	}
+	sort.Strings(f.Labels)
+	if len(list) == 0 {
+		f.warning("", "", "no rules defined.")
+	}
+}
+
+//
+// validate a rule.
+func (f *File) validate(id string, d map[interface{}]interface{}) {
+	for _, k := range keys(d) {
+		if !fields[k] {
+			f.warning(id, k, "unknown field.")
+		}
+	}
+	if v, found := d["description"]; found {
+		if _, isString := v.(string); !isString {
+			f.error(id, "description", "must be a string.")
+		}
+	}
+	if v, found := d["category"]; found {
+		s, _ := v.(string)
+		if !categories[s] {
+			f.error(id, "category", "must be: mandatory|optional|potential.")
+		}
+	}
+	if v, found := d["effort"]; found {
+		n, isInt := v.(int)
+		if !isInt || n < 0 {
+			f.error(id, "effort", "must be a non-negative integer.")
+		}
+	}
+	_, hasMessage := d["message"]
+	_, hasTag := d["tag"]
+	if !hasMessage && !hasTag {
+		f.error(id, "", "message or tag required.")
+	}
+	if hasMessage {
+		if _, isString := d["message"].(string); !isString {
+			f.error(id, "message", "must be a string.")
+		}
+	}
+	if hasTag {
+		if !isStrings(d["tag"]) {
+			f.error(id, "tag", "must be a list of strings.")
+		}
+	}
+	if v, found := d["links"]; found {
+		list, isList := v.([]interface{})
+		if !isList {
+			f.error(id, "links", "must be a list.")
+		}
+		for i, item := range list {
+			link, _ := item.(map[interface{}]interface{})
+			if _, isString := link["url"].(string); !isString {
+				f.error(id, fmt.Sprintf("links[%d].url", i), "must be a string.")
+			}
+		}
+	}
+	when, found := d["when"]
+	if !found {
+		f.error(id, "when", "condition required.")
+		return
+	}
+	f.condition(id, "when", when)
+}
+
+//
+// condition validates a condition.
+// A condition is a mapping with exactly one: and|or|<provider>.<capability>
+// entry and optional modifiers.
+func (f *File) condition(id, path string, v interface{}) {
+	d, isMap := v.(map[interface{}]interface{})
+	if !isMap {
+		f.error(id, path, "condition must be a mapping.")
+		return
+	}
+	var conditions []string
+	for _, k := range keys(d) {
+		switch {
+		case modifiers[k]:
+		case k == "and" || k == "or":
+			conditions = append(conditions, k)
+			list, isList := d[k].([]interface{})
+			if !isList || len(list) == 0 {
+				f.error(id, path+"."+k, "must be a non-empty list of conditions.")
+				continue
+			}
+			for i, item := range list {
+				f.condition(id, fmt.Sprintf("%s.%s[%d]", path, k, i), item)
+			}
+		case conditionPattern.MatchString(k):
+			conditions = append(conditions, k)
+		default:
+			f.error(id, path+"."+k, "unknown condition; expected: and|or|<provider>.<capability>.")
+		}
+	}
+	switch len(conditions) {
+	case 0:
+		f.error(id, path, "condition not specified.")
+	case 1:
+	default:
+		f.error(
+			id,
+			path,
+			"multiple conditions: "+strings.Join(conditions, ",")+"; use: and|or.")
+	}
+	if v, found := d["not"]; found {
+		if _, isBool := v.(bool); !isBool {
+			f.error(id, path+".not", "must be a boolean.")
+		}
+	}
+}
+
+//
+// labels validates and returns labels.
+func (f *File) labels(id string, v interface{}) (labels []string) {
+	if v == nil {
+		return
+	}
+	list, isList := v.([]interface{})
+	if !isList {
+		f.error(id, "labels", "must be a list of strings.")
+		return
+	}
+	for i, item := range list {
+		label, isString := item.(string)
+		if !isString || !labelPattern.MatchString(label) {
+			f.error(
+				id,
+				fmt.Sprintf("labels[%d]", i),
+				"must be: key[=value].")
+			continue
+		}
+		labels = append(labels, label)
+	}
+	return
+}
+
+//
+// error reports an error.
+func (f *File) error(id, field, message string) {
+	f.Issues = append(
+		f.Issues,
+		Issue{
+			File:     f.Name,
+			RuleID:   id,
+			Field:    field,
+			Severity: Error,
+			Message:  message,
+		})
+}
+
+//
+// warning reports a warning.
+func (f *File) warning(id, field, message string) {
+	f.Issues = append(
+		f.Issues,
+		Issue{
+			File:     f.Name,
+			RuleID:   id,
+			Field:    field,
+			Severity: Warning,
+			Message:  message,
+		})
+}
+
+//
+// keys returns the sorted (string) keys.
+func keys(d map[interface{}]interface{}) (list []string) {
+	for k := range d {
+		list = append(list, fmt.Sprintf("%v", k))
+	}
+	sort.Strings(list)
+	return
+}
+
+//
+// isStrings returns true when v is a list of strings.
+func isStrings(v interface{}) (b bool) {
+	list, isList := v.([]interface{})
+	if !isList {
+		return
+	}
+	for _, item := range list {
+		if _, isString := item.(string); !isString {
+			return
+		}
+	}
+	b = true
+	return
+}
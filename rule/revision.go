@@ -0,0 +1,214 @@
+package rule
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/storage"
+	"gorm.io/gorm"
+	"io"
+	"regexp"
+	"sort"
+)
+
+//
+// commitRef a (full) commit SHA.
+var commitRef = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+//
+// Revise creates a revision of the ruleset when it has changed
+// since the latest revision. Returns the latest revision.
+// The revision digest is calculated using the content of the
+// rule files rather than the file IDs so that re-uploading the
+// same content does not create a revision.
+func Revise(db *gorm.DB, id uint) (revision *model.RuleSetRevision, err error) {
+	ruleSet := &model.RuleSet{}
+	db2 := db.Preload("Rules.File")
+	db2 = db2.Preload("DependsOn")
+	err = db2.First(ruleSet, id).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	next := &model.RuleSetRevision{
+		RuleSetID:   ruleSet.ID,
+		Name:        ruleSet.Name,
+		Description: ruleSet.Description,
+		Repository:  ruleSet.Repository,
+		Commit:      commit(ruleSet.Repository),
+		IdentityID:  ruleSet.IdentityID,
+	}
+	dependsOn := []uint{}
+	for _, dep := range ruleSet.DependsOn {
+		dependsOn = append(dependsOn, dep.ID)
+	}
+	sort.Slice(
+		dependsOn,
+		func(i, j int) bool {
+			return dependsOn[i] < dependsOn[j]
+		})
+	next.DependsOn, _ = json.Marshal(dependsOn)
+	for i := range ruleSet.Rules {
+		m := &ruleSet.Rules[i]
+		rule := model.RevisionRule{
+			Name:        m.Name,
+			Description: m.Description,
+			Labels:      m.Labels,
+			RuleIDs:     m.RuleIDs,
+			FileID:      m.FileID,
+		}
+		if m.File != nil {
+			rule.Digest, err = digest(m.File)
+			if err != nil {
+				return
+			}
+		}
+		next.Rules = append(next.Rules, rule)
+	}
+	sort.Slice(
+		next.Rules,
+		func(i, j int) bool {
+			a := next.Rules[i]
+			b := next.Rules[j]
+			if a.Digest != b.Digest {
+				return a.Digest < b.Digest
+			}
+			return a.Name < b.Name
+		})
+	next.Digest = revisionDigest(next)
+	latest := &model.RuleSetRevision{}
+	err = db.Where("RuleSetID", id).Order("Revision DESC").First(latest).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		} else {
+			err = liberr.Wrap(err)
+			return
+		}
+	}
+	if latest.ID != 0 && latest.Digest == next.Digest {
+		revision = latest
+		return
+	}
+	next.Revision = latest.Revision + 1
+	next.CreateUser = ruleSet.UpdateUser
+	if next.CreateUser == "" {
+		next.CreateUser = ruleSet.CreateUser
+	}
+	err = db.Create(next).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	revision = next
+	return
+}
+
+//
+// Reproducible returns true when the revision content is immutable.
+// A revision of a repository-sourced ruleset is reproducible only
+// when the repository references a commit.
+func Reproducible(m *model.RuleSetRevision) (b bool) {
+	b = !sourced(m.Repository) || m.Commit != ""
+	return
+}
+
+//
+// commit returns the commit (SHA) referenced by the repository.
+// The tag (or branch) is a commit when it is a full SHA.
+func commit(repository []byte) (sha string) {
+	r := struct {
+		Branch string `json:"branch"`
+		Tag    string `json:"tag"`
+	}{}
+	_ = json.Unmarshal(repository, &r)
+	for _, ref := range []string{r.Tag, r.Branch} {
+		if commitRef.MatchString(ref) {
+			sha = ref
+			return
+		}
+	}
+	return
+}
+
+//
+// sourced returns true when the repository is specified.
+func sourced(repository []byte) (b bool) {
+	r := struct {
+		URL string `json:"url"`
+	}{}
+	_ = json.Unmarshal(repository, &r)
+	b = r.URL != ""
+	return
+}
+
+//
+// revisionDigest returns the digest of the revision content.
+func revisionDigest(m *model.RuleSetRevision) (d string) {
+	type Rule struct {
+		Name        string
+		Description string
+		Labels      []byte
+		RuleIDs     []byte
+		Digest      string
+	}
+	content := struct {
+		Name        string
+		Description string
+		Repository  []byte
+		IdentityID  *uint
+		DependsOn   []byte
+		Rules       []Rule
+	}{
+		Name:        m.Name,
+		Description: m.Description,
+		Repository:  m.Repository,
+		IdentityID:  m.IdentityID,
+		DependsOn:   m.DependsOn,
+	}
+	for _, r := range m.Rules {
+		content.Rules = append(
+			content.Rules,
+			Rule{
+				Name:        r.Name,
+				Description: r.Description,
+				Labels:      r.Labels,
+				RuleIDs:     r.RuleIDs,
+				Digest:      r.Digest,
+			})
+	}
+	b, _ := json.Marshal(content)
+	h := sha256.Sum256(b)
+	d = hex.EncodeToString(h[:])
+	return
+}
+
+//
+// digest returns the (sha256) digest of the file content.
+// The digest recorded on the file is used when known. Otherwise,
+// the content is read from the store.
+func digest(m *model.File) (d string, err error) {
+	if m.Digest != "" {
+		d = m.Digest
+		return
+	}
+	f, err := storage.Store.Get(m.Path)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	d = hex.EncodeToString(h.Sum(nil))
+	return
+}
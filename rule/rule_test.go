@@ -0,0 +1,190 @@
+package rule
+
+import (
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/storage"
+	"github.com/onsi/gomega"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	content, err := os.ReadFile("../test/api/ruleset/data/rules.yaml")
+	g.Expect(err).To(gomega.BeNil())
+	f := Parse("rules.yaml", content)
+	g.Expect(f.Issues).To(gomega.BeEmpty())
+	g.Expect(f.Valid()).To(gomega.BeTrue())
+	g.Expect(f.RuleSet).To(gomega.BeFalse())
+	g.Expect(len(f.RuleIDs)).To(gomega.Equal(18))
+	g.Expect(f.Labels).To(gomega.Equal([]string{"test", "testing"}))
+	// single rule.
+	f = Parse(
+		"single.yaml",
+		[]byte(`
+- ruleID: r-001
+  description: Single rule.
+  labels:
+  - konveyor.io/source=java-ee
+  - konveyor.io/target=quarkus3+
+  message: hello
+  when:
+    java.referenced:
+      pattern: a.b.C
+`))
+	g.Expect(f.Valid()).To(gomega.BeTrue())
+	g.Expect(f.Description).To(gomega.Equal("Single rule."))
+	g.Expect(f.Labels).To(gomega.Equal([]string{
+		"konveyor.io/source=java-ee",
+		"konveyor.io/target=quarkus3+",
+	}))
+	// ruleset.
+	f = Parse(
+		"ruleset.yaml",
+		[]byte(`
+name: test
+description: Test ruleset.
+labels:
+- konveyor.io/target=cloud-readiness
+`))
+	g.Expect(f.Valid()).To(gomega.BeTrue())
+	g.Expect(f.RuleSet).To(gomega.BeTrue())
+	g.Expect(f.Description).To(gomega.Equal("Test ruleset."))
+	g.Expect(f.Labels).To(gomega.Equal([]string{"konveyor.io/target=cloud-readiness"}))
+}
+
+func TestParseInvalid(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	f := Parse("bad.yaml", []byte("- ruleID: [a"))
+	g.Expect(f.Valid()).To(gomega.BeFalse())
+	f = Parse(
+		"bad.yaml",
+		[]byte(`
+- ruleID: r-001
+  category: critical
+  effort: -1
+  labels:
+  - "=x"
+  when:
+    java.referenced: {}
+    builtin.file: {}
+- ruleID: r-001
+  message: dup
+  when:
+    and: []
+- message: no id
+  when:
+    nope: {}
+- ruleID: r-003
+  message: ok
+  extra: true
+  when:
+    or:
+    - go.referenced: x
+      not: maybe
+`))
+	g.Expect(f.Valid()).To(gomega.BeFalse())
+	got := make(map[string]string)
+	for _, issue := range f.Issues {
+		got[issue.RuleID+"|"+issue.Field] = issue.Severity
+	}
+	g.Expect(got).To(gomega.Equal(map[string]string{
+		"r-001|category":       Error,
+		"r-001|effort":         Error,
+		"r-001|labels[0]":      Error,
+		"r-001|":               Error,
+		"r-001|when":           Error,
+		"r-001|ruleID":         Error,
+		"r-001|when.and":       Error,
+		"#2|ruleID":            Error,
+		"#2|when.nope":         Error,
+		"#2|when":              Error,
+		"r-003|extra":          Warning,
+		"r-003|when.or[0].not": Error,
+	}))
+	g.Expect(f.RuleIDs).To(gomega.Equal([]string{"r-001", "r-003"}))
+}
+
+func TestLint(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	content := []byte(`
+- ruleID: r-001
+  message: hello
+  when:
+    builtin.file:
+      pattern: x
+`)
+	a := Parse("a.yaml", content)
+	b := Parse("b.yaml", content)
+	Lint([]*File{a, b})
+	g.Expect(a.Issues).To(gomega.BeEmpty())
+	g.Expect(len(b.Issues)).To(gomega.Equal(1))
+	g.Expect(b.Issues[0].Severity).To(gomega.Equal(Warning))
+	g.Expect(b.Issues[0].String()).To(gomega.Equal(
+		"b.yaml[r-001].ruleID: duplicate ruleID; also defined in: a.yaml."))
+	g.Expect(b.Valid()).To(gomega.BeTrue())
+}
+
+func TestReproducible(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	sha := "0123456789abcdef0123456789abcdef01234567"
+	g.Expect(commit([]byte(`{"url":"u","branch":"main"}`))).To(gomega.BeEmpty())
+	g.Expect(commit([]byte(`{"url":"u","tag":"` + sha + `"}`))).To(gomega.Equal(sha))
+	g.Expect(commit([]byte(`{"url":"u","branch":"` + sha + `"}`))).To(gomega.Equal(sha))
+	g.Expect(Reproducible(&model.RuleSetRevision{})).To(gomega.BeTrue())
+	g.Expect(
+		Reproducible(
+			&model.RuleSetRevision{
+				Repository: []byte(`{"url":"u","branch":"main"}`),
+			})).To(gomega.BeFalse())
+	g.Expect(
+		Reproducible(
+			&model.RuleSetRevision{
+				Repository: []byte(`{"url":"u","tag":"` + sha + `"}`),
+				Commit:     sha,
+			})).To(gomega.BeTrue())
+}
+
+func TestDigest(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	saved := storage.Store
+	storage.Store = &store{
+		content: map[string]string{
+			"/bucket/.file/1": "rules",
+		},
+	}
+	defer func() {
+		storage.Store = saved
+	}()
+	//
+	// Read from the store.
+	d, err := digest(&model.File{Path: "/bucket/.file/1"})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(d).To(gomega.Equal("6c621d1a05138a7888d37d9269a9da8e2e11e4aced2f6cfd24b05ab1b9e61bb0"))
+	_, err = digest(&model.File{Path: "/bucket/.file/3"})
+	g.Expect(err).ToNot(gomega.BeNil())
+	//
+	// Recorded.
+	d, err = digest(&model.File{Path: "/bucket/.file/2", Digest: "abc"})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(d).To(gomega.Equal("abc"))
+}
+
+//
+// store content (fake).
+type store struct {
+	storage.Backend
+	content map[string]string
+}
+
+func (s *store) Get(path string) (reader io.ReadCloser, err error) {
+	content, found := s.content[path]
+	if !found {
+		err = &storage.NotFound{Path: path}
+		return
+	}
+	reader = io.NopCloser(strings.NewReader(content))
+	return
+}
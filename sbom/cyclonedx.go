@@ -0,0 +1,187 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+//
+// CycloneDX document.
+type cdxDocument struct {
+	BomFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies,omitempty"`
+}
+
+type cdxMetadata struct {
+	Timestamp string        `json:"timestamp,omitempty"`
+	Component *cdxComponent `json:"component,omitempty"`
+}
+
+type cdxComponent struct {
+	Ref        string        `json:"bom-ref,omitempty"`
+	Type       string        `json:"type"`
+	Group      string        `json:"group,omitempty"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Hashes     []cdxHash     `json:"hashes,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+//
+// CycloneDXCodec CycloneDX (JSON) codec.
+type CycloneDXCodec struct {
+}
+
+//
+// Encode the dependencies.
+// The subject (application) is the metadata component and the
+// dependency graph lists the direct dependencies.
+func (r *CycloneDXCodec) Encode(output io.Writer, subject string, deps []Dependency) (err error) {
+	root := "application"
+	doc := cdxDocument{
+		BomFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: cdxMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Component: &cdxComponent{
+				Ref:  root,
+				Type: "application",
+				Name: subject,
+			},
+		},
+		Components: []cdxComponent{},
+	}
+	direct := cdxDependency{Ref: root, DependsOn: []string{}}
+	for i := range deps {
+		d := &deps[i]
+		purl := PURL{}
+		purl.With(d)
+		c := cdxComponent{
+			Ref:     fmt.Sprintf("dependency-%d", i+1),
+			Type:    "library",
+			Name:    d.Name,
+			Version: d.Version,
+			PURL:    purl.String(),
+		}
+		if alg := hashAlg(d.SHA); alg != "" {
+			c.Hashes = []cdxHash{{Alg: alg, Content: d.SHA}}
+		}
+		if d.Provider != "" {
+			c.Properties = append(
+				c.Properties,
+				cdxProperty{Name: PropProvider, Value: d.Provider})
+		}
+		for _, label := range d.Labels {
+			c.Properties = append(
+				c.Properties,
+				cdxProperty{Name: PropLabel, Value: label})
+		}
+		doc.Components = append(doc.Components, c)
+		if !d.Indirect {
+			direct.DependsOn = append(direct.DependsOn, c.Ref)
+		}
+	}
+	doc.Dependencies = []cdxDependency{direct}
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(&doc)
+	return
+}
+
+//
+// Decode the dependencies.
+// When the dependency graph contains the metadata component,
+// components not listed as its dependencies are indirect.
+func (r *CycloneDXCodec) Decode(input io.Reader) (deps []Dependency, err error) {
+	doc := cdxDocument{}
+	err = json.NewDecoder(input).Decode(&doc)
+	if err != nil {
+		err = &DecodeError{Reason: err.Error()}
+		return
+	}
+	if doc.BomFormat != "CycloneDX" {
+		err = &DecodeError{Reason: "bomFormat must be: CycloneDX."}
+		return
+	}
+	var direct map[string]bool
+	if doc.Metadata.Component != nil && doc.Metadata.Component.Ref != "" {
+		for _, d := range doc.Dependencies {
+			if d.Ref == doc.Metadata.Component.Ref {
+				direct = make(map[string]bool)
+				for _, ref := range d.DependsOn {
+					direct[ref] = true
+				}
+				break
+			}
+		}
+	}
+	for _, c := range doc.Components {
+		d := Dependency{
+			Name:    c.Name,
+			Version: c.Version,
+		}
+		if c.Group != "" {
+			d.Name = c.Group + "/" + c.Name
+		}
+		if c.PURL != "" {
+			purl := PURL{}
+			if pErr := purl.Parse(c.PURL); pErr == nil {
+				d.Provider = purl.Provider()
+				if c.Group != "" {
+					d.Name = purl.FullName()
+				}
+				if d.Version == "" {
+					d.Version = purl.Version
+				}
+			}
+		}
+		for _, h := range c.Hashes {
+			if strings.HasPrefix(strings.ToUpper(h.Alg), "SHA") {
+				d.SHA = h.Content
+				break
+			}
+		}
+		for _, p := range c.Properties {
+			switch p.Name {
+			case PropProvider:
+				d.Provider = p.Value
+			case PropLabel:
+				d.Labels = append(d.Labels, p.Value)
+			}
+		}
+		if direct != nil {
+			d.Indirect = !direct[c.Ref]
+		}
+		if d.Name == "" {
+			err = &DecodeError{Reason: "component name required."}
+			return
+		}
+		deps = append(deps, d)
+	}
+	deps = unique(deps)
+	return
+}
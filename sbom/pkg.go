@@ -0,0 +1,279 @@
+package sbom
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+//
+// MIME types.
+const (
+	CycloneDX = "application/vnd.cyclonedx+json"
+	SPDX      = "application/spdx+json"
+)
+
+//
+// MIMEs supported SBOM MIME types.
+var MIMEs = []string{CycloneDX, SPDX}
+
+//
+// Property (and annotation) names.
+const (
+	PropProvider = "konveyor:provider"
+	PropLabel    = "konveyor:label"
+)
+
+//
+// Dependency is a format neutral SBOM dependency.
+type Dependency struct {
+	Provider string
+	Name     string
+	Version  string
+	SHA      string
+	Indirect bool
+	Labels   []string
+}
+
+//
+// key returns the natural key.
+func (r *Dependency) key() (k string) {
+	k = strings.Join(
+		[]string{
+			r.Provider,
+			r.Name,
+			r.Version,
+			r.SHA,
+		},
+		"|")
+	return
+}
+
+//
+// Codec encodes and decodes an SBOM document.
+type Codec interface {
+	// Encode the dependencies of the named subject (application).
+	Encode(output io.Writer, subject string, deps []Dependency) (err error)
+	// Decode the dependencies.
+	Decode(input io.Reader) (deps []Dependency, err error)
+}
+
+//
+// New returns a codec for the MIME type.
+func New(mime string) (codec Codec, err error) {
+	mime = strings.TrimSpace(strings.Split(mime, ";")[0])
+	switch mime {
+	case CycloneDX:
+		codec = &CycloneDXCodec{}
+	case SPDX:
+		codec = &SPDXCodec{}
+	default:
+		err = &MIMEError{MIME: mime}
+	}
+	return
+}
+
+//
+// Supported returns true when the MIME type is supported.
+func Supported(mime string) (b bool) {
+	_, err := New(mime)
+	b = err == nil
+	return
+}
+
+//
+// MIMEError reports an unsupported MIME type.
+type MIMEError struct {
+	MIME string
+}
+
+func (e *MIMEError) Error() (s string) {
+	return fmt.Sprintf("SBOM MIME: '%s' not supported.", e.MIME)
+}
+
+func (e *MIMEError) Is(err error) (matched bool) {
+	_, matched = err.(*MIMEError)
+	return
+}
+
+//
+// DecodeError reports a malformed document.
+type DecodeError struct {
+	Reason string
+}
+
+func (e *DecodeError) Error() (s string) {
+	return fmt.Sprintf("SBOM not valid: %s", e.Reason)
+}
+
+func (e *DecodeError) Is(err error) (matched bool) {
+	_, matched = err.(*DecodeError)
+	return
+}
+
+//
+// unique returns the dependencies with duplicates removed.
+func unique(in []Dependency) (out []Dependency) {
+	seen := make(map[string]bool)
+	for _, d := range in {
+		k := d.key()
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, d)
+	}
+	return
+}
+
+//
+// Package URL (purl) types by provider.
+var purlTypes = map[string]string{
+	"java":       "maven",
+	"go":         "golang",
+	"python":     "pypi",
+	"nodejs":     "npm",
+	"javascript": "npm",
+	"dotnet":     "nuget",
+	"csharp":     "nuget",
+}
+
+//
+// Providers by purl type.
+var providers = map[string]string{
+	"maven":   "java",
+	"golang":  "go",
+	"pypi":    "python",
+	"npm":     "nodejs",
+	"nuget":   "dotnet",
+	"generic": "",
+}
+
+//
+// PURL package URL.
+type PURL struct {
+	Type      string
+	Namespace string
+	Name      string
+	Version   string
+}
+
+//
+// With populates the purl using the dependency.
+// Maven names are in the form of: group.artifact.
+func (r *PURL) With(d *Dependency) {
+	r.Type = d.Provider
+	if t, found := purlTypes[d.Provider]; found {
+		r.Type = t
+	}
+	if r.Type == "" {
+		r.Type = "generic"
+	}
+	r.Name = d.Name
+	r.Version = d.Version
+	switch r.Type {
+	case "maven":
+		n := strings.LastIndex(d.Name, ".")
+		if n > 0 {
+			r.Namespace = d.Name[:n]
+			r.Name = d.Name[n+1:]
+		}
+	default:
+		n := strings.LastIndex(d.Name, "/")
+		if n > 0 {
+			r.Namespace = d.Name[:n]
+			r.Name = d.Name[n+1:]
+		}
+	}
+}
+
+//
+// Parse a purl string.
+// Qualifiers and subpath are ignored.
+func (r *PURL) Parse(s string) (err error) {
+	if !strings.HasPrefix(s, "pkg:") {
+		err = &DecodeError{Reason: "purl: " + s}
+		return
+	}
+	s = s[4:]
+	s = strings.Split(s, "#")[0]
+	s = strings.Split(s, "?")[0]
+	if n := strings.LastIndex(s, "@"); n > 0 {
+		r.Version, _ = url.PathUnescape(s[n+1:])
+		s = s[:n]
+	}
+	part := strings.Split(strings.Trim(s, "/"), "/")
+	if len(part) < 2 {
+		err = &DecodeError{Reason: "purl: " + s}
+		return
+	}
+	for i := range part {
+		part[i], _ = url.PathUnescape(part[i])
+	}
+	r.Type = strings.ToLower(part[0])
+	r.Name = part[len(part)-1]
+	r.Namespace = strings.Join(part[1:len(part)-1], "/")
+	return
+}
+
+//
+// Provider returns the provider by purl type.
+func (r *PURL) Provider() (provider string) {
+	provider = r.Type
+	if p, found := providers[r.Type]; found {
+		provider = p
+	}
+	return
+}
+
+//
+// FullName returns the dependency name.
+// Maven: group.artifact.
+// Others: namespace/name.
+func (r *PURL) FullName() (name string) {
+	name = r.Name
+	if r.Namespace == "" {
+		return
+	}
+	switch r.Type {
+	case "maven":
+		name = r.Namespace + "." + r.Name
+	default:
+		name = r.Namespace + "/" + r.Name
+	}
+	return
+}
+
+//
+// String representation.
+func (r *PURL) String() (s string) {
+	part := []string{url.PathEscape(r.Type)}
+	if r.Namespace != "" {
+		for _, p := range strings.Split(r.Namespace, "/") {
+			part = append(part, url.PathEscape(p))
+		}
+	}
+	part = append(part, url.PathEscape(r.Name))
+	s = "pkg:" + strings.Join(part, "/")
+	if r.Version != "" {
+		s += "@" + url.PathEscape(r.Version)
+	}
+	return
+}
+
+//
+// hashAlg returns the (CycloneDX) hash algorithm by digest length.
+func hashAlg(digest string) (alg string) {
+	switch len(digest) {
+	case 32:
+		alg = "MD5"
+	case 40:
+		alg = "SHA-1"
+	case 64:
+		alg = "SHA-256"
+	case 128:
+		alg = "SHA-512"
+	}
+	return
+}
@@ -0,0 +1,82 @@
+package sbom
+
+import (
+	"bytes"
+	"github.com/onsi/gomega"
+	"strings"
+	"testing"
+)
+
+var samples = []Dependency{
+	{
+		Provider: "java",
+		Name:     "org.springframework.spring-core",
+		Version:  "5.3.20",
+		SHA:      "8f4d0b0b4c8a2a4f2e8a0b7c1e1d2d3c4b5a6f70",
+		Labels:   []string{"konveyor.io/dep-source=open-source"},
+	},
+	{
+		Provider: "go",
+		Name:     "github.com/konveyor/analyzer",
+		Version:  "v0.3.0",
+		Indirect: true,
+	},
+	{
+		Name:    "thing",
+		Version: "1.0",
+	},
+}
+
+func TestCodec(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	for _, mime := range MIMEs {
+		codec, err := New(mime + "; charset=utf-8")
+		g.Expect(err).To(gomega.BeNil())
+		b := bytes.Buffer{}
+		err = codec.Encode(&b, "Test", samples)
+		g.Expect(err).To(gomega.BeNil())
+		deps, err := codec.Decode(&b)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(deps).To(gomega.Equal(samples), mime)
+	}
+}
+
+func TestDecodeDuplicates(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	doc := `{
+	  "bomFormat": "CycloneDX",
+	  "specVersion": "1.4",
+	  "components": [
+	    {"type": "library", "name": "lib", "version": "1", "purl": "pkg:npm/%40scope/lib@1"},
+	    {"type": "library", "name": "lib", "version": "1", "purl": "pkg:npm/%40scope/lib@1"}
+	  ]
+	}`
+	codec, _ := New(CycloneDX)
+	deps, err := codec.Decode(strings.NewReader(doc))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(deps)).To(gomega.Equal(1))
+	g.Expect(deps[0].Provider).To(gomega.Equal("nodejs"))
+	g.Expect(deps[0].Indirect).To(gomega.BeFalse())
+}
+
+func TestDecodeNotValid(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	codec, _ := New(SPDX)
+	_, err := codec.Decode(strings.NewReader(`{"bomFormat": "CycloneDX"}`))
+	g.Expect(err).ToNot(gomega.BeNil())
+	_, err = New("application/json")
+	g.Expect(err).ToNot(gomega.BeNil())
+}
+
+func TestPURL(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	purl := PURL{}
+	purl.With(&samples[0])
+	g.Expect(purl.String()).To(
+		gomega.Equal("pkg:maven/org.springframework/spring-core@5.3.20"))
+	parsed := PURL{}
+	err := parsed.Parse(purl.String() + "?type=jar")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(parsed.FullName()).To(gomega.Equal(samples[0].Name))
+	g.Expect(parsed.Provider()).To(gomega.Equal("java"))
+}
@@ -0,0 +1,263 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+//
+// SPDX document.
+type spdxDocument struct {
+	Version           string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	ID                string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	Namespace         string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	DocumentDescribes []string           `json:"documentDescribes,omitempty"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships,omitempty"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	ID               string           `json:"SPDXID"`
+	Name             string           `json:"name"`
+	Version          string           `json:"versionInfo,omitempty"`
+	DownloadLocation string           `json:"downloadLocation"`
+	Checksums        []spdxChecksum   `json:"checksums,omitempty"`
+	ExternalRefs     []spdxRef        `json:"externalRefs,omitempty"`
+	Annotations      []spdxAnnotation `json:"annotations,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+type spdxRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type spdxAnnotation struct {
+	Type      string `json:"annotationType"`
+	Annotator string `json:"annotator"`
+	Date      string `json:"annotationDate"`
+	Comment   string `json:"comment"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+//
+// SPDXCodec SPDX 2.3 (JSON) codec.
+type SPDXCodec struct {
+}
+
+//
+// Encode the dependencies.
+// The subject (application) is the described package which
+// DEPENDS_ON the direct dependencies.
+func (r *SPDXCodec) Encode(output io.Writer, subject string, deps []Dependency) (err error) {
+	root := "SPDXRef-Application"
+	now := time.Now().UTC().Format(time.RFC3339)
+	annotator := "Tool: konveyor-hub"
+	doc := spdxDocument{
+		Version:     "SPDX-2.3",
+		DataLicense: "CC0-1.0",
+		ID:          "SPDXRef-DOCUMENT",
+		Name:        subject,
+		Namespace: fmt.Sprintf(
+			"https://konveyor.io/spdx/%s-%d",
+			strings.ReplaceAll(subject, " ", "-"),
+			time.Now().UnixNano()),
+		CreationInfo: spdxCreationInfo{
+			Created:  now,
+			Creators: []string{annotator},
+		},
+		DocumentDescribes: []string{root},
+		Packages: []spdxPackage{
+			{
+				ID:               root,
+				Name:             subject,
+				DownloadLocation: "NOASSERTION",
+			},
+		},
+		Relationships: []spdxRelationship{
+			{
+				Element: "SPDXRef-DOCUMENT",
+				Type:    "DESCRIBES",
+				Related: root,
+			},
+		},
+	}
+	for i := range deps {
+		d := &deps[i]
+		purl := PURL{}
+		purl.With(d)
+		p := spdxPackage{
+			ID:               fmt.Sprintf("SPDXRef-Package-%d", i+1),
+			Name:             d.Name,
+			Version:          d.Version,
+			DownloadLocation: "NOASSERTION",
+			ExternalRefs: []spdxRef{
+				{
+					Category: "PACKAGE-MANAGER",
+					Type:     "purl",
+					Locator:  purl.String(),
+				},
+			},
+		}
+		if alg := hashAlg(d.SHA); alg != "" {
+			p.Checksums = []spdxChecksum{
+				{
+					Algorithm: strings.ReplaceAll(alg, "-", ""),
+					Value:     d.SHA,
+				},
+			}
+		}
+		comments := []string{}
+		if d.Provider != "" {
+			comments = append(comments, PropProvider+"="+d.Provider)
+		}
+		for _, label := range d.Labels {
+			comments = append(comments, PropLabel+"="+label)
+		}
+		for _, comment := range comments {
+			p.Annotations = append(
+				p.Annotations,
+				spdxAnnotation{
+					Type:      "OTHER",
+					Annotator: annotator,
+					Date:      now,
+					Comment:   comment,
+				})
+		}
+		doc.Packages = append(doc.Packages, p)
+		if !d.Indirect {
+			doc.Relationships = append(
+				doc.Relationships,
+				spdxRelationship{
+					Element: root,
+					Type:    "DEPENDS_ON",
+					Related: p.ID,
+				})
+		}
+	}
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(&doc)
+	return
+}
+
+//
+// Decode the dependencies.
+// The described package(s) are the subject and are not dependencies.
+// When the subject is found, packages not related as its (direct)
+// dependencies are indirect.
+func (r *SPDXCodec) Decode(input io.Reader) (deps []Dependency, err error) {
+	doc := spdxDocument{}
+	err = json.NewDecoder(input).Decode(&doc)
+	if err != nil {
+		err = &DecodeError{Reason: err.Error()}
+		return
+	}
+	if !strings.HasPrefix(doc.Version, "SPDX-") {
+		err = &DecodeError{Reason: "spdxVersion must be: SPDX-2.x."}
+		return
+	}
+	described := make(map[string]bool)
+	for _, id := range doc.DocumentDescribes {
+		described[id] = true
+	}
+	for _, rel := range doc.Relationships {
+		switch rel.Type {
+		case "DESCRIBES":
+			if rel.Element == doc.ID {
+				described[rel.Related] = true
+			}
+		case "DESCRIBED_BY":
+			if rel.Related == doc.ID {
+				described[rel.Element] = true
+			}
+		}
+	}
+	var direct map[string]bool
+	if len(described) > 0 {
+		direct = make(map[string]bool)
+		for _, rel := range doc.Relationships {
+			switch rel.Type {
+			case "DEPENDS_ON":
+				if described[rel.Element] {
+					direct[rel.Related] = true
+				}
+			case "DEPENDENCY_OF":
+				if described[rel.Related] {
+					direct[rel.Element] = true
+				}
+			}
+		}
+	}
+	for _, p := range doc.Packages {
+		if described[p.ID] {
+			continue
+		}
+		d := Dependency{
+			Name:    p.Name,
+			Version: p.Version,
+		}
+		for _, ref := range p.ExternalRefs {
+			if ref.Type != "purl" {
+				continue
+			}
+			purl := PURL{}
+			if pErr := purl.Parse(ref.Locator); pErr == nil {
+				d.Provider = purl.Provider()
+				if d.Version == "" {
+					d.Version = purl.Version
+				}
+			}
+			break
+		}
+		for _, sum := range p.Checksums {
+			if strings.HasPrefix(strings.ToUpper(sum.Algorithm), "SHA") {
+				d.SHA = sum.Value
+				break
+			}
+		}
+		for _, a := range p.Annotations {
+			part := strings.SplitN(a.Comment, "=", 2)
+			if len(part) != 2 {
+				continue
+			}
+			switch part[0] {
+			case PropProvider:
+				d.Provider = part[1]
+			case PropLabel:
+				d.Labels = append(d.Labels, part[1])
+			}
+		}
+		if direct != nil {
+			d.Indirect = !direct[p.ID]
+		}
+		if d.Name == "" {
+			err = &DecodeError{Reason: "package name required."}
+			return
+		}
+		deps = append(deps, d)
+	}
+	deps = unique(deps)
+	return
+}
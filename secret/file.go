@@ -0,0 +1,90 @@
+package secret
+
+import (
+	"encoding/json"
+	"errors"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/settings"
+	"os"
+	"path"
+)
+
+//
+// File backend.
+// Secrets are stored as (JSON) files in a local directory.
+// Intended for development and testing.
+type File struct {
+	// Path to the directory.
+	Path string
+}
+
+//
+// Kind returns the backend kind.
+func (r *File) Kind() (kind string) {
+	kind = settings.SecretFile
+	return
+}
+
+//
+// Get the secret by name.
+func (r *File) Get(name string) (s Secret, err error) {
+	b, err := os.ReadFile(r.path(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = &NotFound{Name: name}
+		} else {
+			err = liberr.Wrap(err)
+		}
+		return
+	}
+	s = Secret{}
+	err = json.Unmarshal(b, &s)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// Put (create or replace) the secret.
+func (r *File) Put(name string, s Secret) (err error) {
+	err = os.MkdirAll(r.Path, 0700)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	err = os.WriteFile(r.path(name), b, 0600)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// Delete the secret.
+func (r *File) Delete(name string) (err error) {
+	err = os.Remove(r.path(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = &NotFound{Name: name}
+		} else {
+			err = liberr.Wrap(err)
+		}
+		return
+	}
+	return
+}
+
+//
+// path returns the path to the secret file.
+func (r *File) path(name string) (p string) {
+	p = path.Join(r.Path, path.Base(name)+".json")
+	return
+}
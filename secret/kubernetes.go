@@ -0,0 +1,109 @@
+package secret
+
+import (
+	"context"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/settings"
+	core "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//
+// Kubernetes backend.
+// Secrets are stored as k8s Secrets in the hub namespace.
+type Kubernetes struct {
+	// k8s client.
+	Client k8s.Client
+	// Namespace.
+	Namespace string
+}
+
+//
+// Kind returns the backend kind.
+func (r *Kubernetes) Kind() (kind string) {
+	kind = settings.SecretKubernetes
+	return
+}
+
+//
+// Get the secret by name.
+func (r *Kubernetes) Get(name string) (s Secret, err error) {
+	secret := &core.Secret{}
+	err = r.Client.Get(
+		context.TODO(),
+		k8s.ObjectKey{
+			Namespace: r.Namespace,
+			Name:      name,
+		},
+		secret)
+	if err != nil {
+		if k8serr.IsNotFound(err) {
+			err = &NotFound{Name: name}
+		} else {
+			err = liberr.Wrap(err)
+		}
+		return
+	}
+	s = Secret{}
+	for k, v := range secret.Data {
+		s[k] = string(v)
+	}
+	return
+}
+
+//
+// Put (create or replace) the secret.
+func (r *Kubernetes) Put(name string, s Secret) (err error) {
+	secret := &core.Secret{
+		ObjectMeta: meta.ObjectMeta{
+			Namespace: r.Namespace,
+			Name:      name,
+			Labels:    r.labels(),
+		},
+		Data: map[string][]byte{},
+	}
+	for k, v := range s {
+		secret.Data[k] = []byte(v)
+	}
+	err = r.Client.Update(context.TODO(), secret)
+	if k8serr.IsNotFound(err) {
+		err = r.Client.Create(context.TODO(), secret)
+	}
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// Delete the secret.
+func (r *Kubernetes) Delete(name string) (err error) {
+	secret := &core.Secret{
+		ObjectMeta: meta.ObjectMeta{
+			Namespace: r.Namespace,
+			Name:      name,
+		},
+	}
+	err = r.Client.Delete(context.TODO(), secret)
+	if err != nil {
+		if k8serr.IsNotFound(err) {
+			err = &NotFound{Name: name}
+		} else {
+			err = liberr.Wrap(err)
+		}
+		return
+	}
+	return
+}
+
+//
+// labels returns the secret labels.
+func (r *Kubernetes) labels() map[string]string {
+	return map[string]string{
+		"app":  "tackle",
+		"role": "identity",
+	}
+}
@@ -0,0 +1,251 @@
+package secret
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/jortel/go-utils/logr"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/settings"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+)
+
+var (
+	Settings = &settings.Settings
+	Log      = logr.WithName("secret")
+)
+
+//
+// Identity (sensitive) fields.
+const (
+	Password = "password"
+	Key      = "key"
+	Config   = "settings"
+)
+
+//
+// Store is the configured backend.
+// Nil when identity credentials are encrypted and
+// stored in the DB.
+var Store Backend
+
+//
+// Secret credentials.
+type Secret map[string]string
+
+//
+// Backend stores identity credentials outside the DB.
+type Backend interface {
+	// Kind returns the backend kind.
+	Kind() string
+	// Get the secret by name.
+	// Returns NotFound when the secret does not exist.
+	Get(name string) (s Secret, err error)
+	// Put (create or replace) the secret.
+	Put(name string, s Secret) (err error)
+	// Delete the secret.
+	Delete(name string) (err error)
+}
+
+//
+// New returns the backend selected by settings.
+// Returns nil for the (default) database backend.
+func New(client k8s.Client) (backend Backend, err error) {
+	switch Settings.Secret.Backend {
+	case settings.SecretDatabase, "":
+	case settings.SecretKubernetes:
+		backend = &Kubernetes{
+			Client:    client,
+			Namespace: Settings.Hub.Namespace,
+		}
+	case settings.SecretVault:
+		backend = &Vault{
+			URL:   Settings.Secret.Vault.URL,
+			Token: Settings.Secret.Vault.Token,
+			Mount: Settings.Secret.Vault.Mount,
+		}
+	case settings.SecretFile:
+		backend = &File{
+			Path: Settings.Secret.Path,
+		}
+	default:
+		err = liberr.New("Secret backend: " + Settings.Secret.Backend + " not supported.")
+	}
+	return
+}
+
+//
+// NotFound reports secret not found.
+type NotFound struct {
+	Name string
+}
+
+func (e *NotFound) Error() string {
+	return "Secret: " + e.Name + " not found."
+}
+
+func (e *NotFound) Is(err error) (matched bool) {
+	_, matched = err.(*NotFound)
+	return
+}
+
+//
+// Encrypt (stores) the sensitive fields of the identity.
+// The ref identity (as stored) is used to determine when sensitive
+// fields have changed. When a backend is configured, changed fields
+// are stored in the backend secret referenced by the identity and
+// the DB field is set to a reference (marker).
+// Otherwise, the fields are encrypted and stored in the DB.
+func Encrypt(m, ref *model.Identity) (err error) {
+	m.Secret = ref.Secret
+	if Store == nil {
+		err = m.Encrypt(ref)
+		return
+	}
+	name := Name(ref.Secret)
+	if name == "" {
+		name, err = newName()
+		if err != nil {
+			return
+		}
+	}
+	s := Secret{}
+	if ref.Secret != "" {
+		s, err = Store.Get(name)
+		if err != nil {
+			if !errors.Is(err, &NotFound{}) {
+				return
+			}
+			s = Secret{}
+			err = nil
+		}
+	}
+	changed := false
+	for key, field := range fields(m) {
+		if *field == *fields(ref)[key] {
+			continue
+		}
+		changed = true
+		delete(s, key)
+		if *field != "" {
+			s[key] = *field
+			*field = marker(Store, name, key)
+		}
+	}
+	if !changed {
+		return
+	}
+	if len(s) > 0 {
+		err = Store.Put(name, s)
+		if err != nil {
+			return
+		}
+		m.Secret = Store.Kind() + ":" + name
+	} else {
+		m.Secret = ""
+		if ref.Secret != "" {
+			err = Store.Delete(name)
+			if errors.Is(err, &NotFound{}) {
+				err = nil
+			}
+		}
+	}
+	return
+}
+
+//
+// Decrypt the sensitive fields of the identity.
+// Fields referencing the backend secret are resolved through
+// the backend. Other fields are decrypted.
+func Decrypt(m *model.Identity) (err error) {
+	if m.Secret == "" {
+		err = m.Decrypt()
+		return
+	}
+	kind := strings.SplitN(m.Secret, ":", 2)[0]
+	if Store == nil || Store.Kind() != kind {
+		err = liberr.New("Secret: " + m.Secret + " backend not configured.")
+		return
+	}
+	name := Name(m.Secret)
+	s, err := Store.Get(name)
+	if err != nil {
+		return
+	}
+	legacy := &model.Identity{}
+	for key, field := range fields(m) {
+		if *field == marker(Store, name, key) {
+			*field = s[key]
+		} else {
+			*fields(legacy)[key] = *field
+		}
+	}
+	err = legacy.Decrypt()
+	if err != nil {
+		return
+	}
+	for key, field := range fields(legacy) {
+		if *field != "" {
+			*fields(m)[key] = *field
+		}
+	}
+	return
+}
+
+//
+// Delete the backend secret referenced by the identity.
+func Delete(m *model.Identity) (err error) {
+	if m.Secret == "" || Store == nil {
+		return
+	}
+	err = Store.Delete(Name(m.Secret))
+	if errors.Is(err, &NotFound{}) {
+		err = nil
+	}
+	return
+}
+
+//
+// Name returns the secret name from the reference.
+// Format: <kind>:<name>.
+func Name(ref string) (name string) {
+	part := strings.SplitN(ref, ":", 2)
+	if len(part) == 2 {
+		name = part[1]
+	}
+	return
+}
+
+//
+// fields returns the sensitive fields of the identity.
+func fields(m *model.Identity) (mp map[string]*string) {
+	mp = map[string]*string{
+		Password: &m.Password,
+		Key:      &m.Key,
+		Config:   &m.Settings,
+	}
+	return
+}
+
+//
+// marker returns the value stored in the DB field
+// referencing the backend secret.
+func marker(backend Backend, name, key string) (s string) {
+	s = backend.Kind() + ":" + name + "#" + key
+	return
+}
+
+//
+// newName returns a new (unique) secret name.
+func newName() (name string, err error) {
+	b := make([]byte, 8)
+	_, err = rand.Read(b)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	name = "identity-" + hex.EncodeToString(b)
+	return
+}
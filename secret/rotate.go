@@ -0,0 +1,182 @@
+package secret
+
+import (
+	"github.com/konveyor/tackle2-hub/encryption"
+	"github.com/konveyor/tackle2-hub/model"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+//
+// Kinds of resources rotated.
+const (
+	KindIdentity = "identity"
+	KindWebhook  = "webhook"
+)
+
+//
+// Rotation report.
+type Rotation struct {
+	// KeyID the current key ID.
+	KeyID string
+	// Total identities and webhooks.
+	Total int
+	// Rotated identities and webhooks.
+	Rotated int
+	// Skipped identities and webhooks (already current).
+	Skipped int
+	// Failed identities and webhooks.
+	Failed []RotationFailure
+}
+
+//
+// RotationFailure identity (or webhook) not rotated.
+type RotationFailure struct {
+	Kind  string
+	ID    uint
+	Name  string
+	Error string
+}
+
+//
+// RotationError reports identities failed to be rotated.
+type RotationError struct {
+	Failed int
+}
+
+func (e *RotationError) Error() string {
+	return "Rotation failed: " + strconv.Itoa(e.Failed) + " identities."
+}
+
+func (e *RotationError) Is(err error) (matched bool) {
+	_, matched = err.(*RotationError)
+	return
+}
+
+//
+// Rotate (re-encrypts) the sensitive fields of all identities
+// and the secrets of all webhooks using the current key.
+// Fields stored in a secret backend are not affected.
+// All identities are processed and RotationError is returned
+// when any have failed. The caller is expected to provide a
+// transaction and roll back on error.
+func Rotate(db *gorm.DB) (report *Rotation, err error) {
+	keyring := encryption.NewKeyring(
+		Settings.Encryption.Salt,
+		Settings.Encryption.Passphrase,
+		Settings.Encryption.Previous...)
+	report = &Rotation{KeyID: keyring.Current()}
+	var list []model.Identity
+	err = db.Find(&list).Error
+	if err != nil {
+		return
+	}
+	report.Total = len(list)
+	Log.Info("Rotation started.", "key", report.KeyID, "identities", report.Total)
+	for i := range list {
+		m := &list[i]
+		rotated, rErr := rotate(keyring, m)
+		if rErr == nil && rotated {
+			rErr = db.Model(&model.Identity{}).Where("ID", m.ID).UpdateColumns(
+				map[string]interface{}{
+					"Password": m.Password,
+					"Key":      m.Key,
+					"Settings": m.Settings,
+				}).Error
+		}
+		report.add(KindIdentity, m.ID, m.Name, rotated, rErr)
+		if (i+1)%100 == 0 {
+			Log.Info("Rotation progress.", "processed", i+1, "total", report.Total)
+		}
+	}
+	var webhooks []model.Webhook
+	err = db.Find(&webhooks).Error
+	if err != nil {
+		return
+	}
+	report.Total += len(webhooks)
+	for i := range webhooks {
+		m := &webhooks[i]
+		rotated, rErr := rotateField(keyring, &m.Secret)
+		if rErr == nil && rotated {
+			rErr = db.Model(&model.Webhook{}).Where("ID", m.ID).UpdateColumn("Secret", m.Secret).Error
+		}
+		report.add(KindWebhook, m.ID, m.Name, rotated, rErr)
+	}
+	Log.Info(
+		"Rotation completed.",
+		"rotated",
+		report.Rotated,
+		"skipped",
+		report.Skipped,
+		"failed",
+		len(report.Failed))
+	if len(report.Failed) > 0 {
+		err = &RotationError{Failed: len(report.Failed)}
+	}
+	return
+}
+
+//
+// add the result to the report.
+func (r *Rotation) add(kind string, id uint, name string, rotated bool, err error) {
+	switch {
+	case err != nil:
+		Log.Error(err, "Not rotated.", "kind", kind, "id", id, "name", name)
+		r.Failed = append(
+			r.Failed,
+			RotationFailure{
+				Kind:  kind,
+				ID:    id,
+				Name:  name,
+				Error: err.Error(),
+			})
+	case rotated:
+		r.Rotated++
+	default:
+		r.Skipped++
+	}
+}
+
+//
+// rotate re-encrypts the fields of the identity not encrypted
+// using the current key.
+func rotate(keyring *encryption.Keyring, m *model.Identity) (rotated bool, err error) {
+	for key, field := range fields(m) {
+		if m.Secret != "" && *field == m.Secret+"#"+key {
+			continue
+		}
+		var fieldRotated bool
+		fieldRotated, err = rotateField(keyring, field)
+		if err != nil {
+			return
+		}
+		if fieldRotated {
+			rotated = true
+		}
+	}
+	return
+}
+
+//
+// rotateField re-encrypts the field when not encrypted
+// using the current key.
+func rotateField(keyring *encryption.Keyring, field *string) (rotated bool, err error) {
+	if *field == "" {
+		return
+	}
+	id, versioned := keyring.KeyID(*field)
+	if versioned && id == keyring.Current() {
+		return
+	}
+	plain, err := keyring.Decrypt(*field)
+	if err != nil {
+		return
+	}
+	*field, err = keyring.Encrypt(plain)
+	if err != nil {
+		return
+	}
+	rotated = true
+	return
+}
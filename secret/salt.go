@@ -0,0 +1,53 @@
+package secret
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/model"
+	"gorm.io/gorm"
+)
+
+//
+// SaltKey is the (private) setting containing the
+// installation salt used to derive encryption key IDs.
+const SaltKey = ".encryption.salt"
+
+//
+// LoadSalt loads the installation salt into the settings.
+// The salt is generated and stored when not found.
+func LoadSalt(db *gorm.DB) (err error) {
+	setting := &model.Setting{}
+	err = db.First(setting, "Key", SaltKey).Error
+	if err == nil {
+		var salt []byte
+		err = json.Unmarshal(setting.Value, &salt)
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		Settings.Encryption.Salt = salt
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		err = liberr.Wrap(err)
+		return
+	}
+	salt := make([]byte, 32)
+	_, err = rand.Read(salt)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	setting.Key = SaltKey
+	setting.Value, _ = json.Marshal(salt)
+	err = db.Create(setting).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	Settings.Encryption.Salt = salt
+	Log.Info("Encryption salt created.")
+	return
+}
@@ -0,0 +1,250 @@
+package secret
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/konveyor/tackle2-hub/encryption"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	Store = &File{Path: t.TempDir()}
+	defer func() {
+		Store = nil
+	}()
+	//
+	// Create.
+	m := &model.Identity{Password: "p1", Key: "k1"}
+	err := Encrypt(m, &model.Identity{})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(strings.HasPrefix(m.Secret, "file:identity-")).To(gomega.BeTrue())
+	g.Expect(m.Password).To(gomega.Equal(m.Secret + "#password"))
+	g.Expect(m.Key).To(gomega.Equal(m.Secret + "#key"))
+	g.Expect(m.Settings).To(gomega.Equal(""))
+	decrypted := *m
+	err = Decrypt(&decrypted)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(decrypted.Password).To(gomega.Equal("p1"))
+	g.Expect(decrypted.Key).To(gomega.Equal("k1"))
+	//
+	// Update: password changed; key unchanged.
+	ref := *m
+	updated := &model.Identity{Password: "p2", Key: ref.Key}
+	err = Encrypt(updated, &ref)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(updated.Secret).To(gomega.Equal(ref.Secret))
+	decrypted = *updated
+	err = Decrypt(&decrypted)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(decrypted.Password).To(gomega.Equal("p2"))
+	g.Expect(decrypted.Key).To(gomega.Equal("k1"))
+	//
+	// Update: all cleared.
+	ref = *updated
+	cleared := &model.Identity{}
+	err = Encrypt(cleared, &ref)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cleared.Secret).To(gomega.Equal(""))
+	_, err = Store.Get(Name(ref.Secret))
+	g.Expect(errors.Is(err, &NotFound{})).To(gomega.BeTrue())
+}
+
+func TestLegacy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	Settings.Encryption.Passphrase = "tackle"
+	//
+	// Encrypted (DB).
+	ref := &model.Identity{}
+	m := &model.Identity{Password: "p1", Key: "k1"}
+	err := Encrypt(m, ref)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(m.Secret).To(gomega.Equal(""))
+	g.Expect(m.Password).ToNot(gomega.Equal("p1"))
+	//
+	// Backend configured; key updated.
+	Store = &File{Path: t.TempDir()}
+	defer func() {
+		Store = nil
+	}()
+	ref = m
+	updated := &model.Identity{Password: ref.Password, Key: "k2"}
+	err = Encrypt(updated, ref)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(updated.Password).To(gomega.Equal(ref.Password))
+	g.Expect(updated.Key).To(gomega.Equal(updated.Secret + "#key"))
+	err = Decrypt(updated)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(updated.Password).To(gomega.Equal("p1"))
+	g.Expect(updated.Key).To(gomega.Equal("k2"))
+}
+
+func TestVault(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	mutex := sync.Mutex{}
+	stored := map[string]Secret{}
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if r.Header.Get("X-Vault-Token") != "t" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			switch r.Method + " " + strings.TrimSuffix(r.URL.Path, name) {
+			case "GET /v1/kv/data/":
+				s, found := stored[name]
+				if !found {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(
+					map[string]interface{}{
+						"data": map[string]interface{}{
+							"data": s,
+						},
+					})
+			case "POST /v1/kv/data/":
+				body := struct {
+					Data Secret `json:"data"`
+				}{}
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				stored[name] = body.Data
+				_, _ = w.Write([]byte("{}"))
+			case "DELETE /v1/kv/metadata/":
+				delete(stored, name)
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	defer server.Close()
+	Store = &Vault{URL: server.URL, Token: "t", Mount: "kv"}
+	defer func() {
+		Store = nil
+	}()
+	m := &model.Identity{Password: "p1", Settings: "s1"}
+	err := Encrypt(m, &model.Identity{})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(strings.HasPrefix(m.Secret, "vault:")).To(gomega.BeTrue())
+	g.Expect(stored[Name(m.Secret)]).To(
+		gomega.Equal(Secret{Password: "p1", Config: "s1"}))
+	decrypted := *m
+	err = Decrypt(&decrypted)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(decrypted.Password).To(gomega.Equal("p1"))
+	g.Expect(decrypted.Settings).To(gomega.Equal("s1"))
+	err = Delete(m)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(stored).To(gomega.BeEmpty())
+	//
+	// Not authorized.
+	Store = &Vault{URL: server.URL, Token: "x", Mount: "kv"}
+	_, err = Store.Get("any")
+	g.Expect(err).ToNot(gomega.BeNil())
+	g.Expect(errors.Is(err, &NotFound{})).To(gomega.BeFalse())
+}
+
+func TestRotate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	db, err := gorm.Open(
+		sqlite.Open(path.Join(t.TempDir(), "test.db")),
+		&gorm.Config{
+			NamingStrategy: &schema.NamingStrategy{
+				SingularTable: true,
+				NoLowerCase:   true,
+			},
+		})
+	g.Expect(err).To(gomega.BeNil())
+	err = db.AutoMigrate(
+		&model.Identity{},
+		&model.Setting{},
+		&model.Webhook{},
+		&model.WebhookDelivery{})
+	g.Expect(err).To(gomega.BeNil())
+	defer func() {
+		Settings.Encryption.Passphrase = "tackle"
+		Settings.Encryption.Previous = nil
+		Settings.Encryption.Salt = nil
+	}()
+	//
+	// Salt created and reloaded.
+	err = LoadSalt(db)
+	g.Expect(err).To(gomega.BeNil())
+	salt := Settings.Encryption.Salt
+	g.Expect(len(salt)).To(gomega.Equal(32))
+	err = LoadSalt(db)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(Settings.Encryption.Salt).To(gomega.Equal(salt))
+	//
+	// Legacy (unversioned).
+	legacy, _ := encryption.New("k0").Encrypt("p0")
+	err = db.Create(&model.Identity{Kind: "git", Name: "legacy", Password: legacy}).Error
+	g.Expect(err).To(gomega.BeNil())
+	//
+	// Versioned (k1).
+	Settings.Encryption.Passphrase = "k1"
+	Settings.Encryption.Previous = []string{"k0"}
+	m := &model.Identity{Kind: "git", Name: "k1", Password: "p1", Key: "key1"}
+	err = Encrypt(m, &model.Identity{})
+	g.Expect(err).To(gomega.BeNil())
+	err = db.Create(m).Error
+	g.Expect(err).To(gomega.BeNil())
+	webhook := &model.Webhook{Name: "w", URL: "http://w", Secret: "s1"}
+	err = webhook.Encrypt()
+	g.Expect(err).To(gomega.BeNil())
+	err = db.Create(webhook).Error
+	g.Expect(err).To(gomega.BeNil())
+	//
+	// Rotate to k2.
+	Settings.Encryption.Passphrase = "k2"
+	Settings.Encryption.Previous = []string{"k1", "k0"}
+	report, err := Rotate(db)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(report.KeyID).To(gomega.Equal(encryption.KeyID(salt, "k2")))
+	g.Expect(report.Total).To(gomega.Equal(3))
+	g.Expect(report.Rotated).To(gomega.Equal(3))
+	report, err = Rotate(db)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(report.Skipped).To(gomega.Equal(3))
+	//
+	// Previous keys retired.
+	Settings.Encryption.Previous = nil
+	var list []model.Identity
+	err = db.Order("ID").Find(&list).Error
+	g.Expect(err).To(gomega.BeNil())
+	plain := []string{}
+	for i := range list {
+		m := &list[i]
+		err = Decrypt(m)
+		g.Expect(err).To(gomega.BeNil())
+		plain = append(plain, m.Password, m.Key)
+	}
+	g.Expect(plain).To(gomega.Equal([]string{"p0", "", "p1", "key1"}))
+	webhook = &model.Webhook{}
+	err = db.First(webhook).Error
+	g.Expect(err).To(gomega.BeNil())
+	err = webhook.Decrypt()
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(webhook.Secret).To(gomega.Equal("s1"))
+	//
+	// Failed: key not active.
+	Settings.Encryption.Passphrase = "k3"
+	tx := db.Begin()
+	report, err = Rotate(tx)
+	tx.Rollback()
+	g.Expect(errors.Is(err, &RotationError{})).To(gomega.BeTrue())
+	g.Expect(len(report.Failed)).To(gomega.Equal(3))
+	g.Expect(report.Failed[2].Kind).To(gomega.Equal(KindWebhook))
+}
@@ -0,0 +1,158 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/settings"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//
+// Vault backend.
+// Secrets are stored in a (HashiCorp) vault KV (v2) secrets engine.
+type Vault struct {
+	// URL of the vault.
+	URL string
+	// Token used to authenticate.
+	Token string
+	// Mount (path) of the KV engine.
+	Mount string
+	// client
+	client *http.Client
+}
+
+//
+// Kind returns the backend kind.
+func (r *Vault) Kind() (kind string) {
+	kind = settings.SecretVault
+	return
+}
+
+//
+// Get the secret by name.
+func (r *Vault) Get(name string) (s Secret, err error) {
+	response, err := r.send(http.MethodGet, r.path("data", name), nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		err = &NotFound{Name: name}
+		return
+	default:
+		err = r.failed(response)
+		return
+	}
+	body := struct {
+		Data struct {
+			Data Secret `json:"data"`
+		} `json:"data"`
+	}{}
+	err = json.NewDecoder(response.Body).Decode(&body)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	s = body.Data.Data
+	if s == nil {
+		err = &NotFound{Name: name}
+	}
+	return
+}
+
+//
+// Put (create or replace) the secret.
+func (r *Vault) Put(name string, s Secret) (err error) {
+	body := map[string]interface{}{
+		"data": s,
+	}
+	response, err := r.send(http.MethodPost, r.path("data", name), body)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+	default:
+		err = r.failed(response)
+	}
+	return
+}
+
+//
+// Delete the secret (all versions).
+func (r *Vault) Delete(name string) (err error) {
+	response, err := r.send(http.MethodDelete, r.path("metadata", name), nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+	case http.StatusNotFound:
+		err = &NotFound{Name: name}
+	default:
+		err = r.failed(response)
+	}
+	return
+}
+
+//
+// send the request.
+func (r *Vault) send(method, path string, body interface{}) (response *http.Response, err error) {
+	if r.client == nil {
+		r.client = &http.Client{Timeout: 30 * time.Second}
+	}
+	var b []byte
+	if body != nil {
+		b, err = json.Marshal(body)
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+	}
+	url := strings.TrimSuffix(r.URL, "/") + path
+	request, err := http.NewRequest(method, url, bytes.NewReader(b))
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	request.Header.Set("X-Vault-Token", r.Token)
+	request.Header.Set("Content-Type", "application/json")
+	response, err = r.client.Do(request)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// path returns the API path.
+func (r *Vault) path(kind, name string) (p string) {
+	mount := strings.Trim(r.Mount, "/")
+	p = "/v1/" + mount + "/" + kind + "/" + name
+	return
+}
+
+//
+// failed returns an error for the response.
+func (r *Vault) failed(response *http.Response) (err error) {
+	err = liberr.New(
+		"Vault request failed: "+strconv.Itoa(response.StatusCode),
+		"url",
+		response.Request.URL.String())
+	return
+}
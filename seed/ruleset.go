@@ -1,14 +1,18 @@
 package seed
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	liberr "github.com/jortel/go-utils/error"
 	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/rule"
+	"github.com/konveyor/tackle2-hub/storage"
 	libseed "github.com/konveyor/tackle2-seed/pkg"
 	"gorm.io/gorm"
-	"io"
 	"os"
 	"path"
 )
@@ -109,6 +113,10 @@ func (r *RuleSet) Apply(db *gorm.DB) (err error) {
 			err = liberr.Wrap(result.Error)
 			return
 		}
+		_, err = rule.Revise(db, ruleSet.ID)
+		if err != nil {
+			return
+		}
 	}
 
 	value, _ := json.Marshal(ids)
@@ -137,12 +145,20 @@ func (r *RuleSet) applyRules(db *gorm.DB, ruleSet *model.RuleSet, rs libseed.Rul
 			err = liberr.Wrap(fErr)
 			return
 		}
-		rule := model.Rule{
+		content, fErr := os.ReadFile(rl.Path)
+		if fErr != nil {
+			err = liberr.Wrap(fErr)
+			return
+		}
+		parsed := rule.Parse(path.Base(rl.Path), content)
+		ruleIDs, _ := json.Marshal(parsed.RuleIDs)
+		m := model.Rule{
 			Labels:    labels,
+			RuleIDs:   ruleIDs,
 			RuleSetID: ruleSet.ID,
 			FileID:    &f.ID,
 		}
-		result = db.Save(&rule)
+		result = db.Save(&m)
 		if result.Error != nil {
 			err = liberr.Wrap(result.Error)
 			return
@@ -152,31 +168,53 @@ func (r *RuleSet) applyRules(db *gorm.DB, ruleSet *model.RuleSet, rs libseed.Rul
 }
 
 //
-// Create a File model and copy a real file to its path.
+// Find or create a File model for the content of a real file.
+// Files are matched by name and (content) digest to prevent
+// duplication on reseed. The content is stored as needed.
 func file(db *gorm.DB, filePath string) (file *model.File, err error) {
-	file = &model.File{
-		Name: path.Base(filePath),
-	}
-	err = db.Create(&file).Error
+	content, err := os.ReadFile(filePath)
 	if err != nil {
 		err = liberr.Wrap(err)
 		return
 	}
-	src, err := os.Open(filePath)
-	if err != nil {
+	sum := sha256.Sum256(content)
+	name := path.Base(filePath)
+	digest := hex.EncodeToString(sum[:])
+	unlock := storage.Lock(digest)
+	defer unlock()
+	file = &model.File{}
+	err = db.First(file, "Name = ? AND Digest = ?", name, digest).Error
+	switch {
+	case err == nil:
+		if file.Expiration != nil {
+			file.Expiration = nil
+			err = db.Save(file).Error
+			if err != nil {
+				err = liberr.Wrap(err)
+				return
+			}
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		file = &model.File{
+			Name:   name,
+			Digest: digest,
+		}
+		err = db.Create(file).Error
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+	default:
 		err = liberr.Wrap(err)
 		return
 	}
-	defer src.Close()
-	dst, err := os.Create(file.Path)
-	if err != nil {
-		err = liberr.Wrap(err)
+	size := int64(len(content))
+	info, sErr := storage.Store.Stat(file.Path)
+	if sErr == nil && info.Size == size {
 		return
 	}
-	defer dst.Close()
-	_, err = io.Copy(dst, src)
+	err = storage.Store.Put(file.Path, bytes.NewReader(content), size)
 	if err != nil {
-		err = liberr.Wrap(err)
 		return
 	}
 	return
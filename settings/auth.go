@@ -2,12 +2,15 @@ package settings
 
 import (
 	"os"
+	"strconv"
 )
 
 //
 // Environment variables
 const (
 	EnvAuthRequired          = "AUTH_REQUIRED"
+	EnvAuthProvider          = "AUTH_PROVIDER"
+	EnvAuthOwnership         = "AUTH_OWNERSHIP"
 	EnvKeycloakHost          = "KEYCLOAK_HOST"
 	EnvKeycloakRealm         = "KEYCLOAK_REALM"
 	EnvKeycloakClientID      = "KEYCLOAK_CLIENT_ID"
@@ -17,13 +20,35 @@ const (
 	EnvKeycloakAdminRealm    = "KEYCLOAK_ADMIN_REALM"
 	EnvKeycloakReqPassUpdate = "KEYCLOAK_REQ_PASS_UPDATE"
 	EnvBuiltinTokenKey       = "ADDON_TOKEN"
+	EnvAPITokenLifespan      = "API_TOKEN_LIFESPAN"
+	EnvAPITokenUnmanaged     = "API_TOKEN_UNMANAGED_LIFESPAN"
 	EnvRolePath              = "ROLE_PATH"
 	EnvUserPath              = "USER_PATH"
+	EnvOIDCIssuer            = "OIDC_ISSUER"
+	EnvOIDCClientID          = "OIDC_CLIENT_ID"
+	EnvOIDCClientSecret      = "OIDC_CLIENT_SECRET"
+	EnvOIDCAudience          = "OIDC_AUDIENCE"
+	EnvOIDCUserClaim         = "OIDC_USER_CLAIM"
+	EnvOIDCRoleClaim         = "OIDC_ROLE_CLAIM"
+	EnvOIDCRoleMap           = "OIDC_ROLE_MAP"
+	EnvOIDCScopeClaim        = "OIDC_SCOPE_CLAIM"
+	EnvOIDCKeyTTL            = "OIDC_KEY_TTL"
+)
+
+//
+// Auth providers.
+const (
+	AuthKeycloak = "keycloak"
+	AuthOIDC     = "oidc"
 )
 
 type Auth struct {
 	// Auth required
 	Required bool
+	// Provider (remote) keycloak|oidc.
+	Provider string
+	// Ownership (row-level) authorization of applications.
+	Ownership bool
 	// Keycloak client config
 	Keycloak struct {
 		Host         string
@@ -37,6 +62,22 @@ type Auth struct {
 		}
 		RequirePasswordUpdate bool
 	}
+	// Generic OIDC provider config.
+	OIDC struct {
+		Issuer       string
+		ClientID     string
+		ClientSecret string
+		Audience     string
+		UserClaim    string
+		RoleClaim    string
+		// RoleMap value=role[,value=role]
+		RoleMap string
+		// ScopeClaim enables granting the scopes
+		// (resource:verb) found in the `scope` claim.
+		ScopeClaim bool
+		// KeyTTL (minutes) JWKS cache.
+		KeyTTL int
+	}
 	// Path to role yaml
 	RolePath string
 	// Path to user yaml
@@ -45,14 +86,35 @@ type Auth struct {
 	Token struct {
 		Key string
 	}
+	// APIToken settings for hub-managed tokens.
+	APIToken struct {
+		// Lifespan (days) when expiration not specified.
+		// 0 = never expires.
+		Lifespan int
+		// Unmanaged lifespan (days) of personal tokens issued to
+		// users not managed by the hub (keycloak|oidc). The scopes
+		// of these tokens cannot be limited to the roles currently
+		// granted by the provider so the lifespan is capped.
+		// 0 = not capped.
+		Unmanaged int
+	}
 }
 
 func (r *Auth) Load() (err error) {
 	var found bool
+	r.Token.Key, found = os.LookupEnv(EnvBuiltinTokenKey)
+	if !found {
+		r.Token.Key = "konveyor"
+	}
 	r.Required = getEnvBool(EnvAuthRequired, false)
 	if !r.Required {
 		return
 	}
+	r.Ownership = getEnvBool(EnvAuthOwnership, false)
+	r.Provider, found = os.LookupEnv(EnvAuthProvider)
+	if !found {
+		r.Provider = AuthKeycloak
+	}
 	r.Keycloak.Host, found = os.LookupEnv(EnvKeycloakHost)
 	if !found {
 		r.Keycloak.Host = "https://localhost:8081"
@@ -82,9 +144,19 @@ func (r *Auth) Load() (err error) {
 		r.Keycloak.Admin.Realm = "master"
 	}
 	r.Keycloak.RequirePasswordUpdate = getEnvBool(EnvKeycloakReqPassUpdate, true)
-	r.Token.Key, found = os.LookupEnv(EnvBuiltinTokenKey)
-	if !found {
-		r.Token.Key = "konveyor"
+	s, found := os.LookupEnv(EnvAPITokenLifespan)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.APIToken.Lifespan = n
+	} else {
+		r.APIToken.Lifespan = 90
+	}
+	s, found = os.LookupEnv(EnvAPITokenUnmanaged)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.APIToken.Unmanaged = n
+	} else {
+		r.APIToken.Unmanaged = 7
 	}
 	r.RolePath, found = os.LookupEnv(EnvRolePath)
 	if !found {
@@ -95,5 +167,29 @@ func (r *Auth) Load() (err error) {
 		r.UserPath = "/tmp/users.yaml"
 
 	}
+	r.OIDC.Issuer = os.Getenv(EnvOIDCIssuer)
+	r.OIDC.ClientID, found = os.LookupEnv(EnvOIDCClientID)
+	if !found {
+		r.OIDC.ClientID = "konveyor"
+	}
+	r.OIDC.ClientSecret = os.Getenv(EnvOIDCClientSecret)
+	r.OIDC.Audience = os.Getenv(EnvOIDCAudience)
+	r.OIDC.UserClaim, found = os.LookupEnv(EnvOIDCUserClaim)
+	if !found {
+		r.OIDC.UserClaim = "preferred_username"
+	}
+	r.OIDC.RoleClaim, found = os.LookupEnv(EnvOIDCRoleClaim)
+	if !found {
+		r.OIDC.RoleClaim = "groups"
+	}
+	r.OIDC.RoleMap = os.Getenv(EnvOIDCRoleMap)
+	r.OIDC.ScopeClaim = getEnvBool(EnvOIDCScopeClaim, false)
+	s, found = os.LookupEnv(EnvOIDCKeyTTL)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.OIDC.KeyTTL = n
+	} else {
+		r.OIDC.KeyTTL = 60
+	}
 	return
 }
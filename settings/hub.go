@@ -3,6 +3,7 @@ package settings
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -13,7 +14,10 @@ const (
 	EnvRwxSupported       = "RWX_SUPPORTED"
 	EnvCachePath          = "CACHE_PATH"
 	EnvCachePvc           = "CACHE_PVC"
+	EnvCacheCapacity      = "CACHE_CAPACITY"
 	EnvPassphrase         = "ENCRYPTION_PASSPHRASE"
+	EnvPassphrasePrevious = "ENCRYPTION_PASSPHRASE_PREVIOUS"
+	EnvEncryptionRotate   = "ENCRYPTION_ROTATE"
 	EnvTaskReapCreated    = "TASK_REAP_CREATED"
 	EnvTaskReapSucceeded  = "TASK_REAP_SUCCEEDED"
 	EnvTaskReapFailed     = "TASK_REAP_FAILED"
@@ -23,10 +27,50 @@ const (
 	EnvFrequencyReaper    = "FREQUENCY_REAPER"
 	EnvDevelopment        = "DEVELOPMENT"
 	EnvBucketTTL          = "BUCKET_TTL"
+	EnvBucketSnapshotTTL  = "BUCKET_SNAPSHOT_TTL"
+	EnvBucketSnapshot     = "BUCKET_SNAPSHOT"
 	EnvFileTTL            = "FILE_TTL"
 	EnvAppName            = "APP_NAME"
 	EnvDisconnected       = "DISCONNECTED"
 	EnvAnalysisReportPath = "ANALYSIS_REPORT_PATH"
+	EnvAdvisoryPath       = "ADVISORY_PATH"
+	EnvFrequencyAdvisory  = "FREQUENCY_ADVISORY"
+	EnvAuditRetention     = "AUDIT_RETENTION"
+	EnvReaperHistory      = "REAPER_HISTORY"
+	EnvSecretBackend      = "SECRET_BACKEND"
+	EnvSecretPath         = "SECRET_PATH"
+	EnvVaultURL           = "VAULT_URL"
+	EnvVaultToken         = "VAULT_TOKEN"
+	EnvVaultMount         = "VAULT_MOUNT"
+	EnvRateLimit          = "RATE_LIMIT"
+	EnvRateLimitBurst     = "RATE_LIMIT_BURST"
+	EnvRateLimitGroups    = "RATE_LIMIT_GROUPS"
+	EnvStorageBackend     = "STORAGE_BACKEND"
+	EnvS3Endpoint         = "S3_ENDPOINT"
+	EnvS3Region           = "S3_REGION"
+	EnvS3Bucket           = "S3_BUCKET"
+	EnvS3AccessKey        = "S3_ACCESS_KEY"
+	EnvS3SecretKey        = "S3_SECRET_KEY"
+	EnvS3PartSize         = "S3_PART_SIZE"
+	EnvQuotaBucket        = "QUOTA_BUCKET"
+	EnvQuotaTotal         = "QUOTA_TOTAL"
+	EnvQuotaPressure      = "QUOTA_PRESSURE"
+)
+
+//
+// Secret backends.
+const (
+	SecretDatabase   = "database"
+	SecretKubernetes = "kubernetes"
+	SecretVault      = "vault"
+	SecretFile       = "file"
+)
+
+//
+// Storage (bucket and file) backends.
+const (
+	StorageFilesystem = "filesystem"
+	StorageS3         = "s3"
 )
 
 type Hub struct {
@@ -41,6 +85,12 @@ type Hub struct {
 	Bucket struct {
 		Path string
 		TTL  int
+		// SnapshotTTL (minutes) for snapshots
+		// not referenced by analyses.
+		SnapshotTTL int
+		// Snapshot (automatically) before a bucket
+		// is first written by a task.
+		Snapshot bool
 	}
 	// File settings.
 	File struct {
@@ -51,10 +101,57 @@ type Hub struct {
 		RWX  bool
 		Path string
 		PVC  string
+		// Capacity (MiB) of the (managed) cache volumes.
+		// Least recently used volumes are evicted when
+		// exceeded. Zero (0) = unlimited.
+		Capacity int
 	}
 	// Encryption settings.
 	Encryption struct {
 		Passphrase string
+		// Previous passphrases (newest first).
+		// Used only to decrypt.
+		Previous []string
+		// Rotate (re-encrypt) using the current
+		// passphrase on startup.
+		Rotate bool
+		// Salt (installation) used to derive key IDs.
+		// Generated and stored in the DB.
+		Salt []byte
+	}
+	// Storage (bucket and file content) settings.
+	Storage struct {
+		Backend string
+		// S3 (compatible) object storage.
+		S3 struct {
+			Endpoint  string
+			Region    string
+			Bucket    string
+			AccessKey string
+			SecretKey string
+			PartSize  int // MiB.
+		}
+	}
+	// Quota (storage) settings.
+	Quota struct {
+		// Bucket quota (MiB). Zero (0) = unlimited.
+		Bucket int
+		// Total (buckets and files) quota (MiB).
+		// Zero (0) = unlimited.
+		Total int
+		// Pressure (percent of total) at which orphaned
+		// content is evicted.
+		Pressure int
+	}
+	// Secret (identity credentials) storage.
+	Secret struct {
+		Backend string
+		Path    string
+		Vault   struct {
+			URL   string
+			Token string
+			Mount string
+		}
 	}
 	// Task
 	Task struct {
@@ -68,9 +165,10 @@ type Hub struct {
 	}
 	// Frequency
 	Frequency struct {
-		Task   int
-		Reaper int
-		Volume int
+		Task     int
+		Reaper   int
+		Volume   int
+		Advisory int
 	}
 	// Development environment
 	Development bool
@@ -82,6 +180,29 @@ type Hub struct {
 	Analysis struct {
 		ReportPath string
 	}
+	// Advisory (vulnerability) feed settings.
+	Advisory struct {
+		Path string
+	}
+	// Audit log settings.
+	Audit struct {
+		Retention int // days.
+	}
+	// Reaper settings.
+	Reaper struct {
+		History int // days.
+	}
+	// RateLimit (per user) settings.
+	RateLimit struct {
+		// Rate (sustained) requests per second.
+		// Zero (0) = unlimited.
+		Rate float64
+		// Burst requests.
+		Burst int
+		// Groups (route) limits.
+		// Format: <route>=<rate>:<burst>[,<route>=<rate>:<burst>].
+		Groups string
+	}
 }
 
 func (r *Hub) Load() (err error) {
@@ -115,10 +236,78 @@ func (r *Hub) Load() (err error) {
 	if !found {
 		r.Cache.Path = "/cache"
 	}
+	s, found = os.LookupEnv(EnvCacheCapacity)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.Cache.Capacity = n
+	}
 	r.Encryption.Passphrase, found = os.LookupEnv(EnvPassphrase)
 	if !found {
 		r.Encryption.Passphrase = "tackle"
 	}
+	s, found = os.LookupEnv(EnvPassphrasePrevious)
+	if found {
+		for _, passphrase := range strings.Split(s, ",") {
+			if passphrase != "" {
+				r.Encryption.Previous = append(r.Encryption.Previous, passphrase)
+			}
+		}
+	}
+	s, found = os.LookupEnv(EnvEncryptionRotate)
+	if found {
+		b, _ := strconv.ParseBool(s)
+		r.Encryption.Rotate = b
+	}
+	r.Storage.Backend, found = os.LookupEnv(EnvStorageBackend)
+	if !found {
+		r.Storage.Backend = StorageFilesystem
+	}
+	r.Storage.S3.Endpoint, _ = os.LookupEnv(EnvS3Endpoint)
+	r.Storage.S3.Region, found = os.LookupEnv(EnvS3Region)
+	if !found {
+		r.Storage.S3.Region = "us-east-1"
+	}
+	r.Storage.S3.Bucket, _ = os.LookupEnv(EnvS3Bucket)
+	r.Storage.S3.AccessKey, _ = os.LookupEnv(EnvS3AccessKey)
+	r.Storage.S3.SecretKey, _ = os.LookupEnv(EnvS3SecretKey)
+	s, found = os.LookupEnv(EnvS3PartSize)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.Storage.S3.PartSize = n
+	} else {
+		r.Storage.S3.PartSize = 16
+	}
+	s, found = os.LookupEnv(EnvQuotaBucket)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.Quota.Bucket = n
+	}
+	s, found = os.LookupEnv(EnvQuotaTotal)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.Quota.Total = n
+	}
+	s, found = os.LookupEnv(EnvQuotaPressure)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.Quota.Pressure = n
+	} else {
+		r.Quota.Pressure = 90
+	}
+	r.Secret.Backend, found = os.LookupEnv(EnvSecretBackend)
+	if !found {
+		r.Secret.Backend = SecretDatabase
+	}
+	r.Secret.Path, found = os.LookupEnv(EnvSecretPath)
+	if !found {
+		r.Secret.Path = "/tmp/secret"
+	}
+	r.Secret.Vault.URL, _ = os.LookupEnv(EnvVaultURL)
+	r.Secret.Vault.Token, _ = os.LookupEnv(EnvVaultToken)
+	r.Secret.Vault.Mount, found = os.LookupEnv(EnvVaultMount)
+	if !found {
+		r.Secret.Vault.Mount = "secret"
+	}
 	s, found = os.LookupEnv(EnvTaskReapCreated)
 	if found {
 		n, _ := strconv.Atoi(s)
@@ -165,6 +354,13 @@ func (r *Hub) Load() (err error) {
 	} else {
 		r.Frequency.Reaper = 1 // 1 minute.
 	}
+	s, found = os.LookupEnv(EnvFrequencyAdvisory)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.Frequency.Advisory = n
+	} else {
+		r.Frequency.Advisory = 10 // 10 minutes.
+	}
 	s, found = os.LookupEnv(EnvDevelopment)
 	if found {
 		b, _ := strconv.ParseBool(s)
@@ -177,6 +373,18 @@ func (r *Hub) Load() (err error) {
 	} else {
 		r.Bucket.TTL = 1 // minutes.
 	}
+	s, found = os.LookupEnv(EnvBucketSnapshotTTL)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.Bucket.SnapshotTTL = n
+	} else {
+		r.Bucket.SnapshotTTL = 10080 // minutes: 7 days.
+	}
+	s, found = os.LookupEnv(EnvBucketSnapshot)
+	if found {
+		b, _ := strconv.ParseBool(s)
+		r.Bucket.Snapshot = b
+	}
 	s, found = os.LookupEnv(EnvFileTTL)
 	if found {
 		n, _ := strconv.Atoi(s)
@@ -197,6 +405,37 @@ func (r *Hub) Load() (err error) {
 	if !found {
 		r.Analysis.ReportPath = "/tmp/analysis/report"
 	}
+	r.Advisory.Path, found = os.LookupEnv(EnvAdvisoryPath)
+	if !found {
+		r.Advisory.Path = "/tmp/advisory"
+	}
+	s, found = os.LookupEnv(EnvAuditRetention)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.Audit.Retention = n
+	} else {
+		r.Audit.Retention = 90 // days.
+	}
+	s, found = os.LookupEnv(EnvReaperHistory)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.Reaper.History = n
+	} else {
+		r.Reaper.History = 7 // days.
+	}
+	s, found = os.LookupEnv(EnvRateLimit)
+	if found {
+		n, _ := strconv.ParseFloat(s, 64)
+		r.RateLimit.Rate = n
+	}
+	s, found = os.LookupEnv(EnvRateLimitBurst)
+	if found {
+		n, _ := strconv.Atoi(s)
+		r.RateLimit.Burst = n
+	} else {
+		r.RateLimit.Burst = 20
+	}
+	r.RateLimit.Groups, _ = os.LookupEnv(EnvRateLimitGroups)
 
 	return
 }
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	liberr "github.com/jortel/go-utils/error"
+	"io"
+	"sync"
+	"time"
+)
+
+//
+// DigestCacheSize maximum number of cached digests.
+var DigestCacheSize = 10000
+
+//
+// digests cached by path.
+var digests = struct {
+	sync.Mutex
+	cached map[string]cachedDigest
+}{
+	cached: make(map[string]cachedDigest),
+}
+
+//
+// cachedDigest a digest cached for stored content.
+type cachedDigest struct {
+	size    int64
+	modTime time.Time
+	digest  string
+}
+
+//
+// Digest returns the (SHA-256) digest of stored content.
+// Digests are cached and keyed by path, size and modified time so the
+// content is read only when changed. Not cached when the modified
+// time is not known.
+func Digest(info Info) (digest string, err error) {
+	cacheable := !info.ModTime.IsZero()
+	digests.Lock()
+	cached, found := digests.cached[info.Path]
+	digests.Unlock()
+	if found && cacheable &&
+		cached.size == info.Size &&
+		cached.modTime.Equal(info.ModTime) {
+		digest = cached.digest
+		return
+	}
+	reader, err := Store.Get(info.Path)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	hash := sha256.New()
+	_, err = io.Copy(hash, reader)
+	if err != nil {
+		err = liberr.Wrap(err, "path", info.Path)
+		return
+	}
+	digest = hex.EncodeToString(hash.Sum(nil))
+	if !cacheable {
+		return
+	}
+	digests.Lock()
+	defer digests.Unlock()
+	if len(digests.cached) >= DigestCacheSize {
+		for path := range digests.cached {
+			delete(digests.cached, path)
+			if len(digests.cached) < DigestCacheSize {
+				break
+			}
+		}
+	}
+	digests.cached[info.Path] = cachedDigest{
+		size:    info.Size,
+		modTime: info.ModTime,
+		digest:  digest,
+	}
+	return
+}
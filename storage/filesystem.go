@@ -0,0 +1,162 @@
+package storage
+
+import (
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/nas"
+	"github.com/konveyor/tackle2-hub/settings"
+	"io"
+	"os"
+	pathlib "path"
+	"path/filepath"
+)
+
+//
+// Filesystem backend.
+// Content is stored on the (shared) filesystem.
+type Filesystem struct {
+}
+
+//
+// Kind returns the backend kind.
+func (r *Filesystem) Kind() (kind string) {
+	kind = settings.StorageFilesystem
+	return
+}
+
+//
+// Stat returns info about the file or directory.
+func (r *Filesystem) Stat(path string) (info Info, err error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		err = r.notFound(path, err)
+		return
+	}
+	info = Info{
+		Path:    path,
+		Size:    st.Size(),
+		ModTime: st.ModTime(),
+		Dir:     st.IsDir(),
+	}
+	return
+}
+
+//
+// List the files within the directory (recursive).
+func (r *Filesystem) List(path string) (list []Info, err error) {
+	err = filepath.Walk(
+		path,
+		func(p string, st os.FileInfo, nErr error) (err error) {
+			if nErr != nil {
+				err = nErr
+				return
+			}
+			if !st.Mode().IsRegular() {
+				return
+			}
+			list = append(
+				list,
+				Info{
+					Path:    p,
+					Size:    st.Size(),
+					ModTime: st.ModTime(),
+				})
+			return
+		})
+	if err != nil {
+		err = r.notFound(path, err)
+	}
+	return
+}
+
+//
+// Get (open) the file.
+func (r *Filesystem) Get(path string) (reader io.ReadCloser, err error) {
+	reader, err = os.Open(path)
+	if err != nil {
+		err = r.notFound(path, err)
+	}
+	return
+}
+
+//
+// GetRange (open) the file and read length bytes
+// starting at the offset.
+func (r *Filesystem) GetRange(path string, offset, length int64) (reader io.ReadCloser, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		err = r.notFound(path, err)
+		return
+	}
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		_ = file.Close()
+		err = liberr.Wrap(err, "path", path)
+		return
+	}
+	reader = &rangeReader{
+		Reader: io.LimitReader(file, length),
+		Closer: file,
+	}
+	return
+}
+
+//
+// Put (create or replace) the file.
+func (r *Filesystem) Put(path string, reader io.Reader, size int64) (err error) {
+	err = os.MkdirAll(pathlib.Dir(path), 0777)
+	if err != nil {
+		err = liberr.Wrap(err, "path", path)
+		return
+	}
+	writer, err := os.Create(path)
+	if err != nil {
+		err = liberr.Wrap(err, "path", path)
+		return
+	}
+	defer func() {
+		_ = writer.Close()
+	}()
+	_, err = io.Copy(writer, reader)
+	if err != nil {
+		err = liberr.Wrap(err, "path", path)
+		return
+	}
+	err = os.Chmod(path, 0666)
+	if err != nil {
+		err = liberr.Wrap(err, "path", path)
+		return
+	}
+	return
+}
+
+//
+// Delete the file or directory (recursive).
+func (r *Filesystem) Delete(path string) (err error) {
+	err = nas.RmDir(path)
+	if err != nil {
+		err = liberr.Wrap(err, "path", path)
+	}
+	return
+}
+
+//
+// MkDir ensures the directory exists.
+func (r *Filesystem) MkDir(path string) (err error) {
+	err = os.MkdirAll(path, 0777)
+	if err != nil {
+		err = liberr.Wrap(err, "path", path)
+	}
+	return
+}
+
+//
+// notFound returns NotFound when the error reports
+// the path does not exist.
+func (r *Filesystem) notFound(path string, err error) (err2 error) {
+	if os.IsNotExist(err) {
+		err2 = &NotFound{Path: path}
+	} else {
+		err2 = liberr.Wrap(err, "path", path)
+	}
+	return
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"sync"
+)
+
+//
+// locks held (or waited on) by key.
+var locks = struct {
+	sync.Mutex
+	held map[string]*keyLock
+}{
+	held: make(map[string]*keyLock),
+}
+
+//
+// keyLock lock for a key.
+type keyLock struct {
+	sync.Mutex
+	// number of holders and waiters.
+	count int
+}
+
+//
+// Lock the (shared) content by key (digest).
+// Serializes storing and deleting content shared by
+// multiple files. Returns the function used to unlock.
+func Lock(key string) (unlock func()) {
+	locks.Lock()
+	lock, found := locks.held[key]
+	if !found {
+		lock = &keyLock{}
+		locks.held[key] = lock
+	}
+	lock.count++
+	locks.Unlock()
+	lock.Lock()
+	unlock = func() {
+		lock.Unlock()
+		locks.Lock()
+		lock.count--
+		if lock.count == 0 {
+			delete(locks.held, key)
+		}
+		locks.Unlock()
+	}
+	return
+}
@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"errors"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/jortel/go-utils/logr"
+	"github.com/konveyor/tackle2-hub/settings"
+	"io"
+	"os"
+	pathlib "path"
+	"strings"
+	"time"
+)
+
+var (
+	Settings = &settings.Settings
+	Log      = logr.WithName("storage")
+)
+
+//
+// Store is the configured backend.
+// Bucket and file content is stored using the backend.
+var Store Backend = &Filesystem{}
+
+//
+// Info describes stored content.
+type Info struct {
+	// Path of the object (file) or directory.
+	Path string
+	// Size (bytes) of the object.
+	Size int64
+	// ModTime last modified.
+	ModTime time.Time
+	// Dir indicates the path is a directory.
+	Dir bool
+}
+
+//
+// Backend stores bucket and file content.
+// Content is addressed by (absolute) path. Directories are
+// implied by the paths of the objects stored within them.
+type Backend interface {
+	// Kind returns the backend kind.
+	Kind() string
+	// Stat returns info about the object or directory.
+	// Returns NotFound when the path does not exist.
+	Stat(path string) (info Info, err error)
+	// List the objects (files) within the directory (recursive).
+	List(path string) (list []Info, err error)
+	// Get (open) the object. The reader must be closed.
+	// Returns NotFound when the object does not exist.
+	Get(path string) (reader io.ReadCloser, err error)
+	// GetRange (open) the object and read length bytes
+	// starting at the offset. The reader must be closed.
+	// Returns NotFound when the object does not exist.
+	GetRange(path string, offset, length int64) (reader io.ReadCloser, err error)
+	// Put (create or replace) the object.
+	// The size is -1 when not known.
+	Put(path string, reader io.Reader, size int64) (err error)
+	// Delete the object or directory (recursive).
+	// Deleting a path that does not exist is not an error.
+	Delete(path string) (err error)
+	// MkDir ensures the directory exists.
+	MkDir(path string) (err error)
+}
+
+//
+// New returns the backend selected by settings.
+func New() (backend Backend, err error) {
+	switch Settings.Storage.Backend {
+	case settings.StorageFilesystem, "":
+		backend = &Filesystem{}
+	case settings.StorageS3:
+		s3 := &S3{
+			Endpoint:  Settings.Storage.S3.Endpoint,
+			Region:    Settings.Storage.S3.Region,
+			Bucket:    Settings.Storage.S3.Bucket,
+			AccessKey: Settings.Storage.S3.AccessKey,
+			SecretKey: Settings.Storage.S3.SecretKey,
+			PartSize:  int64(Settings.Storage.S3.PartSize) * MiB,
+		}
+		err = s3.Validate()
+		if err != nil {
+			return
+		}
+		backend = s3
+	default:
+		err = liberr.New("Storage backend: " + Settings.Storage.Backend + " not supported.")
+	}
+	return
+}
+
+//
+// NotFound reports content not found.
+// Matches os.ErrNotExist.
+type NotFound struct {
+	Path string
+}
+
+func (e *NotFound) Error() string {
+	return "Storage: " + e.Path + " not found."
+}
+
+func (e *NotFound) Is(err error) (matched bool) {
+	_, matched = err.(*NotFound)
+	matched = matched || err == os.ErrNotExist
+	return
+}
+
+//
+// ReadFile returns the content of the stored object.
+func ReadFile(path string) (content []byte, err error) {
+	reader, err := Store.Get(path)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	content, err = io.ReadAll(reader)
+	if err != nil {
+		err = liberr.Wrap(err, "path", path)
+	}
+	return
+}
+
+//
+// rangeReader reads a range of the content.
+type rangeReader struct {
+	io.Reader
+	io.Closer
+}
+
+//
+// Copy the content of the source directory to
+// the destination directory.
+func Copy(source, destination string) (err error) {
+	list, err := Store.List(source)
+	if err != nil {
+		if errors.Is(err, &NotFound{}) {
+			err = nil
+		}
+		return
+	}
+	err = Store.MkDir(destination)
+	if err != nil {
+		return
+	}
+	for _, info := range list {
+		err = copyObject(
+			info,
+			pathlib.Join(destination, strings.TrimPrefix(info.Path, source)))
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+//
+// copyObject copies the object.
+func copyObject(info Info, destination string) (err error) {
+	reader, err := Store.Get(info.Path)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	err = Store.Put(destination, reader, info.Size)
+	return
+}
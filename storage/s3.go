@@ -0,0 +1,660 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/settings"
+	"io"
+	"net/http"
+	"net/url"
+	pathlib "path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//
+// Sizes.
+const (
+	MiB = int64(1024 * 1024)
+	// MinPartSize (multipart) required by S3.
+	MinPartSize = 5 * MiB
+)
+
+//
+// S3 signing.
+const (
+	s3Algorithm   = "AWS4-HMAC-SHA256"
+	s3Service     = "s3"
+	s3Unsigned    = "UNSIGNED-PAYLOAD"
+	s3DateFormat  = "20060102T150405Z"
+	s3ShortFormat = "20060102"
+)
+
+//
+// S3 backend.
+// Content is stored in an S3 (compatible) object store.
+// Paths are mapped to object keys. Requests use path-style
+// addressing and are signed (V4). Objects larger than the
+// part size are uploaded using multipart uploads.
+type S3 struct {
+	// Endpoint URL.
+	Endpoint string
+	// Region used to sign requests.
+	Region string
+	// Bucket name.
+	Bucket string
+	// AccessKey (ID).
+	AccessKey string
+	// SecretKey.
+	SecretKey string
+	// PartSize (bytes) for multipart uploads.
+	PartSize int64
+	// client
+	client *http.Client
+}
+
+//
+// Kind returns the backend kind.
+func (r *S3) Kind() (kind string) {
+	kind = settings.StorageS3
+	return
+}
+
+//
+// Validate the settings.
+func (r *S3) Validate() (err error) {
+	if r.Endpoint == "" || r.Bucket == "" {
+		err = liberr.New("S3: endpoint and bucket required.")
+		return
+	}
+	u, pErr := url.Parse(r.Endpoint)
+	if pErr != nil || u.Host == "" {
+		err = liberr.New("S3: endpoint: " + r.Endpoint + " not valid.")
+		return
+	}
+	if r.PartSize < MinPartSize {
+		r.PartSize = MinPartSize
+	}
+	return
+}
+
+//
+// Stat returns info about the object or directory.
+// The path is a directory when objects are stored within it.
+func (r *S3) Stat(path string) (info Info, err error) {
+	response, err := r.send(http.MethodHead, r.key(path), nil, nil, -1)
+	if err != nil {
+		return
+	}
+	_ = response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusOK:
+		info = Info{
+			Path: path,
+			Size: response.ContentLength,
+		}
+		info.ModTime, _ = http.ParseTime(response.Header.Get("Last-Modified"))
+		return
+	case http.StatusNotFound:
+	default:
+		err = r.failed(response)
+		return
+	}
+	result, err := r.list(r.prefix(path), "", 1)
+	if err != nil {
+		return
+	}
+	if len(result.Contents) > 0 {
+		info = Info{
+			Path: path,
+			Dir:  true,
+		}
+	} else {
+		err = &NotFound{Path: path}
+	}
+	return
+}
+
+//
+// List the objects within the directory (recursive).
+func (r *S3) List(path string) (list []Info, err error) {
+	prefix := r.prefix(path)
+	token := ""
+	for {
+		var result *s3List
+		result, err = r.list(prefix, token, 1000)
+		if err != nil {
+			return
+		}
+		for _, object := range result.Contents {
+			list = append(
+				list,
+				Info{
+					Path:    pathlib.Join(path, strings.TrimPrefix(object.Key, prefix)),
+					Size:    object.Size,
+					ModTime: object.LastModified,
+				})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+	return
+}
+
+//
+// Get (open) the object.
+func (r *S3) Get(path string) (reader io.ReadCloser, err error) {
+	response, err := r.send(http.MethodGet, r.key(path), nil, nil, -1)
+	if err != nil {
+		return
+	}
+	switch response.StatusCode {
+	case http.StatusOK:
+		reader = response.Body
+	case http.StatusNotFound:
+		_ = response.Body.Close()
+		err = &NotFound{Path: path}
+	default:
+		err = r.failed(response)
+		_ = response.Body.Close()
+	}
+	return
+}
+
+//
+// GetRange (open) the object and read length bytes
+// starting at the offset.
+func (r *S3) GetRange(path string, offset, length int64) (reader io.ReadCloser, err error) {
+	request, err := r.request(http.MethodGet, r.key(path), nil, nil, -1)
+	if err != nil {
+		return
+	}
+	request.Header.Set(
+		"Range",
+		"bytes="+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(offset+length-1, 10))
+	response, err := r.do(request)
+	if err != nil {
+		return
+	}
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		reader = response.Body
+	case http.StatusOK:
+		_, err = io.CopyN(io.Discard, response.Body, offset)
+		if err != nil {
+			_ = response.Body.Close()
+			err = liberr.Wrap(err, "path", path)
+			return
+		}
+		reader = &rangeReader{
+			Reader: io.LimitReader(response.Body, length),
+			Closer: response.Body,
+		}
+	case http.StatusNotFound:
+		_ = response.Body.Close()
+		err = &NotFound{Path: path}
+	default:
+		err = r.failed(response)
+		_ = response.Body.Close()
+	}
+	return
+}
+
+//
+// Put (create or replace) the object.
+// Objects (possibly) larger than the part size are
+// uploaded using a multipart upload.
+func (r *S3) Put(path string, reader io.Reader, size int64) (err error) {
+	key := r.key(path)
+	if size >= 0 && size <= r.PartSize {
+		err = r.put(key, nil, reader, size)
+		return
+	}
+	part := make([]byte, r.PartSize)
+	n, err := io.ReadFull(reader, part)
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		err = r.put(key, nil, bytes.NewReader(part[:n]), int64(n))
+		return
+	default:
+		err = liberr.Wrap(err)
+		return
+	}
+	upload, err := r.createUpload(key)
+	if err != nil {
+		return
+	}
+	err = r.uploadParts(key, upload, part, reader)
+	if err != nil {
+		r.abortUpload(key, upload)
+	}
+	return
+}
+
+//
+// Delete the object or directory (recursive).
+func (r *S3) Delete(path string) (err error) {
+	err = r.delete(r.key(path), nil)
+	if err != nil {
+		return
+	}
+	list, err := r.List(path)
+	if err != nil {
+		return
+	}
+	for _, info := range list {
+		err = r.delete(r.key(info.Path), nil)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+//
+// MkDir ensures the directory exists.
+// Directories are implied by object keys.
+func (r *S3) MkDir(path string) (err error) {
+	return
+}
+
+//
+// put an object (or part).
+func (r *S3) put(key string, query url.Values, reader io.Reader, size int64) (err error) {
+	response, err := r.send(http.MethodPut, key, query, reader, size)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode != http.StatusOK {
+		err = r.failed(response)
+	}
+	return
+}
+
+//
+// createUpload creates a multipart upload.
+// Returns the upload ID.
+func (r *S3) createUpload(key string) (upload string, err error) {
+	query := url.Values{"uploads": []string{""}}
+	response, err := r.send(http.MethodPost, key, query, nil, 0)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode != http.StatusOK {
+		err = r.failed(response)
+		return
+	}
+	result := struct {
+		UploadId string
+	}{}
+	err = xml.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	upload = result.UploadId
+	return
+}
+
+//
+// uploadParts uploads the parts and completes the upload.
+// The first part has already been read.
+func (r *S3) uploadParts(key, upload string, part []byte, reader io.Reader) (err error) {
+	complete := s3Complete{}
+	n := len(part)
+	for number := 1; ; number++ {
+		query := url.Values{
+			"partNumber": []string{strconv.Itoa(number)},
+			"uploadId":   []string{upload},
+		}
+		var response *http.Response
+		response, err = r.send(http.MethodPut, key, query, bytes.NewReader(part[:n]), int64(n))
+		if err != nil {
+			return
+		}
+		_ = response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			err = r.failed(response)
+			return
+		}
+		complete.Parts = append(
+			complete.Parts,
+			s3Part{
+				PartNumber: number,
+				ETag:       response.Header.Get("ETag"),
+			})
+		n, err = io.ReadFull(reader, part)
+		if err == io.EOF {
+			err = nil
+			break
+		}
+		if err == io.ErrUnexpectedEOF {
+			err = nil
+			continue
+		}
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+	}
+	err = r.completeUpload(key, upload, &complete)
+	return
+}
+
+//
+// completeUpload completes the multipart upload.
+func (r *S3) completeUpload(key, upload string, complete *s3Complete) (err error) {
+	body, err := xml.Marshal(complete)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	query := url.Values{"uploadId": []string{upload}}
+	response, err := r.send(http.MethodPost, key, query, bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode != http.StatusOK {
+		err = r.failed(response)
+		return
+	}
+	// The error may be reported after the 200 (OK).
+	content, _ := io.ReadAll(response.Body)
+	if bytes.Contains(content, []byte("<Error>")) {
+		err = liberr.New("S3: upload failed: " + string(content))
+	}
+	return
+}
+
+//
+// abortUpload aborts the multipart upload.
+func (r *S3) abortUpload(key, upload string) {
+	err := r.delete(key, url.Values{"uploadId": []string{upload}})
+	if err != nil {
+		Log.Error(err, "S3: abort upload failed.", "key", key)
+	}
+}
+
+//
+// delete an object.
+func (r *S3) delete(key string, query url.Values) (err error) {
+	response, err := r.send(http.MethodDelete, key, query, nil, -1)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	switch response.StatusCode {
+	case http.StatusOK,
+		http.StatusNoContent,
+		http.StatusNotFound:
+	default:
+		err = r.failed(response)
+	}
+	return
+}
+
+//
+// list objects (page) by prefix.
+func (r *S3) list(prefix, token string, max int) (result *s3List, err error) {
+	query := url.Values{
+		"list-type": []string{"2"},
+		"prefix":    []string{prefix},
+		"max-keys":  []string{strconv.Itoa(max)},
+	}
+	if token != "" {
+		query.Set("continuation-token", token)
+	}
+	response, err := r.send(http.MethodGet, "", query, nil, -1)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode != http.StatusOK {
+		err = r.failed(response)
+		return
+	}
+	result = &s3List{}
+	err = xml.NewDecoder(response.Body).Decode(result)
+	if err != nil {
+		err = liberr.Wrap(err)
+	}
+	return
+}
+
+//
+// send a (signed) request.
+func (r *S3) send(method, key string, query url.Values, body io.Reader, size int64) (response *http.Response, err error) {
+	request, err := r.request(method, key, query, body, size)
+	if err != nil {
+		return
+	}
+	response, err = r.do(request)
+	return
+}
+
+//
+// request builds a request.
+func (r *S3) request(method, key string, query url.Values, body io.Reader, size int64) (request *http.Request, err error) {
+	u, err := url.Parse(r.Endpoint)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	u.Path = "/" + r.Bucket
+	u.RawPath = "/" + s3Encode(r.Bucket, false)
+	if key != "" {
+		u.Path += "/" + key
+		u.RawPath += "/" + s3Encode(key, false)
+	}
+	u.RawQuery = s3Query(query)
+	request, err = http.NewRequest(method, u.String(), body)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	if size >= 0 {
+		request.ContentLength = size
+		if size == 0 {
+			request.Body = http.NoBody
+		}
+	}
+	return
+}
+
+//
+// do signs and sends the request.
+func (r *S3) do(request *http.Request) (response *http.Response, err error) {
+	if r.client == nil {
+		r.client = &http.Client{}
+	}
+	r.sign(request, time.Now().UTC())
+	response, err = r.client.Do(request)
+	if err != nil {
+		err = liberr.Wrap(err)
+	}
+	return
+}
+
+//
+// sign the request (V4).
+// The payload is not signed.
+func (r *S3) sign(request *http.Request, now time.Time) {
+	date := now.Format(s3DateFormat)
+	request.Header.Set("X-Amz-Date", date)
+	request.Header.Set("X-Amz-Content-Sha256", s3Unsigned)
+	signed := "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join(
+		[]string{
+			request.Method,
+			request.URL.EscapedPath(),
+			request.URL.RawQuery,
+			"host:" + request.URL.Host,
+			"x-amz-content-sha256:" + s3Unsigned,
+			"x-amz-date:" + date,
+			"",
+			signed,
+			s3Unsigned,
+		},
+		"\n")
+	scope := strings.Join(
+		[]string{
+			now.Format(s3ShortFormat),
+			r.Region,
+			s3Service,
+			"aws4_request",
+		},
+		"/")
+	digest := sha256.Sum256([]byte(canonical))
+	toSign := strings.Join(
+		[]string{
+			s3Algorithm,
+			date,
+			scope,
+			hex.EncodeToString(digest[:]),
+		},
+		"\n")
+	key := []byte("AWS4" + r.SecretKey)
+	for _, part := range strings.Split(scope, "/") {
+		key = s3Hmac(key, part)
+	}
+	signature := hex.EncodeToString(s3Hmac(key, toSign))
+	request.Header.Set(
+		"Authorization",
+		s3Algorithm+
+			" Credential="+r.AccessKey+"/"+scope+
+			", SignedHeaders="+signed+
+			", Signature="+signature)
+}
+
+//
+// failed returns an error for the response.
+func (r *S3) failed(response *http.Response) (err error) {
+	content, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	err = liberr.New(
+		"S3: request failed.",
+		"method",
+		response.Request.Method,
+		"url",
+		response.Request.URL.Path,
+		"status",
+		response.StatusCode,
+		"body",
+		string(content))
+	return
+}
+
+//
+// key returns the object key for the path.
+func (r *S3) key(path string) (key string) {
+	key = strings.TrimPrefix(pathlib.Clean("/"+path), "/")
+	return
+}
+
+//
+// prefix returns the object key prefix for the directory.
+func (r *S3) prefix(path string) (prefix string) {
+	prefix = r.key(path)
+	if prefix != "" {
+		prefix += "/"
+	}
+	return
+}
+
+//
+// s3List list objects (V2) result.
+type s3List struct {
+	IsTruncated           bool
+	NextContinuationToken string
+	Contents              []struct {
+		Key          string
+		Size         int64
+		LastModified time.Time
+	}
+}
+
+//
+// s3Part multipart upload part.
+type s3Part struct {
+	PartNumber int
+	ETag       string
+}
+
+//
+// s3Complete multipart upload request.
+type s3Complete struct {
+	XMLName xml.Name `xml:"CompleteMultipartUpload"`
+	Parts   []s3Part `xml:"Part"`
+}
+
+//
+// s3Hmac returns the HMAC-SHA256.
+func s3Hmac(key []byte, s string) (b []byte) {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(s))
+	b = mac.Sum(nil)
+	return
+}
+
+//
+// s3Query returns the canonical (sorted and encoded) query.
+func s3Query(query url.Values) (s string) {
+	var keys []string
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, s3Encode(k, true)+"="+s3Encode(v, true))
+		}
+	}
+	s = strings.Join(parts, "&")
+	return
+}
+
+//
+// s3Encode (URI) encodes the string as required by S3.
+// Only unreserved characters are not encoded.
+func s3Encode(s string, slash bool) (encoded string) {
+	b := strings.Builder{}
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z',
+			'a' <= c && c <= 'z',
+			'0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !slash:
+			b.WriteByte(c)
+		default:
+			b.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+		}
+	}
+	encoded = b.String()
+	return
+}
@@ -0,0 +1,357 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"github.com/onsi/gomega"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	pathlib "path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFilesystem(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	root := t.TempDir()
+	testBackend(g, &Filesystem{}, root)
+}
+
+func TestDigest(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	path := pathlib.Join(t.TempDir(), "a.txt")
+	err := os.WriteFile(path, []byte("a"), 0666)
+	g.Expect(err).To(gomega.BeNil())
+	info, err := Store.Stat(path)
+	g.Expect(err).To(gomega.BeNil())
+	digest, err := Digest(info)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(digest).To(gomega.Equal("ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"))
+	//
+	// Cached (content not read).
+	err = os.Remove(path)
+	g.Expect(err).To(gomega.BeNil())
+	cached, err := Digest(info)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cached).To(gomega.Equal(digest))
+	//
+	// Modified.
+	info.ModTime = info.ModTime.Add(time.Second)
+	_, err = Digest(info)
+	g.Expect(errors.Is(err, &NotFound{})).To(gomega.BeTrue())
+}
+
+func TestS3(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	fake := &FakeS3{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	s3 := &S3{
+		Endpoint:  server.URL,
+		Region:    "us-east-1",
+		Bucket:    "hub",
+		AccessKey: "access",
+		SecretKey: "secret",
+	}
+	g.Expect(s3.Validate()).To(gomega.BeNil())
+	g.Expect(s3.PartSize).To(gomega.Equal(MinPartSize))
+	testBackend(g, s3, "/tmp/bucket")
+	// multipart (size known).
+	content := bytes.Repeat([]byte("0123456789"), int(MinPartSize)/4)
+	path := "/tmp/bucket/large"
+	err := s3.Put(path, bytes.NewReader(content), int64(len(content)))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(fake.completed).To(gomega.Equal(1))
+	g.Expect(fake.parts).To(gomega.Equal(3))
+	read, err := readFile(s3, path)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(bytes.Equal(read, content)).To(gomega.BeTrue())
+	// multipart (size not known).
+	err = s3.Put(path, io.MultiReader(bytes.NewReader(content)), -1)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(fake.completed).To(gomega.Equal(2))
+	// small (size not known).
+	err = s3.Put(path, strings.NewReader("small"), -1)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(fake.completed).To(gomega.Equal(2))
+	read, _ = readFile(s3, path)
+	g.Expect(string(read)).To(gomega.Equal("small"))
+	// aborted.
+	fake.failPart = true
+	err = s3.Put(path, bytes.NewReader(content), int64(len(content)))
+	g.Expect(err).ToNot(gomega.BeNil())
+	g.Expect(fake.uploads).To(gomega.BeEmpty())
+	// paged list.
+	fake.failPart = false
+	for i := 0; i < 5; i++ {
+		err = s3.Put("/tmp/paged/"+strconv.Itoa(i), strings.NewReader("x"), 1)
+		g.Expect(err).To(gomega.BeNil())
+	}
+	fake.pageSize = 2
+	list, err := s3.List("/tmp/paged")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(list)).To(gomega.Equal(5))
+	g.Expect(list[4].Path).To(gomega.Equal("/tmp/paged/4"))
+	// not authorized.
+	s3.AccessKey = ""
+	_, err = s3.Stat(path)
+	g.Expect(err).ToNot(gomega.BeNil())
+}
+
+func TestS3Encode(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(s3Encode("a b/c+d~e", false)).To(gomega.Equal("a%20b/c%2Bd~e"))
+	g.Expect(s3Encode("a/b", true)).To(gomega.Equal("a%2Fb"))
+	q := s3Query(map[string][]string{"uploads": {""}, "b": {"2"}, "a": {"x y"}})
+	g.Expect(q).To(gomega.Equal("a=x%20y&b=2&uploads="))
+}
+
+//
+// testBackend tests the backend.
+func testBackend(g *gomega.WithT, backend Backend, root string) {
+	dir := pathlib.Join(root, "bucket")
+	err := backend.MkDir(dir)
+	g.Expect(err).To(gomega.BeNil())
+	for _, p := range []string{"a.txt", "sub/b.txt", "sub/deep/c.txt"} {
+		content := "content:" + p
+		err = backend.Put(
+			pathlib.Join(dir, p),
+			strings.NewReader(content),
+			int64(len(content)))
+		g.Expect(err).To(gomega.BeNil())
+	}
+	// stat.
+	info, err := backend.Stat(pathlib.Join(dir, "a.txt"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(info.Dir).To(gomega.BeFalse())
+	g.Expect(info.Size).To(gomega.Equal(int64(len("content:a.txt"))))
+	info, err = backend.Stat(pathlib.Join(dir, "sub"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(info.Dir).To(gomega.BeTrue())
+	_, err = backend.Stat(pathlib.Join(dir, "none"))
+	g.Expect(errors.Is(err, &NotFound{})).To(gomega.BeTrue())
+	g.Expect(errors.Is(err, os.ErrNotExist)).To(gomega.BeTrue())
+	// list.
+	list, err := backend.List(dir)
+	g.Expect(err).To(gomega.BeNil())
+	var paths []string
+	for _, info := range list {
+		paths = append(paths, info.Path)
+	}
+	sort.Strings(paths)
+	g.Expect(paths).To(gomega.Equal(
+		[]string{
+			pathlib.Join(dir, "a.txt"),
+			pathlib.Join(dir, "sub/b.txt"),
+			pathlib.Join(dir, "sub/deep/c.txt"),
+		}))
+	// get.
+	content, err := readFile(backend, pathlib.Join(dir, "sub/b.txt"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(string(content)).To(gomega.Equal("content:sub/b.txt"))
+	_, err = backend.Get(pathlib.Join(dir, "none"))
+	g.Expect(errors.Is(err, &NotFound{})).To(gomega.BeTrue())
+	// get range.
+	reader, err := backend.GetRange(pathlib.Join(dir, "sub/b.txt"), 8, 3)
+	g.Expect(err).To(gomega.BeNil())
+	content, _ = io.ReadAll(reader)
+	_ = reader.Close()
+	g.Expect(string(content)).To(gomega.Equal("sub"))
+	_, err = backend.GetRange(pathlib.Join(dir, "none"), 0, 1)
+	g.Expect(errors.Is(err, &NotFound{})).To(gomega.BeTrue())
+	// copy.
+	copied := pathlib.Join(root, "copy")
+	saved := Store
+	Store = backend
+	err = Copy(pathlib.Join(dir, "sub"), copied)
+	Store = saved
+	g.Expect(err).To(gomega.BeNil())
+	content, _ = readFile(backend, pathlib.Join(copied, "deep/c.txt"))
+	g.Expect(string(content)).To(gomega.Equal("content:sub/deep/c.txt"))
+	// replace.
+	err = backend.Put(pathlib.Join(dir, "a.txt"), strings.NewReader("new"), -1)
+	g.Expect(err).To(gomega.BeNil())
+	content, _ = readFile(backend, pathlib.Join(dir, "a.txt"))
+	g.Expect(string(content)).To(gomega.Equal("new"))
+	// delete.
+	err = backend.Delete(pathlib.Join(dir, "sub"))
+	g.Expect(err).To(gomega.BeNil())
+	_, err = backend.Stat(pathlib.Join(dir, "sub/deep/c.txt"))
+	g.Expect(errors.Is(err, &NotFound{})).To(gomega.BeTrue())
+	list, _ = backend.List(dir)
+	g.Expect(len(list)).To(gomega.Equal(1))
+	err = backend.Delete(pathlib.Join(dir, "none"))
+	g.Expect(err).To(gomega.BeNil())
+}
+
+//
+// readFile reads the object using the backend.
+func readFile(backend Backend, path string) (content []byte, err error) {
+	saved := Store
+	Store = backend
+	defer func() {
+		Store = saved
+	}()
+	content, err = ReadFile(path)
+	return
+}
+
+//
+// FakeS3 is an (in-memory) S3 server.
+type FakeS3 struct {
+	objects   map[string][]byte
+	uploads   map[string]map[int][]byte
+	parts     int
+	completed int
+	pageSize  int
+	failPart  bool
+	mutex     sync.Mutex
+}
+
+func (f *FakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.objects == nil {
+		f.objects = make(map[string][]byte)
+		f.uploads = make(map[string]map[int][]byte)
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, s3Algorithm+" Credential=access/") ||
+		r.Header.Get("X-Amz-Date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	part := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if part[0] != "hub" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	key := ""
+	if len(part) > 1 {
+		key = part[1]
+	}
+	query := r.URL.Query()
+	switch r.Method {
+	case http.MethodGet:
+		if key == "" {
+			f.list(w, r)
+			return
+		}
+		content, found := f.objects[key]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if rng := r.Header.Get("Range"); rng != "" {
+			var begin, end int
+			part := strings.SplitN(strings.TrimPrefix(rng, "bytes="), "-", 2)
+			begin, _ = strconv.Atoi(part[0])
+			end, _ = strconv.Atoi(part[1])
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(content[begin : end+1])
+			return
+		}
+		_, _ = w.Write(content)
+	case http.MethodHead:
+		content, found := f.objects[key]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+	case http.MethodPut:
+		content, _ := io.ReadAll(r.Body)
+		upload := query.Get("uploadId")
+		if upload == "" {
+			f.objects[key] = content
+			return
+		}
+		if f.failPart {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		n, _ := strconv.Atoi(query.Get("partNumber"))
+		f.uploads[upload][n] = content
+		f.parts++
+		w.Header().Set("ETag", "\"etag-"+strconv.Itoa(n)+"\"")
+	case http.MethodPost:
+		if _, found := query["uploads"]; found {
+			upload := "upload-" + strconv.Itoa(len(f.uploads)+f.completed)
+			f.uploads[upload] = make(map[int][]byte)
+			_, _ = w.Write([]byte(
+				"<InitiateMultipartUploadResult><UploadId>" +
+					upload +
+					"</UploadId></InitiateMultipartUploadResult>"))
+			return
+		}
+		upload := query.Get("uploadId")
+		complete := s3Complete{}
+		_ = xml.NewDecoder(r.Body).Decode(&complete)
+		content := []byte{}
+		for _, p := range complete.Parts {
+			content = append(content, f.uploads[upload][p.PartNumber]...)
+		}
+		delete(f.uploads, upload)
+		f.objects[key] = content
+		f.completed++
+		_, _ = w.Write([]byte("<CompleteMultipartUploadResult/>"))
+	case http.MethodDelete:
+		upload := query.Get("uploadId")
+		if upload != "" {
+			delete(f.uploads, upload)
+		} else {
+			delete(f.objects, key)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *FakeS3) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+	max, _ := strconv.Atoi(query.Get("max-keys"))
+	if f.pageSize > 0 && f.pageSize < max {
+		max = f.pageSize
+	}
+	var keys []string
+	for k := range f.objects {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	start, _ := strconv.Atoi(query.Get("continuation-token"))
+	keys = keys[start:]
+	result := s3List{}
+	if len(keys) > max {
+		keys = keys[:max]
+		result.IsTruncated = true
+		result.NextContinuationToken = strconv.Itoa(start + max)
+	}
+	for _, k := range keys {
+		result.Contents = append(
+			result.Contents,
+			struct {
+				Key          string
+				Size         int64
+				LastModified time.Time
+			}{
+				Key:          k,
+				Size:         int64(len(f.objects[k])),
+				LastModified: time.Now(),
+			})
+	}
+	b, _ := xml.Marshal(struct {
+		XMLName xml.Name `xml:"ListBucketResult"`
+		s3List
+	}{s3List: result})
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,51 @@
+package tar
+
+import (
+	"strings"
+)
+
+//
+// Compression formats.
+const (
+	Gzip = "gzip"
+	Zstd = "zstd"
+)
+
+//
+// Supported compression formats in order of preference.
+const Supported = Zstd + "," + Gzip
+
+//
+// zstdMagic zstd frame magic number.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+//
+// Negotiate the compression.
+// The accepted is a (comma separated) list of compression
+// formats in order of preference. Returns the first supported
+// format. Default: gzip.
+func Negotiate(accepted string) (compression string) {
+	compression = Gzip
+	for _, s := range strings.Split(accepted, ",") {
+		s = strings.ToLower(strings.TrimSpace(s))
+		switch s {
+		case Gzip, Zstd:
+			compression = s
+			return
+		}
+	}
+	return
+}
+
+//
+// Extension returns the (archive) file extension
+// for the compression.
+func Extension(compression string) (ext string) {
+	switch compression {
+	case Zstd:
+		ext = ".tar.zst"
+	default:
+		ext = ".tar.gz"
+	}
+	return
+}
@@ -2,8 +2,11 @@ package tar
 
 import (
 	"archive/tar"
+	"bufio"
+	"bytes"
 	"compress/gzip"
 	liberr "github.com/jortel/go-utils/error"
+	"github.com/klauspost/compress/zstd"
 	"github.com/konveyor/tackle2-hub/nas"
 	"io"
 	"os"
@@ -26,9 +29,8 @@ type Reader struct {
 //
 // Extract archive content to the destination path.
 func (r *Reader) Extract(outDir string, reader io.Reader) (err error) {
-	zipReader, err := gzip.NewReader(reader)
+	zipReader, err := r.decompressor(reader)
 	if err != nil {
-		err = liberr.Wrap(err)
 		return
 	}
 	defer func() {
@@ -77,3 +79,64 @@ func (r *Reader) Extract(outDir string, reader io.Reader) (err error) {
 	}
 	return
 }
+
+//
+// Walk the archive. The function is called for each regular
+// file with the (relative) path, size and content.
+func (r *Reader) Walk(reader io.Reader, fn func(path string, size int64, reader io.Reader) error) (err error) {
+	zipReader, err := r.decompressor(reader)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = zipReader.Close()
+	}()
+	tarReader := tar.NewReader(zipReader)
+	for {
+		header, nErr := tarReader.Next()
+		if nErr != nil {
+			if nErr == io.EOF {
+				break
+			} else {
+				err = liberr.Wrap(nErr)
+				return
+			}
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		path := pathlib.Clean("/" + header.Name)
+		if !r.Filter.Match(path) {
+			continue
+		}
+		err = fn(path, header.Size, tarReader)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+//
+// decompressor returns the decompressing reader.
+// The compression (gzip|zstd) is detected.
+func (r *Reader) decompressor(reader io.Reader) (zipReader io.ReadCloser, err error) {
+	bufReader := bufio.NewReader(reader)
+	magic, _ := bufReader.Peek(len(zstdMagic))
+	if bytes.Equal(magic, zstdMagic) {
+		var zReader *zstd.Decoder
+		zReader, err = zstd.NewReader(bufReader)
+		if err != nil {
+			err = liberr.Wrap(err)
+			return
+		}
+		zipReader = zReader.IOReadCloser()
+		return
+	}
+	zipReader, err = gzip.NewReader(bufReader)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
@@ -1,14 +1,17 @@
 package tar
 
 import (
+	"bytes"
 	liberr "github.com/jortel/go-utils/error"
 	"github.com/konveyor/tackle2-hub/nas"
 	"github.com/konveyor/tackle2-hub/test/assert"
 	"github.com/onsi/gomega"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestWriter(t *testing.T) {
@@ -59,3 +62,112 @@ func TestWriter(t *testing.T) {
 	// Validate ./data/pet/rabbit
 	_ = assert.EqualFileContent("./data/rabbit", path.Join(tmpDir, "data", "pet", "rabbit"))
 }
+
+func TestWriterStream(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	// Setup
+	tmpDir, err := os.MkdirTemp("", "tar-*")
+	g.Expect(err).To(gomega.BeNil())
+	defer func() {
+		_ = nas.RmDir(tmpDir)
+	}()
+	outPath := path.Join(tmpDir, "output.tar.gz")
+	file, err := os.Create(outPath)
+	g.Expect(err).To(gomega.BeNil())
+
+	// Write the stream => stream.
+	content := []byte("hello world")
+	writer := NewWriter(file)
+	err = writer.AddStream(
+		"stream",
+		int64(len(content)),
+		func(w io.Writer) (err error) {
+			_, err = w.Write(content)
+			return
+		})
+	g.Expect(err).To(gomega.BeNil())
+	writer.Close()
+	_ = file.Close()
+
+	// Read/expand the tarball.
+	reader := NewReader()
+	file, err = os.Open(outPath)
+	g.Expect(err).To(gomega.BeNil())
+	err = reader.Extract(tmpDir, file)
+	g.Expect(err).To(gomega.BeNil())
+	b, err := os.ReadFile(path.Join(tmpDir, "stream"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(b).To(gomega.Equal(content))
+}
+
+func TestWalk(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	// Write dir entry and stream => sub/stream.
+	content := []byte("hello world")
+	bfr := &bytes.Buffer{}
+	writer := NewWriter(bfr)
+	err := writer.AddDirEntry("/sub", time.Now())
+	g.Expect(err).To(gomega.BeNil())
+	err = writer.AddStream(
+		"/sub/stream",
+		int64(len(content)),
+		func(w io.Writer) (err error) {
+			_, err = w.Write(content)
+			return
+		})
+	g.Expect(err).To(gomega.BeNil())
+	writer.Close()
+	tarball := bfr.Bytes()
+
+	// Expand the tarball.
+	tmpDir := t.TempDir()
+	reader := NewReader()
+	err = reader.Extract(tmpDir, bytes.NewReader(tarball))
+	g.Expect(err).To(gomega.BeNil())
+	b, err := os.ReadFile(path.Join(tmpDir, "sub", "stream"))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(b).To(gomega.Equal(content))
+
+	// Walk the tarball.
+	walked := make(map[string][]byte)
+	err = reader.Walk(
+		bytes.NewReader(tarball),
+		func(path string, size int64, r io.Reader) (err error) {
+			walked[path], err = io.ReadAll(r)
+			return
+		})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(walked).To(gomega.Equal(map[string][]byte{"/sub/stream": content}))
+}
+
+func TestCompression(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(Negotiate("")).To(gomega.Equal(Gzip))
+	g.Expect(Negotiate("br, ZSTD, gzip")).To(gomega.Equal(Zstd))
+	g.Expect(Negotiate("gzip,zstd")).To(gomega.Equal(Gzip))
+	g.Expect(Extension(Zstd)).To(gomega.Equal(".tar.zst"))
+	content := []byte("hello world")
+	for _, compression := range []string{Gzip, Zstd} {
+		bfr := &bytes.Buffer{}
+		writer := NewWriterWith(bfr, compression)
+		err := writer.AddStream(
+			"/stream",
+			int64(len(content)),
+			func(w io.Writer) (err error) {
+				_, err = w.Write(content)
+				return
+			})
+		g.Expect(err).To(gomega.BeNil())
+		writer.Close()
+		walked := make(map[string][]byte)
+		reader := NewReader()
+		err = reader.Walk(
+			bytes.NewReader(bfr.Bytes()),
+			func(path string, size int64, r io.Reader) (err error) {
+				walked[path], err = io.ReadAll(r)
+				return
+			})
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(walked).To(gomega.Equal(map[string][]byte{"/stream": content}))
+	}
+}
@@ -4,17 +4,26 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	liberr "github.com/jortel/go-utils/error"
+	"github.com/klauspost/compress/zstd"
 	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 )
 
 //
-// NewWriter returns a new writer.
+// NewWriter returns a new (gzip) writer.
 func NewWriter(output io.Writer) (writer *Writer) {
-	writer = &Writer{}
+	writer = NewWriterWith(output, Gzip)
+	return
+}
+
+//
+// NewWriterWith returns a new writer using the compression.
+func NewWriterWith(output io.Writer, compression string) (writer *Writer) {
+	writer = &Writer{Compression: compression}
 	writer.Open(output)
 	runtime.SetFinalizer(
 		writer,
@@ -25,9 +34,11 @@ func NewWriter(output io.Writer) (writer *Writer) {
 }
 
 //
-// Writer is a Zipped TAR streamed writer.
+// Writer is a compressed TAR streamed writer.
 type Writer struct {
 	Filter Filter
+	// Compression (gzip|zstd). Default: gzip.
+	Compression string
 	//
 	drained   chan int
 	tarWriter *tar.Writer
@@ -46,7 +57,7 @@ func (r *Writer) Open(output io.Writer) {
 	r.drained = make(chan int)
 	r.bridge.reader, r.bridge.writer = io.Pipe()
 	r.tarWriter = tar.NewWriter(r.bridge.writer)
-	zipWriter := gzip.NewWriter(output)
+	zipWriter := r.compressor(output)
 	go func() {
 		defer func() {
 			_ = zipWriter.Close()
@@ -56,6 +67,21 @@ func (r *Writer) Open(output io.Writer) {
 	}()
 }
 
+//
+// compressor returns the compressing writer.
+func (r *Writer) compressor(output io.Writer) (writer io.WriteCloser) {
+	switch r.Compression {
+	case Zstd:
+		zWriter, err := zstd.NewWriter(output)
+		if err == nil {
+			writer = zWriter
+			return
+		}
+	}
+	writer = gzip.NewWriter(output)
+	return
+}
+
 //
 // AssertDir validates the path is a readable directory.
 func (r *Writer) AssertDir(pathIn string) (err error) {
@@ -226,6 +252,56 @@ func (r *Writer) AddFile(pathIn, destPath string) (err error) {
 	return
 }
 
+//
+// AddStream adds a file with content written by the function.
+// The size must be known in advance; the function must write
+// exactly size bytes.
+func (r *Writer) AddStream(destPath string, size int64, fn func(io.Writer) error) (err error) {
+	if r.tarWriter == nil {
+		err = liberr.New("Writer not open.")
+		return
+	}
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     destPath,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  time.Now(),
+	}
+	err = r.tarWriter.WriteHeader(header)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	err = fn(r.tarWriter)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// AddDirEntry adds a directory entry.
+func (r *Writer) AddDirEntry(destPath string, modTime time.Time) (err error) {
+	if r.tarWriter == nil {
+		err = liberr.New("Writer not open.")
+		return
+	}
+	header := &tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     destPath,
+		Mode:     0777,
+		ModTime:  modTime,
+	}
+	err = r.tarWriter.WriteHeader(header)
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
 //
 // Close the writer.
 func (r *Writer) Close() {
@@ -0,0 +1,206 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/metrics"
+	"github.com/konveyor/tackle2-hub/model"
+	"github.com/konveyor/tackle2-hub/nas"
+	"gorm.io/gorm"
+	"os"
+	"path"
+	"regexp"
+	"time"
+)
+
+//
+// DefaultCache the cache volume mounted (shared) by
+// tasks that do not request cache volumes.
+const DefaultCache = "default"
+
+//
+// cacheName valid cache volume name.
+var cacheName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+//
+// CacheNotValid reports an invalid cache mount.
+type CacheNotValid struct {
+	Name   string
+	Reason string
+}
+
+func (e *CacheNotValid) Error() string {
+	return fmt.Sprintf("Cache: '%s' not valid: %s", e.Name, e.Reason)
+}
+
+func (e *CacheNotValid) Is(err error) (matched bool) {
+	_, matched = err.(*CacheNotValid)
+	return
+}
+
+//
+// Caches returns the cache volumes requested by the task.
+func Caches(m *model.Task) (mounts []model.CacheMount) {
+	if m.Caches != nil {
+		_ = json.Unmarshal(m.Caches, &mounts)
+	}
+	return
+}
+
+//
+// Mounts returns the cache volumes mounted by the task.
+// Tasks that do not request cache volumes mount the
+// default volume.
+func Mounts(m *model.Task) (mounts []model.CacheMount) {
+	mounts = Caches(m)
+	if len(mounts) == 0 {
+		mounts = []model.CacheMount{
+			{Name: DefaultCache},
+		}
+	}
+	return
+}
+
+//
+// ValidateCaches validates the requested cache volumes.
+// The name must be a (single) path segment, not reserved
+// and requested once.
+func ValidateCaches(mounts []model.CacheMount) (err error) {
+	requested := make(map[string]bool)
+	for _, mount := range mounts {
+		if !cacheName.MatchString(mount.Name) {
+			err = &CacheNotValid{
+				Name:   mount.Name,
+				Reason: "must match: " + cacheName.String(),
+			}
+			return
+		}
+		if mount.Name == DefaultCache {
+			err = &CacheNotValid{
+				Name:   mount.Name,
+				Reason: "reserved.",
+			}
+			return
+		}
+		if requested[mount.Name] {
+			err = &CacheNotValid{
+				Name:   mount.Name,
+				Reason: "requested more than once.",
+			}
+			return
+		}
+		requested[mount.Name] = true
+	}
+	return
+}
+
+//
+// CachePath returns the path of the (named) cache volume.
+func CachePath(name string) string {
+	return path.Join(Settings.Cache.Path, name)
+}
+
+//
+// lease grants the cache volume leases to the task.
+// Volumes (and the directories) are created as needed. The
+// lease is a hit when the volume has content.
+func (m *Manager) lease(task *model.Task) (err error) {
+	mounts := Mounts(task)
+	err = m.DB.Transaction(
+		func(tx *gorm.DB) (err error) {
+			for _, mount := range mounts {
+				volume := &model.CacheVolume{}
+				err = tx.Where("Name", mount.Name).FirstOrCreate(volume, model.CacheVolume{Name: mount.Name}).Error
+				if err != nil {
+					return
+				}
+				dir := CachePath(mount.Name)
+				if occupied(dir) {
+					volume.Hits++
+					metrics.CacheHits.WithLabelValues(volume.Name).Inc()
+				} else {
+					volume.Misses++
+					metrics.CacheMisses.WithLabelValues(volume.Name).Inc()
+				}
+				err = nas.MkDir(dir, 0777)
+				if err != nil {
+					return
+				}
+				volume.LastUsed = time.Now()
+				err = tx.Save(volume).Error
+				if err != nil {
+					return
+				}
+				lease := &model.CacheLease{
+					CacheVolumeID: volume.ID,
+					TaskID:        task.ID,
+					ReadOnly:      mount.ReadOnly,
+				}
+				err = tx.Create(lease).Error
+				if err != nil {
+					return
+				}
+			}
+			return
+		})
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	Log.V(1).Info("Cache leased.", "id", task.ID, "caches", mounts)
+	return
+}
+
+//
+// release the cache volume leases held by the task.
+func (m *Manager) release(task *model.Task) (err error) {
+	err = m.DB.Delete(&model.CacheLease{}, "TaskID", task.ID).Error
+	if err != nil {
+		err = liberr.Wrap(err)
+		return
+	}
+	return
+}
+
+//
+// releaseCaches releases the cache volume leases held by
+// tasks no longer pending or running.
+func (m *Manager) releaseCaches() {
+	active := m.DB.Model(&model.Task{})
+	active = active.Select("ID")
+	active = active.Where("State IN ?", []string{Pending, Running})
+	var leases []model.CacheLease
+	err := m.DB.Find(&leases, "TaskID NOT IN (?)", active).Error
+	if err != nil {
+		Log.Error(err, "")
+		return
+	}
+	if len(leases) == 0 {
+		return
+	}
+	var ids []uint
+	for _, lease := range leases {
+		ids = append(ids, lease.CacheVolumeID)
+	}
+	db := m.DB.Model(&model.CacheVolume{})
+	err = db.Where("ID IN ?", ids).Update("LastUsed", time.Now()).Error
+	if err != nil {
+		Log.Error(err, "")
+		return
+	}
+	err = m.DB.Delete(&leases).Error
+	if err != nil {
+		Log.Error(err, "")
+		return
+	}
+	Log.V(1).Info("Cache released.", "leases", len(leases))
+}
+
+//
+// occupied determines if the directory has content.
+func occupied(dir string) (b bool) {
+	entries, err := os.ReadDir(dir)
+	b = err == nil && len(entries) > 0
+	return
+}
@@ -0,0 +1,332 @@
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	liberr "github.com/jortel/go-utils/error"
+	"github.com/konveyor/tackle2-hub/model"
+	"gorm.io/gorm"
+	"sort"
+	"time"
+)
+
+//
+// LifecycleKey is the setting containing the lifecycle policies.
+const LifecycleKey = "task.lifecycle"
+
+//
+// Released resources.
+const (
+	ReleasePod    = "pod"
+	ReleaseBucket = "bucket"
+	ReleaseReport = "report"
+)
+
+//
+// InvalidPolicy reports an invalid lifecycle policy.
+type InvalidPolicy struct {
+	Reason string
+}
+
+func (e *InvalidPolicy) Error() string {
+	return "Lifecycle policy invalid: " + e.Reason
+}
+
+func (e *InvalidPolicy) Is(err error) (matched bool) {
+	_, matched = err.(*InvalidPolicy)
+	return
+}
+
+//
+// Lifecycle (task) policies.
+// The rule for a task state is resolved in order: task group,
+// addon, default, built-in. The built-in policy is defined by
+// the TASK_REAP_* settings. The TTL defined on the task
+// overrides the resolved deletion.
+type Lifecycle struct {
+	// Default policy.
+	Default Policy `json:"default"`
+	// Addon policies keyed by addon name.
+	Addon map[string]Policy `json:"addon,omitempty"`
+	// Group policies keyed by task group ID.
+	Group map[uint]Policy `json:"group,omitempty"`
+}
+
+//
+// Policy lifecycle policy.
+type Policy struct {
+	// States rules keyed by task state.
+	States map[string]StateRule `json:"states,omitempty"`
+	// Keep the last (N) terminated tasks per application and
+	// addon. Kept tasks are not deleted. Zero (0) = none.
+	Keep int `json:"keep,omitempty"`
+}
+
+//
+// StateRule defines the lifecycle of a task in a state.
+// Periods (minutes) are measured from when the task was:
+// created (Created|Postponed|Ready|Pending), started (Running)
+// or terminated (Succeeded|Failed|Canceled). Zero (0) = never.
+type StateRule struct {
+	// Release (minutes) after which the resources are released.
+	Release int `json:"release,omitempty"`
+	// Resources (pod|bucket|report) released.
+	// Default: pod,bucket.
+	Resources []string `json:"resources,omitempty"`
+	// Delete (minutes) after which the task is deleted.
+	Delete int `json:"delete,omitempty"`
+}
+
+//
+// Releases determines if the resource is released.
+func (r *StateRule) Releases(resource string) (b bool) {
+	if len(r.Resources) == 0 {
+		b = resource == ReleasePod || resource == ReleaseBucket
+		return
+	}
+	for _, released := range r.Resources {
+		if released == resource {
+			b = true
+			break
+		}
+	}
+	return
+}
+
+//
+// LoadLifecycle loads the lifecycle policies (setting).
+// Not found = no policies defined.
+func LoadLifecycle(db *gorm.DB) (lifecycle Lifecycle, err error) {
+	setting := &model.Setting{}
+	err = db.First(setting, "Key", LifecycleKey).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		} else {
+			err = liberr.Wrap(err)
+		}
+		return
+	}
+	err = lifecycle.With(setting.Value)
+	return
+}
+
+//
+// With decodes and validates the (json) document.
+// Unknown fields are not permitted.
+func (r *Lifecycle) With(document []byte) (err error) {
+	if len(document) == 0 || string(document) == "null" {
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(r)
+	if err != nil {
+		err = &InvalidPolicy{Reason: err.Error()}
+		return
+	}
+	err = r.Validate()
+	return
+}
+
+//
+// Validate the policies.
+func (r *Lifecycle) Validate() (err error) {
+	err = r.Default.validate("default")
+	if err != nil {
+		return
+	}
+	for name, p := range r.Addon {
+		err = p.validate("addon." + name)
+		if err != nil {
+			return
+		}
+	}
+	for id, p := range r.Group {
+		err = p.validate(fmt.Sprintf("group.%d", id))
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+//
+// Rule returns the (resolved) state rule for the task
+// and the number of tasks kept.
+func (r *Lifecycle) Rule(m *model.Task) (rule StateRule, keep int) {
+	policies := []Policy{}
+	if m.TaskGroupID != nil {
+		if p, found := r.Group[*m.TaskGroupID]; found {
+			policies = append(policies, p)
+		}
+	}
+	if p, found := r.Addon[m.Addon]; found {
+		policies = append(policies, p)
+	}
+	policies = append(policies, r.Default, r.builtin())
+	resolved := false
+	for _, p := range policies {
+		if !resolved {
+			rule, resolved = p.States[m.State]
+		}
+		if keep == 0 {
+			keep = p.Keep
+		}
+	}
+	ttl := model.TTL{}
+	if m.TTL != nil {
+		_ = json.Unmarshal(m.TTL, &ttl)
+	}
+	var n int
+	switch m.State {
+	case Created:
+		n = ttl.Created
+	case Postponed:
+		n = ttl.Postponed
+	case Ready:
+		n = ttl.Ready
+	case Pending:
+		n = ttl.Pending
+	case Running:
+		n = ttl.Running
+	case Succeeded:
+		n = ttl.Succeeded
+	case Failed:
+		n = ttl.Failed
+	case Canceled:
+		n = ttl.Canceled
+	}
+	if n > 0 {
+		rule.Delete = n
+	}
+	return
+}
+
+//
+// Kept returns the IDs of tasks kept.
+// The tasks are ranked (most recently terminated first) by
+// application and addon.
+func (r *Lifecycle) Kept(tasks []model.Task) (kept map[uint]bool) {
+	kept = make(map[uint]bool)
+	type Key struct {
+		application uint
+		addon       string
+	}
+	ranked := make(map[Key][]*model.Task)
+	for i := range tasks {
+		m := &tasks[i]
+		if m.ApplicationID == nil || m.Terminated == nil {
+			continue
+		}
+		key := Key{
+			application: *m.ApplicationID,
+			addon:       m.Addon,
+		}
+		ranked[key] = append(ranked[key], m)
+	}
+	for _, list := range ranked {
+		sort.Slice(
+			list,
+			func(i, j int) bool {
+				return list[i].Terminated.After(*list[j].Terminated)
+			})
+		for n, m := range list {
+			_, keep := r.Rule(m)
+			if n < keep {
+				kept[m.ID] = true
+			}
+		}
+	}
+	return
+}
+
+//
+// Mark returns when the task entered the state.
+// Returns nil when not known.
+func (r *Lifecycle) Mark(m *model.Task) (mark *time.Time) {
+	switch m.State {
+	case Created, Postponed, Ready, Pending:
+		mark = &m.CreateTime
+	case Running:
+		mark = m.Started
+	case Succeeded, Failed, Canceled:
+		mark = m.Terminated
+	}
+	return
+}
+
+//
+// builtin returns the built-in policy.
+// Defines a rule for every state. Canceled tasks are released
+// after the same period as failed tasks. Tasks in other states
+// are neither released nor deleted.
+func (r *Lifecycle) builtin() (p Policy) {
+	p.States = map[string]StateRule{
+		Created: {
+			Release: Settings.Hub.Task.Reaper.Created,
+		},
+		Postponed: {},
+		Ready:     {},
+		Pending:   {},
+		Running:   {},
+		Succeeded: {
+			Release: Settings.Hub.Task.Reaper.Succeeded,
+		},
+		Failed: {
+			Release: Settings.Hub.Task.Reaper.Failed,
+		},
+		Canceled: {
+			Release: Settings.Hub.Task.Reaper.Failed,
+		},
+	}
+	return
+}
+
+//
+// validate the policy.
+func (p *Policy) validate(name string) (err error) {
+	if p.Keep < 0 {
+		err = &InvalidPolicy{
+			Reason: name + ".keep must be >= 0.",
+		}
+		return
+	}
+	for state, rule := range p.States {
+		path := name + ".states." + state
+		switch state {
+		case Created, Succeeded, Failed, Canceled:
+		case Postponed, Ready, Pending, Running:
+			if rule.Release > 0 || len(rule.Resources) > 0 {
+				err = &InvalidPolicy{
+					Reason: path + ": release not supported.",
+				}
+				return
+			}
+		default:
+			err = &InvalidPolicy{
+				Reason: path + ": state not supported.",
+			}
+			return
+		}
+		if rule.Release < 0 || rule.Delete < 0 {
+			err = &InvalidPolicy{
+				Reason: path + ": periods must be >= 0.",
+			}
+			return
+		}
+		for _, resource := range rule.Resources {
+			switch resource {
+			case ReleasePod, ReleaseBucket, ReleaseReport:
+			default:
+				err = &InvalidPolicy{
+					Reason: path + ": resource (" + resource + ") not supported.",
+				}
+				return
+			}
+		}
+	}
+	return
+}
@@ -94,6 +94,7 @@ func (m *Manager) Run(ctx context.Context) {
 				return
 			default:
 				m.updateRunning()
+				m.releaseCaches()
 				m.startReady()
 				m.pause()
 			}
@@ -152,12 +153,25 @@ func (m *Manager) startReady() {
 				Log.Error(sErr, "")
 				continue
 			}
+			err := m.lease(ready)
+			if err != nil {
+				Log.Error(err, "Cache not leased.", "id", ready.ID)
+				rErr := m.release(ready)
+				Log.Error(rErr, "")
+				ready.State = Postponed
+				Log.Info("Task postponed.", "id", ready.ID)
+				sErr := m.DB.Save(ready).Error
+				Log.Error(sErr, "")
+				continue
+			}
 			if ready.Retries == 0 {
 				metrics.TasksInitiated.Inc()
 			}
 			rt := Task{ready}
-			err := rt.Run(m.Client)
+			err = rt.Run(m.Client)
 			if err != nil {
+				rErr := m.release(ready)
+				Log.Error(rErr, "")
 				if errors.Is(err, &AddonNotFound{}) {
 					ready.Error("Error", err.Error())
 					ready.State = Failed
@@ -220,6 +234,7 @@ func (m *Manager) postpone(ready *model.Task, list []model.Task) (postponed bool
 	ruleSet := []Rule{
 		&RuleIsolated{},
 		&RuleUnique{},
+		&RuleCache{},
 	}
 	for i := range list {
 		other := &list[i]
@@ -558,12 +573,7 @@ func (r *Task) container(addon *crd.Addon, secret *core.Secret) (container core.
 				},
 			},
 		},
-		VolumeMounts: []core.VolumeMount{
-			{
-				Name:      "cache",
-				MountPath: Settings.Cache.Path,
-			},
-		},
+		VolumeMounts: r.mounts(),
 		SecurityContext: &core.SecurityContext{
 			RunAsUser: &userid,
 		},
@@ -572,6 +582,28 @@ func (r *Task) container(addon *crd.Addon, secret *core.Secret) (container core.
 	return
 }
 
+//
+// mounts builds the container volume mounts.
+// Each volume is mounted (subPath) read-only or read-write.
+// The default volume is mounted at the cache root.
+func (r *Task) mounts() (mounts []core.VolumeMount) {
+	for _, mount := range Mounts(r.Task) {
+		mountPath := CachePath(mount.Name)
+		if mount.Name == DefaultCache {
+			mountPath = Settings.Cache.Path
+		}
+		mounts = append(
+			mounts,
+			core.VolumeMount{
+				Name:      "cache",
+				MountPath: mountPath,
+				SubPath:   mount.Name,
+				ReadOnly:  mount.ReadOnly,
+			})
+	}
+	return
+}
+
 //
 // secret builds the pod secret.
 func (r *Task) secret(addon *crd.Addon) (secret core.Secret) {
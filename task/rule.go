@@ -77,3 +77,42 @@ func (r *RuleIsolated) hasPolicy(task *model.Task, name string) (matched bool) {
 
 	return
 }
+
+//
+// RuleCache cache volume leases.
+// A read-write lease is exclusive. Read-only leases are shared.
+// The default volume is shared.
+type RuleCache struct {
+}
+
+//
+// Match determines the match.
+func (r *RuleCache) Match(candidate, other *model.Task) (matched bool) {
+	leased := make(map[string]bool)
+	for _, mount := range Mounts(other) {
+		leased[mount.Name] = mount.ReadOnly
+	}
+	for _, mount := range Mounts(candidate) {
+		if mount.Name == DefaultCache {
+			continue
+		}
+		readOnly, found := leased[mount.Name]
+		if !found {
+			continue
+		}
+		if !readOnly || !mount.ReadOnly {
+			matched = true
+			Log.Info(
+				"Rule:Cache matched.",
+				"candidate",
+				candidate.ID,
+				"by",
+				other.ID,
+				"cache",
+				mount.Name)
+			break
+		}
+	}
+
+	return
+}